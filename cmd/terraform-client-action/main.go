@@ -14,7 +14,6 @@ import (
 	"github.com/magodo/terraform-client-go/tfclient/configschema"
 	"github.com/magodo/terraform-client-go/tfclient/typ"
 	"github.com/zclconf/go-cty/cty"
-	ctyjson "github.com/zclconf/go-cty/cty/json"
 )
 
 type FlagSet struct {
@@ -82,7 +81,7 @@ func realMain(logger hclog.Logger, fset FlagSet) error {
 		return err
 	}
 
-	config, err := ctyjson.Unmarshal([]byte(fset.ProviderCfg), configschema.SchemaBlockImpliedType(schResp.Provider.Block))
+	config, err := configschema.SchemaBlockUnmarshalJSON(schResp.Provider.Block, []byte(fset.ProviderCfg))
 	if err != nil {
 		return err
 	}
@@ -94,12 +93,12 @@ func realMain(logger hclog.Logger, fset FlagSet) error {
 		return err
 	}
 
-	sch, ok := schResp.ActionsCty[fset.ActionType]
+	sch, ok := schResp.Actions[fset.ActionType]
 	if !ok {
 		return fmt.Errorf("no action named %q", fset.ActionType)
 	}
 
-	body, err := ctyjson.Unmarshal([]byte(fset.Body), sch)
+	body, err := configschema.SchemaBlockUnmarshalJSON(sch.Block, []byte(fset.Body))
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,281 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/magodo/terraform-client-go/internal/cli"
+	"github.com/magodo/terraform-client-go/tfclient"
+	"github.com/magodo/terraform-client-go/tfclient/configschema"
+	"github.com/magodo/terraform-client-go/tfclient/drift"
+	"github.com/magodo/terraform-client-go/tfclient/typ"
+	"github.com/zclconf/go-cty/cty"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
+)
+
+type FlagSet struct {
+	PluginPath     string
+	ProviderSource string
+	LogLevel       string
+	ProviderCfg    string
+	TimeoutSec     int
+	StatePath      string
+	Parallelism    int
+	JSON           bool
+	ShowSensitive  bool
+}
+
+func main() {
+	var fset FlagSet
+	flag.StringVar(&fset.PluginPath, "path", "", "The path to the plugin")
+	flag.StringVar(&fset.ProviderSource, "source", "", `The provider source address (e.g. "hashicorp/azurerm"). Only the resources of this provider are checked. Defaults to the resources whose types are defined by the provider`)
+	flag.StringVar(&fset.LogLevel, "log-level", hclog.Error.String(), "Log level")
+	flag.StringVar(&fset.ProviderCfg, "cfg", "{}", "The content of provider config block in JSON")
+	flag.IntVar(&fset.TimeoutSec, "timeout", 0, "Timeout in second. Defaults to no timeout.")
+	flag.StringVar(&fset.StatePath, "state", "terraform.tfstate", "The path to the state file")
+	flag.IntVar(&fset.Parallelism, "parallelism", 10, "The number of resources refreshed concurrently")
+	flag.BoolVar(&fset.JSON, "json", false, "Output in JSON")
+	flag.BoolVar(&fset.ShowSensitive, "show-sensitive", false, "Show the sensitive values in the changes, which are redacted by default")
+
+	flag.Parse()
+
+	logger := hclog.New(&hclog.LoggerOptions{
+		Output: hclog.DefaultOutput,
+		Level:  hclog.LevelFromString(fset.LogLevel),
+		Name:   filepath.Base(fset.PluginPath),
+	})
+
+	code, err := realMain(logger, fset)
+	if err != nil {
+		logger.Error(err.Error())
+		os.Exit(cli.ExitError)
+	}
+	os.Exit(code)
+}
+
+// exitDrifted is the exit code when any drift is detected.
+const exitDrifted = 2
+
+// realMain returns the exit code, which is 1 if any resource fails to be checked, otherwise 2 if any
+// drift is detected.
+func realMain(logger hclog.Logger, fset FlagSet) (int, error) {
+	b, err := os.ReadFile(fset.StatePath)
+	if err != nil {
+		return 0, err
+	}
+	resources, err := drift.LoadStateV4(b)
+	if err != nil {
+		return 0, err
+	}
+
+	opts := tfclient.Option{
+		Cmd:           exec.Command(fset.PluginPath),
+		Logger:        logger,
+		MarkSensitive: true,
+	}
+
+	reattach, err := tfclient.ParseReattach(os.Getenv("TF_REATTACH_PROVIDERS"))
+	if err != nil {
+		return 0, err
+	}
+	if reattach != nil {
+		opts.Cmd = nil
+		opts.Reattach = reattach
+	}
+
+	c, err := tfclient.New(opts)
+	if err != nil {
+		return 0, err
+	}
+	defer c.Close()
+
+	ctx := context.TODO()
+	var cancel context.CancelFunc
+	if fset.TimeoutSec > 0 {
+		ctx, cancel = context.WithTimeout(ctx, time.Second*time.Duration(fset.TimeoutSec))
+		defer cancel()
+	}
+
+	schResp, diags := c.GetProviderSchema()
+	if err := cli.ShowDiags(logger, diags); err != nil {
+		return 0, err
+	}
+
+	config, err := configschema.SchemaBlockUnmarshalJSON(schResp.Provider.Block, []byte(fset.ProviderCfg))
+	if err != nil {
+		return 0, err
+	}
+	_, diags = c.ConfigureProvider(ctx, typ.ConfigureProviderRequest{
+		Config: config,
+	})
+	if err := cli.ShowDiags(logger, diags); err != nil {
+		return 0, err
+	}
+
+	var source string
+	if fset.ProviderSource != "" {
+		source, err = tfclient.NormalizeProviderSource(fset.ProviderSource)
+		if err != nil {
+			return 0, err
+		}
+	}
+	var targets []drift.Resource
+	for _, res := range resources {
+		if source != "" && res.ProviderSource() != source {
+			continue
+		}
+		if _, ok := schResp.ResourceTypes[res.Type]; !ok {
+			logger.Debug("skipping resource of other providers", "address", res.Address)
+			continue
+		}
+		targets = append(targets, res)
+	}
+
+	results := drift.Detect(ctx, c, targets, drift.Option{Parallelism: fset.Parallelism})
+
+	var drifted, failed bool
+	for _, result := range results {
+		switch result.Status {
+		case drift.StatusDrifted, drift.StatusGone:
+			drifted = true
+		case drift.StatusError:
+			failed = true
+		}
+		for i, change := range result.Changes {
+			result.Changes[i].Before = cli.OutputValue(change.Before, fset.ShowSensitive)
+			result.Changes[i].After = cli.OutputValue(change.After, fset.ShowSensitive)
+		}
+	}
+	code := cli.ExitOK
+	switch {
+	case failed:
+		code = cli.ExitError
+	case drifted:
+		code = exitDrifted
+	}
+	if fset.JSON {
+		return code, writeJSON(os.Stdout, results)
+	}
+	writeHuman(os.Stdout, results)
+	return code, nil
+}
+
+type resultJSON struct {
+	Address     string       `json:"address"`
+	Type        string       `json:"type"`
+	Status      drift.Status `json:"status"`
+	Changes     []changeJSON `json:"changes,omitempty"`
+	Diagnostics []diagJSON   `json:"diagnostics,omitempty"`
+}
+
+type changeJSON struct {
+	Path   string          `json:"path"`
+	Before json.RawMessage `json:"before"`
+	After  json.RawMessage `json:"after"`
+}
+
+type diagJSON struct {
+	Severity  string `json:"severity"`
+	Summary   string `json:"summary"`
+	Detail    string `json:"detail,omitempty"`
+	Attribute string `json:"attribute,omitempty"`
+}
+
+func writeJSON(w io.Writer, results []drift.Result) error {
+	out := []resultJSON{}
+	for _, result := range results {
+		res := resultJSON{
+			Address: result.Resource.Address,
+			Type:    result.Resource.Type,
+			Status:  result.Status,
+		}
+		for _, change := range result.Changes {
+			before, err := ctyjson.Marshal(change.Before, change.Before.Type())
+			if err != nil {
+				return fmt.Errorf("marshalling the change of %s: %v", result.Resource.Address, err)
+			}
+			after, err := ctyjson.Marshal(change.After, change.After.Type())
+			if err != nil {
+				return fmt.Errorf("marshalling the change of %s: %v", result.Resource.Address, err)
+			}
+			res.Changes = append(res.Changes, changeJSON{
+				Path:   formatPath(change.Path),
+				Before: before,
+				After:  after,
+			})
+		}
+		for _, d := range result.Diagnostics {
+			sev := "error"
+			if d.Severity == typ.Warning {
+				sev = "warning"
+			}
+			res.Diagnostics = append(res.Diagnostics, diagJSON{
+				Severity:  sev,
+				Summary:   d.Summary,
+				Detail:    d.Detail,
+				Attribute: typ.FormatCtyPath(d.Attribute),
+			})
+		}
+		out = append(out, res)
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
+
+func writeHuman(w io.Writer, results []drift.Result) {
+	counts := map[drift.Status]int{}
+	for _, result := range results {
+		counts[result.Status]++
+		switch result.Status {
+		case drift.StatusInSync:
+			fmt.Fprintf(w, "%s: in sync\n", result.Resource.Address)
+		case drift.StatusGone:
+			fmt.Fprintf(w, "%s: gone\n", result.Resource.Address)
+		case drift.StatusDrifted:
+			fmt.Fprintf(w, "%s: drifted\n", result.Resource.Address)
+			for _, change := range result.Changes {
+				fmt.Fprintf(w, "  ~ %s: %s -> %s\n", formatPath(change.Path), formatValue(change.Before), formatValue(change.After))
+			}
+		case drift.StatusError:
+			fmt.Fprintf(w, "%s: error\n", result.Resource.Address)
+		}
+		for _, d := range result.Diagnostics {
+			sev := "Error"
+			if d.Severity == typ.Warning {
+				sev = "Warning"
+			}
+			fmt.Fprintf(w, "  %s: %s", sev, d.Summary)
+			if d.Detail != "" {
+				fmt.Fprintf(w, ": %s", d.Detail)
+			}
+			fmt.Fprintln(w)
+		}
+	}
+	fmt.Fprintf(w, "\n%d resources: %d in sync, %d drifted, %d gone, %d errors\n",
+		len(results), counts[drift.StatusInSync], counts[drift.StatusDrifted], counts[drift.StatusGone], counts[drift.StatusError])
+}
+
+// formatPath formats the path without the leading dot.
+func formatPath(p cty.Path) string {
+	return strings.TrimPrefix(typ.FormatCtyPath(p), ".")
+}
+
+// formatValue formats the value in HCL, in a single line.
+func formatValue(v cty.Value) string {
+	lines := strings.Split(string(hclwrite.TokensForValue(v).Bytes()), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSpace(line)
+	}
+	return strings.Join(lines, " ")
+}
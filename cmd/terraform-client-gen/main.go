@@ -0,0 +1,143 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/magodo/terraform-client-go/tfclient"
+	"github.com/magodo/terraform-client-go/tfclient/codegen"
+	"github.com/magodo/terraform-client-go/tfclient/providerschema"
+	"github.com/magodo/terraform-client-go/tfclient/typ"
+)
+
+type FlagSet struct {
+	PluginPath         string
+	SchemaFile         string
+	ProviderAddr       string
+	LogLevel           string
+	PackageName        string
+	Resources          stringSlice
+	DataSources        stringSlice
+	EphemeralResources stringSlice
+	Functions          stringSlice
+	OutputFile         string
+}
+
+type stringSlice []string
+
+func (l *stringSlice) String() string {
+	return fmt.Sprintf("[%s]", strings.Join(*l, ", "))
+}
+
+func (l *stringSlice) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+func main() {
+	var fset FlagSet
+	flag.StringVar(&fset.PluginPath, "path", "", "The path to the plugin")
+	flag.StringVar(&fset.SchemaFile, "schema", "", "The path to the schema document dumped by terraform-client-schema (or `terraform providers schema -json`), used instead of -path")
+	flag.StringVar(&fset.ProviderAddr, "addr", "", "The provider source address in the schema document. Can be omitted if the document only contains one provider")
+	flag.StringVar(&fset.LogLevel, "log-level", hclog.Error.String(), "Log level")
+	flag.StringVar(&fset.PackageName, "pkg", "", "The package name of the generated code")
+	flag.Var(&fset.Resources, "resource", "The resource type to generate (can be specified multiple times)")
+	flag.Var(&fset.DataSources, "data-source", "The data source type to generate (can be specified multiple times)")
+	flag.Var(&fset.EphemeralResources, "ephemeral-resource", "The ephemeral resource type to generate (can be specified multiple times)")
+	flag.Var(&fset.Functions, "function", "The function to generate (can be specified multiple times)")
+	flag.StringVar(&fset.OutputFile, "o", "", "The file to write the generated code to. Defaults to stdout")
+
+	flag.Parse()
+
+	logger := hclog.New(&hclog.LoggerOptions{
+		Output: hclog.DefaultOutput,
+		Level:  hclog.LevelFromString(fset.LogLevel),
+		Name:   filepath.Base(fset.PluginPath),
+	})
+
+	if err := realMain(logger, fset); err != nil {
+		logger.Error(err.Error())
+		os.Exit(1)
+	}
+}
+
+func realMain(logger hclog.Logger, fset FlagSet) error {
+	if fset.PackageName == "" {
+		return fmt.Errorf("-pkg is required")
+	}
+
+	schResp, err := loadSchema(logger, fset)
+	if err != nil {
+		return err
+	}
+
+	src, err := codegen.Generate(schResp, codegen.Options{
+		PackageName:        fset.PackageName,
+		Resources:          fset.Resources,
+		DataSources:        fset.DataSources,
+		EphemeralResources: fset.EphemeralResources,
+		Functions:          fset.Functions,
+	})
+	if err != nil {
+		return err
+	}
+
+	if fset.OutputFile == "" {
+		fmt.Print(string(src))
+		return nil
+	}
+	return os.WriteFile(fset.OutputFile, src, 0644)
+}
+
+func loadSchema(logger hclog.Logger, fset FlagSet) (*typ.GetProviderSchemaResponse, error) {
+	if fset.SchemaFile != "" {
+		b, err := os.ReadFile(fset.SchemaFile)
+		if err != nil {
+			return nil, err
+		}
+		return providerschema.Unmarshal(b, fset.ProviderAddr)
+	}
+
+	opts := tfclient.Option{
+		Cmd:    exec.Command(fset.PluginPath),
+		Logger: logger,
+	}
+
+	reattach, err := tfclient.ParseReattach(os.Getenv("TF_REATTACH_PROVIDERS"))
+	if err != nil {
+		return nil, err
+	}
+	if reattach != nil {
+		opts.Cmd = nil
+		opts.Reattach = reattach
+	}
+
+	c, err := tfclient.New(opts)
+	if err != nil {
+		return nil, err
+	}
+	defer c.Close()
+
+	schResp, diags := c.GetProviderSchema()
+	if err := showDiags(logger, diags); err != nil {
+		return nil, err
+	}
+	return schResp, nil
+}
+
+func showDiags(logger hclog.Logger, diags typ.Diagnostics) error {
+	for _, diag := range diags {
+		if diag.Severity == typ.Error {
+			return fmt.Errorf("%s: %s", diag.Summary, diag.Detail)
+		}
+	}
+	if len(diags) != 0 {
+		logger.Warn(diags.Err().Error())
+	}
+	return nil
+}
@@ -11,10 +11,15 @@ import (
 
 	jsonpatch "github.com/evanphx/json-patch/v5"
 	"github.com/hashicorp/go-hclog"
+	tfjson "github.com/hashicorp/terraform-json"
+	"github.com/magodo/terraform-client-go/internal/cli"
 	"github.com/magodo/terraform-client-go/tfclient"
 	"github.com/magodo/terraform-client-go/tfclient/configschema"
+	"github.com/magodo/terraform-client-go/tfclient/genconfig"
 	"github.com/magodo/terraform-client-go/tfclient/typ"
+	"github.com/magodo/terraform-client-go/tfclient/valuepath"
 	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/convert"
 	ctyjson "github.com/zclconf/go-cty/cty/json"
 )
 
@@ -34,14 +39,76 @@ func (pl *JSONPatches) Set(value string) error {
 
 }
 
+// StateSet is an assignment of the state, whose path is parsed by valuepath.Parse, and the value is in JSON.
+type StateSet struct {
+	Path  cty.Path
+	Value string
+	raw   string
+}
+
+type StateSets []StateSet
+
+func (sl *StateSets) String() string {
+	var out []string
+	for _, s := range *sl {
+		out = append(out, s.raw)
+	}
+	return fmt.Sprint(out)
+}
+
+func (sl *StateSets) Set(value string) error {
+	p, v, ok := splitAssignment(value)
+	if !ok {
+		return fmt.Errorf("invalid assignment %s, expect path=value", value)
+	}
+	path, err := valuepath.Parse(p)
+	if err != nil {
+		return err
+	}
+	*sl = append(*sl, StateSet{Path: path, Value: v, raw: value})
+	return nil
+}
+
+// splitAssignment splits the "path=value" at the first "=" that is not inside the brackets, braces, parentheses or
+// the quoted strings of the path, as the set elements are addressed by value, e.g. `rule[{name = "a"}].value=...`.
+func splitAssignment(s string) (string, string, bool) {
+	var (
+		depth   int
+		quoted  bool
+		escaped bool
+	)
+	for i, r := range s {
+		switch {
+		case escaped:
+			escaped = false
+		case quoted && r == '\\':
+			escaped = true
+		case r == '"':
+			quoted = !quoted
+		case quoted:
+		case r == '[' || r == '{' || r == '(':
+			depth++
+		case r == ']' || r == '}' || r == ')':
+			depth--
+		case r == '=' && depth == 0:
+			return s[:i], s[i+1:], true
+		}
+	}
+	return "", "", false
+}
+
 type FlagSet struct {
-	PluginPath   string
-	ResourceType string
-	ResourceId   string
-	LogLevel     string
-	ProviderCfg  string
-	StatePatches JSONPatches
-	TimeoutSec   int
+	PluginPath    string
+	ResourceType  string
+	ResourceId    string
+	LogLevel      string
+	ProviderCfg   string
+	StatePatches  JSONPatches
+	StateSets     StateSets
+	TimeoutSec    int
+	GenConfig     bool
+	ResourceName  string
+	ShowSensitive bool
 }
 
 func main() {
@@ -52,7 +119,12 @@ func main() {
 	flag.StringVar(&fset.LogLevel, "log-level", hclog.Error.String(), "Log level")
 	flag.StringVar(&fset.ProviderCfg, "cfg", "{}", "The content of provider config block in JSON")
 	flag.Var(&fset.StatePatches, "state-patch", "The JSON patch to the state after importing, which will then be used as the prior state for reading. Can be specified multiple times")
+	flag.Var(&fset.StateSets, "set", `The assignment of the state after importing (and patching), in the form of "path=value", where the path is a Terraform-style traversal (e.g. a.b[0], tags["env"], or rule[{name = "x"}] for a set element), and the value is in JSON of the addressed type, where the optional attributes and the nested blocks of a block can be omitted, and the values of compatible types are converted (e.g. a number in a string). The resulting state will then be used as the prior state for reading. Can be specified multiple times`)
 	flag.IntVar(&fset.TimeoutSec, "timeout", 0, "Timeout in second. Defaults to no timeout.")
+	flag.BoolVar(&fset.GenConfig, "generate-config", false, "Output the HCL configuration of the resource, instead of the state in JSON")
+	flag.StringVar(&fset.ResourceName, "name", "this", "The resource name used in the generated configuration")
+
+	flag.BoolVar(&fset.ShowSensitive, "show-sensitive", false, "Show the sensitive values in the output, which are redacted by default")
 
 	flag.Parse()
 
@@ -70,8 +142,9 @@ func main() {
 
 func realMain(logger hclog.Logger, fset FlagSet) error {
 	opts := tfclient.Option{
-		Cmd:    exec.Command(fset.PluginPath),
-		Logger: logger,
+		Cmd:           exec.Command(fset.PluginPath),
+		Logger:        logger,
+		MarkSensitive: true,
 	}
 
 	reattach, err := tfclient.ParseReattach(os.Getenv("TF_REATTACH_PROVIDERS"))
@@ -101,7 +174,7 @@ func realMain(logger hclog.Logger, fset FlagSet) error {
 		return err
 	}
 
-	config, err := ctyjson.Unmarshal([]byte(fset.ProviderCfg), configschema.SchemaBlockImpliedType(schResp.Provider.Block))
+	config, err := configschema.SchemaBlockUnmarshalJSON(schResp.Provider.Block, []byte(fset.ProviderCfg))
 	if err != nil {
 		return err
 	}
@@ -129,6 +202,8 @@ func realMain(logger hclog.Logger, fset FlagSet) error {
 	state := res.State
 	if fset.StatePatches != nil {
 		for _, patch := range fset.StatePatches {
+			// The marks can't be marshalled, while the state is marked again by the provider read below.
+			state, _ = state.UnmarkDeep()
 			b, err := ctyjson.Marshal(state, state.Type())
 			if err != nil {
 				return fmt.Errorf("marshalling the state: %v", err)
@@ -144,6 +219,28 @@ func realMain(logger hclog.Logger, fset FlagSet) error {
 		}
 	}
 
+	if len(fset.StateSets) != 0 {
+		sch, ok := schResp.ResourceTypes[res.TypeName]
+		if !ok {
+			return fmt.Errorf("no schema found for resource type %q", res.TypeName)
+		}
+		ty := configschema.SchemaBlockImpliedType(sch.Block)
+		for _, set := range fset.StateSets {
+			path, vty, err := valuepath.Resolve(ty, set.Path)
+			if err != nil {
+				return fmt.Errorf("resolving the path of %s: %s", set.raw, typ.FormatError(err))
+			}
+			v, err := decodeSetValue(sch.Block, path, vty, set.Value)
+			if err != nil {
+				return fmt.Errorf("unmarshalling the value of %s: %v", set.raw, err)
+			}
+			state, err = valuepath.Set(state, path, v)
+			if err != nil {
+				return fmt.Errorf("setting the state by %s: %s", set.raw, typ.FormatError(err))
+			}
+		}
+	}
+
 	readResp, diags := c.ReadResource(ctx, typ.ReadResourceRequest{
 		TypeName:     res.TypeName,
 		PriorState:   state,
@@ -154,7 +251,17 @@ func realMain(logger hclog.Logger, fset FlagSet) error {
 		return err
 	}
 
-	b, err := ctyjson.Marshal(readResp.NewState, configschema.SchemaBlockImpliedType(schResp.ResourceTypes[fset.ResourceType].Block))
+	if fset.GenConfig {
+		b, err := genconfig.GenerateResource(fset.ResourceType, fset.ResourceName, schResp.ResourceTypes[fset.ResourceType].Block, readResp.NewState)
+		if err != nil {
+			return err
+		}
+		fmt.Print(string(b))
+		return nil
+	}
+
+	out := cli.OutputValue(readResp.NewState, fset.ShowSensitive)
+	b, err := ctyjson.Marshal(out, out.Type())
 	if err != nil {
 		return err
 	}
@@ -163,6 +270,54 @@ func realMain(logger hclog.Logger, fset FlagSet) error {
 	return nil
 }
 
+// decodeSetValue decodes the JSON encoded value of the path in the state of the block. If the path addresses a
+// nested block, the value is decoded by configschema.SchemaBlockUnmarshalJSON, where the optional attributes and
+// the nested blocks can be omitted. Otherwise, the value is decoded as of its implied type, and converted to the type.
+func decodeSetValue(block *tfjson.SchemaBlock, path cty.Path, ty cty.Type, value string) (cty.Value, error) {
+	if b := blockByPath(block, path); b != nil {
+		return configschema.SchemaBlockUnmarshalJSON(b, []byte(value))
+	}
+	vty, err := ctyjson.ImpliedType([]byte(value))
+	if err != nil {
+		return cty.NilVal, err
+	}
+	v, err := ctyjson.Unmarshal([]byte(value), vty)
+	if err != nil {
+		return cty.NilVal, err
+	}
+	return convert.Convert(v, ty)
+}
+
+// blockByPath returns the nested block (or the block itself) addressed by the resolved path, or nil if the path
+// doesn't address a block, e.g. an attribute, or a collection of nested blocks.
+func blockByPath(block *tfjson.SchemaBlock, path cty.Path) *tfjson.SchemaBlock {
+	for len(path) != 0 {
+		step, ok := path[0].(cty.GetAttrStep)
+		if !ok {
+			return nil
+		}
+		nb, ok := block.NestedBlocks[step.Name]
+		if !ok {
+			return nil
+		}
+		path = path[1:]
+		switch nb.NestingMode {
+		case tfjson.SchemaNestingModeSingle, tfjson.SchemaNestingModeGroup:
+		default:
+			// The next step is the index of the element of the collection.
+			if len(path) == 0 {
+				return nil
+			}
+			path = path[1:]
+		}
+		block = nb.Block
+		if block == nil {
+			block = &tfjson.SchemaBlock{}
+		}
+	}
+	return block
+}
+
 func showDiags(logger hclog.Logger, diags typ.Diagnostics) error {
 	for _, diag := range diags {
 		if diag.Severity == typ.Error {
@@ -10,8 +10,11 @@ import (
 	"time"
 
 	"github.com/hashicorp/go-hclog"
+	"github.com/magodo/terraform-client-go/internal/cli"
 	"github.com/magodo/terraform-client-go/tfclient"
 	"github.com/magodo/terraform-client-go/tfclient/configschema"
+	"github.com/magodo/terraform-client-go/tfclient/genconfig"
+	"github.com/magodo/terraform-client-go/tfclient/marks"
 	"github.com/magodo/terraform-client-go/tfclient/typ"
 	"github.com/zclconf/go-cty/cty"
 	ctyjson "github.com/zclconf/go-cty/cty/json"
@@ -26,6 +29,8 @@ type FlagSet struct {
 	Body            string
 	IncludeResource bool
 	Limit           int
+	GenConfig       bool
+	ShowSensitive   bool
 }
 
 func main() {
@@ -38,6 +43,9 @@ func main() {
 	flag.StringVar(&fset.Body, "body", "{}", "The block body for the list resource")
 	flag.BoolVar(&fset.IncludeResource, "include-resource", false, "Should the provider include the full resource object for each result")
 	flag.IntVar(&fset.Limit, "limit", 100, "The maximum number of results to return. Default: 100.")
+	flag.BoolVar(&fset.GenConfig, "generate-config", false, "Output the HCL configuration of the listed resources, instead of the results in JSON. Requires -include-resource")
+
+	flag.BoolVar(&fset.ShowSensitive, "show-sensitive", false, "Show the sensitive values in the output, which are redacted by default")
 
 	flag.Parse()
 
@@ -55,8 +63,9 @@ func main() {
 
 func realMain(logger hclog.Logger, fset FlagSet) error {
 	opts := tfclient.Option{
-		Cmd:    exec.Command(fset.PluginPath),
-		Logger: logger,
+		Cmd:           exec.Command(fset.PluginPath),
+		Logger:        logger,
+		MarkSensitive: true,
 	}
 
 	reattach, err := tfclient.ParseReattach(os.Getenv("TF_REATTACH_PROVIDERS"))
@@ -86,7 +95,7 @@ func realMain(logger hclog.Logger, fset FlagSet) error {
 		return err
 	}
 
-	config, err := ctyjson.Unmarshal([]byte(fset.ProviderCfg), configschema.SchemaBlockImpliedType(schResp.Provider.Block))
+	config, err := configschema.SchemaBlockUnmarshalJSON(schResp.Provider.Block, []byte(fset.ProviderCfg))
 	if err != nil {
 		return err
 	}
@@ -112,7 +121,11 @@ func realMain(logger hclog.Logger, fset FlagSet) error {
 		return fmt.Errorf("no resource named %q", fset.ResourceType)
 	}
 
-	body, err := ctyjson.Unmarshal([]byte(fset.Body), configschema.SchemaBlockImpliedType(sch.Block))
+	if fset.GenConfig && !fset.IncludeResource {
+		return fmt.Errorf("-generate-config requires -include-resource")
+	}
+
+	body, err := configschema.SchemaBlockUnmarshalJSON(sch.Block, []byte(fset.Body))
 	if err != nil {
 		return err
 	}
@@ -132,11 +145,33 @@ func realMain(logger hclog.Logger, fset FlagSet) error {
 		return fmt.Errorf(`no "data" in the list resource`)
 	}
 
-	b, err := ctyjson.Marshal(datas, cty.List(cty.Object(map[string]cty.Type{
+	if fset.GenConfig {
+		var i int
+		for it := datas.ElementIterator(); it.Next(); i++ {
+			_, data := it.Element()
+			b, err := genconfig.GenerateResource(fset.ResourceType, fmt.Sprintf("res_%d", i), resSch.Block, data.GetAttr("state"))
+			if err != nil {
+				return err
+			}
+			if i != 0 {
+				fmt.Println()
+			}
+			fmt.Print(string(b))
+		}
+		return nil
+	}
+
+	ty := cty.List(cty.Object(map[string]cty.Type{
 		"display_name": cty.String,
 		"state":        resSchCty,
 		"identity":     configschema.SchemaNestedAttributeTypeImpliedType(resSch.Identity),
-	})))
+	}))
+	out := cli.OutputValue(datas, fset.ShowSensitive)
+	if marks.Contains(datas, marks.Sensitive) && !fset.ShowSensitive {
+		// The redacted values change the type.
+		ty = out.Type()
+	}
+	b, err := ctyjson.Marshal(out, ty)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/magodo/terraform-client-go/tfclient"
+	"github.com/magodo/terraform-client-go/tfclient/providerschema"
+	"github.com/magodo/terraform-client-go/tfclient/typ"
+)
+
+type FlagSet struct {
+	PluginPath   string
+	LogLevel     string
+	ProviderAddr string
+	OutputFile   string
+}
+
+func main() {
+	var fset FlagSet
+	flag.StringVar(&fset.PluginPath, "path", "", "The path to the plugin")
+	flag.StringVar(&fset.LogLevel, "log-level", hclog.Error.String(), "Log level")
+	flag.StringVar(&fset.ProviderAddr, "addr", "", `The provider source address used as the key of the schema document (e.g. "registry.terraform.io/hashicorp/azurerm"). Defaults to the address in the TF_REATTACH_PROVIDERS, or the one derived from the plugin path in a provider plugin directory (e.g. ".terraform/providers/registry.terraform.io/hashicorp/azurerm/4.0.0/linux_amd64/terraform-provider-azurerm_v4.0.0")`)
+	flag.StringVar(&fset.OutputFile, "o", "", "The file to write the schema document to. Defaults to stdout")
+
+	flag.Parse()
+
+	logger := hclog.New(&hclog.LoggerOptions{
+		Output: hclog.DefaultOutput,
+		Level:  hclog.LevelFromString(fset.LogLevel),
+		Name:   filepath.Base(fset.PluginPath),
+	})
+
+	if err := realMain(logger, fset); err != nil {
+		logger.Error(err.Error())
+		os.Exit(1)
+	}
+}
+
+func realMain(logger hclog.Logger, fset FlagSet) error {
+	opts := tfclient.Option{
+		Cmd:    exec.Command(fset.PluginPath),
+		Logger: logger,
+	}
+
+	reattaches, err := tfclient.ParseReattachProviders(os.Getenv("TF_REATTACH_PROVIDERS"))
+	if err != nil {
+		return err
+	}
+	if len(reattaches) > 1 {
+		return fmt.Errorf("expect only one of provider specified in the TF_REATTACH_PROVIDERS, got=%d", len(reattaches))
+	}
+
+	addr := fset.ProviderAddr
+	for source, reattach := range reattaches {
+		opts.Cmd = nil
+		opts.Reattach = reattach
+		if addr == "" {
+			if addr, err = tfclient.NormalizeProviderSource(source); err != nil {
+				return err
+			}
+		}
+	}
+	if addr == "" {
+		addr, err = providerAddrFromPath(fset.PluginPath)
+		if err != nil {
+			return err
+		}
+	}
+
+	c, err := tfclient.New(opts)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	schResp, diags := c.GetProviderSchema()
+	if err := showDiags(logger, diags); err != nil {
+		return err
+	}
+
+	b, err := providerschema.Marshal(addr, schResp)
+	if err != nil {
+		return err
+	}
+
+	if fset.OutputFile == "" {
+		fmt.Println(string(b))
+		return nil
+	}
+	return os.WriteFile(fset.OutputFile, b, 0644)
+}
+
+// providerAddrFromPath derives the provider source address from the plugin path, which follows the layout of the
+// provider plugin directories, i.e. "<host>/<namespace>/<type>/<version>/<os>_<arch>/terraform-provider-<type>*".
+// The namespace can't be derived from the plugin file name alone, in which case it is not guessed.
+func providerAddrFromPath(path string) (string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	ptype, ok := strings.CutPrefix(filepath.Base(abs), "terraform-provider-")
+	if ok {
+		ptype, _, _ = strings.Cut(ptype, "_")
+		parts := strings.Split(filepath.ToSlash(filepath.Dir(abs)), "/")
+		if n := len(parts); n >= 5 && parts[n-3] == ptype && strings.Contains(parts[n-1], "_") {
+			return tfclient.NormalizeProviderSource(strings.Join(parts[n-5:n-2], "/"))
+		}
+	}
+	return "", fmt.Errorf("can't derive the provider address from %q, please specify it via -addr", path)
+}
+
+func showDiags(logger hclog.Logger, diags typ.Diagnostics) error {
+	for _, diag := range diags {
+		if diag.Severity == typ.Error {
+			return fmt.Errorf("%s: %s", diag.Summary, diag.Detail)
+		}
+	}
+	if len(diags) != 0 {
+		logger.Warn(diags.Err().Error())
+	}
+	return nil
+}
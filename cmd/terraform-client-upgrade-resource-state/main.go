@@ -15,6 +15,7 @@ import (
 	"github.com/hashicorp/go-hclog"
 	"github.com/hashicorp/go-version"
 	"github.com/hashicorp/terraform-exec/tfexec"
+	"github.com/magodo/terraform-client-go/internal/cli"
 	"github.com/magodo/terraform-client-go/internal/find"
 	"github.com/magodo/terraform-client-go/tfclient"
 	"github.com/magodo/terraform-client-go/tfclient/typ"
@@ -22,13 +23,14 @@ import (
 )
 
 type FlagSet struct {
-	PluginPath   string
-	LogLevel     string
-	ProviderCfg  string
-	TimeoutSec   int
-	ModuleDir    string
-	ResourceAddr string
-	ModuleAddr   string
+	PluginPath    string
+	LogLevel      string
+	ProviderCfg   string
+	TimeoutSec    int
+	ModuleDir     string
+	ResourceAddr  string
+	ModuleAddr    string
+	ShowSensitive bool
 }
 
 func main() {
@@ -41,6 +43,8 @@ func main() {
 	flag.StringVar(&fset.ResourceAddr, "resource-addr", "", "The resource address (e.g. azurerm_resource_group.test)")
 	flag.StringVar(&fset.ModuleAddr, "module-addr", "", "The module address (e.g. mod1.mod2). Defaults to the root module")
 
+	flag.BoolVar(&fset.ShowSensitive, "show-sensitive", false, "Show the sensitive values in the output, which are redacted by default")
+
 	flag.Parse()
 
 	logger := hclog.New(&hclog.LoggerOptions{
@@ -133,8 +137,9 @@ func realMain(logger hclog.Logger, fset FlagSet) error {
 
 	// Upgrade state
 	opts := tfclient.Option{
-		Cmd:    exec.Command(fset.PluginPath),
-		Logger: logger,
+		Cmd:           exec.Command(fset.PluginPath),
+		Logger:        logger,
+		MarkSensitive: true,
 	}
 
 	reattach, err := tfclient.ParseReattach(os.Getenv("TF_REATTACH_PROVIDERS"))
@@ -158,7 +163,7 @@ func realMain(logger hclog.Logger, fset FlagSet) error {
 		defer cancel()
 	}
 
-	schResp, diags := c.GetProviderSchema()
+	_, diags := c.GetProviderSchema()
 	if err := showDiags(logger, diags); err != nil {
 		return err
 	}
@@ -173,7 +178,8 @@ func realMain(logger hclog.Logger, fset FlagSet) error {
 		return err
 	}
 
-	b, err := ctyjson.Marshal(resp.UpgradedState, schResp.ResourceTypesCty[rt])
+	out := cli.OutputValue(resp.UpgradedState, fset.ShowSensitive)
+	b, err := ctyjson.Marshal(out, out.Type())
 	if err != nil {
 		return err
 	}
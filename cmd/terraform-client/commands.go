@@ -0,0 +1,852 @@
+package main
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	tfjson "github.com/hashicorp/terraform-json"
+	"github.com/magodo/terraform-client-go/internal/cli"
+	"github.com/magodo/terraform-client-go/tfclient"
+	"github.com/magodo/terraform-client-go/tfclient/diffrender"
+	"github.com/magodo/terraform-client-go/tfclient/objchange"
+	"github.com/magodo/terraform-client-go/tfclient/planjson"
+	"github.com/magodo/terraform-client-go/tfclient/providerschema"
+	"github.com/magodo/terraform-client-go/tfclient/typ"
+	"github.com/zclconf/go-cty/cty"
+)
+
+var commands = map[string]command{
+	"schema":           {Synopsis: "Print the provider schema", Run: runSchema},
+	"validate":         {Synopsis: "Validate the provider, resource, data source, ephemeral resource, list resource or action config", Run: runValidate},
+	"configure":        {Synopsis: "Configure the provider", Run: runConfigure},
+	"read":             {Synopsis: "Read a resource", Run: runRead},
+	"plan":             {Synopsis: "Plan a resource change", Run: runPlan},
+	"apply":            {Synopsis: "Plan and apply a resource change", Run: runApply},
+	"import":           {Synopsis: "Import a resource", Run: runImport},
+	"move":             {Synopsis: "Move a resource state across resource types", Run: runMove},
+	"read-data":        {Synopsis: "Read a data source", Run: runReadData},
+	"open-ephemeral":   {Synopsis: "Open an ephemeral resource", Run: runOpenEphemeral},
+	"renew-ephemeral":  {Synopsis: "Renew an ephemeral resource", Run: runRenewEphemeral},
+	"close-ephemeral":  {Synopsis: "Close an ephemeral resource", Run: runCloseEphemeral},
+	"call":             {Synopsis: "Call a provider function", Run: runCall},
+	"list":             {Synopsis: "List resources", Run: runList},
+	"plan-action":      {Synopsis: "Plan an action", Run: runPlanAction},
+	"invoke-action":    {Synopsis: "Invoke an action", Run: runInvokeAction},
+	"upgrade-state":    {Synopsis: "Upgrade a resource state", Run: runUpgradeState},
+	"upgrade-identity": {Synopsis: "Upgrade a resource identity", Run: runUpgradeIdentity},
+	"repl":             {Synopsis: "Start an interactive session against the configured provider", Run: runRepl},
+	"serve":            {Synopsis: "Serve the provider as a local HTTP/JSON gateway", Run: runServe},
+	"conformance":      {Synopsis: "Check the provider against the protocol contract with sample configs", Run: runConformance},
+	"proxy":            {Synopsis: "Serve a proxy of the provider for Terraform to attach to, which logs every call", Run: runProxy},
+}
+
+const valueUsage = "in JSON, or @<file> to read it from a file"
+
+func runSchema(args []string) error {
+	var g cli.GlobalFlags
+	fs := newFlagSet("schema", &g)
+	addr := fs.String("addr", "", `The provider source address used as the key of the schema document. Defaults to the -source, or be derived from the plugin file name`)
+	if err := parseFlags(fs, &g, args); err != nil {
+		return err
+	}
+
+	return withSession(&g, false, func(s *session) error {
+		key := *addr
+		if key == "" {
+			var err error
+			key, err = providerAddr(&g)
+			if err != nil {
+				return err
+			}
+		}
+		b, err := providerschema.Marshal(key, s.schema)
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(b))
+		return nil
+	})
+}
+
+// providerAddr returns the provider source address, either from the -source, or derived from the plugin
+// file name, which follows the convention of "terraform-provider-<type>[_<version>]".
+func providerAddr(g *cli.GlobalFlags) (string, error) {
+	if g.ProviderSource != "" {
+		return tfclient.NormalizeProviderSource(g.ProviderSource)
+	}
+	name := filepath.Base(g.PluginPath)
+	ptype, ok := strings.CutPrefix(name, "terraform-provider-")
+	if !ok {
+		return "", cli.Usagef("can't derive the provider address from %q, please specify it via -addr", name)
+	}
+	ptype, _, _ = strings.Cut(ptype, "_")
+	return tfclient.NormalizeProviderSource(ptype)
+}
+
+func runValidate(args []string) error {
+	var g cli.GlobalFlags
+	fs := newFlagSet("validate", &g)
+	kind := fs.String("kind", "resource", `The kind of the config, one of "provider", "resource", "data", "ephemeral", "list" and "action"`)
+	typeName := fs.String("type", "", "The type name, not needed for the provider config")
+	config := fs.String("config", "{}", "The config "+valueUsage+". For the provider config, use -cfg or -cfg-file instead")
+	if err := parseFlags(fs, &g, args); err != nil {
+		return err
+	}
+	if *kind != "provider" && *typeName == "" {
+		return cli.Usagef("-type is required")
+	}
+
+	return withSession(&g, false, func(s *session) error {
+		var diags typ.Diagnostics
+		if *kind == "provider" {
+			b, err := g.ProviderConfig()
+			if err != nil {
+				return err
+			}
+			cfg, err := cli.DecodeBlockValue(b, s.schema.Provider.Block)
+			if err != nil {
+				return fmt.Errorf("decoding the provider config: %v", err)
+			}
+			_, diags = s.client.ValidateProviderConfig(s.ctx, typ.ValidateProviderConfigRequest{Config: cfg})
+		} else {
+			var schs map[string]tfjson.Schema
+			switch *kind {
+			case "resource":
+				schs = s.schema.ResourceTypes
+			case "data":
+				schs = s.schema.DataSources
+			case "ephemeral":
+				schs = s.schema.EphemeralResourceTypes
+			case "list":
+				schs = s.schema.ListResourceTypes
+			case "action":
+				schs = s.schema.Actions
+			default:
+				return cli.Usagef("invalid kind %q", *kind)
+			}
+			sch, err := lookupSchema(schs, *kind, *typeName)
+			if err != nil {
+				return err
+			}
+			cfg, err := cli.DecodeBlockArg(*config, sch.Block)
+			if err != nil {
+				return fmt.Errorf("decoding the config: %v", err)
+			}
+			switch *kind {
+			case "resource":
+				_, diags = s.client.ValidateResourceConfig(s.ctx, typ.ValidateResourceConfigRequest{TypeName: *typeName, Config: cfg})
+			case "data":
+				_, diags = s.client.ValidateDataResourceConfig(s.ctx, typ.ValidateDataResourceConfigRequest{TypeName: *typeName, Config: cfg})
+			case "ephemeral":
+				diags = s.client.ValidateEphemeralResourceConfig(s.ctx, typ.ValidateEphemeralResourceConfigRequest{TypeName: *typeName, Config: cfg})
+			case "list":
+				diags = s.client.ValidateListResourceConfig(s.ctx, typ.ValidateListResourceConfigRequest{TypeName: *typeName, Config: cty.ObjectVal(map[string]cty.Value{"config": cfg})})
+			case "action":
+				diags = s.client.ValidateActionConfig(s.ctx, typ.ValidateActionConfigRequest{TypeName: *typeName, Config: cfg})
+			}
+		}
+
+		if err := s.write(new(cli.Result).Add("diagnostics", diags)); err != nil {
+			return err
+		}
+		if diags.HasErrors() {
+			return fmt.Errorf("the config is invalid")
+		}
+		return nil
+	})
+}
+
+func runConfigure(args []string) error {
+	var g cli.GlobalFlags
+	fs := newFlagSet("configure", &g)
+	if err := parseFlags(fs, &g, args); err != nil {
+		return err
+	}
+	return withSession(&g, true, func(s *session) error {
+		return nil
+	})
+}
+
+func runRead(args []string) error {
+	var g cli.GlobalFlags
+	fs := newFlagSet("read", &g)
+	typeName := fs.String("type", "", "The resource type")
+	state := fs.String("state", "", "The prior state "+valueUsage)
+	private := fs.String("private", "", "The base64 encoded private data, or @<file> to read it from a file")
+	identity := fs.String("identity", "", "The current identity "+valueUsage)
+	if err := parseFlags(fs, &g, args, "type", "state"); err != nil {
+		return err
+	}
+
+	return withSession(&g, true, func(s *session) error {
+		sch, err := lookupSchema(s.schema.ResourceTypes, "resource", *typeName)
+		if err != nil {
+			return err
+		}
+		prior, err := cli.DecodeBlockArg(*state, sch.Block)
+		if err != nil {
+			return fmt.Errorf("decoding the state: %v", err)
+		}
+		priv, err := cli.DecodeBytes(*private)
+		if err != nil {
+			return fmt.Errorf("decoding the private data: %v", err)
+		}
+		idVal, err := cli.DecodeIdentityArg(*identity, sch.Identity)
+		if err != nil {
+			return fmt.Errorf("decoding the identity: %v", err)
+		}
+
+		resp, diags := s.client.ReadResource(s.ctx, typ.ReadResourceRequest{
+			TypeName:        *typeName,
+			PriorState:      prior,
+			Private:         priv,
+			CurrentIdentity: idVal,
+		})
+		if err := s.showDiags(diags); err != nil {
+			return err
+		}
+		return s.write(new(cli.Result).
+			Add("new_state", resp.NewState).
+			Add("private", resp.Private).
+			Add("identity", resp.Identity).
+			Add("deferred", deferredReason(resp.Deferred)))
+	})
+}
+
+// planFlags are the flags shared by the plan and apply subcommands.
+type planFlags struct {
+	typeName      string
+	prior         string
+	priorPrivate  string
+	priorIdentity string
+	config        string
+	destroy       bool
+}
+
+func (f *planFlags) register(fs interface {
+	StringVar(p *string, name string, value string, usage string)
+	BoolVar(p *bool, name string, value bool, usage string)
+}) {
+	fs.StringVar(&f.typeName, "type", "", "The resource type")
+	fs.StringVar(&f.prior, "prior", "", "The prior state "+valueUsage+". Defaults to null for creation")
+	fs.StringVar(&f.priorPrivate, "prior-private", "", "The base64 encoded prior private data, or @<file> to read it from a file")
+	fs.StringVar(&f.priorIdentity, "prior-identity", "", "The prior identity "+valueUsage)
+	fs.StringVar(&f.config, "config", "", "The resource config "+valueUsage)
+	fs.BoolVar(&f.destroy, "destroy", false, "Plan to destroy the resource, where the -config is ignored")
+}
+
+// plan plans the resource change, and returns the request and response.
+func (f *planFlags) plan(s *session) (*typ.PlanResourceChangeRequest, *typ.PlanResourceChangeResponse, error) {
+	sch, err := lookupSchema(s.schema.ResourceTypes, "resource", f.typeName)
+	if err != nil {
+		return nil, nil, err
+	}
+	ty := s.schema.ResourceTypesCty[f.typeName]
+
+	prior, err := cli.DecodeBlockArg(f.prior, sch.Block)
+	if err != nil {
+		return nil, nil, fmt.Errorf("decoding the prior state: %v", err)
+	}
+	priv, err := cli.DecodeBytes(f.priorPrivate)
+	if err != nil {
+		return nil, nil, fmt.Errorf("decoding the prior private data: %v", err)
+	}
+	idVal, err := cli.DecodeIdentityArg(f.priorIdentity, sch.Identity)
+	if err != nil {
+		return nil, nil, fmt.Errorf("decoding the prior identity: %v", err)
+	}
+
+	config := cty.NullVal(ty)
+	proposed := cty.NullVal(ty)
+	if !f.destroy {
+		if f.config == "" {
+			return nil, nil, cli.Usagef("-config is required, unless -destroy is specified")
+		}
+		config, err = cli.DecodeBlockArg(f.config, sch.Block)
+		if err != nil {
+			return nil, nil, fmt.Errorf("decoding the config: %v", err)
+		}
+		proposed = objchange.ProposedNew(sch.Block, prior, config)
+	}
+
+	req := typ.PlanResourceChangeRequest{
+		TypeName:         f.typeName,
+		PriorState:       prior,
+		ProposedNewState: proposed,
+		Config:           config,
+		PriorPrivate:     priv,
+		PriorIdentity:    idVal,
+	}
+	resp, diags := s.client.PlanResourceChange(s.ctx, req)
+	if err := s.showDiags(diags); err != nil {
+		return nil, nil, err
+	}
+	return &req, resp, nil
+}
+
+func runPlan(args []string) error {
+	var g cli.GlobalFlags
+	var pf planFlags
+	fs := newFlagSet("plan", &g)
+	pf.register(fs)
+	diff := fs.Bool("diff", false, "Print the change in the human readable format of terraform plan, instead of the planned state")
+	noColor := fs.Bool("no-color", false, "Disable the colour of the -diff output")
+	tfjsonPlan := fs.Bool("tfjson", false, "Print the change as a plan document of terraform-json, in the format of terraform show -json, instead of the planned state")
+	if err := parseFlags(fs, &g, args, "type"); err != nil {
+		return err
+	}
+	if *diff && *tfjsonPlan {
+		return cli.Usagef("-diff and -tfjson are mutually exclusive")
+	}
+
+	return withSession(&g, true, func(s *session) error {
+		req, resp, err := pf.plan(s)
+		if err != nil {
+			return err
+		}
+		if *diff {
+			out := diffrender.Render(diffrender.Change{
+				Address:         req.TypeName + ".this",
+				Type:            req.TypeName,
+				Schema:          s.schema.ResourceTypes[req.TypeName].Block,
+				Before:          req.PriorState,
+				After:           resp.PlannedState,
+				RequiresReplace: resp.RequiresReplace,
+			}, diffrender.Option{Color: !*noColor, ShowSensitive: s.g.ShowSensitive})
+			if out == "" {
+				out = "No changes.\n"
+			}
+			fmt.Print(out)
+			return nil
+		}
+		if *tfjsonPlan {
+			providerName := s.g.ProviderSource
+			if addr, err := tfclient.NormalizeProviderSource(providerName); err == nil {
+				providerName = addr
+			}
+			rc, err := planjson.ResourceChange(planjson.Change{
+				ProviderName: providerName,
+				Schema:       s.schema.ResourceTypes[req.TypeName],
+				Request:      *req,
+				Response:     resp,
+			})
+			if err != nil {
+				return err
+			}
+			plan, err := planjson.Plan([]*tfjson.ResourceChange{rc})
+			if err != nil {
+				return err
+			}
+			b, err := json.MarshalIndent(plan, "", "  ")
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(b))
+			return nil
+		}
+		var replace []string
+		for _, p := range resp.RequiresReplace {
+			replace = append(replace, typ.FormatCtyPath(p))
+		}
+		return s.write(new(cli.Result).
+			Add("planned_state", resp.PlannedState).
+			Add("requires_replace", replace).
+			Add("planned_private", resp.PlannedPrivate).
+			Add("planned_identity", resp.PlannedIdentity).
+			Add("deferred", deferredReason(resp.Deferred)))
+	})
+}
+
+func runApply(args []string) error {
+	var g cli.GlobalFlags
+	var pf planFlags
+	fs := newFlagSet("apply", &g)
+	pf.register(fs)
+	if err := parseFlags(fs, &g, args, "type"); err != nil {
+		return err
+	}
+
+	return withSession(&g, true, func(s *session) error {
+		req, planResp, err := pf.plan(s)
+		if err != nil {
+			return err
+		}
+		if planResp.Deferred != nil {
+			return fmt.Errorf("the change is deferred by the provider: %s", planResp.Deferred.Reason)
+		}
+		resp, diags := s.client.ApplyResourceChange(s.ctx, typ.ApplyResourceChangeRequest{
+			TypeName:        req.TypeName,
+			PriorState:      req.PriorState,
+			PlannedState:    planResp.PlannedState,
+			Config:          req.Config,
+			PlannedPrivate:  planResp.PlannedPrivate,
+			PlannedIdentity: planResp.PlannedIdentity,
+		})
+		if err := s.showDiags(diags); err != nil {
+			return err
+		}
+		return s.write(new(cli.Result).
+			Add("new_state", resp.NewState).
+			Add("private", resp.Private).
+			Add("identity", resp.NewIdentity))
+	})
+}
+
+func runImport(args []string) error {
+	var g cli.GlobalFlags
+	fs := newFlagSet("import", &g)
+	typeName := fs.String("type", "", "The resource type")
+	id := fs.String("id", "", "The resource id")
+	identity := fs.String("identity", "", "The resource identity "+valueUsage+", used instead of -id")
+	if err := parseFlags(fs, &g, args, "type"); err != nil {
+		return err
+	}
+	if (*id == "") == (*identity == "") {
+		return cli.Usagef("exactly one of -id and -identity is required")
+	}
+
+	return withSession(&g, true, func(s *session) error {
+		sch, err := lookupSchema(s.schema.ResourceTypes, "resource", *typeName)
+		if err != nil {
+			return err
+		}
+		idVal, err := cli.DecodeIdentityArg(*identity, sch.Identity)
+		if err != nil {
+			return fmt.Errorf("decoding the identity: %v", err)
+		}
+		resp, diags := s.client.ImportResourceState(s.ctx, typ.ImportResourceStateRequest{
+			TypeName: *typeName,
+			ID:       *id,
+			Identity: idVal,
+		})
+		if err := s.showDiags(diags); err != nil {
+			return err
+		}
+		var resources []cty.Value
+		for _, res := range resp.ImportedResources {
+			resources = append(resources, cty.ObjectVal(map[string]cty.Value{
+				"type_name": cty.StringVal(res.TypeName),
+				"state":     res.State,
+				"private":   bytesVal(res.Private),
+				"identity":  nilToNull(res.Identity),
+			}))
+		}
+		return s.write(new(cli.Result).
+			Add("imported_resources", cty.TupleVal(resources)).
+			Add("deferred", deferredReason(resp.Deferred)))
+	})
+}
+
+func runMove(args []string) error {
+	var g cli.GlobalFlags
+	fs := newFlagSet("move", &g)
+	srcProvider := fs.String("source-provider", "", "The source provider address")
+	srcType := fs.String("source-type", "", "The source resource type")
+	srcVersion := fs.Int64("source-version", 0, "The schema version of the source resource state")
+	srcState := fs.String("source-state", "", "The raw source resource state in JSON, or @<file> to read it from a file")
+	srcPrivate := fs.String("source-private", "", "The base64 encoded source private data, or @<file> to read it from a file")
+	srcIdentity := fs.String("source-identity", "", "The raw source resource identity in JSON, or @<file> to read it from a file")
+	targetType := fs.String("target-type", "", "The target resource type")
+	if err := parseFlags(fs, &g, args, "source-provider", "source-type", "source-state", "target-type"); err != nil {
+		return err
+	}
+
+	return withSession(&g, true, func(s *session) error {
+		state, err := cli.ReadArg(*srcState)
+		if err != nil {
+			return err
+		}
+		priv, err := cli.DecodeBytes(*srcPrivate)
+		if err != nil {
+			return fmt.Errorf("decoding the source private data: %v", err)
+		}
+		var identity []byte
+		if *srcIdentity != "" {
+			identity, err = cli.ReadArg(*srcIdentity)
+			if err != nil {
+				return err
+			}
+		}
+		resp, diags := s.client.MoveResourceState(s.ctx, typ.MoveResourceStateRequest{
+			SourceProviderAddress: *srcProvider,
+			SourceTypeName:        *srcType,
+			SourceSchemaVersion:   *srcVersion,
+			SourceStateJSON:       state,
+			SourcePrivate:         priv,
+			TargetTypeName:        *targetType,
+			SourceIdentity:        identity,
+		})
+		if err := s.showDiags(diags); err != nil {
+			return err
+		}
+		return s.write(new(cli.Result).
+			Add("target_state", resp.TargetState).
+			Add("target_private", resp.TargetPrivate).
+			Add("target_identity", resp.TargetIdentity))
+	})
+}
+
+func runReadData(args []string) error {
+	var g cli.GlobalFlags
+	fs := newFlagSet("read-data", &g)
+	typeName := fs.String("type", "", "The data source type")
+	config := fs.String("config", "{}", "The data source config "+valueUsage)
+	if err := parseFlags(fs, &g, args, "type"); err != nil {
+		return err
+	}
+
+	return withSession(&g, true, func(s *session) error {
+		sch, err := lookupSchema(s.schema.DataSources, "data source", *typeName)
+		if err != nil {
+			return err
+		}
+		cfg, err := cli.DecodeBlockArg(*config, sch.Block)
+		if err != nil {
+			return fmt.Errorf("decoding the config: %v", err)
+		}
+		resp, diags := s.client.ReadDataSource(s.ctx, typ.ReadDataSourceRequest{
+			TypeName: *typeName,
+			Config:   cfg,
+		})
+		if err := s.showDiags(diags); err != nil {
+			return err
+		}
+		return s.write(new(cli.Result).
+			Add("state", resp.State).
+			Add("deferred", deferredReason(resp.Deferred)))
+	})
+}
+
+func runOpenEphemeral(args []string) error {
+	var g cli.GlobalFlags
+	fs := newFlagSet("open-ephemeral", &g)
+	typeName := fs.String("type", "", "The ephemeral resource type")
+	config := fs.String("config", "{}", "The ephemeral resource config "+valueUsage)
+	if err := parseFlags(fs, &g, args, "type"); err != nil {
+		return err
+	}
+
+	return withSession(&g, true, func(s *session) error {
+		sch, err := lookupSchema(s.schema.EphemeralResourceTypes, "ephemeral resource", *typeName)
+		if err != nil {
+			return err
+		}
+		cfg, err := cli.DecodeBlockArg(*config, sch.Block)
+		if err != nil {
+			return fmt.Errorf("decoding the config: %v", err)
+		}
+		resp, diags := s.client.OpenEphemeralResource(s.ctx, typ.OpenEphemeralResourceRequest{
+			TypeName: *typeName,
+			Config:   cfg,
+		})
+		if err := s.showDiags(diags); err != nil {
+			return err
+		}
+		r := new(cli.Result).
+			Add("result", resp.Result).
+			Add("private", resp.Private).
+			Add("deferred", deferredReason(resp.Deferred))
+		if !resp.RenewAt.IsZero() {
+			r.Add("renew_at", resp.RenewAt)
+		}
+		return s.write(r)
+	})
+}
+
+func runRenewEphemeral(args []string) error {
+	var g cli.GlobalFlags
+	fs := newFlagSet("renew-ephemeral", &g)
+	typeName := fs.String("type", "", "The ephemeral resource type")
+	private := fs.String("private", "", "The base64 encoded private data, or @<file> to read it from a file")
+	if err := parseFlags(fs, &g, args, "type"); err != nil {
+		return err
+	}
+
+	return withSession(&g, true, func(s *session) error {
+		priv, err := cli.DecodeBytes(*private)
+		if err != nil {
+			return fmt.Errorf("decoding the private data: %v", err)
+		}
+		resp, diags := s.client.RenewEphemeralResource(s.ctx, typ.RenewEphemeralResourceRequest{
+			TypeName: *typeName,
+			Private:  priv,
+		})
+		if err := s.showDiags(diags); err != nil {
+			return err
+		}
+		r := new(cli.Result).Add("private", resp.Private)
+		if !resp.RenewAt.IsZero() {
+			r.Add("renew_at", resp.RenewAt)
+		}
+		return s.write(r)
+	})
+}
+
+func runCloseEphemeral(args []string) error {
+	var g cli.GlobalFlags
+	fs := newFlagSet("close-ephemeral", &g)
+	typeName := fs.String("type", "", "The ephemeral resource type")
+	private := fs.String("private", "", "The base64 encoded private data, or @<file> to read it from a file")
+	if err := parseFlags(fs, &g, args, "type"); err != nil {
+		return err
+	}
+
+	return withSession(&g, true, func(s *session) error {
+		priv, err := cli.DecodeBytes(*private)
+		if err != nil {
+			return fmt.Errorf("decoding the private data: %v", err)
+		}
+		diags := s.client.CloseEphemeralResource(s.ctx, typ.CloseEphemeralResourceRequest{
+			TypeName: *typeName,
+			Private:  priv,
+		})
+		return s.showDiags(diags)
+	})
+}
+
+func runCall(args []string) error {
+	var g cli.GlobalFlags
+	var fargs stringSlice
+	fs := newFlagSet("call", &g)
+	funcName := fs.String("func", "", "The name of the function")
+	fs.Var(&fargs, "arg", "The argument of the function "+valueUsage+" (can be specified multiple times)")
+	if err := parseFlags(fs, &g, args, "func"); err != nil {
+		return err
+	}
+
+	// Functions don't need the provider to be configured
+	return withSession(&g, false, func(s *session) error {
+		decl, ok := s.schema.Functions[*funcName]
+		if !ok {
+			return fmt.Errorf("no function named %q", *funcName)
+		}
+		var argVals []cty.Value
+		for i, arg := range fargs {
+			var ty cty.Type
+			switch {
+			case i < len(decl.Parameters):
+				ty = decl.Parameters[i].Type
+			case decl.VariadicParameter != nil:
+				ty = decl.VariadicParameter.Type
+			default:
+				return fmt.Errorf("too many arguments, expect %d", len(decl.Parameters))
+			}
+			v, err := cli.DecodeArg(arg, ty)
+			if err != nil {
+				return fmt.Errorf("decoding the argument %d: %v", i, err)
+			}
+			argVals = append(argVals, v)
+		}
+		resp, diags := s.client.CallFunction(s.ctx, typ.CallFunctionRequest{
+			FunctionName: *funcName,
+			Arguments:    argVals,
+		})
+		if err := s.showDiags(diags); err != nil {
+			return err
+		}
+		if resp.Err != nil {
+			return resp.Err
+		}
+		return s.write(new(cli.Result).Add("result", resp.Result))
+	})
+}
+
+func runList(args []string) error {
+	var g cli.GlobalFlags
+	fs := newFlagSet("list", &g)
+	typeName := fs.String("type", "", "The list resource type")
+	config := fs.String("config", "{}", "The list resource config "+valueUsage)
+	includeResource := fs.Bool("include-resource", false, "Should the provider include the full resource object for each result")
+	limit := fs.Int64("limit", 100, "The maximum number of results to return")
+	if err := parseFlags(fs, &g, args, "type"); err != nil {
+		return err
+	}
+
+	return withSession(&g, true, func(s *session) error {
+		sch, err := lookupSchema(s.schema.ListResourceTypes, "list resource", *typeName)
+		if err != nil {
+			return err
+		}
+		cfg, err := cli.DecodeBlockArg(*config, sch.Block)
+		if err != nil {
+			return fmt.Errorf("decoding the config: %v", err)
+		}
+		resp, diags := s.client.ListResource(s.ctx, typ.ListResourceRequest{
+			TypeName:              *typeName,
+			Config:                cty.ObjectVal(map[string]cty.Value{"config": cfg}),
+			IncludeResourceObject: *includeResource,
+			Limit:                 *limit,
+		})
+		if err := s.showDiags(diags); err != nil {
+			return err
+		}
+		data := cty.NullVal(cty.DynamicPseudoType)
+		if !resp.Result.IsNull() && resp.Result.Type().IsObjectType() && resp.Result.Type().HasAttribute("data") {
+			data = resp.Result.GetAttr("data")
+		}
+		return s.write(new(cli.Result).Add("data", data))
+	})
+}
+
+func runPlanAction(args []string) error {
+	var g cli.GlobalFlags
+	fs := newFlagSet("plan-action", &g)
+	typeName := fs.String("type", "", "The action type")
+	config := fs.String("config", "{}", "The action config "+valueUsage)
+	if err := parseFlags(fs, &g, args, "type"); err != nil {
+		return err
+	}
+
+	return withSession(&g, true, func(s *session) error {
+		sch, err := lookupSchema(s.schema.Actions, "action", *typeName)
+		if err != nil {
+			return err
+		}
+		cfg, err := cli.DecodeBlockArg(*config, sch.Block)
+		if err != nil {
+			return fmt.Errorf("decoding the config: %v", err)
+		}
+		resp, diags := s.client.PlanAction(s.ctx, typ.PlanActionRequest{
+			ActionType:         *typeName,
+			ProposedActionData: cfg,
+		})
+		if err := s.showDiags(diags); err != nil {
+			return err
+		}
+		return s.write(new(cli.Result).Add("deferred", deferredReason(resp.Deferred)))
+	})
+}
+
+func runInvokeAction(args []string) error {
+	var g cli.GlobalFlags
+	fs := newFlagSet("invoke-action", &g)
+	typeName := fs.String("type", "", "The action type")
+	config := fs.String("config", "{}", "The action config "+valueUsage)
+	if err := parseFlags(fs, &g, args, "type"); err != nil {
+		return err
+	}
+
+	return withSession(&g, true, func(s *session) error {
+		sch, err := lookupSchema(s.schema.Actions, "action", *typeName)
+		if err != nil {
+			return err
+		}
+		cfg, err := cli.DecodeBlockArg(*config, sch.Block)
+		if err != nil {
+			return fmt.Errorf("decoding the config: %v", err)
+		}
+		resp, diags := s.client.InvokeAction(s.ctx, typ.InvokeActionRequest{
+			ActionType:        *typeName,
+			PlannedActionData: cfg,
+		})
+		if err := s.showDiags(diags); err != nil {
+			return err
+		}
+
+		// Progress messages are streamed to the stderr, while the diagnostics are reported at completion.
+		var completedDiags typ.Diagnostics
+		for evt := range resp.Events {
+			switch evt := evt.(type) {
+			case typ.InvokeActionEvent_Progress:
+				fmt.Fprintln(os.Stderr, evt.Message)
+			case typ.InvokeActionEvent_Completed:
+				completedDiags = append(completedDiags, evt.Diagnostics...)
+			}
+		}
+		if err := s.write(new(cli.Result).Add("diagnostics", completedDiags)); err != nil {
+			return err
+		}
+		if completedDiags.HasErrors() {
+			return fmt.Errorf("the action failed")
+		}
+		return nil
+	})
+}
+
+func runUpgradeState(args []string) error {
+	var g cli.GlobalFlags
+	fs := newFlagSet("upgrade-state", &g)
+	typeName := fs.String("type", "", "The resource type")
+	version := fs.Int64("version", 0, "The schema version of the state")
+	state := fs.String("state", "", "The raw state in JSON, or @<file> to read it from a file")
+	if err := parseFlags(fs, &g, args, "type", "state"); err != nil {
+		return err
+	}
+
+	// Upgrading state doesn't need the provider to be configured
+	return withSession(&g, false, func(s *session) error {
+		raw, err := cli.ReadArg(*state)
+		if err != nil {
+			return err
+		}
+		resp, diags := s.client.UpgradeResourceState(s.ctx, typ.UpgradeResourceStateRequest{
+			TypeName:     *typeName,
+			Version:      *version,
+			RawStateJSON: raw,
+		})
+		if err := s.showDiags(diags); err != nil {
+			return err
+		}
+		return s.write(new(cli.Result).Add("upgraded_state", resp.UpgradedState))
+	})
+}
+
+func runUpgradeIdentity(args []string) error {
+	var g cli.GlobalFlags
+	fs := newFlagSet("upgrade-identity", &g)
+	typeName := fs.String("type", "", "The resource type")
+	version := fs.Int64("version", 0, "The schema version of the identity")
+	identity := fs.String("identity", "", "The raw identity in JSON, or @<file> to read it from a file")
+	if err := parseFlags(fs, &g, args, "type", "identity"); err != nil {
+		return err
+	}
+
+	return withSession(&g, false, func(s *session) error {
+		raw, err := cli.ReadArg(*identity)
+		if err != nil {
+			return err
+		}
+		resp, diags := s.client.UpgradeResourceIdentity(s.ctx, typ.UpgradeResourceIdentityRequest{
+			TypeName:        *typeName,
+			Version:         *version,
+			RawIdentityJSON: raw,
+		})
+		if err := s.showDiags(diags); err != nil {
+			return err
+		}
+		return s.write(new(cli.Result).Add("upgraded_identity", resp.UpgradedIdentity))
+	})
+}
+
+type stringSlice []string
+
+func (l *stringSlice) String() string {
+	if l == nil {
+		return ""
+	}
+	return strconv.Quote(strings.Join(*l, ", "))
+}
+
+func (l *stringSlice) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+func bytesVal(b []byte) cty.Value {
+	if b == nil {
+		return cty.NullVal(cty.String)
+	}
+	return cty.StringVal(base64.StdEncoding.EncodeToString(b))
+}
+
+func nilToNull(v cty.Value) cty.Value {
+	if v == cty.NilVal {
+		return cty.NullVal(cty.DynamicPseudoType)
+	}
+	return v
+}
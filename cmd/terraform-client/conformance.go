@@ -0,0 +1,44 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/magodo/terraform-client-go/internal/cli"
+	"github.com/magodo/terraform-client-go/tfclient/conformance"
+)
+
+func runConformance(args []string) error {
+	var g cli.GlobalFlags
+	fs := newFlagSet("conformance", &g)
+	suiteArg := fs.String("suite", "", `The conformance suite `+valueUsage+`, in the form of {"resources": {"<type>": {"config": <config>, "import_id": "<id>", "import_ignore": ["<attribute>"]}}, "functions": {"<name>": {"arguments": [<argument>]}}}`)
+	if err := parseFlags(fs, &g, args, "suite"); err != nil {
+		return err
+	}
+
+	return withSession(&g, true, func(s *session) error {
+		b, err := cli.ReadArg(*suiteArg)
+		if err != nil {
+			return err
+		}
+		suite, err := conformance.ParseSuite(b, s.schema)
+		if err != nil {
+			return fmt.Errorf("parsing the suite: %v", err)
+		}
+
+		r := new(cli.Result)
+		var failed int
+		for _, res := range conformance.Run(s.ctx, s.client, suite) {
+			if res.Diagnostics.HasErrors() {
+				failed++
+			}
+			r.Add(res.Name, res.Diagnostics)
+		}
+		if err := s.write(r); err != nil {
+			return err
+		}
+		if failed != 0 {
+			return fmt.Errorf("%d of %d cases failed", failed, len(suite.Resources)+len(suite.Functions))
+		}
+		return nil
+	})
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/go-hclog"
+	tfjson "github.com/hashicorp/terraform-json"
+	"github.com/magodo/terraform-client-go/internal/cli"
+	"github.com/magodo/terraform-client-go/tfclient"
+	"github.com/magodo/terraform-client-go/tfclient/typ"
+)
+
+type command struct {
+	Synopsis string
+	Run      func(args []string) error
+}
+
+func main() {
+	os.Exit(realMain(os.Args[1:]))
+}
+
+func realMain(args []string) int {
+	if len(args) == 0 || args[0] == "-h" || args[0] == "-help" || args[0] == "--help" || args[0] == "help" {
+		usage()
+		return cli.ExitUsage
+	}
+	cmd, ok := commands[args[0]]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown subcommand %q\n\n", args[0])
+		usage()
+		return cli.ExitUsage
+	}
+	err := cmd.Run(args[1:])
+	if err != nil && !errors.Is(err, flag.ErrHelp) {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+	}
+	return cli.ExitCode(err)
+}
+
+func usage() {
+	var names []string
+	width := 0
+	for name := range commands {
+		names = append(names, name)
+		width = max(width, len(name))
+	}
+	sort.Strings(names)
+
+	var sb strings.Builder
+	sb.WriteString("Usage: terraform-client <subcommand> [flags]\n\nSubcommands:\n")
+	for _, name := range names {
+		fmt.Fprintf(&sb, "  %-*s  %s\n", width, name, commands[name].Synopsis)
+	}
+	sb.WriteString("\nRun \"terraform-client <subcommand> -h\" for the flags of each subcommand.\n")
+	fmt.Fprint(os.Stderr, sb.String())
+}
+
+// newFlagSet creates the flag set of a subcommand, with the global flags registered.
+func newFlagSet(name string, g *cli.GlobalFlags) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	g.Register(fs)
+	return fs
+}
+
+// parseFlags parses the flags of a subcommand, and validates the global flags and the required flags.
+func parseFlags(fs *flag.FlagSet, g *cli.GlobalFlags, args []string, required ...string) error {
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		return cli.Usagef("unexpected arguments: %s", strings.Join(fs.Args(), " "))
+	}
+	if err := g.Validate(); err != nil {
+		return err
+	}
+	for _, name := range required {
+		if f := fs.Lookup(name); f != nil && f.Value.String() == "" {
+			return cli.Usagef("-%s is required", name)
+		}
+	}
+	return nil
+}
+
+// session holds the states of a subcommand invocation against a provider.
+type session struct {
+	g      *cli.GlobalFlags
+	ctx    context.Context
+	logger hclog.Logger
+	client tfclient.Client
+	schema *typ.GetProviderSchemaResponse
+}
+
+// withSession starts the provider, optionally configures it, then runs the function.
+func withSession(g *cli.GlobalFlags, configure bool, f func(s *session) error) error {
+	logger := g.NewLogger()
+	c, err := g.NewClient(logger)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	ctx, cancel := g.Context()
+	defer cancel()
+
+	schema, diags := c.GetProviderSchema()
+	if err := cli.ShowDiags(logger, diags); err != nil {
+		return err
+	}
+
+	if configure {
+		if err := g.ConfigureProvider(ctx, logger, c); err != nil {
+			return err
+		}
+	}
+
+	return f(&session{
+		g:      g,
+		ctx:    ctx,
+		logger: logger,
+		client: c,
+		schema: schema,
+	})
+}
+
+func (s *session) showDiags(diags typ.Diagnostics) error {
+	return cli.ShowDiags(s.logger, diags)
+}
+
+// write writes the result to the stdout, where the sensitive values are redacted, unless -show-sensitive is set.
+func (s *session) write(r *cli.Result) error {
+	if !s.g.ShowSensitive {
+		r = r.RedactSensitive()
+	}
+	return r.Write(os.Stdout, s.g.Output)
+}
+
+func lookupSchema(m map[string]tfjson.Schema, kind, name string) (tfjson.Schema, error) {
+	sch, ok := m[name]
+	if !ok {
+		return sch, fmt.Errorf("no %s named %q", kind, name)
+	}
+	return sch, nil
+}
+
+func deferredReason(d *typ.Deferred) any {
+	if d == nil {
+		return nil
+	}
+	return string(d.Reason)
+}
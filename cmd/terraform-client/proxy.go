@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/go-plugin"
+	"github.com/magodo/terraform-client-go/internal/cli"
+	"github.com/magodo/terraform-client-go/tfclient"
+	"github.com/magodo/terraform-client-go/tfclient/proxy"
+)
+
+func runProxy(args []string) error {
+	var g cli.GlobalFlags
+	fs := newFlagSet("proxy", &g)
+	addr := fs.String("addr", "", `The provider source address used as the key of the TF_REATTACH_PROVIDERS. Defaults to the -source, or be derived from the plugin file name`)
+	logFile := fs.String("log-file", "", "The file to write the logs of the proxied calls to. Defaults to stderr")
+	logJSON := fs.Bool("log-json", false, "Write the logs of the proxied calls in JSON")
+	showSensitive := fs.Bool("show-sensitive", false, "Show the sensitive values in the logs of the proxied calls, which are redacted by default")
+	if err := parseFlags(fs, &g, args); err != nil {
+		return err
+	}
+
+	key := *addr
+	if key == "" {
+		var err error
+		if key, err = providerAddr(&g); err != nil {
+			return err
+		}
+	}
+
+	var out io.Writer = os.Stderr
+	if *logFile != "" {
+		f, err := os.OpenFile(*logFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		out = f
+	}
+	callLogger := hclog.New(&hclog.LoggerOptions{
+		Name:       "proxy",
+		Output:     out,
+		Level:      hclog.Info,
+		JSONFormat: *logJSON,
+	})
+
+	logger := g.NewLogger()
+	opts, err := g.ClientOption(logger)
+	if err != nil {
+		return err
+	}
+	client, err := tfclient.NewRaw(opts)
+	if err != nil {
+		return err
+	}
+	defer client.Kill()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	reattachCh := make(chan *plugin.ReattachConfig, 1)
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- proxy.Serve(ctx, client, callLogger, reattachCh, proxy.Option{ShowSensitive: *showSensitive})
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case config := <-reattachCh:
+		env, err := proxy.ReattachEnv(key, config)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "Proxy started, set the following environment variable for Terraform to attach to it. Stop it with Ctrl-C.\n\n")
+		fmt.Printf("TF_REATTACH_PROVIDERS='%s'\n", strings.ReplaceAll(env, `'`, `'"'"'`))
+	}
+	return <-errCh
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/magodo/terraform-client-go/internal/cli"
+	"github.com/magodo/terraform-client-go/internal/repl"
+	"github.com/peterh/liner"
+)
+
+func runRepl(args []string) error {
+	var g cli.GlobalFlags
+	fs := newFlagSet("repl", &g)
+	history := fs.String("history", defaultHistoryFile(), "The file to persist the input history, empty to disable")
+	if err := parseFlags(fs, &g, args); err != nil {
+		return err
+	}
+
+	return withSession(&g, true, func(s *session) error {
+		sess := repl.New(s.ctx, s.client, s.schema)
+		sess.ShowSensitive = s.g.ShowSensitive
+
+		line := liner.NewLiner()
+		defer line.Close()
+		line.SetCtrlCAborts(true)
+		line.SetTabCompletionStyle(liner.TabPrints)
+		line.SetWordCompleter(sess.Complete)
+
+		if *history != "" {
+			if f, err := os.Open(*history); err == nil {
+				line.ReadHistory(f)
+				f.Close()
+			}
+			defer func() {
+				if err := os.MkdirAll(filepath.Dir(*history), 0o755); err != nil {
+					return
+				}
+				// The history can contain credentials, e.g. of the provider config, so it is only accessible by the user.
+				if f, err := os.OpenFile(*history, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600); err == nil {
+					f.Chmod(0o600)
+					line.WriteHistory(f)
+					f.Close()
+				}
+			}()
+		}
+
+		fmt.Fprintln(os.Stderr, `Type "help" for the available statements, "exit" or Ctrl-D to exit.`)
+		for {
+			src, err := readStatement(line)
+			if err != nil {
+				if errors.Is(err, io.EOF) {
+					return nil
+				}
+				if errors.Is(err, liner.ErrPromptAborted) {
+					continue
+				}
+				return err
+			}
+			if strings.TrimSpace(src) == "" {
+				continue
+			}
+			line.AppendHistory(src)
+
+			out, err := sess.Exec(src)
+			if err != nil {
+				if errors.Is(err, repl.ErrExit) {
+					return nil
+				}
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				continue
+			}
+			fmt.Print(out)
+		}
+	})
+}
+
+// readStatement reads lines until the brackets of the statement are closed.
+func readStatement(line *liner.State) (string, error) {
+	src, err := line.Prompt("> ")
+	if err != nil {
+		return "", err
+	}
+	for repl.Incomplete(src) {
+		more, err := line.Prompt(". ")
+		if err != nil {
+			return "", err
+		}
+		src += "\n" + more
+	}
+	return src, nil
+}
+
+func defaultHistoryFile() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "terraform-client", "repl_history")
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/magodo/terraform-client-go/internal/cli"
+	"github.com/magodo/terraform-client-go/tfclient/gateway"
+)
+
+func runServe(args []string) error {
+	var g cli.GlobalFlags
+	fs := newFlagSet("serve", &g)
+	listen := fs.String("listen", "127.0.0.1:0", `The address to listen on, either a loopback TCP address (e.g. "127.0.0.1:8080"), or "unix:<path>" for a unix socket`)
+	addr := fs.String("addr", "", `The provider source address used as the key of the schema document. Defaults to the -source, or be derived from the plugin file name`)
+	configure := fs.Bool("configure", true, "Configure the provider with the provider config on start")
+	token := fs.String("token", "", "The bearer token required by the requests to a TCP address. Defaults to a random token printed on start. The unix socket is protected by the file permission instead")
+	if err := parseFlags(fs, &g, args); err != nil {
+		return err
+	}
+
+	return withSession(&g, *configure, func(s *session) error {
+		key := *addr
+		if key == "" {
+			var err error
+			if key, err = providerAddr(&g); err != nil {
+				return err
+			}
+		}
+
+		l, err := gatewayListen(*listen)
+		if err != nil {
+			return err
+		}
+
+		var opts gateway.Option
+		if l.Addr().Network() == "unix" {
+			opts.AllowAnyHost = true
+		} else {
+			opts.Token = *token
+			if opts.Token == "" {
+				b := make([]byte, 32)
+				if _, err := rand.Read(b); err != nil {
+					return err
+				}
+				opts.Token = hex.EncodeToString(b)
+			}
+		}
+
+		srv := &http.Server{
+			Handler:           gateway.New(s.client, s.schema, key, opts),
+			ReadHeaderTimeout: 10 * time.Second,
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		go func() {
+			<-ctx.Done()
+			sctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			// nolint:errcheck
+			srv.Shutdown(sctx)
+		}()
+
+		fmt.Fprintf(os.Stderr, "Serving on %s://%s\n", l.Addr().Network(), l.Addr().String())
+		if opts.Token != "" && *token == "" {
+			fmt.Fprintf(os.Stderr, "Token: %s\n", opts.Token)
+		}
+		if err := srv.Serve(l); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			return err
+		}
+		return nil
+	})
+}
+
+// gatewayListen listens on a unix socket, or a loopback TCP address, as the gateway is not meant to be
+// exposed to the network.
+func gatewayListen(addr string) (net.Listener, error) {
+	if path, ok := strings.CutPrefix(addr, "unix:"); ok {
+		if fi, err := os.Stat(path); err == nil && fi.Mode()&os.ModeSocket != 0 {
+			// Remove the stale socket of the previous run
+			if err := os.Remove(path); err != nil {
+				return nil, err
+			}
+		}
+		return net.Listen("unix", path)
+	}
+
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, cli.Usagef("invalid listen address %q: %v", addr, err)
+	}
+	if host != "localhost" {
+		if ip := net.ParseIP(host); ip == nil || !ip.IsLoopback() {
+			return nil, cli.Usagef("the listen address %q is not a loopback address", addr)
+		}
+	}
+	return net.Listen("tcp", addr)
+}
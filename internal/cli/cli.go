@@ -0,0 +1,217 @@
+// Package cli contains the helpers shared by the commands.
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/go-plugin"
+	"github.com/magodo/terraform-client-go/tfclient"
+	"github.com/magodo/terraform-client-go/tfclient/typ"
+)
+
+// Exit codes of the commands.
+const (
+	ExitOK    = 0
+	ExitError = 1
+	ExitUsage = 2
+)
+
+// UsageError indicates the command is not invoked properly, which results in ExitUsage.
+type UsageError struct {
+	Msg string
+}
+
+func (e UsageError) Error() string {
+	return e.Msg
+}
+
+// Usagef returns a UsageError with the formatted message.
+func Usagef(format string, a ...any) error {
+	return UsageError{Msg: fmt.Sprintf(format, a...)}
+}
+
+// ExitCode returns the exit code for the error returned by a command.
+func ExitCode(err error) int {
+	if err == nil {
+		return ExitOK
+	}
+	if errors.Is(err, flag.ErrHelp) || errors.As(err, &UsageError{}) {
+		return ExitUsage
+	}
+	return ExitError
+}
+
+// GlobalFlags are the flags shared by all the subcommands.
+type GlobalFlags struct {
+	PluginPath      string
+	ProviderSource  string
+	ProviderCfg     string
+	ProviderCfgFile string
+	LogLevel        string
+	TimeoutSec      int
+	Output          string
+	ShowSensitive   bool
+	StaticValidate  bool
+}
+
+// Output formats
+const (
+	OutputJSON = "json"
+	OutputHCL  = "hcl"
+	// OutputValueJSON is the JSON format where the values are encoded by the valuejson package, which keeps the
+	// unknown values and the sensitive marks.
+	OutputValueJSON = "valuejson"
+)
+
+// Register registers the global flags to the flag set.
+func (g *GlobalFlags) Register(fs *flag.FlagSet) {
+	fs.StringVar(&g.PluginPath, "path", "", "The path to the plugin")
+	fs.StringVar(&g.ProviderSource, "source", "", `The provider source address (e.g. "hashicorp/azurerm"), used to select the provider from the TF_REATTACH_PROVIDERS`)
+	fs.StringVar(&g.ProviderCfg, "cfg", "{}", "The content of provider config block in JSON")
+	fs.StringVar(&g.ProviderCfgFile, "cfg-file", "", "The file containing the provider config block in JSON, which takes precedence over -cfg")
+	fs.StringVar(&g.LogLevel, "log-level", hclog.Error.String(), "Log level")
+	fs.IntVar(&g.TimeoutSec, "timeout", 0, "Timeout in second. Defaults to no timeout.")
+	fs.StringVar(&g.Output, "output", OutputJSON, `The output format, one of "json", "hcl" and "valuejson"`)
+	fs.BoolVar(&g.ShowSensitive, "show-sensitive", false, "Show the sensitive values in the output, which are redacted by default")
+	fs.BoolVar(&g.StaticValidate, "static-validate", false, "Validate the configs against the schema before sending them to the provider")
+}
+
+// Validate validates the global flags.
+func (g *GlobalFlags) Validate() error {
+	switch g.Output {
+	case OutputJSON, OutputHCL, OutputValueJSON:
+	default:
+		return Usagef("invalid output format %q", g.Output)
+	}
+	if g.PluginPath == "" && os.Getenv("TF_REATTACH_PROVIDERS") == "" {
+		return Usagef("-path is required, unless the TF_REATTACH_PROVIDERS is set")
+	}
+	return nil
+}
+
+// NewLogger creates the logger of the command.
+func (g *GlobalFlags) NewLogger() hclog.Logger {
+	name := filepath.Base(g.PluginPath)
+	if g.ProviderSource != "" {
+		name = g.ProviderSource
+	}
+	return hclog.New(&hclog.LoggerOptions{
+		Output: hclog.DefaultOutput,
+		Level:  hclog.LevelFromString(g.LogLevel),
+		Name:   name,
+	})
+}
+
+// Context returns the context honoring the timeout.
+func (g *GlobalFlags) Context() (context.Context, context.CancelFunc) {
+	if g.TimeoutSec > 0 {
+		return context.WithTimeout(context.Background(), time.Second*time.Duration(g.TimeoutSec))
+	}
+	return context.WithCancel(context.Background())
+}
+
+// ClientOption returns the client option, which either starts the plugin at PluginPath, or reattaches
+// to the provider specified in the TF_REATTACH_PROVIDERS. The values returned by the client are marked as
+// sensitive by schema.
+func (g *GlobalFlags) ClientOption(logger hclog.Logger) (tfclient.Option, error) {
+	opts := tfclient.Option{
+		Cmd:              exec.Command(g.PluginPath),
+		Logger:           logger,
+		MarkSensitive:    true,
+		StaticValidation: g.StaticValidate,
+	}
+
+	reattach, err := selectReattach(os.Getenv("TF_REATTACH_PROVIDERS"), g.ProviderSource)
+	if err != nil {
+		return opts, err
+	}
+	if reattach != nil {
+		opts.Cmd = nil
+		opts.Reattach = reattach
+	}
+	return opts, nil
+}
+
+// NewClient creates the client as is specified by the global flags.
+func (g *GlobalFlags) NewClient(logger hclog.Logger) (tfclient.Client, error) {
+	opts, err := g.ClientOption(logger)
+	if err != nil {
+		return nil, err
+	}
+	return tfclient.New(opts)
+}
+
+// ProviderConfig returns the content of the provider config in JSON.
+func (g *GlobalFlags) ProviderConfig() ([]byte, error) {
+	if g.ProviderCfgFile != "" {
+		return os.ReadFile(g.ProviderCfgFile)
+	}
+	return []byte(g.ProviderCfg), nil
+}
+
+// ConfigureProvider configures the provider with the provider config specified by the global flags.
+func (g *GlobalFlags) ConfigureProvider(ctx context.Context, logger hclog.Logger, c tfclient.Client) error {
+	schResp, diags := c.GetProviderSchema()
+	if err := ShowDiags(logger, diags); err != nil {
+		return err
+	}
+	b, err := g.ProviderConfig()
+	if err != nil {
+		return err
+	}
+	config, err := DecodeBlockValue(b, schResp.Provider.Block)
+	if err != nil {
+		return fmt.Errorf("decoding the provider config: %v", err)
+	}
+	_, diags = c.ConfigureProvider(ctx, typ.ConfigureProviderRequest{
+		Config: config,
+	})
+	return ShowDiags(logger, diags)
+}
+
+// selectReattach returns the reattach config of the provider specified by the source address,
+// which is nil if the input is empty. If the source is empty, the input is expected to contain
+// only one provider.
+func selectReattach(in string, source string) (*plugin.ReattachConfig, error) {
+	if source == "" {
+		return tfclient.ParseReattach(in)
+	}
+	m, err := tfclient.ParseReattachProviders(in)
+	if err != nil {
+		return nil, err
+	}
+	if m == nil {
+		return nil, nil
+	}
+	addr, err := tfclient.NormalizeProviderSource(source)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range m {
+		if kaddr, err := tfclient.NormalizeProviderSource(k); err == nil && kaddr == addr {
+			return v, nil
+		}
+	}
+	return nil, Usagef("provider %q not found in TF_REATTACH_PROVIDERS", source)
+}
+
+// ShowDiags returns the first error diagnostic as an error, and logs the rest as warnings.
+func ShowDiags(logger hclog.Logger, diags typ.Diagnostics) error {
+	for _, diag := range diags {
+		if diag.Severity == typ.Error {
+			return fmt.Errorf("%s: %s", diag.Summary, diag.Detail)
+		}
+	}
+	if len(diags) != 0 {
+		logger.Warn(diags.Err().Error())
+	}
+	return nil
+}
@@ -0,0 +1,29 @@
+package cli
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestSelectReattach(t *testing.T) {
+	in := `{"registry.terraform.io/hashicorp/foo": {"Protocol": "grpc", "ProtocolVersion": 5, "Pid": 1, "Addr": {"Network": "unix", "String": "/tmp/plugin"}}}`
+
+	config, err := selectReattach(in, "hashicorp/foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config == nil || config.Addr.String() != "/tmp/plugin" {
+		t.Errorf("wrong reattach config: %#v", config)
+	}
+
+	// The provider is started from the plugin path if TF_REATTACH_PROVIDERS is not set.
+	if config, err := selectReattach("", "hashicorp/foo"); err != nil || config != nil {
+		t.Errorf("expect no reattach config, got %#v, %v", config, err)
+	}
+
+	_, err = selectReattach(in, "hashicorp/bar")
+	var uerr UsageError
+	if !errors.As(err, &uerr) || uerr.Msg != `provider "hashicorp/bar" not found in TF_REATTACH_PROVIDERS` {
+		t.Errorf("expect a usage error, got %v", err)
+	}
+}
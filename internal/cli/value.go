@@ -0,0 +1,296 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	tfjson "github.com/hashicorp/terraform-json"
+	"github.com/magodo/terraform-client-go/tfclient/configschema"
+	"github.com/magodo/terraform-client-go/tfclient/marks"
+	"github.com/magodo/terraform-client-go/tfclient/typ"
+	"github.com/magodo/terraform-client-go/tfclient/valuejson"
+	"github.com/zclconf/go-cty/cty"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
+)
+
+// ReadArg returns the content of a flag value, which is either the literal content, or
+// "@<path>" that refers to a file containing the content.
+func ReadArg(s string) ([]byte, error) {
+	if path, ok := strings.CutPrefix(s, "@"); ok {
+		return os.ReadFile(path)
+	}
+	return []byte(s), nil
+}
+
+// DecodeValue decodes the JSON encoded value of the given type. An empty input results in a null value.
+func DecodeValue(b []byte, ty cty.Type) (cty.Value, error) {
+	if len(bytes.TrimSpace(b)) == 0 {
+		return cty.NullVal(ty), nil
+	}
+	return ctyjson.Unmarshal(b, ty)
+}
+
+// DecodeArg decodes the JSON encoded value of the given type from the flag value, as is read by ReadArg.
+func DecodeArg(s string, ty cty.Type) (cty.Value, error) {
+	b, err := ReadArg(s)
+	if err != nil {
+		return cty.NilVal, err
+	}
+	return DecodeValue(b, ty)
+}
+
+// DecodeBlockValue decodes the JSON encoded value of the block, where the optional attributes and the nested blocks
+// can be omitted, and the values of compatible types are converted. An empty input results in a null value.
+func DecodeBlockValue(b []byte, sch *tfjson.SchemaBlock) (cty.Value, error) {
+	v, err := configschema.SchemaBlockUnmarshalJSON(sch, b)
+	return v, formatPathError(err)
+}
+
+// DecodeBlockArg decodes the JSON encoded value of the block from the flag value, as is read by ReadArg.
+func DecodeBlockArg(s string, sch *tfjson.SchemaBlock) (cty.Value, error) {
+	b, err := ReadArg(s)
+	if err != nil {
+		return cty.NilVal, err
+	}
+	return DecodeBlockValue(b, sch)
+}
+
+// DecodeIdentityArg decodes the JSON encoded resource identity from the flag value, as is read by ReadArg, where
+// the optional attributes can be omitted. An empty input results in a null value.
+func DecodeIdentityArg(s string, sch *tfjson.SchemaNestedAttributeType) (cty.Value, error) {
+	b, err := ReadArg(s)
+	if err != nil {
+		return cty.NilVal, err
+	}
+	v, err := configschema.SchemaNestedAttributeTypeUnmarshalJSON(sch, b)
+	return v, formatPathError(err)
+}
+
+// formatPathError prefixes the message of the cty.PathError with the formatted path.
+func formatPathError(err error) error {
+	var perr cty.PathError
+	if errors.As(err, &perr) && len(perr.Path) != 0 {
+		return errors.New(typ.FormatError(perr))
+	}
+	return err
+}
+
+// DecodeBytes decodes the base64 encoded bytes from the flag value, as is read by ReadArg.
+func DecodeBytes(s string) ([]byte, error) {
+	b, err := ReadArg(s)
+	if err != nil {
+		return nil, err
+	}
+	return base64.StdEncoding.DecodeString(strings.TrimSpace(string(b)))
+}
+
+// Result is the output of a command, which consists of ordered fields.
+type Result struct {
+	fields []resultField
+}
+
+type resultField struct {
+	name  string
+	value any
+}
+
+// Add adds a field to the result. The value can be a cty.Value, a typ.Diagnostics, a []byte,
+// or any other JSON marshalable value.
+func (r *Result) Add(name string, value any) *Result {
+	r.fields = append(r.fields, resultField{name: name, value: value})
+	return r
+}
+
+// RedactSensitive returns a copy of the result, whose cty.Value fields are redacted by marks.RedactSensitive.
+func (r *Result) RedactSensitive() *Result {
+	out := &Result{fields: make([]resultField, len(r.fields))}
+	for i, f := range r.fields {
+		if v, ok := f.value.(cty.Value); ok && v != cty.NilVal {
+			f.value = marks.RedactSensitive(v)
+		}
+		out.fields[i] = f
+	}
+	return out
+}
+
+// Write writes the result in the given format. The sensitive values are written as is, unless they are redacted by
+// RedactSensitive beforehand. The marks are only kept by the OutputValueJSON format.
+func (r *Result) Write(w io.Writer, format string) error {
+	switch format {
+	case OutputHCL:
+		return r.writeHCL(w)
+	case OutputValueJSON:
+		return r.writeJSON(w, true)
+	default:
+		return r.writeJSON(w, false)
+	}
+}
+
+// writeJSON writes the result in JSON, where the values are either encoded by the valuejson package, or with the
+// unknown values written as null and their paths collected in the "unknown_paths".
+func (r *Result) writeJSON(w io.Writer, valueJSON bool) error {
+	var buf bytes.Buffer
+	buf.WriteString("{")
+	unknowns := map[string][]string{}
+	for i, f := range r.fields {
+		if i != 0 {
+			buf.WriteString(",")
+		}
+		k, _ := json.Marshal(f.name)
+		buf.Write(k)
+		buf.WriteString(":")
+		var b []byte
+		var err error
+		switch v := f.value.(type) {
+		case cty.Value:
+			if valueJSON {
+				b, err = valuejson.Marshal(v)
+				break
+			}
+			var paths []string
+			v, paths = nullUnknowns(v)
+			if len(paths) != 0 {
+				unknowns[f.name] = paths
+			}
+			b, err = ctyjson.Marshal(v, cty.DynamicPseudoType)
+			if err == nil {
+				// Unwrap the {"value": ..., "type": ...} form, as the type is known by schema.
+				var dyn struct {
+					Value json.RawMessage `json:"value"`
+				}
+				err = json.Unmarshal(b, &dyn)
+				b = dyn.Value
+			}
+		case typ.Diagnostics:
+			b, err = json.Marshal(diagsJSON(v))
+		default:
+			b, err = json.Marshal(v)
+		}
+		if err != nil {
+			return fmt.Errorf("marshalling %q: %v", f.name, err)
+		}
+		buf.Write(b)
+	}
+	if len(unknowns) != 0 {
+		b, err := json.Marshal(unknowns)
+		if err != nil {
+			return err
+		}
+		buf.WriteString(`,"unknown_paths":`)
+		buf.Write(b)
+	}
+	buf.WriteString("}")
+
+	var out bytes.Buffer
+	if err := json.Indent(&out, buf.Bytes(), "", "  "); err != nil {
+		return err
+	}
+	out.WriteString("\n")
+	_, err := w.Write(out.Bytes())
+	return err
+}
+
+func (r *Result) writeHCL(w io.Writer) error {
+	f := hclwrite.NewEmptyFile()
+	body := f.Body()
+	for _, fd := range r.fields {
+		switch v := fd.value.(type) {
+		case cty.Value:
+			v, paths := nullUnknowns(v)
+			if len(paths) != 0 {
+				body.AppendUnstructuredTokens(hclwrite.Tokens{
+					{Type: hclsyntax.TokenComment, Bytes: []byte(fmt.Sprintf("# unknown: %s\n", strings.Join(paths, ", ")))},
+				})
+			}
+			body.SetAttributeValue(fd.name, v)
+		case typ.Diagnostics:
+			if len(v) == 0 {
+				continue
+			}
+			var lines []string
+			for _, d := range diagsJSON(v) {
+				lines = append(lines, fmt.Sprintf("# %s: %s: %s", d.Severity, d.Summary, d.Detail))
+			}
+			body.AppendUnstructuredTokens(hclwrite.Tokens{
+				{Type: hclsyntax.TokenComment, Bytes: []byte(strings.Join(lines, "\n") + "\n")},
+			})
+		default:
+			b, err := json.Marshal(v)
+			if err != nil {
+				return fmt.Errorf("marshalling %q: %v", fd.name, err)
+			}
+			ty, err := ctyjson.ImpliedType(b)
+			if err != nil {
+				return fmt.Errorf("marshalling %q: %v", fd.name, err)
+			}
+			cv, err := ctyjson.Unmarshal(b, ty)
+			if err != nil {
+				return fmt.Errorf("marshalling %q: %v", fd.name, err)
+			}
+			body.SetAttributeValue(fd.name, cv)
+		}
+	}
+	_, err := w.Write(f.Bytes())
+	return err
+}
+
+type diagJSON struct {
+	Severity  string `json:"severity"`
+	Summary   string `json:"summary"`
+	Detail    string `json:"detail,omitempty"`
+	Attribute string `json:"attribute,omitempty"`
+}
+
+func diagsJSON(diags typ.Diagnostics) []diagJSON {
+	out := []diagJSON{}
+	for _, d := range diags {
+		sev := "error"
+		if d.Severity == typ.Warning {
+			sev = "warning"
+		}
+		out = append(out, diagJSON{
+			Severity:  sev,
+			Summary:   d.Summary,
+			Detail:    d.Detail,
+			Attribute: typ.FormatCtyPath(d.Attribute),
+		})
+	}
+	return out
+}
+
+// nullUnknowns replaces the unknown values with null, and returns the formatted paths of them.
+func nullUnknowns(v cty.Value) (cty.Value, []string) {
+	if v == cty.NilVal {
+		return cty.NullVal(cty.DynamicPseudoType), nil
+	}
+	v, _ = v.UnmarkDeep()
+	var paths []string
+	v, _ = cty.Transform(v, func(p cty.Path, v cty.Value) (cty.Value, error) {
+		if !v.IsKnown() {
+			paths = append(paths, typ.FormatCtyPath(p))
+			return cty.NullVal(v.Type()), nil
+		}
+		return v, nil
+	})
+	sort.Strings(paths)
+	return v, paths
+}
+
+// OutputValue returns the value to output, whose sensitive values are redacted by marks.RedactSensitive, unless
+// showSensitive is set, in which case only the marks are removed.
+func OutputValue(v cty.Value, showSensitive bool) cty.Value {
+	if showSensitive {
+		v, _ = v.UnmarkDeep()
+		return v
+	}
+	return marks.RedactSensitive(v)
+}
@@ -0,0 +1,91 @@
+package cli
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/magodo/terraform-client-go/tfclient/marks"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestRedactSensitive(t *testing.T) {
+	val := cty.ObjectVal(map[string]cty.Value{
+		"id":     cty.StringVal("1"),
+		"secret": cty.NumberIntVal(1).Mark(marks.Sensitive),
+		"list": cty.ListVal([]cty.Value{
+			cty.ObjectVal(map[string]cty.Value{"token": cty.StringVal("t").Mark(marks.Sensitive)}),
+		}),
+		"map": cty.MapVal(map[string]cty.Value{"k": cty.StringVal("v")}).Mark("other"),
+	})
+
+	var buf bytes.Buffer
+	r := (&Result{}).Add("state", val)
+	if err := r.RedactSensitive().Write(&buf, OutputJSON); err != nil {
+		t.Fatal(err)
+	}
+	want := `{
+  "state": {
+    "id": "1",
+    "list": [
+      {
+        "token": "(sensitive value)"
+      }
+    ],
+    "map": {
+      "k": "v"
+    },
+    "secret": "(sensitive value)"
+  }
+}
+`
+	if diff := cmp.Diff(want, buf.String()); diff != "" {
+		t.Errorf("wrong output\n%s", diff)
+	}
+
+	// The sensitive values are written as is without redaction.
+	buf.Reset()
+	if err := r.Write(&buf, OutputJSON); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(buf.Bytes(), []byte(`"token": "t"`)) {
+		t.Errorf("wrong output\n%s", buf.String())
+	}
+}
+
+func TestWriteValueJSON(t *testing.T) {
+	val := cty.ObjectVal(map[string]cty.Value{
+		"id":     cty.UnknownVal(cty.String),
+		"secret": cty.StringVal("s").Mark(marks.Sensitive),
+	})
+
+	var buf bytes.Buffer
+	if err := (&Result{}).Add("planned_state", val).Write(&buf, OutputValueJSON); err != nil {
+		t.Fatal(err)
+	}
+	want := `{
+  "planned_state": {
+    "type": [
+      "object",
+      {
+        "id": "string",
+        "secret": "string"
+      }
+    ],
+    "value": {
+      "id": null,
+      "secret": "s"
+    },
+    "unknown": {
+      "id": true
+    },
+    "sensitive": {
+      "secret": true
+    }
+  }
+}
+`
+	if diff := cmp.Diff(want, buf.String()); diff != "" {
+		t.Errorf("wrong output\n%s", diff)
+	}
+}
@@ -0,0 +1,322 @@
+package repl
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	tfjson "github.com/hashicorp/terraform-json"
+	"github.com/magodo/terraform-client-go/tfclient/typ"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// kind is the kind of the type name argument of a command.
+type kind int
+
+const (
+	kindNone kind = iota
+	kindResource
+	kindDataSource
+	kindListResource
+	kindAny
+)
+
+type command struct {
+	args     string
+	synopsis string
+	kind     kind
+	// body indicates the argument is a block body of the schema of the type.
+	body bool
+	// exec executes the command and returns the result value, along with the warnings.
+	exec func(s *Session, stmt *statement) (cty.Value, typ.Diagnostics, error)
+	// print executes the command that has no result value, and returns the output.
+	print func(s *Session, stmt *statement) (string, error)
+}
+
+var commands map[string]command
+
+func init() {
+	commands = map[string]command{
+		"read-data": {args: "<type> { <config> }", synopsis: "Read a data source", kind: kindDataSource, body: true, exec: (*Session).readData},
+		"import":    {args: "<type> <id>", synopsis: "Import a resource by id, the result is the imported state", kind: kindResource, exec: (*Session).importResource},
+		"read":      {args: "<type> <state>", synopsis: "Read a resource from its prior state", kind: kindResource, exec: (*Session).readResource},
+		"list":      {args: "<type> { <config> }", synopsis: fmt.Sprintf("List resources, up to %d results", ListLimit), kind: kindListResource, body: true, exec: (*Session).listResource},
+		"describe":  {args: "<name>", synopsis: "Describe the schema of a type or the signature of a function", kind: kindAny, print: (*Session).describe},
+		"vars":      {synopsis: "List the variables", print: (*Session).printVars},
+		"help":      {synopsis: "Show this help", print: (*Session).help},
+		"exit":      {synopsis: "Exit the session", print: func(*Session, *statement) (string, error) { return "", ErrExit }},
+	}
+}
+
+func clientDiags(diags typ.Diagnostics) (typ.Diagnostics, error) {
+	if diags.HasErrors() {
+		return nil, diags.Err()
+	}
+	return diags, nil
+}
+
+func (s *Session) readData(stmt *statement) (cty.Value, typ.Diagnostics, error) {
+	sch, ok := s.schema.DataSources[stmt.typeName]
+	if !ok {
+		return cty.NilVal, nil, fmt.Errorf("no data source named %q", stmt.typeName)
+	}
+	config, err := s.decodeBody(stmt, sch.Block)
+	if err != nil {
+		return cty.NilVal, nil, err
+	}
+	resp, diags := s.client.ReadDataSource(s.ctx, typ.ReadDataSourceRequest{
+		TypeName: stmt.typeName,
+		Config:   config,
+	})
+	warnings, err := clientDiags(diags)
+	if err != nil {
+		return cty.NilVal, nil, err
+	}
+	if resp.Deferred != nil {
+		return cty.NilVal, nil, fmt.Errorf("the read is deferred by the provider: %s", resp.Deferred.Reason)
+	}
+	return resp.State, warnings, nil
+}
+
+func (s *Session) importResource(stmt *statement) (cty.Value, typ.Diagnostics, error) {
+	if _, ok := s.schema.ResourceTypes[stmt.typeName]; !ok {
+		return cty.NilVal, nil, fmt.Errorf("no resource named %q", stmt.typeName)
+	}
+	id, err := s.evalArg(stmt, cty.String)
+	if err != nil {
+		return cty.NilVal, nil, err
+	}
+	if id.IsNull() || !id.IsKnown() {
+		return cty.NilVal, nil, fmt.Errorf("the id must be a known string")
+	}
+	resp, diags := s.client.ImportResourceState(s.ctx, typ.ImportResourceStateRequest{
+		TypeName: stmt.typeName,
+		ID:       id.AsString(),
+	})
+	warnings, err := clientDiags(diags)
+	if err != nil {
+		return cty.NilVal, nil, err
+	}
+	if resp.Deferred != nil {
+		return cty.NilVal, nil, fmt.Errorf("the import is deferred by the provider: %s", resp.Deferred.Reason)
+	}
+	for _, res := range resp.ImportedResources {
+		if res.TypeName == stmt.typeName {
+			return res.State, warnings, nil
+		}
+	}
+	return cty.NilVal, nil, fmt.Errorf("no %s is imported", stmt.typeName)
+}
+
+func (s *Session) readResource(stmt *statement) (cty.Value, typ.Diagnostics, error) {
+	if _, ok := s.schema.ResourceTypes[stmt.typeName]; !ok {
+		return cty.NilVal, nil, fmt.Errorf("no resource named %q", stmt.typeName)
+	}
+	state, err := s.evalArg(stmt, s.schema.ResourceTypesCty[stmt.typeName])
+	if err != nil {
+		return cty.NilVal, nil, err
+	}
+	resp, diags := s.client.ReadResource(s.ctx, typ.ReadResourceRequest{
+		TypeName:   stmt.typeName,
+		PriorState: state,
+	})
+	warnings, err := clientDiags(diags)
+	if err != nil {
+		return cty.NilVal, nil, err
+	}
+	if resp.Deferred != nil {
+		return cty.NilVal, nil, fmt.Errorf("the read is deferred by the provider: %s", resp.Deferred.Reason)
+	}
+	return resp.NewState, warnings, nil
+}
+
+func (s *Session) listResource(stmt *statement) (cty.Value, typ.Diagnostics, error) {
+	sch, ok := s.schema.ListResourceTypes[stmt.typeName]
+	if !ok {
+		return cty.NilVal, nil, fmt.Errorf("no list resource named %q", stmt.typeName)
+	}
+	config, err := s.decodeBody(stmt, sch.Block)
+	if err != nil {
+		return cty.NilVal, nil, err
+	}
+	resp, diags := s.client.ListResource(s.ctx, typ.ListResourceRequest{
+		TypeName:              stmt.typeName,
+		Config:                cty.ObjectVal(map[string]cty.Value{"config": config}),
+		IncludeResourceObject: true,
+		Limit:                 ListLimit,
+	})
+	warnings, err := clientDiags(diags)
+	if err != nil {
+		return cty.NilVal, nil, err
+	}
+	if resp.Result.IsNull() || !resp.Result.IsKnown() || !resp.Result.Type().IsObjectType() || !resp.Result.Type().HasAttribute("data") {
+		return cty.EmptyTupleVal, warnings, nil
+	}
+	return resp.Result.GetAttr("data"), warnings, nil
+}
+
+func (s *Session) typeNames(k kind) []string {
+	var out []string
+	add := func(m map[string]tfjson.Schema) {
+		for name := range m {
+			out = append(out, name)
+		}
+	}
+	switch k {
+	case kindResource:
+		add(s.schema.ResourceTypes)
+	case kindDataSource:
+		add(s.schema.DataSources)
+	case kindListResource:
+		add(s.schema.ListResourceTypes)
+	case kindAny:
+		add(s.schema.ResourceTypes)
+		add(s.schema.DataSources)
+		add(s.schema.EphemeralResourceTypes)
+		add(s.schema.ListResourceTypes)
+		add(s.schema.Actions)
+		for name := range s.schema.Functions {
+			out = append(out, name)
+		}
+	}
+	return out
+}
+
+func (s *Session) blockOf(k kind, typeName string) *tfjson.SchemaBlock {
+	var m map[string]tfjson.Schema
+	switch k {
+	case kindResource:
+		m = s.schema.ResourceTypes
+	case kindDataSource:
+		m = s.schema.DataSources
+	case kindListResource:
+		m = s.schema.ListResourceTypes
+	}
+	if sch, ok := m[typeName]; ok {
+		return sch.Block
+	}
+	return nil
+}
+
+func (s *Session) describe(stmt *statement) (string, error) {
+	var sb strings.Builder
+	for _, kind := range []struct {
+		name string
+		m    map[string]tfjson.Schema
+	}{
+		{"resource", s.schema.ResourceTypes},
+		{"data source", s.schema.DataSources},
+		{"ephemeral resource", s.schema.EphemeralResourceTypes},
+		{"list resource", s.schema.ListResourceTypes},
+		{"action", s.schema.Actions},
+	} {
+		sch, ok := kind.m[stmt.typeName]
+		if !ok {
+			continue
+		}
+		fmt.Fprintf(&sb, "%s %q (version %d)\n", kind.name, stmt.typeName, sch.Version)
+		describeBlock(&sb, sch.Block, "  ")
+	}
+	if decl, ok := s.schema.Functions[stmt.typeName]; ok {
+		sb.WriteString(FunctionSignature(stmt.typeName, decl) + "\n")
+		if decl.Summary != "" {
+			sb.WriteString("  " + decl.Summary + "\n")
+		}
+	}
+	if sb.Len() == 0 {
+		return "", fmt.Errorf("no type or function named %q", stmt.typeName)
+	}
+	return sb.String(), nil
+}
+
+// FunctionSignature returns the signature of a provider function, e.g. "f(a string, ...b number) bool".
+func FunctionSignature(name string, decl typ.FunctionDecl) string {
+	var params []string
+	for _, p := range decl.Parameters {
+		params = append(params, p.Name+" "+p.Type.FriendlyNameForConstraint())
+	}
+	if p := decl.VariadicParameter; p != nil {
+		params = append(params, "..."+p.Name+" "+p.Type.FriendlyNameForConstraint())
+	}
+	return fmt.Sprintf("%s(%s) %s", name, strings.Join(params, ", "), decl.ReturnType.FriendlyNameForConstraint())
+}
+
+func describeBlock(sb *strings.Builder, block *tfjson.SchemaBlock, indent string) {
+	if block == nil {
+		return
+	}
+	var names []string
+	for name := range block.Attributes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		attr := block.Attributes[name]
+		var flags []string
+		for _, f := range []struct {
+			set  bool
+			name string
+		}{
+			{attr.Required, "required"},
+			{attr.Optional, "optional"},
+			{attr.Computed, "computed"},
+			{attr.Sensitive, "sensitive"},
+			{attr.Deprecated, "deprecated"},
+		} {
+			if f.set {
+				flags = append(flags, f.name)
+			}
+		}
+		if attr.AttributeNestedType != nil {
+			fmt.Fprintf(sb, "%s%s (%s, nested %s)\n", indent, name, strings.Join(flags, ", "), attr.AttributeNestedType.NestingMode)
+			describeBlock(sb, &tfjson.SchemaBlock{Attributes: attr.AttributeNestedType.Attributes}, indent+"  ")
+			continue
+		}
+		fmt.Fprintf(sb, "%s%s %s (%s)\n", indent, name, attr.AttributeType.FriendlyNameForConstraint(), strings.Join(flags, ", "))
+	}
+
+	names = names[:0]
+	for name := range block.NestedBlocks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		nb := block.NestedBlocks[name]
+		fmt.Fprintf(sb, "%s%s (block, %s)\n", indent, name, nb.NestingMode)
+		describeBlock(sb, nb.Block, indent+"  ")
+	}
+}
+
+func (s *Session) printVars(*statement) (string, error) {
+	var names []string
+	for name := range s.vars {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var sb strings.Builder
+	for _, name := range names {
+		fmt.Fprintf(&sb, "%s: %s\n", name, s.vars[name].Type().FriendlyName())
+	}
+	return sb.String(), nil
+}
+
+func (s *Session) help(*statement) (string, error) {
+	var names []string
+	width := 0
+	for name, cmd := range commands {
+		names = append(names, name)
+		width = max(width, len(name+" "+cmd.args))
+	}
+	sort.Strings(names)
+
+	var sb strings.Builder
+	sb.WriteString("Statements:\n")
+	fmt.Fprintf(&sb, "  %-*s  %s\n", width, "<expr>", "Evaluate an HCL expression, where provider functions can be called by name")
+	fmt.Fprintf(&sb, "  %-*s  %s\n", width, "<name> = <stmt>", "Keep the result of the statement as a variable")
+	for _, name := range names {
+		cmd := commands[name]
+		fmt.Fprintf(&sb, "  %-*s  %s\n", width, strings.TrimSpace(name+" "+cmd.args), cmd.synopsis)
+	}
+	fmt.Fprintf(&sb, "\nThe result of the last statement is kept as %q.\n", LastVar)
+	return sb.String(), nil
+}
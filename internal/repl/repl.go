@@ -0,0 +1,423 @@
+// Package repl implements an interactive session against a configured provider.
+//
+// Each input is a statement, which is either an HCL expression, or one of the commands that invoke the
+// provider (see the "help" command). The result of a statement can be kept as a variable by prefixing it
+// with "<name> =", while the result of the last statement is always kept as "_". Provider functions are
+// callable by their names in expressions.
+package repl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hcldec"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	tfjson "github.com/hashicorp/terraform-json"
+	"github.com/magodo/terraform-client-go/tfclient"
+	"github.com/magodo/terraform-client-go/tfclient/configschema"
+	"github.com/magodo/terraform-client-go/tfclient/marks"
+	"github.com/magodo/terraform-client-go/tfclient/typ"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/convert"
+	"github.com/zclconf/go-cty/cty/function"
+)
+
+// LastVar is the name of the variable that holds the result of the last statement.
+const LastVar = "_"
+
+// ListLimit is the maximum number of results returned by the list command.
+const ListLimit = 100
+
+// ErrExit is returned by Exec when the session is requested to exit.
+var ErrExit = errors.New("exit")
+
+// Session is an interactive session against a configured provider.
+type Session struct {
+	// ShowSensitive shows the values marked as sensitive in the output, which are redacted by default. The variables
+	// always hold the original values.
+	ShowSensitive bool
+
+	ctx    context.Context
+	client tfclient.Client
+	schema *typ.GetProviderSchemaResponse
+	vars   map[string]cty.Value
+	funcs  map[string]function.Function
+}
+
+// New creates a session against the client, which is expected to be configured already.
+func New(ctx context.Context, client tfclient.Client, schema *typ.GetProviderSchemaResponse) *Session {
+	s := &Session{
+		ctx:    ctx,
+		client: client,
+		schema: schema,
+		vars:   map[string]cty.Value{},
+		funcs:  map[string]function.Function{},
+	}
+	for name, decl := range schema.Functions {
+		s.funcs[name] = s.providerFunction(name, decl)
+	}
+	return s
+}
+
+// Vars returns the variables of the session.
+func (s *Session) Vars() map[string]cty.Value {
+	return s.vars
+}
+
+// providerFunction returns the cty function that calls the provider function.
+func (s *Session) providerFunction(name string, decl typ.FunctionDecl) function.Function {
+	param := func(p typ.FunctionParam) function.Parameter {
+		return function.Parameter{
+			Name:         p.Name,
+			Description:  p.Description,
+			Type:         p.Type,
+			AllowNull:    p.AllowNullValue,
+			AllowUnknown: p.AllowUnknownValues,
+		}
+	}
+	spec := &function.Spec{
+		Description: decl.Summary,
+		Type:        function.StaticReturnType(decl.ReturnType),
+		Impl: func(args []cty.Value, _ cty.Type) (cty.Value, error) {
+			resp, diags := s.client.CallFunction(s.ctx, typ.CallFunctionRequest{
+				FunctionName: name,
+				Arguments:    args,
+			})
+			if diags.HasErrors() {
+				return cty.NilVal, diags.Err()
+			}
+			if resp.Err != nil {
+				return cty.NilVal, resp.Err
+			}
+			return resp.Result, nil
+		},
+	}
+	for _, p := range decl.Parameters {
+		spec.Params = append(spec.Params, param(p))
+	}
+	if decl.VariadicParameter != nil {
+		p := param(*decl.VariadicParameter)
+		spec.VarParam = &p
+	}
+	return function.New(spec)
+}
+
+func (s *Session) evalContext() *hcl.EvalContext {
+	return &hcl.EvalContext{
+		Variables: s.vars,
+		Functions: s.funcs,
+	}
+}
+
+// Incomplete reports whether the input has unclosed brackets, in which case more lines are expected.
+func Incomplete(src string) bool {
+	tokens, _ := hclsyntax.LexConfig([]byte(src), "", hcl.InitialPos)
+	depth := 0
+	for _, tok := range tokens {
+		switch tok.Type {
+		case hclsyntax.TokenOBrace, hclsyntax.TokenOBrack, hclsyntax.TokenOParen,
+			hclsyntax.TokenTemplateInterp, hclsyntax.TokenTemplateControl, hclsyntax.TokenOHeredoc:
+			depth++
+		case hclsyntax.TokenCBrace, hclsyntax.TokenCBrack, hclsyntax.TokenCParen,
+			hclsyntax.TokenTemplateSeqEnd, hclsyntax.TokenCHeredoc:
+			depth--
+		}
+	}
+	return depth > 0
+}
+
+// Exec executes a statement, and returns the output to be printed.
+func (s *Session) Exec(src string) (string, error) {
+	stmt, err := parseStatement(src)
+	if err != nil {
+		return "", err
+	}
+	if stmt == nil {
+		return "", nil
+	}
+
+	var (
+		v        cty.Value
+		warnings typ.Diagnostics
+	)
+	if stmt.command == "" {
+		expr, diags := hclsyntax.ParseExpression(stmt.arg, "<input>", stmt.argPos)
+		if diags.HasErrors() {
+			return "", diags
+		}
+		v, diags = expr.Value(s.evalContext())
+		if diags.HasErrors() {
+			return "", diags
+		}
+	} else {
+		cmd := commands[stmt.command]
+		if cmd.kind != kindNone && stmt.typeName == "" {
+			return "", fmt.Errorf("usage: %s %s", stmt.command, cmd.args)
+		}
+		if cmd.exec == nil {
+			return cmd.print(s, stmt)
+		}
+		v, warnings, err = cmd.exec(s, stmt)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	s.vars[LastVar] = v
+	if stmt.name != "" {
+		s.vars[stmt.name] = v
+	}
+
+	var sb strings.Builder
+	for _, w := range warnings {
+		fmt.Fprintf(&sb, "# Warning: %s\n", strings.TrimSpace(w.Summary+": "+w.Detail))
+	}
+	if !s.ShowSensitive && v != cty.NilVal {
+		v = marks.RedactSensitive(v)
+	}
+	sb.WriteString(FormatValue(v))
+	return sb.String(), nil
+}
+
+// statement is a parsed input of the session.
+type statement struct {
+	// name is the variable name that the result is assigned to, if any.
+	name string
+	// command is the command name, which is empty for an expression.
+	command string
+	// typeName is the type name argument of the command, if any.
+	typeName string
+	// arg is the remaining source, which is the expression, or the argument of the command.
+	arg    []byte
+	argPos hcl.Pos
+}
+
+func parseStatement(src string) (*statement, error) {
+	b := []byte(src)
+	tokens, diags := hclsyntax.LexConfig(b, "<input>", hcl.InitialPos)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	tokens = significantTokens(tokens)
+	if len(tokens) == 0 {
+		return nil, nil
+	}
+
+	stmt := &statement{}
+	if len(tokens) >= 2 && tokens[0].Type == hclsyntax.TokenIdent && tokens[1].Type == hclsyntax.TokenEqual {
+		stmt.name = string(tokens[0].Bytes)
+		if _, ok := commands[stmt.name]; ok {
+			return nil, fmt.Errorf("%q is a reserved command name", stmt.name)
+		}
+		if !hclsyntax.ValidIdentifier(stmt.name) {
+			return nil, fmt.Errorf("invalid variable name %q", stmt.name)
+		}
+		tokens = tokens[2:]
+		if len(tokens) == 0 {
+			return nil, fmt.Errorf("missing the value to assign to %q", stmt.name)
+		}
+	}
+
+	// A command is an identifier that is followed by another identifier, or nothing.
+	if tokens[0].Type == hclsyntax.TokenIdent {
+		if _, ok := commands[string(tokens[0].Bytes)]; ok && (len(tokens) == 1 || tokens[1].Type == hclsyntax.TokenIdent) {
+			stmt.command = string(tokens[0].Bytes)
+			tokens = tokens[1:]
+			if len(tokens) != 0 {
+				stmt.typeName = string(tokens[0].Bytes)
+				tokens = tokens[1:]
+			}
+		}
+	}
+
+	if len(tokens) != 0 {
+		stmt.arg = b[tokens[0].Range.Start.Byte:]
+		stmt.argPos = tokens[0].Range.Start
+	}
+	if stmt.name != "" && stmt.command != "" && commands[stmt.command].exec == nil {
+		return nil, fmt.Errorf("the result of %q can't be assigned", stmt.command)
+	}
+	return stmt, nil
+}
+
+// significantTokens removes the trailing newlines and EOF tokens.
+func significantTokens(tokens hclsyntax.Tokens) hclsyntax.Tokens {
+	for len(tokens) != 0 {
+		switch tokens[len(tokens)-1].Type {
+		case hclsyntax.TokenEOF, hclsyntax.TokenNewline:
+			tokens = tokens[:len(tokens)-1]
+			continue
+		}
+		break
+	}
+	return tokens
+}
+
+// decodeBody decodes the "{ ... }" argument of a command as a block body of the schema.
+func (s *Session) decodeBody(stmt *statement, block *tfjson.SchemaBlock) (cty.Value, error) {
+	src := strings.TrimSpace(string(stmt.arg))
+	if src == "" {
+		src = "{}"
+	}
+	if !strings.HasPrefix(src, "{") || !strings.HasSuffix(src, "}") {
+		return cty.NilVal, fmt.Errorf("the config of %s must be a block body enclosed in braces", stmt.command)
+	}
+	pos := stmt.argPos
+	pos.Byte++
+	pos.Column++
+	f, diags := hclsyntax.ParseConfig([]byte(src[1:len(src)-1]), "<input>", pos)
+	if diags.HasErrors() {
+		return cty.NilVal, diags
+	}
+	v, diags := hcldec.Decode(f.Body, configschema.DecoderSpec(block), s.evalContext())
+	if diags.HasErrors() {
+		return cty.NilVal, diags
+	}
+	return v, nil
+}
+
+// evalArg evaluates the expression argument of a command, and converts it to the given type.
+func (s *Session) evalArg(stmt *statement, ty cty.Type) (cty.Value, error) {
+	if len(stmt.arg) == 0 {
+		return cty.NilVal, fmt.Errorf("usage: %s %s", stmt.command, commands[stmt.command].args)
+	}
+	expr, diags := hclsyntax.ParseExpression(stmt.arg, "<input>", stmt.argPos)
+	if diags.HasErrors() {
+		return cty.NilVal, diags
+	}
+	v, diags := expr.Value(s.evalContext())
+	if diags.HasErrors() {
+		return cty.NilVal, diags
+	}
+	v, err := convert.Convert(v, ty)
+	if err != nil {
+		return cty.NilVal, fmt.Errorf("invalid value for %s: %v", stmt.typeName, err)
+	}
+	return v, nil
+}
+
+// FormatValue formats the value in HCL expression syntax. Unknown values are written as null.
+func FormatValue(v cty.Value) string {
+	if v == cty.NilVal {
+		return "null\n"
+	}
+	v, _ = v.UnmarkDeep()
+	v, _ = cty.Transform(v, func(_ cty.Path, v cty.Value) (cty.Value, error) {
+		if !v.IsKnown() {
+			return cty.NullVal(v.Type()), nil
+		}
+		return v, nil
+	})
+	return string(hclwrite.Format(hclwrite.TokensForValue(v).Bytes())) + "\n"
+}
+
+// Complete returns the completion candidates of the word at the position of the line, as is expected
+// by liner.WordCompleter.
+func (s *Session) Complete(line string, pos int) (head string, completions []string, tail string) {
+	head, tail = line[:pos], line[pos:]
+	start := len(head)
+	for start > 0 && isWordByte(head[start-1]) {
+		start--
+	}
+	word := head[start:]
+	head = head[:start]
+
+	fields := strings.Fields(head)
+	if len(fields) >= 2 && fields[1] == "=" {
+		fields = fields[2:]
+	}
+
+	var candidates []string
+	cmd, isCmd := commands[firstOf(fields)]
+	switch {
+	case len(fields) == 0:
+		for name := range commands {
+			candidates = append(candidates, name)
+		}
+		candidates = append(candidates, s.exprCandidates(word)...)
+	case isCmd && len(fields) == 1 && cmd.kind != kindNone:
+		candidates = s.typeNames(cmd.kind)
+	case isCmd && cmd.body && len(fields) >= 2:
+		if !strings.Contains(word, ".") && !strings.HasSuffix(strings.TrimSpace(head), "=") {
+			if block := s.blockOf(cmd.kind, fields[1]); block != nil {
+				for name := range block.Attributes {
+					candidates = append(candidates, name)
+				}
+				for name := range block.NestedBlocks {
+					candidates = append(candidates, name)
+				}
+			}
+		}
+		candidates = append(candidates, s.exprCandidates(word)...)
+	default:
+		candidates = s.exprCandidates(word)
+	}
+
+	for _, c := range candidates {
+		if strings.HasPrefix(c, word) {
+			completions = append(completions, c)
+		}
+	}
+	sort.Strings(completions)
+	completions = uniq(completions)
+	return head, completions, tail
+}
+
+// exprCandidates returns the variable and function names, or the attribute names when the word is a
+// traversal of a variable.
+func (s *Session) exprCandidates(word string) []string {
+	var out []string
+	if idx := strings.LastIndex(word, "."); idx != -1 {
+		parts := strings.Split(word[:idx], ".")
+		v, ok := s.vars[parts[0]]
+		if !ok {
+			return nil
+		}
+		ty := v.Type()
+		for _, p := range parts[1:] {
+			if !ty.IsObjectType() || !ty.HasAttribute(p) {
+				return nil
+			}
+			ty = ty.AttributeType(p)
+		}
+		if ty.IsObjectType() {
+			for name := range ty.AttributeTypes() {
+				out = append(out, word[:idx+1]+name)
+			}
+		}
+		return out
+	}
+	for name := range s.vars {
+		out = append(out, name)
+	}
+	for name := range s.funcs {
+		out = append(out, name+"(")
+	}
+	return out
+}
+
+func isWordByte(c byte) bool {
+	return c == '_' || c == '-' || c == '.' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
+
+func firstOf(l []string) string {
+	if len(l) == 0 {
+		return ""
+	}
+	return l[0]
+}
+
+func uniq(l []string) []string {
+	var out []string
+	for i, v := range l {
+		if i == 0 || l[i-1] != v {
+			out = append(out, v)
+		}
+	}
+	return out
+}
@@ -0,0 +1,165 @@
+package repl
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	tfjson "github.com/hashicorp/terraform-json"
+	"github.com/magodo/terraform-client-go/tfclient"
+	"github.com/magodo/terraform-client-go/tfclient/configschema"
+	"github.com/magodo/terraform-client-go/tfclient/typ"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// fakeClient implements the RPCs used by the tests, the others panic.
+type fakeClient struct {
+	tfclient.Client
+}
+
+func (fakeClient) ReadDataSource(_ context.Context, req typ.ReadDataSourceRequest) (*typ.ReadDataSourceResponse, typ.Diagnostics) {
+	attrs := req.Config.AsValueMap()
+	attrs["id"] = cty.StringVal("id-" + attrs["name"].AsString())
+	return &typ.ReadDataSourceResponse{State: cty.ObjectVal(attrs)}, nil
+}
+
+func (fakeClient) CallFunction(_ context.Context, req typ.CallFunctionRequest) (*typ.CallFunctionResponse, typ.Diagnostics) {
+	return &typ.CallFunctionResponse{Result: cty.StringVal(strings.ToUpper(req.Arguments[0].AsString()))}, nil
+}
+
+func newTestSession() *Session {
+	block := &tfjson.SchemaBlock{
+		Attributes: map[string]*tfjson.SchemaAttribute{
+			"id":   {AttributeType: cty.String, Computed: true},
+			"name": {AttributeType: cty.String, Required: true},
+		},
+		NestedBlocks: map[string]*tfjson.SchemaBlockType{
+			"rule": {
+				NestingMode: tfjson.SchemaNestingModeList,
+				Block: &tfjson.SchemaBlock{
+					Attributes: map[string]*tfjson.SchemaAttribute{
+						"port": {AttributeType: cty.Number, Optional: true},
+					},
+				},
+			},
+		},
+	}
+	schema := &typ.GetProviderSchemaResponse{
+		DataSources:    map[string]tfjson.Schema{"foo_thing": {Block: block}},
+		DataSourcesCty: map[string]cty.Type{"foo_thing": configschema.SchemaBlockImpliedType(block)},
+		Functions: map[string]typ.FunctionDecl{
+			"upper": {
+				Parameters: []typ.FunctionParam{{Name: "input", Type: cty.String}},
+				ReturnType: cty.String,
+			},
+		},
+	}
+	return New(context.Background(), fakeClient{}, schema)
+}
+
+func TestExec(t *testing.T) {
+	s := newTestSession()
+
+	cases := []struct {
+		src    string
+		output string
+		err    string
+	}{
+		{src: ``, output: ``},
+		{src: `1 + 1`, output: "2\n"},
+		{src: `_ * 2`, output: "4\n"},
+		{src: `x = upper("abc")`, output: "\"ABC\"\n"},
+		{src: `read-data foo_thing { name = lower(x) }`, err: `Call to unknown function`},
+		{
+			src: `d = read-data foo_thing {
+  name = x
+  rule {
+    port = 80
+  }
+}`,
+			output: `{
+  id   = "id-ABC"
+  name = "ABC"
+  rule = [{
+    port = 80
+  }]
+}
+`,
+		},
+		{src: `d.rule[0].port`, output: "80\n"},
+		{src: `read-data foo_thing { rule {} }`, err: `Missing required argument`},
+		{src: `read-data bar_thing {}`, err: `no data source named "bar_thing"`},
+		{src: `read-data`, err: `usage: read-data <type> { <config> }`},
+		{src: `help = 1`, err: `"help" is a reserved command name`},
+		{src: `v = vars`, err: `the result of "vars" can't be assigned`},
+		{src: `vars`, output: "_: number\nd: object\nx: string\n"},
+		{src: `describe upper`, output: "upper(input string) string\n"},
+	}
+
+	for _, c := range cases {
+		out, err := s.Exec(c.src)
+		if c.err != "" {
+			if err == nil || !strings.Contains(err.Error(), c.err) {
+				t.Errorf("%q: expect error containing %q, got %v", c.src, c.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: unexpected error: %v", c.src, err)
+			continue
+		}
+		if out != c.output {
+			t.Errorf("%q: wrong output\ngot:\n%s\nwant:\n%s", c.src, out, c.output)
+		}
+	}
+
+	if _, err := s.Exec("exit"); !errors.Is(err, ErrExit) {
+		t.Errorf("exit: expect ErrExit, got %v", err)
+	}
+}
+
+func TestIncomplete(t *testing.T) {
+	cases := map[string]bool{
+		`read-data foo_thing {`:  true,
+		`upper(`:                 true,
+		`[1, {`:                  true,
+		`read-data foo_thing {}`: false,
+		`"{"`:                    false,
+	}
+	for src, want := range cases {
+		if got := Incomplete(src); got != want {
+			t.Errorf("%q: got %t, want %t", src, got, want)
+		}
+	}
+}
+
+func TestComplete(t *testing.T) {
+	s := newTestSession()
+	if _, err := s.Exec(`d = read-data foo_thing { name = "a" }`); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		line        string
+		head        string
+		completions []string
+	}{
+		{line: "rea", head: "", completions: []string{"read", "read-data"}},
+		{line: "read-data f", head: "read-data ", completions: []string{"foo_thing"}},
+		{line: "read-data foo_thing { na", head: "read-data foo_thing { ", completions: []string{"name"}},
+		{line: "x = up", head: "x = ", completions: []string{"upper("}},
+		{line: "d.n", head: "", completions: []string{"d.name"}},
+		{line: "upper(d.", head: "upper(", completions: []string{"d.id", "d.name", "d.rule"}},
+	}
+	for _, c := range cases {
+		head, completions, tail := s.Complete(c.line, len(c.line))
+		if diff := cmp.Diff(c.completions, completions); diff != "" {
+			t.Errorf("%q: wrong completions\n%s", c.line, diff)
+		}
+		if head != c.head || tail != "" {
+			t.Errorf("%q: wrong head or tail: %q, %q", c.line, head, tail)
+		}
+	}
+}
@@ -0,0 +1,636 @@
+// Package codegen generates Go structs, together with the helpers converting from/to cty values,
+// for the resources, data sources, ephemeral resources and functions of a provider schema.
+//
+// The generated structs use the `cty` struct tags, which are consumed by
+// github.com/zclconf/go-cty/cty/gocty. The mapping from the schema to the Go types is:
+//
+//   - Required attributes are mapped to the plain Go type, while the optional and/or computed
+//     attributes are mapped to a pointer (except for lists and maps, which are nil-able already).
+//   - The string and bool types are mapped to string and bool, the number type is mapped to *big.Float.
+//   - The list and set types are mapped to slices, the map type is mapped to map[string]T.
+//   - The object, tuple and dynamic types are mapped to cty.Value.
+//   - Nested blocks and nested attribute types are mapped to nested structs, according to
+//     their nesting modes.
+//
+// The null lists, sets and maps are decoded as nil slices and maps, which are encoded back as null. The
+// unknown values are decoded as the zero values of their types, with their paths recorded in the Unknown
+// field of the top level struct, so that the required attributes can be unknown as well.
+package codegen
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"slices"
+	"sort"
+	"strings"
+
+	tfjson "github.com/hashicorp/terraform-json"
+	"github.com/magodo/terraform-client-go/tfclient/configschema"
+	"github.com/magodo/terraform-client-go/tfclient/typ"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// Options specifies what to generate.
+type Options struct {
+	// PackageName is the package name of the generated file.
+	PackageName string
+
+	// Resources is the list of the resource type names to generate.
+	Resources []string
+
+	// DataSources is the list of the data source type names to generate.
+	DataSources []string
+
+	// EphemeralResources is the list of the ephemeral resource type names to generate.
+	EphemeralResources []string
+
+	// Functions is the list of the function names to generate.
+	Functions []string
+}
+
+// Generate generates the Go source file from the provider schema.
+func Generate(schema *typ.GetProviderSchemaResponse, opts Options) ([]byte, error) {
+	g := &generator{
+		names: map[string]bool{},
+	}
+
+	if opts.PackageName == "" {
+		return nil, fmt.Errorf("package name is not specified")
+	}
+
+	for _, name := range sortedUnique(opts.Resources) {
+		sch, ok := schema.ResourceTypes[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown resource type %q", name)
+		}
+		g.genTopLevel(goName(name), fmt.Sprintf("the %s resource", name), sch.Block)
+	}
+	for _, name := range sortedUnique(opts.DataSources) {
+		sch, ok := schema.DataSources[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown data source type %q", name)
+		}
+		g.genTopLevel("Data"+goName(name), fmt.Sprintf("the %s data source", name), sch.Block)
+	}
+	for _, name := range sortedUnique(opts.EphemeralResources) {
+		sch, ok := schema.EphemeralResourceTypes[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown ephemeral resource type %q", name)
+		}
+		g.genTopLevel("Ephemeral"+goName(name), fmt.Sprintf("the %s ephemeral resource", name), sch.Block)
+	}
+	for _, name := range sortedUnique(opts.Functions) {
+		decl, ok := schema.Functions[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown function %q", name)
+		}
+		g.genFunction(name, decl)
+	}
+
+	var out bytes.Buffer
+	fmt.Fprintf(&out, "// Code generated by terraform-client-gen. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&out, "package %s\n\n", opts.PackageName)
+	out.WriteString("import (\n")
+	if g.useBig {
+		out.WriteString("\t\"math/big\"\n\n")
+	}
+	out.WriteString("\t\"github.com/zclconf/go-cty/cty\"\n")
+	out.WriteString("\t\"github.com/zclconf/go-cty/cty/convert\"\n")
+	out.WriteString("\t\"github.com/zclconf/go-cty/cty/gocty\"\n")
+	out.WriteString("\tctyjson \"github.com/zclconf/go-cty/cty/json\"\n")
+	out.WriteString(")\n\n")
+	out.Write(g.buf.Bytes())
+	out.WriteString(helpers)
+
+	src, err := format.Source(out.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("formatting the generated code: %v\n%s", err, out.String())
+	}
+	return src, nil
+}
+
+type generator struct {
+	buf    bytes.Buffer
+	names  map[string]bool
+	useBig bool
+}
+
+// uniqueTypeName returns a Go type name that hasn't been used in the generated file.
+func (g *generator) uniqueTypeName(name string) string {
+	for g.names[name] {
+		name += "_"
+	}
+	g.names[name] = true
+	return name
+}
+
+func (g *generator) genTopLevel(name, desc string, block *tfjson.SchemaBlock) {
+	name = g.uniqueTypeName(name)
+	ty := configschema.SchemaBlockImpliedType(block)
+	tyJSON, err := ty.MarshalJSON()
+	if err != nil {
+		// This should never happen as the implied type is always serializable.
+		panic(err)
+	}
+
+	nested := g.genBlockStruct(name, fmt.Sprintf("%s is %s.", name, desc), block, true)
+
+	fmt.Fprintf(&g.buf, "// %sType is the implied cty type of %s.\n", name, name)
+	fmt.Fprintf(&g.buf, "var %sType = mustUnmarshalType(`%s`)\n\n", name, string(tyJSON))
+
+	decodeType := name + "Type"
+	if dty := blockDecodeType(block); !dty.Equals(ty) {
+		decodeType = "decodeType" + name
+		fmt.Fprintf(&g.buf, "// %s is %sType with the sets replaced by lists, for decoding the null sets as nil slices.\n", decodeType, name)
+		fmt.Fprintf(&g.buf, "var %s = mustUnmarshalType(`%s`)\n\n", decodeType, mustMarshalType(dty))
+	}
+
+	fmt.Fprintf(&g.buf, `// FromValue decodes the value, which conforms to %[1]sType, into the struct.
+// The unknown values are decoded as zero values, with their paths recorded in the Unknown field.
+func (o *%[1]s) FromValue(v cty.Value) error {
+	unknowns, err := decodeValue(v, %[2]s, o)
+	if err != nil {
+		return err
+	}
+	o.Unknown = unknowns
+	return nil
+}
+
+// ToValue encodes the struct into a value, which conforms to %[1]sType.
+// The values at the paths recorded in the Unknown field are encoded as unknown.
+func (o %[1]s) ToValue() (cty.Value, error) {
+	o.normalize()
+	v, err := gocty.ToCtyValue(o, %[1]sType)
+	if err != nil {
+		return cty.NilVal, err
+	}
+	return applyUnknowns(v, o.Unknown), nil
+}
+
+`, name, decodeType)
+
+	for _, n := range nested {
+		n()
+	}
+}
+
+// genBlockStruct generates the struct for a block, returns the functions to generate the nested structs.
+func (g *generator) genBlockStruct(name, doc string, block *tfjson.SchemaBlock, topLevel bool) []func() {
+	if block == nil {
+		block = &tfjson.SchemaBlock{}
+	}
+
+	s := newStructGen(name)
+	if topLevel {
+		s.used["Unknown"] = true
+	}
+
+	for _, attrName := range sortedKeys(block.Attributes) {
+		g.genAttrField(s, attrName, block.Attributes[attrName])
+	}
+
+	for _, blockName := range sortedKeys(block.NestedBlocks) {
+		nb := block.NestedBlocks[blockName]
+		fname := s.fieldName(blockName)
+		sname := g.uniqueTypeName(name + fname)
+		var gotyp string
+		switch nb.NestingMode {
+		case tfjson.SchemaNestingModeSingle:
+			gotyp = "*" + sname
+			s.normalizers = append(s.normalizers, fmt.Sprintf("if o.%[1]s != nil {\no.%[1]s.normalize()\n}", fname))
+		case tfjson.SchemaNestingModeGroup:
+			gotyp = sname
+			s.normalizers = append(s.normalizers, fmt.Sprintf("o.%s.normalize()", fname))
+		case tfjson.SchemaNestingModeList, tfjson.SchemaNestingModeSet:
+			// Nested blocks are never null
+			gotyp = "[]" + sname
+			s.normalizers = append(s.normalizers, fmt.Sprintf("if o.%[1]s == nil {\no.%[1]s = %[2]s{}\n}\nfor i := range o.%[1]s {\no.%[1]s[i].normalize()\n}", fname, gotyp))
+		case tfjson.SchemaNestingModeMap:
+			gotyp = "map[string]" + sname
+			s.normalizers = append(s.normalizers, fmt.Sprintf("if o.%[1]s == nil {\no.%[1]s = %[2]s{}\n}\nfor k, v := range o.%[1]s {\nv.normalize()\no.%[1]s[k] = v\n}", fname, gotyp))
+		default:
+			continue
+		}
+		s.fields = append(s.fields, fieldDecl(fname, gotyp, blockName, fmt.Sprintf("%s block", nb.NestingMode)))
+		s.nested = append(s.nested, func() {
+			g.genBlockStruct(sname, fmt.Sprintf("%s is the %s block of %s.", sname, blockName, name), nb.Block, false)
+		})
+	}
+
+	var extra string
+	if topLevel {
+		extra = "\n// Unknown records the paths of the unknown values.\nUnknown []cty.Path\n"
+	}
+	g.writeStruct(s, doc, extra)
+
+	if topLevel {
+		return s.nested
+	}
+	for _, n := range s.nested {
+		n()
+	}
+	return nil
+}
+
+// genAttrField generates the field for an attribute.
+func (g *generator) genAttrField(s *structGen, attrName string, attr *tfjson.SchemaAttribute) {
+	fname := s.fieldName(attrName)
+	nullable := !attr.Required
+	var gotyp string
+	if nt := attr.AttributeNestedType; nt != nil {
+		sname := g.uniqueTypeName(s.name + fname)
+		switch nt.NestingMode {
+		case tfjson.SchemaNestingModeList, tfjson.SchemaNestingModeSet:
+			gotyp = "[]" + sname
+			s.normalizers = append(s.normalizers, fmt.Sprintf("for i := range o.%[1]s {\no.%[1]s[i].normalize()\n}", fname))
+		case tfjson.SchemaNestingModeMap:
+			gotyp = "map[string]" + sname
+			s.normalizers = append(s.normalizers, fmt.Sprintf("for k, v := range o.%[1]s {\nv.normalize()\no.%[1]s[k] = v\n}", fname))
+		default:
+			if nullable {
+				gotyp = "*" + sname
+				s.normalizers = append(s.normalizers, fmt.Sprintf("if o.%[1]s != nil {\no.%[1]s.normalize()\n}", fname))
+			} else {
+				gotyp = sname
+				s.normalizers = append(s.normalizers, fmt.Sprintf("o.%s.normalize()", fname))
+			}
+		}
+		s.nested = append(s.nested, func() {
+			ns := newStructGen(sname)
+			for _, attrName := range sortedKeys(nt.Attributes) {
+				g.genAttrField(ns, attrName, nt.Attributes[attrName])
+			}
+			g.writeStruct(ns, fmt.Sprintf("%s is the %s attribute of %s.", sname, attrName, s.name), "")
+			for _, n := range ns.nested {
+				n()
+			}
+		})
+	} else {
+		gotyp = g.goType(attr.AttributeType, nullable)
+		if gotyp == "cty.Value" {
+			// The zero value of cty.Value is not a valid value, use a typed null instead.
+			s.normalizers = append(s.normalizers, fmt.Sprintf("if o.%[1]s == cty.NilVal {\no.%[1]s = cty.NullVal(mustUnmarshalType(%[2]q))\n}", fname, mustMarshalType(attr.AttributeType)))
+		}
+	}
+	s.fields = append(s.fields, fieldDecl(fname, gotyp, attrName, attrComment(attr)))
+}
+
+func (g *generator) writeStruct(s *structGen, doc, extra string) {
+	fmt.Fprintf(&g.buf, "// %s\ntype %s struct {\n", doc, s.name)
+	for _, f := range s.fields {
+		g.buf.WriteString(f)
+	}
+	g.buf.WriteString(extra)
+	g.buf.WriteString("}\n\n")
+
+	fmt.Fprintf(&g.buf, "func (o *%s) normalize() {\n", s.name)
+	for _, n := range s.normalizers {
+		g.buf.WriteString(n + "\n")
+	}
+	g.buf.WriteString("}\n\n")
+}
+
+// structGen holds the states of a struct being generated.
+type structGen struct {
+	name        string
+	fields      []string
+	normalizers []string
+	nested      []func()
+	used        map[string]bool
+}
+
+func newStructGen(name string) *structGen {
+	return &structGen{
+		name: name,
+		used: map[string]bool{},
+	}
+}
+
+// fieldName returns a unique field name for the attribute or block.
+func (s *structGen) fieldName(name string) string {
+	n := goName(name)
+	for s.used[n] {
+		n += "_"
+	}
+	s.used[n] = true
+	return n
+}
+
+// blockDecodeType returns the implied type of the block, with the sets mapped to slices replaced by lists,
+// which gocty decodes from null to nil slices.
+func blockDecodeType(block *tfjson.SchemaBlock) cty.Type {
+	if block == nil {
+		return cty.EmptyObject
+	}
+	attrs := map[string]cty.Type{}
+	for name, attr := range block.Attributes {
+		attrs[name] = attrDecodeType(attr)
+	}
+	for name, nb := range block.NestedBlocks {
+		ty := blockDecodeType(nb.Block)
+		switch nb.NestingMode {
+		case tfjson.SchemaNestingModeList, tfjson.SchemaNestingModeSet:
+			ty = cty.List(ty)
+		case tfjson.SchemaNestingModeMap:
+			ty = cty.Map(ty)
+		}
+		attrs[name] = ty
+	}
+	return cty.Object(attrs)
+}
+
+func attrDecodeType(attr *tfjson.SchemaAttribute) cty.Type {
+	nt := attr.AttributeNestedType
+	if nt == nil {
+		return decodeType(attr.AttributeType)
+	}
+	attrs := map[string]cty.Type{}
+	for name, attr := range nt.Attributes {
+		attrs[name] = attrDecodeType(attr)
+	}
+	ty := cty.Object(attrs)
+	switch nt.NestingMode {
+	case tfjson.SchemaNestingModeList, tfjson.SchemaNestingModeSet:
+		ty = cty.List(ty)
+	case tfjson.SchemaNestingModeMap:
+		ty = cty.Map(ty)
+	}
+	return ty
+}
+
+// decodeType returns the type with the sets replaced by lists, following the mapping of goType.
+func decodeType(ty cty.Type) cty.Type {
+	switch {
+	case ty.IsListType(), ty.IsSetType():
+		return cty.List(decodeType(ty.ElementType()))
+	case ty.IsMapType():
+		return cty.Map(decodeType(ty.ElementType()))
+	default:
+		return ty
+	}
+}
+
+// goType returns the Go type for the cty type.
+func (g *generator) goType(ty cty.Type, nullable bool) string {
+	switch {
+	case ty == cty.String:
+		if nullable {
+			return "*string"
+		}
+		return "string"
+	case ty == cty.Bool:
+		if nullable {
+			return "*bool"
+		}
+		return "bool"
+	case ty == cty.Number:
+		g.useBig = true
+		return "*big.Float"
+	case ty.IsListType(), ty.IsSetType():
+		return "[]" + g.goType(ty.ElementType(), false)
+	case ty.IsMapType():
+		return "map[string]" + g.goType(ty.ElementType(), false)
+	default:
+		// Object, tuple and dynamic types
+		return "cty.Value"
+	}
+}
+
+func (g *generator) genFunction(name string, decl typ.FunctionDecl) {
+	sname := g.uniqueTypeName("Func" + goName(name) + "Args")
+	used := map[string]bool{}
+
+	doc := fmt.Sprintf("%s is the arguments of the %s function.", sname, name)
+	if decl.Summary != "" {
+		doc += " " + decl.Summary
+	}
+	fmt.Fprintf(&g.buf, "// %s\ntype %s struct {\n", doc, sname)
+
+	var convs []string
+	for i, param := range decl.Parameters {
+		pname := param.Name
+		if pname == "" {
+			pname = fmt.Sprintf("arg%d", i)
+		}
+		fname := goName(pname)
+		for used[fname] {
+			fname += "_"
+		}
+		used[fname] = true
+		fmt.Fprintf(&g.buf, "%s %s\n", fname, g.goType(param.Type, param.AllowNullValue))
+		convs = append(convs, fmt.Sprintf(`v, err = gocty.ToCtyValue(o.%s, mustUnmarshalType(%q))
+if err != nil {
+	return nil, err
+}
+args = append(args, v)`, fname, mustMarshalType(param.Type)))
+	}
+	if vp := decl.VariadicParameter; vp != nil {
+		pname := vp.Name
+		if pname == "" {
+			pname = "args"
+		}
+		fname := goName(pname)
+		for used[fname] {
+			fname += "_"
+		}
+		fmt.Fprintf(&g.buf, "%s []%s\n", fname, g.goType(vp.Type, vp.AllowNullValue))
+		convs = append(convs, fmt.Sprintf(`for _, arg := range o.%s {
+	v, err = gocty.ToCtyValue(arg, mustUnmarshalType(%q))
+	if err != nil {
+		return nil, err
+	}
+	args = append(args, v)
+}`, fname, mustMarshalType(vp.Type)))
+	}
+	g.buf.WriteString("}\n\n")
+
+	fmt.Fprintf(&g.buf, "// Arguments encodes the arguments to the positional argument values of the %s function.\n", name)
+	fmt.Fprintf(&g.buf, "func (o %s) Arguments() ([]cty.Value, error) {\nvar args []cty.Value\nvar v cty.Value\nvar err error\n", sname)
+	if len(convs) == 0 {
+		g.buf.WriteString("_, _ = v, err\n")
+	}
+	for _, c := range convs {
+		g.buf.WriteString(c + "\n")
+	}
+	g.buf.WriteString("return args, nil\n}\n\n")
+
+	rname := g.uniqueTypeName("Func" + goName(name) + "Result")
+	fmt.Fprintf(&g.buf, "// %s decodes the result of the %s function.\n", rname, name)
+	fmt.Fprintf(&g.buf, "func %s(v cty.Value) (%s, error) {\nvar ret %[2]s\nerr := gocty.FromCtyValue(v, &ret)\nreturn ret, err\n}\n\n", rname, g.goType(decl.ReturnType, false))
+}
+
+func fieldDecl(name, gotyp, tag, comment string) string {
+	if comment != "" {
+		return fmt.Sprintf("%s %s `cty:%q` // %s\n", name, gotyp, tag, comment)
+	}
+	return fmt.Sprintf("%s %s `cty:%q`\n", name, gotyp, tag)
+}
+
+func attrComment(attr *tfjson.SchemaAttribute) string {
+	var flags []string
+	if attr.Required {
+		flags = append(flags, "required")
+	}
+	if attr.Optional {
+		flags = append(flags, "optional")
+	}
+	if attr.Computed {
+		flags = append(flags, "computed")
+	}
+	if attr.Sensitive {
+		flags = append(flags, "sensitive")
+	}
+	if attr.WriteOnly {
+		flags = append(flags, "write-only")
+	}
+	if attr.Deprecated {
+		flags = append(flags, "deprecated")
+	}
+	return strings.Join(flags, ", ")
+}
+
+func mustMarshalType(ty cty.Type) string {
+	b, err := ty.MarshalJSON()
+	if err != nil {
+		panic(err)
+	}
+	return string(b)
+}
+
+var initialisms = map[string]string{
+	"acl":   "ACL",
+	"api":   "API",
+	"arn":   "ARN",
+	"cidr":  "CIDR",
+	"cpu":   "CPU",
+	"dns":   "DNS",
+	"http":  "HTTP",
+	"https": "HTTPS",
+	"id":    "ID",
+	"ip":    "IP",
+	"json":  "JSON",
+	"ssh":   "SSH",
+	"ssl":   "SSL",
+	"tls":   "TLS",
+	"ttl":   "TTL",
+	"uri":   "URI",
+	"url":   "URL",
+	"uuid":  "UUID",
+	"vm":    "VM",
+}
+
+// goName converts a snake case name to an exported Go identifier.
+func goName(name string) string {
+	var sb strings.Builder
+	for _, seg := range strings.FieldsFunc(name, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9')
+	}) {
+		if v, ok := initialisms[strings.ToLower(seg)]; ok {
+			sb.WriteString(v)
+			continue
+		}
+		sb.WriteString(strings.ToUpper(seg[:1]) + seg[1:])
+	}
+	out := sb.String()
+	if out == "" || out[0] >= '0' && out[0] <= '9' {
+		out = "X" + out
+	}
+	return out
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func sortedUnique(l []string) []string {
+	out := slices.Clone(l)
+	slices.Sort(out)
+	return slices.Compact(out)
+}
+
+const helpers = `
+func mustUnmarshalType(s string) cty.Type {
+	ty, err := ctyjson.UnmarshalType([]byte(s))
+	if err != nil {
+		panic(err)
+	}
+	return ty
+}
+
+// decodeValue decodes the value into the target via the decode type, and returns the paths of the unknown values.
+func decodeValue(v cty.Value, ty cty.Type, target any) ([]cty.Path, error) {
+	v, paths := extractUnknowns(v)
+	v, err := convert.Convert(v, ty)
+	if err != nil {
+		return nil, err
+	}
+	return paths, gocty.FromCtyValue(v, target)
+}
+
+// extractUnknowns replaces the unknown values with zero values, and returns their paths.
+func extractUnknowns(v cty.Value) (cty.Value, []cty.Path) {
+	var paths []cty.Path
+	v, _ = cty.Transform(v, func(p cty.Path, v cty.Value) (cty.Value, error) {
+		if !v.IsKnown() {
+			paths = append(paths, p.Copy())
+			return zeroValue(v.Type()), nil
+		}
+		return v, nil
+	})
+	return v, paths
+}
+
+// zeroValue returns the zero value of the type, which is null for the dynamic type.
+func zeroValue(ty cty.Type) cty.Value {
+	switch {
+	case ty == cty.String:
+		return cty.StringVal("")
+	case ty == cty.Number:
+		return cty.Zero
+	case ty == cty.Bool:
+		return cty.False
+	case ty.IsListType():
+		return cty.ListValEmpty(ty.ElementType())
+	case ty.IsSetType():
+		return cty.SetValEmpty(ty.ElementType())
+	case ty.IsMapType():
+		return cty.MapValEmpty(ty.ElementType())
+	case ty.IsObjectType():
+		attrs := map[string]cty.Value{}
+		for name, aty := range ty.AttributeTypes() {
+			attrs[name] = zeroValue(aty)
+		}
+		return cty.ObjectVal(attrs)
+	case ty.IsTupleType():
+		var elems []cty.Value
+		for _, ety := range ty.TupleElementTypes() {
+			elems = append(elems, zeroValue(ety))
+		}
+		return cty.TupleVal(elems)
+	default:
+		return cty.NullVal(ty)
+	}
+}
+
+// applyUnknowns replaces the values at the given paths with unknown values.
+func applyUnknowns(v cty.Value, paths []cty.Path) cty.Value {
+	if len(paths) == 0 {
+		return v
+	}
+	v, _ = cty.Transform(v, func(p cty.Path, v cty.Value) (cty.Value, error) {
+		for _, up := range paths {
+			if p.Equals(up) {
+				return cty.UnknownVal(v.Type()), nil
+			}
+		}
+		return v, nil
+	})
+	return v
+}
+`
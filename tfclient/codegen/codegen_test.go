@@ -0,0 +1,338 @@
+package codegen
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	tfjson "github.com/hashicorp/terraform-json"
+	"github.com/magodo/terraform-client-go/tfclient/typ"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestGoName(t *testing.T) {
+	cases := map[string]string{
+		"name":           "Name",
+		"resource_id":    "ResourceID",
+		"api_url":        "APIURL",
+		"ip_config_name": "IPConfigName",
+		"1st":            "X1st",
+		"foo-bar":        "FooBar",
+	}
+	for in, want := range cases {
+		if got := goName(in); got != want {
+			t.Errorf("goName(%q): want %q, got %q", in, want, got)
+		}
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	block := &tfjson.SchemaBlock{
+		Attributes: map[string]*tfjson.SchemaAttribute{
+			"id":    {AttributeType: cty.String, Computed: true},
+			"name":  {AttributeType: cty.String, Required: true},
+			"count": {AttributeType: cty.Number, Optional: true},
+			"tags":  {AttributeType: cty.Map(cty.String), Optional: true},
+			"dyn":   {AttributeType: cty.DynamicPseudoType, Optional: true},
+			"rules": {
+				Optional: true,
+				AttributeNestedType: &tfjson.SchemaNestedAttributeType{
+					NestingMode: tfjson.SchemaNestingModeSet,
+					Attributes: map[string]*tfjson.SchemaAttribute{
+						"port": {AttributeType: cty.Number, Required: true},
+					},
+				},
+			},
+		},
+		NestedBlocks: map[string]*tfjson.SchemaBlockType{
+			"timeouts": {
+				NestingMode: tfjson.SchemaNestingModeSingle,
+				Block: &tfjson.SchemaBlock{
+					Attributes: map[string]*tfjson.SchemaAttribute{
+						"create": {AttributeType: cty.String, Optional: true},
+					},
+				},
+			},
+		},
+	}
+	schema := &typ.GetProviderSchemaResponse{
+		ResourceTypes: map[string]tfjson.Schema{
+			"foo_resource": {Block: block},
+		},
+		DataSources: map[string]tfjson.Schema{
+			"foo_resource": {Block: block},
+		},
+		Functions: map[string]typ.FunctionDecl{
+			"parse_id": {
+				Parameters: []typ.FunctionParam{{Name: "id", Type: cty.String}},
+				ReturnType: cty.Map(cty.String),
+			},
+		},
+	}
+
+	src, err := Generate(schema, Options{
+		PackageName: "foo",
+		Resources:   []string{"foo_resource"},
+		DataSources: []string{"foo_resource"},
+		Functions:   []string{"parse_id"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := parser.ParseFile(token.NewFileSet(), "", src, 0)
+	if err != nil {
+		t.Fatalf("parsing the generated code: %v\n%s", err, src)
+	}
+	var decls []string
+	for _, d := range f.Decls {
+		switch d := d.(type) {
+		case *ast.GenDecl:
+			for _, spec := range d.Specs {
+				switch spec := spec.(type) {
+				case *ast.TypeSpec:
+					decls = append(decls, spec.Name.Name)
+				case *ast.ValueSpec:
+					for _, n := range spec.Names {
+						decls = append(decls, n.Name)
+					}
+				}
+			}
+		case *ast.FuncDecl:
+			name := d.Name.Name
+			if d.Recv != nil {
+				expr := d.Recv.List[0].Type
+				if star, ok := expr.(*ast.StarExpr); ok {
+					expr = star.X
+				}
+				name = expr.(*ast.Ident).Name + "." + name
+			}
+			decls = append(decls, name)
+		}
+	}
+	sort.Strings(decls)
+
+	want := []string{
+		"DataFooResource",
+		"DataFooResource.FromValue",
+		"DataFooResource.ToValue",
+		"DataFooResource.normalize",
+		"DataFooResourceRules",
+		"DataFooResourceRules.normalize",
+		"DataFooResourceTimeouts",
+		"DataFooResourceTimeouts.normalize",
+		"DataFooResourceType",
+		"FooResource",
+		"FooResource.FromValue",
+		"FooResource.ToValue",
+		"FooResource.normalize",
+		"FooResourceRules",
+		"FooResourceRules.normalize",
+		"FooResourceTimeouts",
+		"FooResourceTimeouts.normalize",
+		"FooResourceType",
+		"FuncParseIDArgs",
+		"FuncParseIDArgs.Arguments",
+		"FuncParseIDResult",
+		"applyUnknowns",
+		"decodeTypeDataFooResource",
+		"decodeTypeFooResource",
+		"decodeValue",
+		"extractUnknowns",
+		"mustUnmarshalType",
+		"zeroValue",
+	}
+	if diff := cmp.Diff(want, decls); diff != "" {
+		t.Fatalf("unexpected declarations (-want +got):\n%s", diff)
+	}
+
+	if _, err := Generate(schema, Options{PackageName: "foo", Resources: []string{"bar_resource"}}); err == nil {
+		t.Fatal("expect error for unknown resource type")
+	}
+}
+
+// roundTripMain is the main program compiled together with the generated code, which decodes the value into the
+// generated struct and encodes it back. The value is the first argument, with the top level attributes named by the
+// rest of the arguments being unknown.
+const roundTripMain = `package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/zclconf/go-cty/cty"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
+)
+
+func main() {
+	v, err := ctyjson.Unmarshal([]byte(os.Args[1]), FooResourceType)
+	if err != nil {
+		panic(err)
+	}
+	var unknowns []cty.Path
+	for _, name := range os.Args[2:] {
+		unknowns = append(unknowns, cty.GetAttrPath(name))
+	}
+	v = applyUnknowns(v, unknowns)
+
+	var o FooResource
+	if err := o.FromValue(v); err != nil {
+		panic(err)
+	}
+	got, err := o.ToValue()
+	if err != nil {
+		panic(err)
+	}
+	if !got.RawEquals(v) {
+		fmt.Printf("got:  %#v\nwant: %#v\n", got, v)
+		os.Exit(1)
+	}
+}
+`
+
+func TestGenerateRoundTrip(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping compiling the generated code in short mode")
+	}
+	gobin, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go is not found")
+	}
+
+	ruleBlock := &tfjson.SchemaBlock{
+		Attributes: map[string]*tfjson.SchemaAttribute{
+			"action": {AttributeType: cty.String, Required: true},
+			"ports":  {AttributeType: cty.Set(cty.Number), Optional: true},
+		},
+		NestedBlocks: map[string]*tfjson.SchemaBlockType{
+			"target": {
+				NestingMode: tfjson.SchemaNestingModeSet,
+				Block: &tfjson.SchemaBlock{
+					Attributes: map[string]*tfjson.SchemaAttribute{
+						"host": {AttributeType: cty.String, Required: true},
+					},
+				},
+			},
+		},
+	}
+	block := &tfjson.SchemaBlock{
+		Attributes: map[string]*tfjson.SchemaAttribute{
+			"id":     {AttributeType: cty.String, Computed: true},
+			"name":   {AttributeType: cty.String, Required: true},
+			"count":  {AttributeType: cty.Number, Optional: true},
+			"tags":   {AttributeType: cty.Map(cty.String), Optional: true},
+			"labels": {AttributeType: cty.Set(cty.String), Optional: true},
+			"dyn":    {AttributeType: cty.DynamicPseudoType, Optional: true},
+			"endpoints": {
+				Optional: true,
+				AttributeNestedType: &tfjson.SchemaNestedAttributeType{
+					NestingMode: tfjson.SchemaNestingModeMap,
+					Attributes: map[string]*tfjson.SchemaAttribute{
+						"url": {AttributeType: cty.String, Required: true},
+					},
+				},
+			},
+		},
+		NestedBlocks: map[string]*tfjson.SchemaBlockType{
+			"rule": {
+				NestingMode: tfjson.SchemaNestingModeList,
+				Block:       ruleBlock,
+			},
+			"timeouts": {
+				NestingMode: tfjson.SchemaNestingModeSingle,
+				Block: &tfjson.SchemaBlock{
+					Attributes: map[string]*tfjson.SchemaAttribute{
+						"create": {AttributeType: cty.String, Optional: true},
+					},
+				},
+			},
+		},
+	}
+	src, err := Generate(&typ.GetProviderSchemaResponse{
+		ResourceTypes: map[string]tfjson.Schema{"foo_resource": {Block: block}},
+	}, Options{PackageName: "main", Resources: []string{"foo_resource"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The program is built in its own module, which uses the dependencies of this module.
+	gomod, err := exec.Command(gobin, "env", "GOMOD").Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	root := filepath.Dir(strings.TrimSpace(string(gomod)))
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"go.mod": fmt.Sprintf(`module roundtrip
+
+go 1.24
+
+require github.com/magodo/terraform-client-go v0.0.0
+
+replace github.com/magodo/terraform-client-go => %s
+`, root),
+		"generated.go": string(src),
+		"main.go":      roundTripMain,
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	sum, err := os.ReadFile(filepath.Join(root, "go.sum"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "go.sum"), sum, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		name     string
+		value    string
+		unknowns []string
+	}{
+		{
+			name: "full",
+			value: `{
+  "id": null, "name": "a", "count": 1.5, "tags": {"k": "v"}, "labels": ["x", "y"], "dyn": {"value": [1, "a"], "type": ["tuple", ["number", "string"]]},
+  "endpoints": {"e": {"url": "http://e"}},
+  "rule": [
+    {"action": "allow", "ports": [80, 443], "target": [{"host": "h1"}, {"host": "h2"}]},
+    {"action": "deny", "ports": [], "target": []}
+  ],
+  "timeouts": {"create": "1m"}
+}`,
+			unknowns: []string{"id"},
+		},
+		{
+			name: "null",
+			value: `{
+  "id": null, "name": "b", "count": null, "tags": null, "labels": null, "dyn": {"value": null, "type": "dynamic"},
+  "endpoints": null, "rule": [{"action": "allow", "ports": null, "target": []}], "timeouts": null
+}`,
+		},
+		{
+			name: "unknown required",
+			value: `{
+  "id": null, "name": "c", "count": null, "tags": null, "labels": [], "dyn": {"value": null, "type": "dynamic"},
+  "endpoints": null, "rule": [], "timeouts": null
+}`,
+			unknowns: []string{"id", "name", "labels", "rule", "timeouts"},
+		},
+	} {
+		cmd := exec.Command(gobin, append([]string{"run", ".", tt.value}, tt.unknowns...)...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(), "GOFLAGS=-mod=mod", "GOWORK=off")
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Errorf("round trip of %s: %v\n%s", tt.name, err, out)
+		}
+	}
+}
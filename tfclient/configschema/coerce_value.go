@@ -0,0 +1,251 @@
+// This is derived from github.com/hashicorp/terraform/internal/configs/configschema/coerce_value.go (v1.13.0-alpha20250521)
+
+package configschema
+
+import (
+	"fmt"
+
+	tfjson "github.com/hashicorp/terraform-json"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/convert"
+)
+
+// SchemaBlockCoerceValue attempts to force the given value to conform to the type
+// implied by the receiever.
+//
+// This is useful in situations where a configuration must be derived from
+// an already-decoded value. It is always better to decode directly from
+// configuration where possible since then source location information is
+// still available to produce diagnostics, but in special situations this
+// function allows a compatible result to be obtained even if the
+// configuration objects are not available.
+//
+// If the given value cannot be converted to conform to the receiving schema
+// then an error is returned describing one of possibly many problems. This
+// error may be a cty.PathError indicating a position within the nested
+// data structure where the problem applies.
+func SchemaBlockCoerceValue(b *tfjson.SchemaBlock, in cty.Value) (cty.Value, error) {
+	var path cty.Path
+	return schemaBlockCoerceValue(b, in, path)
+}
+
+func schemaBlockCoerceValue(b *tfjson.SchemaBlock, in cty.Value, path cty.Path) (cty.Value, error) {
+	if b == nil {
+		b = &tfjson.SchemaBlock{}
+	}
+	convType := schemaBlockSpecType(b)
+	impliedType := convType.WithoutOptionalAttributesDeep()
+
+	switch {
+	case in.IsNull():
+		return cty.NullVal(impliedType), nil
+	case !in.IsKnown():
+		return cty.UnknownVal(impliedType), nil
+	}
+
+	ty := in.Type()
+	if !ty.IsObjectType() {
+		return cty.UnknownVal(impliedType), path.NewErrorf("an object is required")
+	}
+
+	for name := range ty.AttributeTypes() {
+		if _, defined := b.Attributes[name]; defined {
+			continue
+		}
+		if _, defined := b.NestedBlocks[name]; defined {
+			continue
+		}
+		return cty.UnknownVal(impliedType), path.NewErrorf("unexpected attribute %q", name)
+	}
+
+	attrs := make(map[string]cty.Value)
+
+	for name, blockS := range b.NestedBlocks {
+		blockPath := copyAndExtendPath(path, cty.GetAttrStep{Name: name})
+		blockTy := SchemaBlockImpliedType(blockS.Block)
+
+		switch blockS.NestingMode {
+
+		case tfjson.SchemaNestingModeSingle, tfjson.SchemaNestingModeGroup:
+			switch {
+			case ty.HasAttribute(name):
+				var err error
+				val := in.GetAttr(name)
+				attrs[name], err = schemaBlockCoerceValue(blockS.Block, val, blockPath)
+				if err != nil {
+					return cty.UnknownVal(impliedType), err
+				}
+			default:
+				attrs[name] = SchemaBlockTypeEmptyValue(blockS)
+			}
+
+		case tfjson.SchemaNestingModeList:
+			switch {
+			case ty.HasAttribute(name):
+				coll := in.GetAttr(name)
+
+				switch {
+				case coll.IsNull():
+					attrs[name] = cty.NullVal(impliedType.AttributeType(name))
+					continue
+				case !coll.IsKnown():
+					attrs[name] = cty.UnknownVal(impliedType.AttributeType(name))
+					continue
+				}
+
+				if !coll.CanIterateElements() {
+					return cty.UnknownVal(impliedType), blockPath.NewErrorf("must be a list")
+				}
+				l := coll.LengthInt()
+
+				if l == 0 {
+					attrs[name] = SchemaBlockTypeEmptyValue(blockS)
+					continue
+				}
+				elems := make([]cty.Value, 0, l)
+				for it := coll.ElementIterator(); it.Next(); {
+					var err error
+					idx, val := it.Element()
+					val, err = schemaBlockCoerceValue(blockS.Block, val, copyAndExtendPath(blockPath, cty.IndexStep{Key: idx}))
+					if err != nil {
+						return cty.UnknownVal(impliedType), err
+					}
+					elems = append(elems, val)
+				}
+				if blockTy.HasDynamicTypes() {
+					attrs[name] = cty.TupleVal(elems)
+				} else {
+					attrs[name] = cty.ListVal(elems)
+				}
+			default:
+				attrs[name] = SchemaBlockTypeEmptyValue(blockS)
+			}
+
+		case tfjson.SchemaNestingModeSet:
+			switch {
+			case ty.HasAttribute(name):
+				coll := in.GetAttr(name)
+
+				switch {
+				case coll.IsNull():
+					attrs[name] = cty.NullVal(impliedType.AttributeType(name))
+					continue
+				case !coll.IsKnown():
+					attrs[name] = cty.UnknownVal(impliedType.AttributeType(name))
+					continue
+				}
+
+				if !coll.CanIterateElements() {
+					return cty.UnknownVal(impliedType), blockPath.NewErrorf("must be a set")
+				}
+				l := coll.LengthInt()
+
+				if l == 0 {
+					attrs[name] = SchemaBlockTypeEmptyValue(blockS)
+					continue
+				}
+				elems := make([]cty.Value, 0, l)
+				for it := coll.ElementIterator(); it.Next(); {
+					var err error
+					idx, val := it.Element()
+					val, err = schemaBlockCoerceValue(blockS.Block, val, copyAndExtendPath(blockPath, cty.IndexStep{Key: idx}))
+					if err != nil {
+						return cty.UnknownVal(impliedType), err
+					}
+					elems = append(elems, val)
+				}
+				attrs[name] = cty.SetVal(elems)
+			default:
+				attrs[name] = SchemaBlockTypeEmptyValue(blockS)
+			}
+
+		case tfjson.SchemaNestingModeMap:
+			switch {
+			case ty.HasAttribute(name):
+				coll := in.GetAttr(name)
+
+				switch {
+				case coll.IsNull():
+					attrs[name] = cty.NullVal(impliedType.AttributeType(name))
+					continue
+				case !coll.IsKnown():
+					attrs[name] = cty.UnknownVal(impliedType.AttributeType(name))
+					continue
+				}
+
+				if !coll.CanIterateElements() {
+					return cty.UnknownVal(impliedType), blockPath.NewErrorf("must be a map")
+				}
+				l := coll.LengthInt()
+				if l == 0 {
+					attrs[name] = SchemaBlockTypeEmptyValue(blockS)
+					continue
+				}
+				elems := make(map[string]cty.Value)
+				for it := coll.ElementIterator(); it.Next(); {
+					var err error
+					key, val := it.Element()
+					if key.Type() != cty.String || key.IsNull() || !key.IsKnown() {
+						return cty.UnknownVal(impliedType), blockPath.NewErrorf("must be a map")
+					}
+					val, err = schemaBlockCoerceValue(blockS.Block, val, copyAndExtendPath(blockPath, cty.IndexStep{Key: key}))
+					if err != nil {
+						return cty.UnknownVal(impliedType), err
+					}
+					elems[key.AsString()] = val
+				}
+
+				// If the attribute values here contain any DynamicPseudoTypes,
+				// the concrete type must be an object.
+				useObject := false
+				switch {
+				case blockTy.HasDynamicTypes():
+					useObject = true
+				default:
+					// It's possible that we were given a map, and need to coerce it to an object
+					for _, v := range elems {
+						if !v.Type().Equals(blockTy) {
+							useObject = true
+							break
+						}
+					}
+				}
+
+				if useObject {
+					attrs[name] = cty.ObjectVal(elems)
+				} else {
+					attrs[name] = cty.MapVal(elems)
+				}
+			default:
+				attrs[name] = SchemaBlockTypeEmptyValue(blockS)
+			}
+
+		default:
+			// should never happen because above is exhaustive
+			return cty.UnknownVal(impliedType), blockPath.NewError(fmt.Errorf("unsupported nesting mode %q", blockS.NestingMode))
+		}
+	}
+
+	for name, attrS := range b.Attributes {
+		attrType := impliedType.AttributeType(name)
+		attrConvType := convType.AttributeType(name)
+
+		var val cty.Value
+		switch {
+		case ty.HasAttribute(name):
+			val = in.GetAttr(name)
+		case attrS.Computed || attrS.Optional:
+			val = cty.NullVal(attrType)
+		default:
+			return cty.UnknownVal(impliedType), path.NewErrorf("attribute %q is required", name)
+		}
+
+		val, err := convert.Convert(val, attrConvType)
+		if err != nil {
+			return cty.UnknownVal(impliedType), copyAndExtendPath(path, cty.GetAttrStep{Name: name}).NewError(err)
+		}
+		attrs[name] = val
+	}
+
+	return cty.ObjectVal(attrs), nil
+}
@@ -0,0 +1,164 @@
+package configschema
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	tfjson "github.com/hashicorp/terraform-json"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestSchemaBlockCoerceValue(t *testing.T) {
+	block := &tfjson.SchemaBlock{
+		Attributes: map[string]*tfjson.SchemaAttribute{
+			"name":  {AttributeType: cty.String, Required: true},
+			"count": {AttributeType: cty.Number, Optional: true},
+			"tags":  {AttributeType: cty.Map(cty.String), Optional: true},
+			"nested": {
+				AttributeNestedType: &tfjson.SchemaNestedAttributeType{
+					NestingMode: tfjson.SchemaNestingModeList,
+					Attributes: map[string]*tfjson.SchemaAttribute{
+						"a": {AttributeType: cty.String, Optional: true},
+						"b": {AttributeType: cty.String, Optional: true},
+					},
+				},
+				Optional: true,
+			},
+		},
+		NestedBlocks: map[string]*tfjson.SchemaBlockType{
+			"single": {
+				NestingMode: tfjson.SchemaNestingModeSingle,
+				Block: &tfjson.SchemaBlock{
+					Attributes: map[string]*tfjson.SchemaAttribute{"x": {AttributeType: cty.String, Optional: true}},
+				},
+			},
+			"list": {
+				NestingMode: tfjson.SchemaNestingModeList,
+				Block: &tfjson.SchemaBlock{
+					Attributes: map[string]*tfjson.SchemaAttribute{"x": {AttributeType: cty.String, Optional: true}},
+				},
+			},
+			"set": {
+				NestingMode: tfjson.SchemaNestingModeSet,
+				Block: &tfjson.SchemaBlock{
+					Attributes: map[string]*tfjson.SchemaAttribute{"x": {AttributeType: cty.String, Optional: true}},
+				},
+			},
+			"map": {
+				NestingMode: tfjson.SchemaNestingModeMap,
+				Block: &tfjson.SchemaBlock{
+					Attributes: map[string]*tfjson.SchemaAttribute{"x": {AttributeType: cty.String, Optional: true}},
+				},
+			},
+		},
+	}
+	xTy := cty.Object(map[string]cty.Type{"x": cty.String})
+	nestedTy := cty.Object(map[string]cty.Type{"a": cty.String, "b": cty.String})
+
+	cases := []struct {
+		name        string
+		json        string
+		want        cty.Value
+		wantErr     string
+		wantErrPath cty.Path
+	}{
+		{
+			name: "minimal",
+			json: `{"name": "a"}`,
+			want: cty.ObjectVal(map[string]cty.Value{
+				"name":   cty.StringVal("a"),
+				"count":  cty.NullVal(cty.Number),
+				"tags":   cty.NullVal(cty.Map(cty.String)),
+				"nested": cty.NullVal(cty.List(nestedTy)),
+				"single": cty.NullVal(xTy),
+				"list":   cty.ListValEmpty(xTy),
+				"set":    cty.SetValEmpty(xTy),
+				"map":    cty.MapValEmpty(xTy),
+			}),
+		},
+		{
+			name: "conversion",
+			json: `{"name": "a", "count": "1", "tags": {"k": 1}, "nested": [{"a": "x"}], "single": {}, "list": [{"x": "1"}, {}], "set": [{"x": "1"}], "map": {"k": {"x": true}}}`,
+			want: cty.ObjectVal(map[string]cty.Value{
+				"name":  cty.StringVal("a"),
+				"count": cty.NumberIntVal(1),
+				"tags":  cty.MapVal(map[string]cty.Value{"k": cty.StringVal("1")}),
+				"nested": cty.ListVal([]cty.Value{
+					cty.ObjectVal(map[string]cty.Value{"a": cty.StringVal("x"), "b": cty.NullVal(cty.String)}),
+				}),
+				"single": cty.ObjectVal(map[string]cty.Value{"x": cty.NullVal(cty.String)}),
+				"list": cty.ListVal([]cty.Value{
+					cty.ObjectVal(map[string]cty.Value{"x": cty.StringVal("1")}),
+					cty.ObjectVal(map[string]cty.Value{"x": cty.NullVal(cty.String)}),
+				}),
+				"set": cty.SetVal([]cty.Value{cty.ObjectVal(map[string]cty.Value{"x": cty.StringVal("1")})}),
+				"map": cty.MapVal(map[string]cty.Value{"k": cty.ObjectVal(map[string]cty.Value{"x": cty.StringVal("true")})}),
+			}),
+		},
+		{
+			name: "null",
+			json: ``,
+			want: cty.NullVal(SchemaBlockImpliedType(block)),
+		},
+		{
+			name:    "missing required",
+			json:    `{}`,
+			wantErr: `attribute "name" is required`,
+		},
+		{
+			name:    "unexpected attribute",
+			json:    `{"name": "a", "foo": 1}`,
+			wantErr: `unexpected attribute "foo"`,
+		},
+		{
+			name:        "invalid nested value",
+			json:        `{"name": "a", "list": [{"x": {}}]}`,
+			wantErr:     `string required`,
+			wantErrPath: cty.GetAttrPath("list").IndexInt(0).GetAttr("x"),
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := SchemaBlockUnmarshalJSON(block, []byte(tt.json))
+			if tt.wantErr != "" {
+				if err == nil {
+					t.Fatalf("expect error %q, got none", tt.wantErr)
+				}
+				if !strings.HasPrefix(err.Error(), tt.wantErr) {
+					t.Fatalf("wrong error\ngot:  %s\nwant: %s", err, tt.wantErr)
+				}
+				var perr cty.PathError
+				if !errors.As(err, &perr) || !perr.Path.Equals(tt.wantErrPath) {
+					t.Fatalf("wrong error path: %#v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !got.RawEquals(tt.want) {
+				t.Errorf("wrong result\ngot:  %#v\nwant: %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSchemaNestedAttributeTypeUnmarshalJSON(t *testing.T) {
+	o := &tfjson.SchemaNestedAttributeType{
+		NestingMode: tfjson.SchemaNestingModeSingle,
+		Attributes: map[string]*tfjson.SchemaAttribute{
+			"id":  {AttributeType: cty.String, Required: true},
+			"sub": {AttributeType: cty.String, Optional: true},
+		},
+	}
+	got, err := SchemaNestedAttributeTypeUnmarshalJSON(o, []byte(`{"id": 1}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := cty.ObjectVal(map[string]cty.Value{"id": cty.StringVal("1"), "sub": cty.NullVal(cty.String)})
+	if !got.RawEquals(want) {
+		t.Errorf("wrong result\ngot:  %#v\nwant: %#v", got, want)
+	}
+}
@@ -0,0 +1,53 @@
+package configschema
+
+import (
+	"bytes"
+
+	tfjson "github.com/hashicorp/terraform-json"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/convert"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
+)
+
+// SchemaBlockUnmarshalJSON decodes the JSON encoded value, which is then coerced to conform to the block by
+// SchemaBlockCoerceValue. Unlike decoding against the implied type of the block, the JSON can omit the optional
+// attributes and the nested blocks, and use the compatible types, e.g. a number in a string. The values of the
+// dynamically typed attributes are decoded as of their JSON types. An empty input results in a null value.
+func SchemaBlockUnmarshalJSON(b *tfjson.SchemaBlock, data []byte) (cty.Value, error) {
+	v, err := unmarshalImplied(data)
+	if err != nil {
+		return cty.NilVal, err
+	}
+	return SchemaBlockCoerceValue(b, v)
+}
+
+// SchemaNestedAttributeTypeUnmarshalJSON decodes the JSON encoded value, which is then coerced to conform to the
+// nested attribute type by SchemaNestedAttributeTypeCoerceValue. An empty input results in a null value.
+func SchemaNestedAttributeTypeUnmarshalJSON(o *tfjson.SchemaNestedAttributeType, data []byte) (cty.Value, error) {
+	v, err := unmarshalImplied(data)
+	if err != nil {
+		return cty.NilVal, err
+	}
+	return SchemaNestedAttributeTypeCoerceValue(o, v)
+}
+
+// SchemaNestedAttributeTypeCoerceValue converts the value to conform to the nested attribute type, where the
+// missing optional attributes are set to null.
+func SchemaNestedAttributeTypeCoerceValue(o *tfjson.SchemaNestedAttributeType, in cty.Value) (cty.Value, error) {
+	if in.IsNull() {
+		return cty.NullVal(SchemaNestedAttributeTypeImpliedType(o)), nil
+	}
+	return convert.Convert(in, schemaNestedAttributeTypeSpecType(o))
+}
+
+// unmarshalImplied decodes the JSON encoded value as of its implied type.
+func unmarshalImplied(data []byte) (cty.Value, error) {
+	if len(bytes.TrimSpace(data)) == 0 {
+		return cty.NullVal(cty.DynamicPseudoType), nil
+	}
+	ty, err := ctyjson.ImpliedType(data)
+	if err != nil {
+		return cty.NilVal, err
+	}
+	return ctyjson.Unmarshal(data, ty)
+}
@@ -0,0 +1,189 @@
+// This is derived from github.com/hashicorp/terraform/internal/configs/configschema/marks.go (v1.13.0-alpha20250521)
+
+package configschema
+
+import (
+	"fmt"
+
+	tfjson "github.com/hashicorp/terraform-json"
+	"github.com/magodo/terraform-client-go/tfclient/marks"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// copyAndExtendPath returns a copy of a cty.Path with some additional
+// `cty.PathStep`s appended to its end, to simplify creating new child paths.
+func copyAndExtendPath(path cty.Path, nextSteps ...cty.PathStep) cty.Path {
+	newPath := make(cty.Path, len(path), len(path)+len(nextSteps))
+	copy(newPath, path)
+	newPath = append(newPath, nextSteps...)
+	return newPath
+}
+
+// SchemaBlockValueMarks returns a set of path value marks for all of the sensitive
+// attributes of the given value, which must conform to the implied type of the block.
+// The path is the path of the value itself, which is nil for the top level.
+func SchemaBlockValueMarks(b *tfjson.SchemaBlock, val cty.Value, path cty.Path) []cty.PathValueMarks {
+	var pvm []cty.PathValueMarks
+
+	// We can mark attributes as sensitive even if the value is null
+	for name, attrS := range b.Attributes {
+		if attrS.Sensitive {
+			// Create a copy of the path, with this step added, to add to our PathValueMarks slice
+			attrPath := copyAndExtendPath(path, cty.GetAttrStep{Name: name})
+			pvm = append(pvm, cty.PathValueMarks{
+				Path:  attrPath,
+				Marks: cty.NewValueMarks(marks.Sensitive),
+			})
+		}
+	}
+
+	// If the value is null, no other marks are possible
+	if val.IsNull() || !val.IsKnown() {
+		return pvm
+	}
+
+	// Extract marks for nested attribute type values
+	for name, attrS := range b.Attributes {
+		// If the attribute has no nested type, or the nested type doesn't
+		// contain any sensitive attributes, skip inspecting it
+		if attrS.AttributeNestedType == nil || !schemaNestedAttributeTypeContainsSensitive(attrS.AttributeNestedType) {
+			continue
+		}
+
+		// Create a copy of the path, with this step added, to add to our PathValueMarks slice
+		attrPath := copyAndExtendPath(path, cty.GetAttrStep{Name: name})
+
+		pvm = append(pvm, SchemaNestedAttributeTypeValueMarks(attrS.AttributeNestedType, val.GetAttr(name), attrPath)...)
+	}
+
+	// Extract marks for nested blocks
+	for name, blockS := range b.NestedBlocks {
+		// If our block doesn't contain any sensitive attributes, skip inspecting it
+		if !schemaBlockContainsSensitive(blockS.Block) {
+			continue
+		}
+
+		blockV := val.GetAttr(name)
+		if blockV.IsNull() || !blockV.IsKnown() {
+			continue
+		}
+
+		// Create a copy of the path, with this step added, to add to our PathValueMarks slice
+		blockPath := copyAndExtendPath(path, cty.GetAttrStep{Name: name})
+
+		switch blockS.NestingMode {
+		case tfjson.SchemaNestingModeSingle, tfjson.SchemaNestingModeGroup:
+			pvm = append(pvm, SchemaBlockValueMarks(blockS.Block, blockV, blockPath)...)
+		case tfjson.SchemaNestingModeList, tfjson.SchemaNestingModeMap, tfjson.SchemaNestingModeSet:
+			for it := blockV.ElementIterator(); it.Next(); {
+				idx, blockEV := it.Element()
+				// Create a copy of the path, with this block instance's index
+				// step added, to add to our PathValueMarks slice
+				blockInstancePath := copyAndExtendPath(blockPath, cty.IndexStep{Key: idx})
+				morePaths := SchemaBlockValueMarks(blockS.Block, blockEV, blockInstancePath)
+				pvm = append(pvm, morePaths...)
+			}
+		default:
+			panic(fmt.Sprintf("unsupported nesting mode %s", blockS.NestingMode))
+		}
+	}
+	return pvm
+}
+
+// SchemaNestedAttributeTypeValueMarks returns a set of path value marks for all of the
+// sensitive attributes of the given value, which must conform to the implied type of the
+// nested attribute type. The path is the path of the value itself.
+func SchemaNestedAttributeTypeValueMarks(o *tfjson.SchemaNestedAttributeType, val cty.Value, path cty.Path) []cty.PathValueMarks {
+	var pvm []cty.PathValueMarks
+
+	if val.IsNull() || !val.IsKnown() {
+		return pvm
+	}
+
+	for name, attrS := range o.Attributes {
+		// Skip attributes which can never produce sensitive path value marks
+		if !attrS.Sensitive && (attrS.AttributeNestedType == nil || !schemaNestedAttributeTypeContainsSensitive(attrS.AttributeNestedType)) {
+			continue
+		}
+
+		switch o.NestingMode {
+		case tfjson.SchemaNestingModeSingle, tfjson.SchemaNestingModeGroup:
+			// Create a path to this attribute
+			attrPath := copyAndExtendPath(path, cty.GetAttrStep{Name: name})
+
+			if attrS.Sensitive {
+				// If the entire attribute is sensitive, mark it so
+				pvm = append(pvm, cty.PathValueMarks{
+					Path:  attrPath,
+					Marks: cty.NewValueMarks(marks.Sensitive),
+				})
+			} else {
+				// The attribute has a nested type which contains sensitive
+				// attributes, so recurse
+				pvm = append(pvm, SchemaNestedAttributeTypeValueMarks(attrS.AttributeNestedType, val.GetAttr(name), attrPath)...)
+			}
+		case tfjson.SchemaNestingModeList, tfjson.SchemaNestingModeMap, tfjson.SchemaNestingModeSet:
+			// For nested attribute types which have a non-single nesting mode,
+			// we add path value marks for each element of the collection
+			for it := val.ElementIterator(); it.Next(); {
+				idx, attrEV := it.Element()
+				attrV := attrEV.GetAttr(name)
+
+				// Create a path to this element of the attribute's collection. Note
+				// that the path is extended in opposite order to the iteration order
+				// of the loops: index into the collection, then the contained
+				// attribute name. This is because we have one type
+				// representing multiple collection elements.
+				attrPath := copyAndExtendPath(path, cty.IndexStep{Key: idx}, cty.GetAttrStep{Name: name})
+
+				if attrS.Sensitive {
+					// If the entire attribute is sensitive, mark it so
+					pvm = append(pvm, cty.PathValueMarks{
+						Path:  attrPath,
+						Marks: cty.NewValueMarks(marks.Sensitive),
+					})
+				} else {
+					// The attribute has a nested type which contains sensitive
+					// attributes, so recurse
+					pvm = append(pvm, SchemaNestedAttributeTypeValueMarks(attrS.AttributeNestedType, attrV, attrPath)...)
+				}
+			}
+		default:
+			panic(fmt.Sprintf("unsupported nesting mode %s", o.NestingMode))
+		}
+	}
+	return pvm
+}
+
+// schemaBlockContainsSensitive returns true if any of the attributes of the
+// block or its nested blocks are sensitive.
+func schemaBlockContainsSensitive(b *tfjson.SchemaBlock) bool {
+	for _, attrS := range b.Attributes {
+		if attrS.Sensitive {
+			return true
+		}
+		if attrS.AttributeNestedType != nil && schemaNestedAttributeTypeContainsSensitive(attrS.AttributeNestedType) {
+			return true
+		}
+	}
+	for _, blockS := range b.NestedBlocks {
+		if schemaBlockContainsSensitive(blockS.Block) {
+			return true
+		}
+	}
+	return false
+}
+
+// schemaNestedAttributeTypeContainsSensitive returns true if any of the
+// attributes of the nested attribute type are sensitive.
+func schemaNestedAttributeTypeContainsSensitive(o *tfjson.SchemaNestedAttributeType) bool {
+	for _, attrS := range o.Attributes {
+		if attrS.Sensitive {
+			return true
+		}
+		if attrS.AttributeNestedType != nil && schemaNestedAttributeTypeContainsSensitive(attrS.AttributeNestedType) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,72 @@
+package configschema
+
+import (
+	"testing"
+
+	tfjson "github.com/hashicorp/terraform-json"
+	"github.com/magodo/terraform-client-go/tfclient/marks"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestSchemaBlockValueMarks(t *testing.T) {
+	schema := &tfjson.SchemaBlock{
+		Attributes: map[string]*tfjson.SchemaAttribute{
+			"plain":     {AttributeType: cty.String, Optional: true},
+			"sensitive": {AttributeType: cty.String, Optional: true, Sensitive: true},
+			"nested": {
+				AttributeNestedType: &tfjson.SchemaNestedAttributeType{
+					NestingMode: tfjson.SchemaNestingModeList,
+					Attributes: map[string]*tfjson.SchemaAttribute{
+						"secret": {AttributeType: cty.String, Optional: true, Sensitive: true},
+						"public": {AttributeType: cty.String, Optional: true},
+					},
+				},
+				Optional: true,
+			},
+		},
+		NestedBlocks: map[string]*tfjson.SchemaBlockType{
+			"blk": {
+				NestingMode: tfjson.SchemaNestingModeMap,
+				Block: &tfjson.SchemaBlock{
+					Attributes: map[string]*tfjson.SchemaAttribute{
+						"token": {AttributeType: cty.String, Optional: true, Sensitive: true},
+					},
+				},
+			},
+		},
+	}
+
+	nestedElem := cty.ObjectVal(map[string]cty.Value{"secret": cty.StringVal("s"), "public": cty.StringVal("p")})
+	val := cty.ObjectVal(map[string]cty.Value{
+		"plain":     cty.StringVal("a"),
+		"sensitive": cty.NullVal(cty.String),
+		"nested":    cty.ListVal([]cty.Value{nestedElem}),
+		"blk": cty.MapVal(map[string]cty.Value{
+			"k": cty.ObjectVal(map[string]cty.Value{"token": cty.StringVal("t")}),
+		}),
+	})
+
+	marked := val.MarkWithPaths(SchemaBlockValueMarks(schema, val, nil))
+	cases := []struct {
+		path cty.Path
+		want bool
+	}{
+		{cty.GetAttrPath("plain"), false},
+		{cty.GetAttrPath("sensitive"), true},
+		{cty.GetAttrPath("nested").IndexInt(0).GetAttr("secret"), true},
+		{cty.GetAttrPath("nested").IndexInt(0).GetAttr("public"), false},
+		{cty.GetAttrPath("blk").IndexString("k").GetAttr("token"), true},
+	}
+	for _, tt := range cases {
+		v, err := tt.path.Apply(marked)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := v.HasMark(marks.Sensitive); got != tt.want {
+			t.Errorf("%#v: sensitive = %t, want %t", tt.path, got, tt.want)
+		}
+	}
+	if !marks.Contains(marked.GetAttr("nested"), marks.Sensitive) {
+		t.Errorf("the nested attribute should contain sensitive values")
+	}
+}
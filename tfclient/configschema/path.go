@@ -0,0 +1,61 @@
+// This is derived from github.com/hashicorp/terraform/internal/configs/configschema/path.go (v1.13.0-alpha20250521)
+
+package configschema
+
+import (
+	tfjson "github.com/hashicorp/terraform-json"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// SchemaBlockAttributeByPath looks up the Attribute schema which corresponds to
+// the given Path. A nil value is returned if the given path does not correspond
+// to a specific attribute.
+func SchemaBlockAttributeByPath(b *tfjson.SchemaBlock, path cty.Path) *tfjson.SchemaAttribute {
+	block := b
+	for i, step := range path {
+		switch step := step.(type) {
+		case cty.GetAttrStep:
+			if block == nil {
+				return nil
+			}
+			if attr := block.Attributes[step.Name]; attr != nil {
+				// If the Attribute is defined with a NestedType and there's
+				// more to the path, descend into the NestedType
+				if attr.AttributeNestedType != nil && i < len(path)-1 {
+					return SchemaNestedAttributeTypeAttributeByPath(attr.AttributeNestedType, path[i+1:])
+				} else if i < len(path)-1 { // There's more to the path, but not more to this Attribute.
+					return nil
+				}
+				return attr
+			}
+
+			if nestedBlock := block.NestedBlocks[step.Name]; nestedBlock != nil {
+				block = nestedBlock.Block
+				continue
+			}
+
+			return nil
+		}
+	}
+	return nil
+}
+
+// SchemaNestedAttributeTypeAttributeByPath looks up the Attribute schema which corresponds
+// to the given Path. A nil value is returned if the given path does not correspond to a
+// specific attribute.
+func SchemaNestedAttributeTypeAttributeByPath(o *tfjson.SchemaNestedAttributeType, path cty.Path) *tfjson.SchemaAttribute {
+	for i, step := range path {
+		switch step := step.(type) {
+		case cty.GetAttrStep:
+			if attr := o.Attributes[step.Name]; attr != nil {
+				if attr.AttributeNestedType != nil && i < len(path)-1 {
+					return SchemaNestedAttributeTypeAttributeByPath(attr.AttributeNestedType, path[i+1:])
+				} else if i < len(path)-1 { // There's more to the path, but not more to this Attribute.
+					return nil
+				}
+				return attr
+			}
+		}
+	}
+	return nil
+}
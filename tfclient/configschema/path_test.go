@@ -0,0 +1,63 @@
+package configschema
+
+import (
+	"testing"
+
+	tfjson "github.com/hashicorp/terraform-json"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// Mimic TestAttributeByPath
+func TestSchemaBlockAttributeByPath(t *testing.T) {
+	schema := &tfjson.SchemaBlock{
+		Attributes: map[string]*tfjson.SchemaAttribute{
+			"a1": {AttributeType: cty.String, Description: "a1"},
+			"a2": {
+				Description: "a2",
+				AttributeNestedType: &tfjson.SchemaNestedAttributeType{
+					NestingMode: tfjson.SchemaNestingModeList,
+					Attributes: map[string]*tfjson.SchemaAttribute{
+						"nt1": {AttributeType: cty.String, Description: "nt1"},
+					},
+				},
+			},
+		},
+		NestedBlocks: map[string]*tfjson.SchemaBlockType{
+			"b1": {
+				NestingMode: tfjson.SchemaNestingModeList,
+				Block: &tfjson.SchemaBlock{
+					Attributes: map[string]*tfjson.SchemaAttribute{
+						"a3": {AttributeType: cty.String, Description: "a3"},
+					},
+				},
+			},
+		},
+	}
+
+	for _, tc := range []struct {
+		path            cty.Path
+		attrDescription string
+		exists          bool
+	}{
+		{cty.GetAttrPath("a1"), "a1", true},
+		{cty.GetAttrPath("a2"), "a2", true},
+		{cty.GetAttrPath("a2").IndexInt(0).GetAttr("nt1"), "nt1", true},
+		{cty.GetAttrPath("b1").IndexInt(0).GetAttr("a3"), "a3", true},
+		{cty.GetAttrPath("b1"), "", false},
+		{cty.GetAttrPath("a1").GetAttr("a3"), "", false},
+		{cty.GetAttrPath("c1"), "", false},
+	} {
+		t.Run(tc.attrDescription, func(t *testing.T) {
+			attr := SchemaBlockAttributeByPath(schema, tc.path)
+			if !tc.exists && attr == nil {
+				return
+			}
+			if attr == nil {
+				t.Fatalf("missing attribute from path %#v\n", tc.path)
+			}
+			if attr.Description != tc.attrDescription {
+				t.Fatalf("expected Attribute for %q, got %#v\n", tc.attrDescription, attr)
+			}
+		})
+	}
+}
@@ -0,0 +1,38 @@
+package conformance
+
+import (
+	"fmt"
+
+	tfjson "github.com/hashicorp/terraform-json"
+	"github.com/magodo/terraform-client-go/tfclient/configschema"
+	"github.com/magodo/terraform-client-go/tfclient/marks"
+	"github.com/magodo/terraform-client-go/tfclient/objchange"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// valueDiffs returns the differences of the two values of the block, which are marked as sensitive by the schema,
+// so that diffDetail redacts them.
+func valueDiffs(block *tfjson.SchemaBlock, before, after cty.Value) []objchange.ValueDiff {
+	before = before.MarkWithPaths(configschema.SchemaBlockValueMarks(block, before, nil))
+	after = after.MarkWithPaths(configschema.SchemaBlockValueMarks(block, after, nil))
+	return objchange.ValueDiffs(before, after)
+}
+
+// diffDetail describes the difference, where the names are of the compared values. The values marked as
+// sensitive are redacted.
+func diffDetail(d objchange.ValueDiff, beforeName, afterName string) string {
+	return fmt.Sprintf("%s: %s\n%s: %s", beforeName, marks.RedactSensitive(d.Before).GoString(), afterName, marks.RedactSensitive(d.After).GoString())
+}
+
+// unknownPaths returns the paths of the unknown values.
+func unknownPaths(v cty.Value) []cty.Path {
+	var paths []cty.Path
+	cty.Walk(v, func(p cty.Path, v cty.Value) (bool, error) {
+		if !v.IsKnown() {
+			paths = append(paths, p.Copy())
+			return false, nil
+		}
+		return true, nil
+	})
+	return paths
+}
@@ -0,0 +1,36 @@
+package conformance
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	tfjson "github.com/hashicorp/terraform-json"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestDiffDetail(t *testing.T) {
+	block := &tfjson.SchemaBlock{
+		Attributes: map[string]*tfjson.SchemaAttribute{
+			"name":     {AttributeType: cty.String, Required: true},
+			"password": {AttributeType: cty.String, Optional: true, Sensitive: true},
+		},
+	}
+	thing := func(name, password string) cty.Value {
+		return cty.ObjectVal(map[string]cty.Value{
+			"name":     cty.StringVal(name),
+			"password": cty.StringVal(password),
+		})
+	}
+
+	var got []string
+	for _, d := range valueDiffs(block, thing("a", "secret1"), thing("b", "secret2")) {
+		got = append(got, diffDetail(d, "applied", "read"))
+	}
+	want := []string{
+		"applied: cty.StringVal(\"a\")\nread: cty.StringVal(\"b\")",
+		"applied: cty.StringVal(\"(sensitive value)\")\nread: cty.StringVal(\"(sensitive value)\")",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("wrong details\n%s", diff)
+	}
+}
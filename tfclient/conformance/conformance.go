@@ -0,0 +1,174 @@
+// Package conformance checks a provider against the contract of the provider protocol, by driving the resources
+// through their lifecycle with sample configs, and calling the functions with sample arguments.
+//
+// The contract violations and the errors reported by the provider are returned as diagnostics, where the
+// summaries are prefixed by the checked resource type or function, and the step of the check.
+package conformance
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/magodo/terraform-client-go/tfclient"
+	"github.com/magodo/terraform-client-go/tfclient/configschema"
+	"github.com/magodo/terraform-client-go/tfclient/typ"
+	"github.com/zclconf/go-cty/cty"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
+)
+
+// ResourceCase is the sample of a resource type to check.
+type ResourceCase struct {
+	TypeName string
+
+	// Config is the sample config, which conforms to the implied type of the resource schema.
+	Config cty.Value
+
+	// ImportID is the ID to import the resource. If empty, the resource is imported by the identity when the
+	// resource supports it, otherwise by the "id" attribute of the applied state.
+	ImportID string
+
+	// ImportIgnore are the top level attributes ignored when comparing the imported state, e.g. the ones that
+	// can't be read from the remote.
+	ImportIgnore []string
+}
+
+// FunctionCase is the sample of a function to check.
+type FunctionCase struct {
+	Name      string
+	Arguments []cty.Value
+}
+
+// Suite is a set of cases to check.
+type Suite struct {
+	Resources []ResourceCase
+	Functions []FunctionCase
+}
+
+// ParseSuite parses the suite in JSON, which has the form of:
+//
+//	{
+//	  "resources": {
+//	    "<type name>": {"config": <config>, "import_id": "<id>", "import_ignore": ["<attribute>"]}
+//	  },
+//	  "functions": {
+//	    "<function name>": {"arguments": [<argument>]}
+//	  }
+//	}
+//
+// The values are decoded by the provider schema. The configs are decoded the same as Terraform does, e.g. the
+// omitted attributes are null and the omitted nested blocks are empty.
+func ParseSuite(b []byte, schema *typ.GetProviderSchemaResponse) (*Suite, error) {
+	var raw struct {
+		Resources map[string]struct {
+			Config       json.RawMessage `json:"config"`
+			ImportID     string          `json:"import_id"`
+			ImportIgnore []string        `json:"import_ignore"`
+		} `json:"resources"`
+		Functions map[string]struct {
+			Arguments []json.RawMessage `json:"arguments"`
+		} `json:"functions"`
+	}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return nil, err
+	}
+
+	var suite Suite
+	for _, name := range sortedKeys(raw.Resources) {
+		rc := raw.Resources[name]
+		sch, ok := schema.ResourceTypes[name]
+		if !ok {
+			return nil, fmt.Errorf("no resource named %q", name)
+		}
+		config, err := configschema.SchemaBlockUnmarshalJSON(sch.Block, rc.Config)
+		if err != nil {
+			return nil, fmt.Errorf("decoding the config of %s: %v", name, err)
+		}
+		suite.Resources = append(suite.Resources, ResourceCase{
+			TypeName:     name,
+			Config:       config,
+			ImportID:     rc.ImportID,
+			ImportIgnore: rc.ImportIgnore,
+		})
+	}
+	for _, name := range sortedKeys(raw.Functions) {
+		fc := raw.Functions[name]
+		decl, ok := schema.Functions[name]
+		if !ok {
+			return nil, fmt.Errorf("no function named %q", name)
+		}
+		var args []cty.Value
+		for i, arg := range fc.Arguments {
+			var ty cty.Type
+			switch {
+			case i < len(decl.Parameters):
+				ty = decl.Parameters[i].Type
+			case decl.VariadicParameter != nil:
+				ty = decl.VariadicParameter.Type
+			default:
+				return nil, fmt.Errorf("too many arguments of %s, expect %d", name, len(decl.Parameters))
+			}
+			v, err := ctyjson.Unmarshal(arg, ty)
+			if err != nil {
+				return nil, fmt.Errorf("decoding the argument %d of %s: %v", i, name, err)
+			}
+			args = append(args, v)
+		}
+		suite.Functions = append(suite.Functions, FunctionCase{Name: name, Arguments: args})
+	}
+	return &suite, nil
+}
+
+// Result is the result of a checked case.
+type Result struct {
+	// Name is the resource type or the function name.
+	Name        string
+	Diagnostics typ.Diagnostics
+}
+
+// Run checks all the cases of the suite against the configured provider.
+func Run(ctx context.Context, c tfclient.Client, suite *Suite) []Result {
+	var results []Result
+	for _, rc := range suite.Resources {
+		results = append(results, Result{Name: rc.TypeName, Diagnostics: CheckResource(ctx, c, rc)})
+	}
+	for _, fc := range suite.Functions {
+		results = append(results, Result{Name: fc.Name, Diagnostics: CheckFunction(ctx, c, fc)})
+	}
+	return results
+}
+
+// reporter collects the diagnostics of a case.
+type reporter struct {
+	name  string
+	diags typ.Diagnostics
+}
+
+// provider adds the diagnostics reported by the provider in a step, and returns whether there is any error.
+func (r *reporter) provider(step string, diags typ.Diagnostics) bool {
+	for _, d := range diags {
+		d.Summary = fmt.Sprintf("%s: %s: %s", r.name, step, d.Summary)
+		r.diags = append(r.diags, d)
+	}
+	return diags.HasErrors()
+}
+
+// fail adds a contract violation of a step.
+func (r *reporter) fail(step string, path cty.Path, summary, detail string) {
+	r.diags = append(r.diags, typ.Diagnostic{
+		Severity:  typ.Error,
+		Summary:   fmt.Sprintf("%s: %s: %s", r.name, step, summary),
+		Detail:    detail,
+		Attribute: path,
+	})
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,220 @@
+package conformance
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	tfjson "github.com/hashicorp/terraform-json"
+	"github.com/magodo/terraform-client-go/tfclient"
+	"github.com/magodo/terraform-client-go/tfclient/configschema"
+	"github.com/magodo/terraform-client-go/tfclient/typ"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// fakeClient is an in-memory provider of the "foo_thing" resource, whose bugs can be switched on.
+// The methods that are not used by the checks panic.
+type fakeClient struct {
+	tfclient.Client
+
+	store map[string]cty.Value
+
+	// readBug makes the read state differ from the applied state.
+	readBug bool
+	// returnBug makes the function to return a value of a different type.
+	returnBug bool
+}
+
+var thingBlock = &tfjson.SchemaBlock{
+	Attributes: map[string]*tfjson.SchemaAttribute{
+		"id":   {AttributeType: cty.String, Computed: true},
+		"name": {AttributeType: cty.String, Required: true},
+		"tags": {AttributeType: cty.Map(cty.String), Optional: true},
+	},
+}
+
+var thingType = configschema.SchemaBlockImpliedType(thingBlock)
+
+func (c *fakeClient) GetProviderSchema() (*typ.GetProviderSchemaResponse, typ.Diagnostics) {
+	return &typ.GetProviderSchemaResponse{
+		ResourceTypes:    map[string]tfjson.Schema{"foo_thing": {Block: thingBlock}},
+		ResourceTypesCty: map[string]cty.Type{"foo_thing": thingType},
+		Functions: map[string]typ.FunctionDecl{
+			"upper": {
+				Parameters: []typ.FunctionParam{{Name: "s", Type: cty.String}},
+				ReturnType: cty.String,
+			},
+		},
+	}, nil
+}
+
+func (c *fakeClient) ValidateResourceConfig(context.Context, typ.ValidateResourceConfigRequest) (*typ.ValidateResourceConfigResponse, typ.Diagnostics) {
+	return &typ.ValidateResourceConfigResponse{}, nil
+}
+
+func (c *fakeClient) PlanResourceChange(_ context.Context, req typ.PlanResourceChangeRequest) (*typ.PlanResourceChangeResponse, typ.Diagnostics) {
+	planned := req.ProposedNewState
+	if req.PriorState.IsNull() && !planned.IsNull() {
+		attrs := planned.AsValueMap()
+		attrs["id"] = cty.UnknownVal(cty.String)
+		planned = cty.ObjectVal(attrs)
+	}
+	return &typ.PlanResourceChangeResponse{PlannedState: planned}, nil
+}
+
+func (c *fakeClient) ApplyResourceChange(_ context.Context, req typ.ApplyResourceChangeRequest) (*typ.ApplyResourceChangeResponse, typ.Diagnostics) {
+	if req.PlannedState.IsNull() {
+		delete(c.store, req.PriorState.GetAttr("id").AsString())
+		return &typ.ApplyResourceChangeResponse{NewState: req.PlannedState}, nil
+	}
+	attrs := req.PlannedState.AsValueMap()
+	attrs["id"] = cty.StringVal("1")
+	state := cty.ObjectVal(attrs)
+	c.store["1"] = state
+	return &typ.ApplyResourceChangeResponse{NewState: state}, nil
+}
+
+func (c *fakeClient) ReadResource(_ context.Context, req typ.ReadResourceRequest) (*typ.ReadResourceResponse, typ.Diagnostics) {
+	state, ok := c.store[req.PriorState.GetAttr("id").AsString()]
+	if !ok {
+		return &typ.ReadResourceResponse{NewState: cty.NullVal(thingType)}, nil
+	}
+	if c.readBug {
+		attrs := state.AsValueMap()
+		attrs["tags"] = cty.MapVal(map[string]cty.Value{"a": cty.StringVal("changed")})
+		state = cty.ObjectVal(attrs)
+	}
+	return &typ.ReadResourceResponse{NewState: state}, nil
+}
+
+func (c *fakeClient) ImportResourceState(_ context.Context, req typ.ImportResourceStateRequest) (*typ.ImportResourceStateResponse, typ.Diagnostics) {
+	return &typ.ImportResourceStateResponse{
+		ImportedResources: []typ.ImportedResource{
+			{
+				TypeName: req.TypeName,
+				State: cty.ObjectVal(map[string]cty.Value{
+					"id":   cty.StringVal(req.ID),
+					"name": cty.NullVal(cty.String),
+					"tags": cty.NullVal(cty.Map(cty.String)),
+				}),
+			},
+		},
+	}, nil
+}
+
+func (c *fakeClient) CallFunction(_ context.Context, req typ.CallFunctionRequest) (*typ.CallFunctionResponse, typ.Diagnostics) {
+	if c.returnBug {
+		return &typ.CallFunctionResponse{Result: cty.NumberIntVal(1)}, nil
+	}
+	return &typ.CallFunctionResponse{Result: cty.StringVal(strings.ToUpper(req.Arguments[0].AsString()))}, nil
+}
+
+func sampleConfig() cty.Value {
+	return cty.ObjectVal(map[string]cty.Value{
+		"id":   cty.NullVal(cty.String),
+		"name": cty.StringVal("a"),
+		"tags": cty.MapVal(map[string]cty.Value{"a": cty.StringVal("b")}),
+	})
+}
+
+func TestCheckResource(t *testing.T) {
+	c := &fakeClient{store: map[string]cty.Value{}}
+	diags := CheckResource(context.Background(), c, ResourceCase{TypeName: "foo_thing", Config: sampleConfig()})
+	if len(diags) != 0 {
+		t.Fatalf("unexpected diagnostics: %v", diags.Err())
+	}
+	if len(c.store) != 0 {
+		t.Errorf("the resource is not destroyed")
+	}
+}
+
+func TestCheckResource_readBug(t *testing.T) {
+	c := &fakeClient{store: map[string]cty.Value{}, readBug: true}
+	diags := CheckResource(context.Background(), c, ResourceCase{TypeName: "foo_thing", Config: sampleConfig()})
+
+	var got []string
+	for _, d := range diags {
+		got = append(got, d.Summary+" at "+typ.FormatCtyPath(d.Attribute))
+	}
+	want := []string{
+		`foo_thing: read: read state differs from the applied state at .tags["a"]`,
+		`foo_thing: re-plan: unexpected change after apply at .tags["a"]`,
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("wrong diagnostics\n%s", diff)
+	}
+	if len(c.store) != 0 {
+		t.Errorf("the resource is not destroyed")
+	}
+}
+
+func TestCheckFunction(t *testing.T) {
+	fc := FunctionCase{Name: "upper", Arguments: []cty.Value{cty.StringVal("a")}}
+	if diags := CheckFunction(context.Background(), &fakeClient{}, fc); len(diags) != 0 {
+		t.Fatalf("unexpected diagnostics: %v", diags.Err())
+	}
+	diags := CheckFunction(context.Background(), &fakeClient{returnBug: true}, fc)
+	if len(diags) != 1 || diags[0].Summary != "upper: return type: result doesn't conform to the return type" {
+		t.Errorf("unexpected diagnostics: %v", diags)
+	}
+}
+
+func TestParseSuite(t *testing.T) {
+	c := &fakeClient{}
+	schema, _ := c.GetProviderSchema()
+	suite, err := ParseSuite([]byte(`{
+  "resources": {"foo_thing": {"config": {"name": "a", "tags": {"a": "b"}}, "import_ignore": ["name"]}},
+  "functions": {"upper": {"arguments": ["a"]}}
+}`), schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(suite.Resources) != 1 || !suite.Resources[0].Config.RawEquals(sampleConfig()) {
+		t.Errorf("wrong resources: %#v", suite.Resources)
+	}
+	if diff := cmp.Diff([]string{"name"}, suite.Resources[0].ImportIgnore); diff != "" {
+		t.Errorf("wrong import ignore\n%s", diff)
+	}
+	if len(suite.Functions) != 1 || !suite.Functions[0].Arguments[0].RawEquals(cty.StringVal("a")) {
+		t.Errorf("wrong functions: %#v", suite.Functions)
+	}
+
+	// The config is coerced to the schema, with the omitted nested blocks being empty.
+	ruleType := cty.Object(map[string]cty.Type{"action": cty.String})
+	suite, err = ParseSuite([]byte(`{"resources": {"foo_rule": {"config": {"priority": "1"}}}}`), &typ.GetProviderSchemaResponse{
+		ResourceTypes: map[string]tfjson.Schema{
+			"foo_rule": {
+				Block: &tfjson.SchemaBlock{
+					Attributes: map[string]*tfjson.SchemaAttribute{
+						"priority": {AttributeType: cty.Number, Optional: true},
+					},
+					NestedBlocks: map[string]*tfjson.SchemaBlockType{
+						"rule": {
+							NestingMode: tfjson.SchemaNestingModeList,
+							Block: &tfjson.SchemaBlock{
+								Attributes: map[string]*tfjson.SchemaAttribute{
+									"action": {AttributeType: cty.String, Required: true},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := cty.ObjectVal(map[string]cty.Value{
+		"priority": cty.NumberIntVal(1),
+		"rule":     cty.ListValEmpty(ruleType),
+	})
+	if len(suite.Resources) != 1 || !suite.Resources[0].Config.RawEquals(want) {
+		t.Errorf("wrong resources: %#v", suite.Resources)
+	}
+
+	if _, err := ParseSuite([]byte(`{"resources": {"foo_other": {}}}`), schema); err == nil {
+		t.Errorf("expect an error for an unknown resource type")
+	}
+}
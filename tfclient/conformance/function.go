@@ -0,0 +1,56 @@
+package conformance
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/magodo/terraform-client-go/tfclient"
+	"github.com/magodo/terraform-client-go/tfclient/typ"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// CheckFunction calls the function with the sample arguments, and checks the result against the declared return type.
+func CheckFunction(ctx context.Context, c tfclient.Client, fc FunctionCase) typ.Diagnostics {
+	r := &reporter{name: fc.Name}
+
+	schema, diags := c.GetProviderSchema()
+	if r.provider("schema", diags) {
+		return r.diags
+	}
+	decl, ok := schema.Functions[fc.Name]
+	if !ok {
+		r.fail("schema", nil, "function not found", "")
+		return r.diags
+	}
+
+	resp, diags := c.CallFunction(ctx, typ.CallFunctionRequest{
+		FunctionName: fc.Name,
+		Arguments:    fc.Arguments,
+	})
+	if r.provider("call", diags) {
+		return r.diags
+	}
+	if resp.Err != nil {
+		r.fail("call", nil, "function error", resp.Err.Error())
+		return r.diags
+	}
+
+	result, _ := resp.Result.Unmark()
+	if result == cty.NilVal {
+		r.fail("call", nil, "no result", "")
+		return r.diags
+	}
+	if !result.IsWhollyKnown() {
+		for _, p := range unknownPaths(result) {
+			r.fail("call", p, "result is not wholly known", "")
+		}
+	}
+	for _, err := range result.Type().TestConformance(decl.ReturnType) {
+		var path cty.Path
+		if perr, ok := err.(cty.PathError); ok {
+			path = perr.Path
+		}
+		r.fail("return type", path, "result doesn't conform to the return type", fmt.Sprintf("The return type is %s, got %s: %s.", decl.ReturnType.FriendlyName(), result.Type().FriendlyName(), err))
+	}
+	return r.diags
+}
@@ -0,0 +1,249 @@
+package conformance
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	tfjson "github.com/hashicorp/terraform-json"
+	"github.com/magodo/terraform-client-go/tfclient"
+	"github.com/magodo/terraform-client-go/tfclient/objchange"
+	"github.com/magodo/terraform-client-go/tfclient/typ"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// CheckResource checks a resource type by the following steps:
+//
+//   - validate the config
+//   - plan and apply the creation
+//   - read the resource, which is expected to match the applied state
+//   - plan again, which is expected to have no changes
+//   - import the resource, which is expected to match the read state
+//   - plan and apply the destroy
+//
+// The resource is destroyed once created, even if the steps in between fail.
+func CheckResource(ctx context.Context, c tfclient.Client, rc ResourceCase) typ.Diagnostics {
+	r := &reporter{name: rc.TypeName}
+	checkResource(ctx, c, r, rc)
+	return r.diags
+}
+
+func checkResource(ctx context.Context, c tfclient.Client, r *reporter, rc ResourceCase) {
+	schema, diags := c.GetProviderSchema()
+	if r.provider("schema", diags) {
+		return
+	}
+	sch, ok := schema.ResourceTypes[rc.TypeName]
+	if !ok {
+		r.fail("schema", nil, "resource type not found", "")
+		return
+	}
+	ty := schema.ResourceTypesCty[rc.TypeName]
+
+	if !rc.Config.Type().Equals(ty) {
+		r.fail("validate", nil, "invalid sample config", fmt.Sprintf("The config type %s doesn't match the schema type %s.", rc.Config.Type().FriendlyName(), ty.FriendlyName()))
+		return
+	}
+
+	_, diags = c.ValidateResourceConfig(ctx, typ.ValidateResourceConfigRequest{
+		TypeName: rc.TypeName,
+		Config:   rc.Config,
+	})
+	if r.provider("validate", diags) {
+		return
+	}
+
+	// Create
+	plan, diags := c.PlanResourceChange(ctx, typ.PlanResourceChangeRequest{
+		TypeName:         rc.TypeName,
+		PriorState:       cty.NullVal(ty),
+		ProposedNewState: objchange.ProposedNew(sch.Block, cty.NullVal(ty), rc.Config),
+		Config:           rc.Config,
+	})
+	if r.provider("plan create", diags) {
+		return
+	}
+	if plan.Deferred != nil {
+		r.fail("plan create", nil, "unexpected deferral", fmt.Sprintf("The creation is deferred for %s.", plan.Deferred.Reason))
+		return
+	}
+	if plan.PlannedState.IsNull() {
+		r.fail("plan create", nil, "planned state is null", "")
+		return
+	}
+
+	applied, diags := c.ApplyResourceChange(ctx, typ.ApplyResourceChangeRequest{
+		TypeName:        rc.TypeName,
+		PriorState:      cty.NullVal(ty),
+		PlannedState:    plan.PlannedState,
+		Config:          rc.Config,
+		PlannedPrivate:  plan.PlannedPrivate,
+		PlannedIdentity: plan.PlannedIdentity,
+	})
+	if applied == nil || applied.NewState == cty.NilVal || applied.NewState.IsNull() {
+		// Nothing is created.
+		if !r.provider("apply create", diags) {
+			r.fail("apply create", nil, "new state is null", "")
+		}
+		return
+	}
+
+	// The state to be destroyed, which is updated as the steps proceed.
+	state := &instance{
+		state:    applied.NewState,
+		private:  applied.Private,
+		identity: applied.NewIdentity,
+	}
+	defer func() {
+		checkDestroy(ctx, c, r, rc, ty, state)
+	}()
+
+	if r.provider("apply create", diags) {
+		return
+	}
+	for _, p := range unknownPaths(applied.NewState) {
+		r.fail("apply create", p, "new state is not wholly known", "")
+	}
+
+	// Read
+	read, diags := c.ReadResource(ctx, typ.ReadResourceRequest{
+		TypeName:        rc.TypeName,
+		PriorState:      applied.NewState,
+		Private:         applied.Private,
+		CurrentIdentity: applied.NewIdentity,
+	})
+	if r.provider("read", diags) {
+		return
+	}
+	if read.NewState.IsNull() {
+		r.fail("read", nil, "resource not found", "The resource is reported as removed right after creation.")
+		return
+	}
+	for _, d := range valueDiffs(sch.Block, applied.NewState, read.NewState) {
+		r.fail("read", d.Path, "read state differs from the applied state", diffDetail(d, "applied", "read"))
+	}
+	if identityKnown(applied.NewIdentity) && identityKnown(read.Identity) {
+		for _, d := range objchange.ValueDiffs(applied.NewIdentity, read.Identity) {
+			r.fail("read", d.Path, "read identity differs from the applied identity", diffDetail(d, "applied", "read"))
+		}
+	}
+	state.state, state.private = read.NewState, read.Private
+	if identityKnown(read.Identity) {
+		state.identity = read.Identity
+	}
+
+	// Re-plan
+	replan, diags := c.PlanResourceChange(ctx, typ.PlanResourceChangeRequest{
+		TypeName:         rc.TypeName,
+		PriorState:       state.state,
+		ProposedNewState: objchange.ProposedNew(sch.Block, state.state, rc.Config),
+		Config:           rc.Config,
+		PriorPrivate:     state.private,
+		PriorIdentity:    state.identity,
+	})
+	if !r.provider("re-plan", diags) {
+		for _, d := range valueDiffs(sch.Block, state.state, replan.PlannedState) {
+			r.fail("re-plan", d.Path, "unexpected change after apply", diffDetail(d, "prior", "planned"))
+		}
+		for _, p := range replan.RequiresReplace {
+			r.fail("re-plan", p, "unexpected replacement after apply", "")
+		}
+	}
+
+	// Import
+	checkImport(ctx, c, r, rc, sch.Block, state)
+}
+
+// instance is a created resource instance.
+type instance struct {
+	state    cty.Value
+	private  []byte
+	identity cty.Value
+}
+
+func checkImport(ctx context.Context, c tfclient.Client, r *reporter, rc ResourceCase, block *tfjson.SchemaBlock, state *instance) {
+	req := typ.ImportResourceStateRequest{TypeName: rc.TypeName}
+	switch {
+	case rc.ImportID != "":
+		req.ID = rc.ImportID
+	case identityKnown(state.identity):
+		req.Identity = state.identity
+	case state.state.Type().HasAttribute("id") && state.state.GetAttr("id").Type() == cty.String && !state.state.GetAttr("id").IsNull():
+		req.ID = state.state.GetAttr("id").AsString()
+	default:
+		r.fail("import", nil, "no import ID", "The resource has neither identity nor the \"id\" attribute, the import ID needs to be specified.")
+		return
+	}
+	imported, diags := c.ImportResourceState(ctx, req)
+	if r.provider("import", diags) {
+		return
+	}
+	idx := slices.IndexFunc(imported.ImportedResources, func(res typ.ImportedResource) bool {
+		return res.TypeName == rc.TypeName
+	})
+	if idx == -1 {
+		r.fail("import", nil, "no resource imported", "")
+		return
+	}
+	res := imported.ImportedResources[idx]
+
+	// Imported resources are read before use, as Terraform does.
+	read, diags := c.ReadResource(ctx, typ.ReadResourceRequest{
+		TypeName:        rc.TypeName,
+		PriorState:      res.State,
+		Private:         res.Private,
+		CurrentIdentity: res.Identity,
+	})
+	if r.provider("import", diags) {
+		return
+	}
+	if read.NewState.IsNull() {
+		r.fail("import", nil, "imported resource not found", "")
+		return
+	}
+	for _, d := range valueDiffs(block, state.state, read.NewState) {
+		if len(d.Path) != 0 {
+			if step, ok := d.Path[0].(cty.GetAttrStep); ok && slices.Contains(rc.ImportIgnore, step.Name) {
+				continue
+			}
+		}
+		r.fail("import", d.Path, "imported state differs from the read state", diffDetail(d, "read", "imported"))
+	}
+}
+
+func checkDestroy(ctx context.Context, c tfclient.Client, r *reporter, rc ResourceCase, ty cty.Type, state *instance) {
+	plan, diags := c.PlanResourceChange(ctx, typ.PlanResourceChangeRequest{
+		TypeName:         rc.TypeName,
+		PriorState:       state.state,
+		ProposedNewState: cty.NullVal(ty),
+		Config:           cty.NullVal(ty),
+		PriorPrivate:     state.private,
+		PriorIdentity:    state.identity,
+	})
+	if r.provider("plan destroy", diags) {
+		return
+	}
+	if !plan.PlannedState.IsNull() {
+		r.fail("plan destroy", nil, "planned state is not null", "")
+		return
+	}
+
+	applied, diags := c.ApplyResourceChange(ctx, typ.ApplyResourceChangeRequest{
+		TypeName:        rc.TypeName,
+		PriorState:      state.state,
+		PlannedState:    plan.PlannedState,
+		Config:          cty.NullVal(ty),
+		PlannedPrivate:  plan.PlannedPrivate,
+		PlannedIdentity: plan.PlannedIdentity,
+	})
+	if r.provider("apply destroy", diags) {
+		return
+	}
+	if applied.NewState != cty.NilVal && !applied.NewState.IsNull() {
+		r.fail("apply destroy", nil, "new state is not null", "")
+	}
+}
+
+func identityKnown(v cty.Value) bool {
+	return v != cty.NilVal && !v.IsNull() && v.IsWhollyKnown()
+}
@@ -0,0 +1,652 @@
+// Package diffrender renders the change of a resource in the human readable format of `terraform plan`.
+package diffrender
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	tfjson "github.com/hashicorp/terraform-json"
+	"github.com/magodo/terraform-client-go/tfclient/configschema"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// Action is the action of a change.
+type Action string
+
+const (
+	NoOp    Action = "no-op"
+	Create  Action = "create"
+	Update  Action = "update"
+	Delete  Action = "delete"
+	Replace Action = "replace"
+)
+
+// ChangeAction returns the action of changing the resource from before to after, where a null value means the
+// resource is absent. The update is a replace if there is any path that requires replacement. The marks are ignored.
+func ChangeAction(before, after cty.Value, requiresReplace []cty.Path) Action {
+	before, _ = before.UnmarkDeep()
+	after, _ = after.UnmarkDeep()
+	act := valueAction(before, after)
+	if act == Update && len(requiresReplace) != 0 {
+		return Replace
+	}
+	return act
+}
+
+// Change is the change of a resource instance, e.g. from the prior state to the planned state.
+type Change struct {
+	// Address is the resource instance address, e.g. `foo_thing.a["x"]`.
+	Address string
+	// Type is the resource type, e.g. `foo_thing`.
+	Type   string
+	Schema *tfjson.SchemaBlock
+
+	// Before and After are the values conforming to the schema. The null value means the resource is absent, and
+	// the cty.NilVal is regarded as null.
+	Before cty.Value
+	After  cty.Value
+
+	// RequiresReplace are the paths that force the replacement.
+	RequiresReplace []cty.Path
+}
+
+// Option is the option of Render.
+type Option struct {
+	// Color enables the ANSI colour codes.
+	Color bool
+	// ShowSensitive shows the values of the sensitive attributes, instead of masking them.
+	ShowSensitive bool
+}
+
+// Render renders the change. The unchanged attributes, elements and blocks are hidden, and the sensitive attributes
+// are masked. The empty string is returned for a no-op change.
+func Render(c Change, opt Option) string {
+	ty := configschema.SchemaBlockImpliedType(c.Schema)
+	before, after := c.Before, c.After
+	if before == cty.NilVal {
+		before = cty.NullVal(ty)
+	}
+	if after == cty.NilVal {
+		after = cty.NullVal(ty)
+	}
+	before, _ = before.UnmarkDeep()
+	after, _ = after.UnmarkDeep()
+
+	act := ChangeAction(before, after, c.RequiresReplace)
+	if act == NoOp {
+		return ""
+	}
+
+	r := &renderer{color: opt.Color, showSensitive: opt.ShowSensitive, replace: c.RequiresReplace}
+	r.buf.WriteString(r.bold(fmt.Sprintf("  # %s %s", c.Address, headers[act])) + "\n")
+	r.buf.WriteString(fmt.Sprintf("%sresource %q %q {\n", r.prefix(0, act), c.Type, resourceName(c.Address, c.Type)))
+	r.writeBody(1, c.Schema.Attributes, c.Schema.NestedBlocks, before, after, nil)
+	r.buf.WriteString(indent(1) + "}\n")
+	return r.buf.String()
+}
+
+var headers = map[Action]string{
+	Create:  "will be created",
+	Update:  "will be updated in-place",
+	Delete:  "will be destroyed",
+	Replace: "must be replaced",
+}
+
+// resourceName returns the resource name in the address, without the module path and the instance key.
+func resourceName(addr, typ string) string {
+	if i := strings.LastIndex(addr, typ+"."); i != -1 {
+		addr = addr[i+len(typ)+1:]
+	}
+	name, _, _ := strings.Cut(addr, "[")
+	return name
+}
+
+const (
+	ansiReset  = "\x1b[0m"
+	ansiBold   = "\x1b[1m"
+	ansiRed    = "\x1b[31m"
+	ansiGreen  = "\x1b[32m"
+	ansiYellow = "\x1b[33m"
+)
+
+type renderer struct {
+	buf           strings.Builder
+	color         bool
+	showSensitive bool
+	replace       []cty.Path
+}
+
+func indent(level int) string {
+	return strings.Repeat("    ", level)
+}
+
+// prefix returns the leading part of a line at the nesting level, ending with the action symbol.
+func (r *renderer) prefix(level int, act Action) string {
+	if act == Replace {
+		return indent(level) + r.symbol(act) + " "
+	}
+	return indent(level) + "  " + r.symbol(act) + " "
+}
+
+func (r *renderer) symbol(act Action) string {
+	switch act {
+	case Create:
+		return r.colorize(ansiGreen, "+")
+	case Update:
+		return r.colorize(ansiYellow, "~")
+	case Delete:
+		return r.colorize(ansiRed, "-")
+	case Replace:
+		return r.colorize(ansiRed, "-") + "/" + r.colorize(ansiGreen, "+")
+	default:
+		return " "
+	}
+}
+
+func (r *renderer) colorize(code, s string) string {
+	if !r.color {
+		return s
+	}
+	return code + s + ansiReset
+}
+
+func (r *renderer) bold(s string) string {
+	return r.colorize(ansiBold, s)
+}
+
+// forces returns the replacement annotation if the path requires replacement.
+func (r *renderer) forces(path cty.Path) string {
+	for _, p := range r.replace {
+		if p.Equals(path) {
+			return " " + r.colorize(ansiRed, "# forces replacement")
+		}
+	}
+	return ""
+}
+
+func (r *renderer) writeHidden(level, n int, kind string) {
+	if n == 0 {
+		return
+	}
+	if n > 1 {
+		kind += "s"
+	}
+	fmt.Fprintf(&r.buf, "%s# (%d unchanged %s hidden)\n", indent(level+1), n, kind)
+}
+
+// writeBody writes the attributes and the nested blocks of an object at the nesting level.
+func (r *renderer) writeBody(level int, attrs map[string]*tfjson.SchemaAttribute, blocks map[string]*tfjson.SchemaBlockType, before, after cty.Value, path cty.Path) {
+	var names []string
+	var width int
+	for _, name := range sortedKeys(attrs) {
+		if valueAction(getAttr(before, name), getAttr(after, name)) == NoOp {
+			continue
+		}
+		names = append(names, name)
+		width = max(width, len(name))
+	}
+	for _, name := range names {
+		b, a := getAttr(before, name), getAttr(after, name)
+		act := valueAction(b, a)
+		r.buf.WriteString(r.prefix(level, act) + name + strings.Repeat(" ", width-len(name)) + " = ")
+		r.writeAttribute(level, attrs[name], b, a, path.GetAttr(name), act)
+		r.buf.WriteString(r.forces(path.GetAttr(name)) + "\n")
+	}
+	var hidden int
+	for name := range attrs {
+		b, a := getAttr(before, name), getAttr(after, name)
+		if !(b.IsNull() && a.IsNull()) && valueAction(b, a) == NoOp {
+			hidden++
+		}
+	}
+	r.writeHidden(level, hidden, "attribute")
+
+	hidden = 0
+	for _, name := range sortedKeys(blocks) {
+		hidden += r.writeBlock(level, name, blocks[name], getAttr(before, name), getAttr(after, name), path.GetAttr(name))
+	}
+	r.writeHidden(level, hidden, "block")
+}
+
+func (r *renderer) writeAttribute(level int, schema *tfjson.SchemaAttribute, before, after cty.Value, path cty.Path, act Action) {
+	switch {
+	case schema.Sensitive && !r.showSensitive:
+		r.buf.WriteString("(sensitive value)")
+		if act == Delete {
+			r.buf.WriteString(" -> null")
+		}
+	case schema.AttributeNestedType != nil:
+		r.writeNestedType(level, schema.AttributeNestedType, before, after, path, act)
+	default:
+		r.writeValue(level, before, after, path, act)
+	}
+}
+
+// writeNestedType writes the value of an attribute of a nested type, whose nested attributes are rendered as of
+// a body, so that their sensitivity is respected.
+func (r *renderer) writeNestedType(level int, schema *tfjson.SchemaNestedAttributeType, before, after cty.Value, path cty.Path, act Action) {
+	if !after.IsKnown() {
+		r.buf.WriteString("(known after apply)")
+		return
+	}
+	if schema.NestingMode == tfjson.SchemaNestingModeSingle {
+		r.buf.WriteString("{\n")
+		r.writeBody(level+1, schema.Attributes, nil, before, after, path)
+		r.buf.WriteString(indent(level+1) + "}")
+	} else {
+		open, close := "[", "]"
+		if schema.NestingMode == tfjson.SchemaNestingModeMap {
+			open, close = "{", "}"
+		}
+		r.buf.WriteString(open + "\n")
+		pairs, hidden := pairElements(schema.NestingMode, before, after)
+		for _, p := range pairs {
+			r.buf.WriteString(r.prefix(level+1, p.act))
+			if schema.NestingMode == tfjson.SchemaNestingModeMap {
+				r.buf.WriteString(formatPrimitive(p.key) + " = ")
+			}
+			r.buf.WriteString("{\n")
+			r.writeBody(level+2, schema.Attributes, nil, p.before, p.after, path.Index(p.key))
+			r.buf.WriteString(indent(level+2) + "}")
+			if schema.NestingMode != tfjson.SchemaNestingModeMap {
+				r.buf.WriteString(",")
+			}
+			r.buf.WriteString(r.forces(path.Index(p.key)) + "\n")
+		}
+		r.writeHidden(level+1, hidden, "element")
+		r.buf.WriteString(indent(level+1) + close)
+	}
+	if act == Delete {
+		r.buf.WriteString(" -> null")
+	}
+}
+
+// writeBlock writes the nested blocks of a block type, and returns the number of the unchanged blocks.
+func (r *renderer) writeBlock(level int, name string, schema *tfjson.SchemaBlockType, before, after cty.Value, path cty.Path) int {
+	switch schema.NestingMode {
+	case tfjson.SchemaNestingModeSingle, tfjson.SchemaNestingModeGroup:
+		act := valueAction(before, after)
+		if act == NoOp {
+			if before.IsNull() {
+				return 0
+			}
+			return 1
+		}
+		r.buf.WriteString(r.prefix(level, act) + name + " {" + r.forces(path) + "\n")
+		r.writeBody(level+1, schema.Block.Attributes, schema.Block.NestedBlocks, before, after, path)
+		r.buf.WriteString(indent(level+1) + "}\n")
+		return 0
+	}
+
+	if !after.IsKnown() {
+		r.buf.WriteString(r.prefix(level, Update) + name + " = (known after apply)\n")
+		return 0
+	}
+	pairs, hidden := pairElements(schema.NestingMode, before, after)
+	for _, p := range pairs {
+		r.buf.WriteString(r.prefix(level, p.act) + name)
+		if schema.NestingMode == tfjson.SchemaNestingModeMap {
+			r.buf.WriteString(" " + formatPrimitive(p.key))
+		}
+		r.buf.WriteString(" {" + r.forces(path.Index(p.key)) + "\n")
+		r.writeBody(level+1, schema.Block.Attributes, schema.Block.NestedBlocks, p.before, p.after, path.Index(p.key))
+		r.buf.WriteString(indent(level+1) + "}\n")
+	}
+	return hidden
+}
+
+// writeValue writes the change of a value that has no schema.
+func (r *renderer) writeValue(level int, before, after cty.Value, path cty.Path, act Action) {
+	switch act {
+	case NoOp, Create:
+		r.writeFull(level, after, act)
+		return
+	case Delete:
+		r.writeFull(level, before, act)
+		r.buf.WriteString(" -> null")
+		return
+	}
+
+	ty := after.Type()
+	switch {
+	case !after.IsKnown():
+		r.writeFull(level, before, Delete)
+		r.buf.WriteString(" -> (known after apply)")
+	case ty.IsPrimitiveType() || !ty.Equals(before.Type()):
+		r.writeFull(level, before, Delete)
+		r.buf.WriteString(" -> ")
+		r.writeFull(level, after, Create)
+	case ty.IsMapType() || ty.IsObjectType():
+		r.writeMapDiff(level, before, after, path)
+	case ty.IsSetType():
+		r.writeSequenceDiff(level, pairSetElements(before, after), path)
+	default:
+		r.writeSequenceDiff(level, pairListElements(before, after), path)
+	}
+}
+
+func (r *renderer) writeMapDiff(level int, before, after cty.Value, path cty.Path) {
+	bm, am := before.AsValueMap(), after.AsValueMap()
+	keys := map[string]bool{}
+	for k := range bm {
+		keys[k] = true
+	}
+	for k := range am {
+		keys[k] = true
+	}
+	isObject := after.Type().IsObjectType()
+	keyText := func(k string) string {
+		if isObject {
+			return k
+		}
+		return formatPrimitive(cty.StringVal(k))
+	}
+
+	var shown []string
+	var width, hidden int
+	for _, k := range sortedKeys(keys) {
+		if valueAction(element(before, bm, k), element(after, am, k)) == NoOp {
+			hidden++
+			continue
+		}
+		shown = append(shown, k)
+		width = max(width, len(keyText(k)))
+	}
+
+	r.buf.WriteString("{\n")
+	for _, k := range shown {
+		b, a := element(before, bm, k), element(after, am, k)
+		act := valueAction(b, a)
+		kt := keyText(k)
+		r.buf.WriteString(r.prefix(level+1, act) + kt + strings.Repeat(" ", width-len(kt)) + " = ")
+		step := path.Index(cty.StringVal(k))
+		if isObject {
+			step = path.GetAttr(k)
+		}
+		r.writeValue(level+1, b, a, step, act)
+		r.buf.WriteString(r.forces(step) + "\n")
+	}
+	r.writeHidden(level+1, hidden, "element")
+	r.buf.WriteString(indent(level+1) + "}")
+}
+
+func (r *renderer) writeSequenceDiff(level int, pairs []elementPair, path cty.Path) {
+	r.buf.WriteString("[\n")
+	var hidden int
+	for _, p := range pairs {
+		if p.act == NoOp {
+			hidden++
+			continue
+		}
+		r.buf.WriteString(r.prefix(level+1, p.act))
+		switch p.act {
+		case Create:
+			r.writeFull(level+1, p.after, Create)
+		case Delete:
+			r.writeFull(level+1, p.before, Delete)
+		default:
+			r.writeValue(level+1, p.before, p.after, path.Index(p.key), p.act)
+		}
+		r.buf.WriteString("," + r.forces(path.Index(p.key)) + "\n")
+	}
+	r.writeHidden(level+1, hidden, "element")
+	r.buf.WriteString(indent(level+1) + "]")
+}
+
+// writeFull writes the whole value, whose nested lines are all of the same action.
+func (r *renderer) writeFull(level int, v cty.Value, act Action) {
+	ty := v.Type()
+	switch {
+	case v.IsNull():
+		r.buf.WriteString("null")
+	case !v.IsKnown():
+		r.buf.WriteString("(known after apply)")
+	case ty.IsPrimitiveType():
+		r.buf.WriteString(formatPrimitive(v))
+	case v.LengthInt() == 0:
+		if ty.IsMapType() || ty.IsObjectType() {
+			r.buf.WriteString("{}")
+		} else {
+			r.buf.WriteString("[]")
+		}
+	case ty.IsMapType() || ty.IsObjectType():
+		m := v.AsValueMap()
+		keys := sortedKeys(m)
+		keyText := func(k string) string {
+			if ty.IsObjectType() {
+				return k
+			}
+			return formatPrimitive(cty.StringVal(k))
+		}
+		var width int
+		for _, k := range keys {
+			width = max(width, len(keyText(k)))
+		}
+		r.buf.WriteString("{\n")
+		for _, k := range keys {
+			kt := keyText(k)
+			r.buf.WriteString(r.prefix(level+1, act) + kt + strings.Repeat(" ", width-len(kt)) + " = ")
+			r.writeFull(level+1, m[k], act)
+			r.buf.WriteString("\n")
+		}
+		r.buf.WriteString(indent(level+1) + "}")
+	default:
+		r.buf.WriteString("[\n")
+		for it := v.ElementIterator(); it.Next(); {
+			_, ev := it.Element()
+			r.buf.WriteString(r.prefix(level+1, act))
+			r.writeFull(level+1, ev, act)
+			r.buf.WriteString(",\n")
+		}
+		r.buf.WriteString(indent(level+1) + "]")
+	}
+}
+
+func formatPrimitive(v cty.Value) string {
+	return string(hclwrite.TokensForValue(v).Bytes())
+}
+
+// valueAction returns the action of changing the value from before to after.
+func valueAction(before, after cty.Value) Action {
+	switch {
+	case before.IsNull() && after.IsNull():
+		return NoOp
+	case before.IsNull():
+		return Create
+	case after.IsNull():
+		return Delete
+	case !before.RawEquals(after):
+		return Update
+	default:
+		return NoOp
+	}
+}
+
+// getAttr returns the attribute of the object, which is null or unknown if the object is.
+func getAttr(obj cty.Value, name string) cty.Value {
+	ty := obj.Type().AttributeType(name)
+	switch {
+	case obj.IsNull():
+		return cty.NullVal(ty)
+	case !obj.IsKnown():
+		return cty.UnknownVal(ty)
+	default:
+		return obj.GetAttr(name)
+	}
+}
+
+// element returns the element of the map or object, or null if absent.
+func element(v cty.Value, m map[string]cty.Value, k string) cty.Value {
+	if ev, ok := m[k]; ok {
+		return ev
+	}
+	if v.Type().IsObjectType() {
+		return cty.NullVal(v.Type().AttributeType(k))
+	}
+	return cty.NullVal(v.Type().ElementType())
+}
+
+// elementPair is a pair of the correlated elements of two collections. The key is the index of the element in the
+// after collection (or the before one for a deletion) for a list, the element itself for a set, and the key for a map.
+type elementPair struct {
+	key           cty.Value
+	before, after cty.Value
+	act           Action
+}
+
+// pairElements correlates the elements of two collections of objects of the nesting mode, and returns the changed
+// pairs together with the number of the unchanged ones. The list elements are correlated by index.
+func pairElements(mode tfjson.SchemaNestingMode, before, after cty.Value) ([]elementPair, int) {
+	var all []elementPair
+	switch mode {
+	case tfjson.SchemaNestingModeSet:
+		all = pairSetElements(before, after)
+	case tfjson.SchemaNestingModeMap:
+		bm, am := valueMap(before), valueMap(after)
+		keys := map[string]bool{}
+		for k := range bm {
+			keys[k] = true
+		}
+		for k := range am {
+			keys[k] = true
+		}
+		ety := collectionElementType(before, after)
+		for _, k := range sortedKeys(keys) {
+			b, ok := bm[k]
+			if !ok {
+				b = cty.NullVal(ety)
+			}
+			a, ok := am[k]
+			if !ok {
+				a = cty.NullVal(ety)
+			}
+			all = append(all, elementPair{key: cty.StringVal(k), before: b, after: a, act: valueAction(b, a)})
+		}
+	default:
+		bl, al := valueList(before), valueList(after)
+		ety := collectionElementType(before, after)
+		for i := 0; i < max(len(bl), len(al)); i++ {
+			b, a := cty.NullVal(ety), cty.NullVal(ety)
+			if i < len(bl) {
+				b = bl[i]
+			}
+			if i < len(al) {
+				a = al[i]
+			}
+			all = append(all, elementPair{key: cty.NumberIntVal(int64(i)), before: b, after: a, act: valueAction(b, a)})
+		}
+	}
+
+	var pairs []elementPair
+	var hidden int
+	for _, p := range all {
+		if p.act == NoOp {
+			hidden++
+			continue
+		}
+		pairs = append(pairs, p)
+	}
+	return pairs, hidden
+}
+
+// pairSetElements correlates the equal elements of two sets, the others are either deleted or created.
+func pairSetElements(before, after cty.Value) []elementPair {
+	bl, al := valueList(before), valueList(after)
+	ety := collectionElementType(before, after)
+	var pairs []elementPair
+	for _, b := range bl {
+		if containsValue(al, b) {
+			pairs = append(pairs, elementPair{key: b, before: b, after: b, act: NoOp})
+			continue
+		}
+		pairs = append(pairs, elementPair{key: b, before: b, after: cty.NullVal(ety), act: Delete})
+	}
+	for _, a := range al {
+		if !containsValue(bl, a) {
+			pairs = append(pairs, elementPair{key: a, before: cty.NullVal(ety), after: a, act: Create})
+		}
+	}
+	return pairs
+}
+
+// pairListElements correlates the elements of two lists by their longest common subsequence, the others are
+// either deleted or created.
+func pairListElements(before, after cty.Value) []elementPair {
+	bl, al := valueList(before), valueList(after)
+
+	// lcs[i][j] is the length of the longest common subsequence of bl[i:] and al[j:].
+	lcs := make([][]int, len(bl)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(al)+1)
+	}
+	for i := len(bl) - 1; i >= 0; i-- {
+		for j := len(al) - 1; j >= 0; j-- {
+			if bl[i].RawEquals(al[j]) {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var pairs []elementPair
+	i, j := 0, 0
+	for i < len(bl) || j < len(al) {
+		switch {
+		case i < len(bl) && j < len(al) && bl[i].RawEquals(al[j]):
+			pairs = append(pairs, elementPair{key: cty.NumberIntVal(int64(j)), before: bl[i], after: al[j], act: NoOp})
+			i++
+			j++
+		case j == len(al) || (i < len(bl) && lcs[i+1][j] >= lcs[i][j+1]):
+			pairs = append(pairs, elementPair{key: cty.NumberIntVal(int64(i)), before: bl[i], after: bl[i], act: Delete})
+			i++
+		default:
+			pairs = append(pairs, elementPair{key: cty.NumberIntVal(int64(j)), before: al[j], after: al[j], act: Create})
+			j++
+		}
+	}
+	return pairs
+}
+
+func containsValue(vals []cty.Value, v cty.Value) bool {
+	for _, e := range vals {
+		if e.RawEquals(v) {
+			return true
+		}
+	}
+	return false
+}
+
+// valueList returns the elements of a sequence, which is empty for a null or unknown value.
+func valueList(v cty.Value) []cty.Value {
+	if v.IsNull() || !v.IsKnown() {
+		return nil
+	}
+	return v.AsValueSlice()
+}
+
+// valueMap returns the elements of a map, which is empty for a null or unknown value.
+func valueMap(v cty.Value) map[string]cty.Value {
+	if v.IsNull() || !v.IsKnown() {
+		return nil
+	}
+	return v.AsValueMap()
+}
+
+func collectionElementType(before, after cty.Value) cty.Type {
+	if ty := after.Type(); ty.IsCollectionType() {
+		return ty.ElementType()
+	}
+	return before.Type().ElementType()
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
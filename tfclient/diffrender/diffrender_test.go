@@ -0,0 +1,325 @@
+package diffrender
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	tfjson "github.com/hashicorp/terraform-json"
+	"github.com/zclconf/go-cty/cty"
+)
+
+var testSchema = &tfjson.SchemaBlock{
+	Attributes: map[string]*tfjson.SchemaAttribute{
+		"id":       {AttributeType: cty.String, Computed: true},
+		"name":     {AttributeType: cty.String, Required: true},
+		"password": {AttributeType: cty.String, Optional: true, Sensitive: true},
+		"tags":     {AttributeType: cty.Map(cty.String), Optional: true},
+		"ports":    {AttributeType: cty.List(cty.Number), Optional: true},
+		"endpoint": {
+			AttributeNestedType: &tfjson.SchemaNestedAttributeType{
+				NestingMode: tfjson.SchemaNestingModeSingle,
+				Attributes: map[string]*tfjson.SchemaAttribute{
+					"host":  {AttributeType: cty.String, Optional: true},
+					"token": {AttributeType: cty.String, Optional: true, Sensitive: true},
+				},
+			},
+			Optional: true,
+		},
+	},
+	NestedBlocks: map[string]*tfjson.SchemaBlockType{
+		"rule": {
+			NestingMode: tfjson.SchemaNestingModeList,
+			Block: &tfjson.SchemaBlock{
+				Attributes: map[string]*tfjson.SchemaAttribute{
+					"action": {AttributeType: cty.String, Required: true},
+				},
+			},
+		},
+	},
+}
+
+var testSetSchema = &tfjson.SchemaBlock{
+	Attributes: map[string]*tfjson.SchemaAttribute{
+		"id": {AttributeType: cty.String, Computed: true},
+	},
+	NestedBlocks: map[string]*tfjson.SchemaBlockType{
+		"ingress": {
+			NestingMode: tfjson.SchemaNestingModeSet,
+			Block: &tfjson.SchemaBlock{
+				Attributes: map[string]*tfjson.SchemaAttribute{
+					"port": {AttributeType: cty.Number, Required: true},
+					"cidr": {AttributeType: cty.String, Optional: true},
+				},
+			},
+		},
+	},
+}
+
+func testSetThing(ports ...int64) cty.Value {
+	var ingress []cty.Value
+	for _, port := range ports {
+		ingress = append(ingress, cty.ObjectVal(map[string]cty.Value{
+			"port": cty.NumberIntVal(port),
+			"cidr": cty.StringVal("10.0.0.0/8"),
+		}))
+	}
+	return cty.ObjectVal(map[string]cty.Value{
+		"id":      cty.StringVal("1"),
+		"ingress": cty.SetVal(ingress),
+	})
+}
+
+type testThing struct {
+	id, name, password, host, token cty.Value
+	tags, ports                     cty.Value
+	rules                           []string
+}
+
+func (o testThing) value() cty.Value {
+	str := func(v cty.Value) cty.Value {
+		if v == cty.NilVal {
+			return cty.NullVal(cty.String)
+		}
+		return v
+	}
+	endpoint := cty.NullVal(cty.Object(map[string]cty.Type{"host": cty.String, "token": cty.String}))
+	if o.host != cty.NilVal {
+		endpoint = cty.ObjectVal(map[string]cty.Value{"host": o.host, "token": str(o.token)})
+	}
+	tags := o.tags
+	if tags == cty.NilVal {
+		tags = cty.NullVal(cty.Map(cty.String))
+	}
+	ports := o.ports
+	if ports == cty.NilVal {
+		ports = cty.NullVal(cty.List(cty.Number))
+	}
+	ruleTy := cty.Object(map[string]cty.Type{"action": cty.String})
+	rules := cty.ListValEmpty(ruleTy)
+	if len(o.rules) != 0 {
+		var l []cty.Value
+		for _, r := range o.rules {
+			l = append(l, cty.ObjectVal(map[string]cty.Value{"action": cty.StringVal(r)}))
+		}
+		rules = cty.ListVal(l)
+	}
+	return cty.ObjectVal(map[string]cty.Value{
+		"id":       str(o.id),
+		"name":     str(o.name),
+		"password": str(o.password),
+		"tags":     tags,
+		"ports":    ports,
+		"endpoint": endpoint,
+		"rule":     rules,
+	})
+}
+
+func TestRender(t *testing.T) {
+	prior := testThing{
+		id:       cty.StringVal("1"),
+		name:     cty.StringVal("a"),
+		password: cty.StringVal("secret"),
+		tags:     cty.MapVal(map[string]cty.Value{"env": cty.StringVal("dev"), "team": cty.StringVal("x")}),
+		ports:    cty.ListVal([]cty.Value{cty.NumberIntVal(80), cty.NumberIntVal(443)}),
+		host:     cty.StringVal("h1"),
+		token:    cty.StringVal("t1"),
+		rules:    []string{"allow", "deny"},
+	}
+
+	cases := []struct {
+		name   string
+		change Change
+		opt    Option
+		want   string
+	}{
+		{
+			name: "no-op",
+			change: Change{
+				Address: "foo_thing.a",
+				Type:    "foo_thing",
+				Schema:  testSchema,
+				Before:  prior.value(),
+				After:   prior.value(),
+			},
+			want: "",
+		},
+		{
+			name: "create",
+			change: Change{
+				Address: `module.m.foo_thing.a["x"]`,
+				Type:    "foo_thing",
+				Schema:  testSchema,
+				After: testThing{
+					id:       cty.UnknownVal(cty.String),
+					name:     cty.StringVal("a"),
+					password: cty.StringVal("secret"),
+					tags:     cty.MapVal(map[string]cty.Value{"env": cty.StringVal("dev")}),
+					host:     cty.StringVal("h1"),
+					token:    cty.StringVal("t1"),
+					rules:    []string{"allow"},
+				}.value(),
+			},
+			want: `  # module.m.foo_thing.a["x"] will be created
+  + resource "foo_thing" "a" {
+      + endpoint = {
+          + host  = "h1"
+          + token = (sensitive value)
+        }
+      + id       = (known after apply)
+      + name     = "a"
+      + password = (sensitive value)
+      + tags     = {
+          + "env" = "dev"
+        }
+      + rule {
+          + action = "allow"
+        }
+    }
+`,
+		},
+		{
+			name: "update",
+			change: Change{
+				Address: "foo_thing.a",
+				Type:    "foo_thing",
+				Schema:  testSchema,
+				Before:  prior.value(),
+				After: testThing{
+					id:       cty.StringVal("1"),
+					name:     cty.StringVal("a"),
+					password: cty.StringVal("changed"),
+					tags:     cty.MapVal(map[string]cty.Value{"env": cty.StringVal("prod"), "team": cty.StringVal("x")}),
+					ports:    cty.ListVal([]cty.Value{cty.NumberIntVal(80), cty.NumberIntVal(8080)}),
+					host:     cty.StringVal("h2"),
+					token:    cty.StringVal("t1"),
+					rules:    []string{"allow"},
+				}.value(),
+			},
+			want: `  # foo_thing.a will be updated in-place
+  ~ resource "foo_thing" "a" {
+      ~ endpoint = {
+          ~ host = "h1" -> "h2"
+            # (1 unchanged attribute hidden)
+        }
+      ~ password = (sensitive value)
+      ~ ports    = [
+          - 443,
+          + 8080,
+            # (1 unchanged element hidden)
+        ]
+      ~ tags     = {
+          ~ "env" = "dev" -> "prod"
+            # (1 unchanged element hidden)
+        }
+        # (2 unchanged attributes hidden)
+      - rule {
+          - action = "deny" -> null
+        }
+        # (1 unchanged block hidden)
+    }
+`,
+		},
+		{
+			name: "replace",
+			change: Change{
+				Address: "foo_thing.a",
+				Type:    "foo_thing",
+				Schema:  testSchema,
+				Before:  prior.value(),
+				After: testThing{
+					id:       cty.UnknownVal(cty.String),
+					name:     cty.StringVal("b"),
+					password: cty.StringVal("secret"),
+					tags:     prior.tags,
+					ports:    prior.ports,
+					host:     cty.StringVal("h1"),
+					token:    cty.StringVal("t1"),
+					rules:    prior.rules,
+				}.value(),
+				RequiresReplace: []cty.Path{cty.GetAttrPath("name")},
+			},
+			want: `  # foo_thing.a must be replaced
+-/+ resource "foo_thing" "a" {
+      ~ id   = "1" -> (known after apply)
+      ~ name = "a" -> "b" # forces replacement
+        # (4 unchanged attributes hidden)
+        # (2 unchanged blocks hidden)
+    }
+`,
+		},
+		{
+			name: "set nested block",
+			change: Change{
+				Address: "foo_thing.a",
+				Type:    "foo_thing",
+				Schema:  testSetSchema,
+				Before:  testSetThing(80, 443),
+				After:   testSetThing(80, 8443),
+			},
+			want: `  # foo_thing.a will be updated in-place
+  ~ resource "foo_thing" "a" {
+        # (1 unchanged attribute hidden)
+      - ingress {
+          - cidr = "10.0.0.0/8" -> null
+          - port = 443 -> null
+        }
+      + ingress {
+          + cidr = "10.0.0.0/8"
+          + port = 8443
+        }
+        # (1 unchanged block hidden)
+    }
+`,
+		},
+		{
+			name: "delete with color",
+			change: Change{
+				Address: "foo_thing.a",
+				Type:    "foo_thing",
+				Schema:  testSchema,
+				Before: testThing{
+					id:   cty.StringVal("1"),
+					name: cty.StringVal("a"),
+				}.value(),
+			},
+			opt: Option{Color: true},
+			want: "\x1b[1m  # foo_thing.a will be destroyed\x1b[0m\n" +
+				"  \x1b[31m-\x1b[0m resource \"foo_thing\" \"a\" {\n" +
+				"      \x1b[31m-\x1b[0m id   = \"1\" -> null\n" +
+				"      \x1b[31m-\x1b[0m name = \"a\" -> null\n" +
+				"    }\n",
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Render(tt.change, tt.opt)
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("wrong rendering\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestChangeAction(t *testing.T) {
+	null := cty.NullVal(cty.String)
+	a, b := cty.StringVal("a"), cty.StringVal("b")
+	cases := []struct {
+		before, after cty.Value
+		replace       []cty.Path
+		want          Action
+	}{
+		{null, null, nil, NoOp},
+		{a, a, nil, NoOp},
+		{null, a, nil, Create},
+		{a, null, nil, Delete},
+		{a, b, nil, Update},
+		{a, cty.UnknownVal(cty.String), nil, Update},
+		{a, b, []cty.Path{nil}, Replace},
+	}
+	for _, tt := range cases {
+		if got := ChangeAction(tt.before, tt.after, tt.replace); got != tt.want {
+			t.Errorf("ChangeAction(%#v, %#v) = %s, want %s", tt.before, tt.after, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,199 @@
+// Package drift detects the drift between the resources stored in the state and the ones of the remote,
+// by refreshing the resources via the provider.
+package drift
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	tfjson "github.com/hashicorp/terraform-json"
+	"github.com/magodo/terraform-client-go/tfclient"
+	"github.com/magodo/terraform-client-go/tfclient/configschema"
+	"github.com/magodo/terraform-client-go/tfclient/objchange"
+	"github.com/magodo/terraform-client-go/tfclient/typ"
+	"github.com/zclconf/go-cty/cty"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
+)
+
+// Status is the drift status of a resource.
+type Status string
+
+const (
+	// StatusInSync means there is no drift, except for the computed attributes.
+	StatusInSync Status = "in_sync"
+	// StatusDrifted means some non-computed attributes are changed.
+	StatusDrifted Status = "drifted"
+	// StatusGone means the resource no longer exists.
+	StatusGone Status = "gone"
+	// StatusError means the drift can't be detected, see the diagnostics for details.
+	StatusError Status = "error"
+)
+
+// Change is the change of an attribute, from the stored value to the refreshed one. The computed only attributes
+// nested in the values are null, as they are not compared.
+type Change struct {
+	Path   cty.Path
+	Before cty.Value
+	After  cty.Value
+}
+
+// Result is the drift detection result of a resource.
+type Result struct {
+	Resource    Resource
+	Status      Status
+	Changes     []Change
+	Diagnostics typ.Diagnostics
+}
+
+// Option is the option of Detect.
+type Option struct {
+	// Parallelism is the number of resources refreshed concurrently. Defaults to 1.
+	Parallelism int
+}
+
+// Detect detects the drift of the resources, whose results are in the same order of the resources.
+// The provider must have been configured.
+func Detect(ctx context.Context, c tfclient.Client, resources []Resource, opt Option) []Result {
+	n := opt.Parallelism
+	if n <= 0 {
+		n = 1
+	}
+	results := make([]Result, len(resources))
+	sem := make(chan struct{}, n)
+	var wg sync.WaitGroup
+	for i, res := range resources {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			results[i] = DetectResource(ctx, c, res)
+		}()
+	}
+	wg.Wait()
+	return results
+}
+
+// DetectResource detects the drift of a resource. The stored state is upgraded in case the schema version changed,
+// then refreshed and compared to the upgraded state. The changes only to the computed attributes are ignored.
+func DetectResource(ctx context.Context, c tfclient.Client, res Resource) Result {
+	result := Result{Resource: res, Status: StatusError}
+
+	schema, diags := c.GetProviderSchema()
+	result.Diagnostics = append(result.Diagnostics, diags...)
+	if diags.HasErrors() {
+		return result
+	}
+	sch, ok := schema.ResourceTypes[res.Type]
+	if !ok {
+		result.Diagnostics = append(result.Diagnostics, typ.ErrorDiagnostics("no schema", fmt.Errorf("unknown resource type %q", res.Type))...)
+		return result
+	}
+
+	prior, diags := upgradeState(ctx, c, res, sch, schema.ResourceTypesCty[res.Type])
+	result.Diagnostics = append(result.Diagnostics, diags...)
+	if diags.HasErrors() {
+		return result
+	}
+	identity, diags := upgradeIdentity(ctx, c, res, sch)
+	result.Diagnostics = append(result.Diagnostics, diags...)
+	if diags.HasErrors() {
+		return result
+	}
+
+	resp, diags := c.ReadResource(ctx, typ.ReadResourceRequest{
+		TypeName:        res.Type,
+		PriorState:      prior,
+		Private:         res.Private,
+		CurrentIdentity: identity,
+	})
+	result.Diagnostics = append(result.Diagnostics, diags...)
+	if diags.HasErrors() {
+		return result
+	}
+	if resp.NewState == cty.NilVal || resp.NewState.IsNull() {
+		result.Status = StatusGone
+		return result
+	}
+
+	// The set and list elements of different lengths are diffed as a whole, the computed only attributes inside
+	// them are nulled beforehand, so that their changes don't make the elements differ.
+	for _, d := range objchange.ValueDiffs(withoutComputedOnly(sch.Block, prior), withoutComputedOnly(sch.Block, resp.NewState)) {
+		if computedOnly(sch.Block, d.Path) {
+			continue
+		}
+		result.Changes = append(result.Changes, Change{Path: d.Path, Before: d.Before, After: d.After})
+	}
+	result.Status = StatusInSync
+	if len(result.Changes) != 0 {
+		result.Status = StatusDrifted
+	}
+	return result
+}
+
+func upgradeState(ctx context.Context, c tfclient.Client, res Resource, sch tfjson.Schema, ty cty.Type) (cty.Value, typ.Diagnostics) {
+	if res.SchemaVersion == int64(sch.Version) && res.AttributesJSON != nil {
+		v, err := ctyjson.Unmarshal(res.AttributesJSON, ty)
+		if err != nil {
+			return cty.NilVal, typ.ErrorDiagnostics("decoding the state", err)
+		}
+		return v, nil
+	}
+	resp, diags := c.UpgradeResourceState(ctx, typ.UpgradeResourceStateRequest{
+		TypeName:        res.Type,
+		Version:         res.SchemaVersion,
+		RawStateJSON:    res.AttributesJSON,
+		RawStateFlatmap: res.AttributesFlat,
+	})
+	if diags.HasErrors() {
+		return cty.NilVal, diags
+	}
+	return resp.UpgradedState, diags
+}
+
+func upgradeIdentity(ctx context.Context, c tfclient.Client, res Resource, sch tfjson.Schema) (cty.Value, typ.Diagnostics) {
+	if res.IdentityJSON == nil || sch.Identity == nil {
+		return cty.NilVal, nil
+	}
+	if res.IdentitySchemaVersion == sch.IdentityVersion {
+		v, err := ctyjson.Unmarshal(res.IdentityJSON, configschema.SchemaNestedAttributeTypeImpliedType(sch.Identity))
+		if err != nil {
+			return cty.NilVal, typ.ErrorDiagnostics("decoding the identity", err)
+		}
+		return v, nil
+	}
+	resp, diags := c.UpgradeResourceIdentity(ctx, typ.UpgradeResourceIdentityRequest{
+		TypeName:        res.Type,
+		Version:         res.IdentitySchemaVersion,
+		RawIdentityJSON: res.IdentityJSON,
+	})
+	if diags.HasErrors() {
+		return cty.NilVal, diags
+	}
+	return resp.UpgradedIdentity, diags
+}
+
+// computedOnly tells whether the path is within a computed attribute that can't be configured.
+func computedOnly(block *tfjson.SchemaBlock, path cty.Path) bool {
+	for i := 1; i <= len(path); i++ {
+		attr := configschema.SchemaBlockAttributeByPath(block, path[:i])
+		if attr != nil && attr.Computed && !attr.Optional {
+			return true
+		}
+	}
+	return false
+}
+
+// withoutComputedOnly returns the value of the block with the computed only attributes being null.
+func withoutComputedOnly(block *tfjson.SchemaBlock, v cty.Value) cty.Value {
+	v, _ = cty.Transform(v, func(path cty.Path, v cty.Value) (cty.Value, error) {
+		if len(path) != 0 && computedOnly(block, path) {
+			return cty.NullVal(v.Type()).WithMarks(v.Marks()), nil
+		}
+		return v, nil
+	})
+	return v
+}
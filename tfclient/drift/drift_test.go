@@ -0,0 +1,225 @@
+package drift
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	tfjson "github.com/hashicorp/terraform-json"
+	"github.com/magodo/terraform-client-go/tfclient"
+	"github.com/magodo/terraform-client-go/tfclient/configschema"
+	"github.com/magodo/terraform-client-go/tfclient/typ"
+	"github.com/zclconf/go-cty/cty"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
+)
+
+// fakeClient is an in-memory provider of the "foo_thing" resource, whose schema version is 1.
+// The methods that are not used by the detection panic.
+type fakeClient struct {
+	tfclient.Client
+
+	remote   map[string]cty.Value
+	upgraded []int64
+}
+
+var thingBlock = &tfjson.SchemaBlock{
+	Attributes: map[string]*tfjson.SchemaAttribute{
+		"id":   {AttributeType: cty.String, Computed: true},
+		"name": {AttributeType: cty.String, Required: true},
+		"etag": {AttributeType: cty.String, Computed: true},
+		"tags": {AttributeType: cty.Map(cty.String), Optional: true},
+	},
+	NestedBlocks: map[string]*tfjson.SchemaBlockType{
+		"rule": {
+			NestingMode: tfjson.SchemaNestingModeSet,
+			Block: &tfjson.SchemaBlock{
+				Attributes: map[string]*tfjson.SchemaAttribute{
+					"port":   {AttributeType: cty.Number, Required: true},
+					"status": {AttributeType: cty.String, Computed: true},
+				},
+			},
+		},
+	},
+}
+
+var ruleType = cty.Object(map[string]cty.Type{"port": cty.Number, "status": cty.String})
+
+var thingType = configschema.SchemaBlockImpliedType(thingBlock)
+
+func (c *fakeClient) GetProviderSchema() (*typ.GetProviderSchemaResponse, typ.Diagnostics) {
+	return &typ.GetProviderSchemaResponse{
+		ResourceTypes:    map[string]tfjson.Schema{"foo_thing": {Version: 1, Block: thingBlock}},
+		ResourceTypesCty: map[string]cty.Type{"foo_thing": thingType},
+	}, nil
+}
+
+func (c *fakeClient) UpgradeResourceState(_ context.Context, req typ.UpgradeResourceStateRequest) (*typ.UpgradeResourceStateResponse, typ.Diagnostics) {
+	c.upgraded = append(c.upgraded, req.Version)
+	v, err := ctyjson.Unmarshal(req.RawStateJSON, thingType)
+	if err != nil {
+		return nil, typ.ErrorDiagnostics("upgrade", err)
+	}
+	return &typ.UpgradeResourceStateResponse{UpgradedState: v}, nil
+}
+
+func (c *fakeClient) ReadResource(_ context.Context, req typ.ReadResourceRequest) (*typ.ReadResourceResponse, typ.Diagnostics) {
+	state, ok := c.remote[req.PriorState.GetAttr("id").AsString()]
+	if !ok {
+		return &typ.ReadResourceResponse{NewState: cty.NullVal(thingType)}, nil
+	}
+	return &typ.ReadResourceResponse{NewState: state}, nil
+}
+
+func thing(id, name, etag string, tags map[string]cty.Value) cty.Value {
+	tagsVal := cty.NullVal(cty.Map(cty.String))
+	if tags != nil {
+		tagsVal = cty.MapVal(tags)
+	}
+	return cty.ObjectVal(map[string]cty.Value{
+		"id":   cty.StringVal(id),
+		"name": cty.StringVal(name),
+		"etag": cty.StringVal(etag),
+		"tags": tagsVal,
+		"rule": cty.NullVal(cty.Set(ruleType)),
+	})
+}
+
+// ruleThing returns the thing with the rules, each of which is a pair of the port and the status.
+func ruleThing(id string, rules ...any) cty.Value {
+	var l []cty.Value
+	for i := 0; i < len(rules); i += 2 {
+		l = append(l, cty.ObjectVal(map[string]cty.Value{
+			"port":   cty.NumberIntVal(int64(rules[i].(int))),
+			"status": cty.StringVal(rules[i+1].(string)),
+		}))
+	}
+	attrs := thing(id, "r", "e", nil).AsValueMap()
+	attrs["rule"] = cty.SetVal(l)
+	return cty.ObjectVal(attrs)
+}
+
+func TestLoadStateV4(t *testing.T) {
+	resources, err := LoadStateV4([]byte(`{
+  "version": 4,
+  "resources": [
+    {
+      "mode": "managed", "type": "foo_thing", "name": "a",
+      "provider": "provider[\"registry.terraform.io/hashicorp/foo\"]",
+      "instances": [
+        {"schema_version": 1, "attributes": {"id": "1"}, "identity_schema_version": 2, "identity": {"id": "1"}},
+        {"deposed": "00000001", "attributes": {"id": "0"}}
+      ]
+    },
+    {
+      "module": "module.m", "mode": "managed", "type": "foo_thing", "name": "b",
+      "provider": "provider[\"registry.terraform.io/hashicorp/foo\"].alias",
+      "instances": [
+        {"index_key": 0, "attributes_flat": {"id": "2"}},
+        {"index_key": "x", "attributes": {"id": "3"}, "identity": null}
+      ]
+    },
+    {
+      "mode": "data", "type": "foo_thing", "name": "c",
+      "provider": "provider[\"registry.terraform.io/hashicorp/foo\"]",
+      "instances": [{"attributes": {"id": "4"}}]
+    }
+  ]
+}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Resource{
+		{
+			Address:               "foo_thing.a",
+			Type:                  "foo_thing",
+			Provider:              `provider["registry.terraform.io/hashicorp/foo"]`,
+			SchemaVersion:         1,
+			AttributesJSON:        []byte(`{"id": "1"}`),
+			IdentitySchemaVersion: 2,
+			IdentityJSON:          []byte(`{"id": "1"}`),
+		},
+		{
+			Address:        "module.m.foo_thing.b[0]",
+			Type:           "foo_thing",
+			Provider:       `provider["registry.terraform.io/hashicorp/foo"].alias`,
+			AttributesFlat: map[string]string{"id": "2"},
+		},
+		{
+			Address:        `module.m.foo_thing.b["x"]`,
+			Type:           "foo_thing",
+			Provider:       `provider["registry.terraform.io/hashicorp/foo"].alias`,
+			AttributesJSON: []byte(`{"id": "3"}`),
+		},
+	}
+	if diff := cmp.Diff(want, resources); diff != "" {
+		t.Errorf("wrong resources\n%s", diff)
+	}
+	if got := resources[1].ProviderSource(); got != "registry.terraform.io/hashicorp/foo" {
+		t.Errorf("wrong provider source %q", got)
+	}
+
+	if _, err := LoadStateV4([]byte(`{"version": 3}`)); err == nil {
+		t.Errorf("expect an error for state version 3")
+	}
+}
+
+func TestDetect(t *testing.T) {
+	c := &fakeClient{
+		remote: map[string]cty.Value{
+			"1": thing("1", "a", "new", nil),
+			"2": thing("2", "changed", "e", map[string]cty.Value{"k": cty.StringVal("v2")}),
+			"4": thing("4", "d", "e", nil),
+			"5": ruleThing("5", 80, "up", 443, "down"),
+			"6": ruleThing("6", 80, "up", 8080, "up"),
+		},
+	}
+	resources := []Resource{
+		{Address: "foo_thing.insync", Type: "foo_thing", SchemaVersion: 1, AttributesJSON: []byte(`{"id":"1","name":"a","etag":"old","tags":null}`)},
+		{Address: "foo_thing.drifted", Type: "foo_thing", SchemaVersion: 1, AttributesJSON: []byte(`{"id":"2","name":"b","etag":"e","tags":{"k":"v1"}}`)},
+		{Address: "foo_thing.gone", Type: "foo_thing", SchemaVersion: 1, AttributesJSON: []byte(`{"id":"3","name":"c","etag":"e","tags":null}`)},
+		{Address: "foo_thing.upgraded", Type: "foo_thing", SchemaVersion: 0, AttributesJSON: []byte(`{"id":"4","name":"d","etag":"e","tags":null}`)},
+		{Address: "bar_thing.unknown", Type: "bar_thing"},
+		{Address: "foo_thing.rule_insync", Type: "foo_thing", SchemaVersion: 1, AttributesJSON: []byte(`{"id":"5","name":"r","etag":"e","rule":[{"port":80,"status":"down"},{"port":443,"status":"up"}]}`)},
+		{Address: "foo_thing.rule_drifted", Type: "foo_thing", SchemaVersion: 1, AttributesJSON: []byte(`{"id":"6","name":"r","etag":"e","rule":[{"port":80,"status":"down"}]}`)},
+	}
+	results := Detect(context.Background(), c, resources, Option{Parallelism: 2})
+
+	var status []Status
+	for _, result := range results {
+		status = append(status, result.Status)
+	}
+	if diff := cmp.Diff([]Status{StatusInSync, StatusDrifted, StatusGone, StatusInSync, StatusError, StatusInSync, StatusDrifted}, status); diff != "" {
+		t.Errorf("wrong status\n%s", diff)
+	}
+
+	var changes []string
+	for _, change := range results[1].Changes {
+		changes = append(changes, typ.FormatCtyPath(change.Path)+": "+change.Before.GoString()+" -> "+change.After.GoString())
+	}
+	want := []string{
+		`.name: cty.StringVal("b") -> cty.StringVal("changed")`,
+		`.tags["k"]: cty.StringVal("v1") -> cty.StringVal("v2")`,
+	}
+	if diff := cmp.Diff(want, changes); diff != "" {
+		t.Errorf("wrong changes\n%s", diff)
+	}
+
+	// The computed only attributes in the set elements are not compared.
+	changes = nil
+	for _, change := range results[6].Changes {
+		changes = append(changes, typ.FormatCtyPath(change.Path)+": "+change.Before.GoString()+" -> "+change.After.GoString())
+	}
+	want = []string{
+		`.rule: cty.SetVal([]cty.Value{cty.ObjectVal(map[string]cty.Value{"port":cty.NumberIntVal(80), "status":cty.NullVal(cty.String)})}) -> cty.SetVal([]cty.Value{cty.ObjectVal(map[string]cty.Value{"port":cty.NumberIntVal(8080), "status":cty.NullVal(cty.String)}), cty.ObjectVal(map[string]cty.Value{"port":cty.NumberIntVal(80), "status":cty.NullVal(cty.String)})})`,
+	}
+	if diff := cmp.Diff(want, changes); diff != "" {
+		t.Errorf("wrong changes\n%s", diff)
+	}
+
+	if diff := cmp.Diff([]int64{0}, c.upgraded); diff != "" {
+		t.Errorf("wrong upgraded versions\n%s", diff)
+	}
+	if !results[4].Diagnostics.HasErrors() {
+		t.Errorf("expect an error for the unknown resource type")
+	}
+}
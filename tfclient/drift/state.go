@@ -0,0 +1,117 @@
+package drift
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Resource is a managed resource instance loaded from the state.
+type Resource struct {
+	// Address is the absolute address of the resource instance, e.g. `module.a.foo_thing.b["x"]`.
+	Address string
+	Type    string
+
+	// Provider is the provider config address, e.g. `provider["registry.terraform.io/hashicorp/foo"].alias`.
+	Provider string
+
+	SchemaVersion  int64
+	AttributesJSON []byte
+	AttributesFlat map[string]string
+	Private        []byte
+
+	IdentitySchemaVersion int64
+	IdentityJSON          []byte
+}
+
+// ProviderSource returns the provider source address of the provider config address.
+func (r Resource) ProviderSource() string {
+	addr, ok := strings.CutPrefix(r.Provider, `provider["`)
+	if !ok {
+		return ""
+	}
+	addr, _, _ = strings.Cut(addr, `"]`)
+	return addr
+}
+
+type stateV4 struct {
+	Version   int          `json:"version"`
+	Resources []resourceV4 `json:"resources"`
+}
+
+type resourceV4 struct {
+	Module    string       `json:"module"`
+	Mode      string       `json:"mode"`
+	Type      string       `json:"type"`
+	Name      string       `json:"name"`
+	Provider  string       `json:"provider"`
+	Instances []instanceV4 `json:"instances"`
+}
+
+type instanceV4 struct {
+	IndexKey any    `json:"index_key"`
+	Deposed  string `json:"deposed"`
+
+	SchemaVersion  int64             `json:"schema_version"`
+	AttributesRaw  json.RawMessage   `json:"attributes"`
+	AttributesFlat map[string]string `json:"attributes_flat"`
+	PrivateRaw     []byte            `json:"private"`
+
+	IdentitySchemaVersion int64           `json:"identity_schema_version"`
+	IdentityRaw           json.RawMessage `json:"identity"`
+}
+
+// LoadStateV4 loads the current managed resource instances from the state file of format version 4, which is used
+// by Terraform since v0.12. The data sources and the deposed instances are skipped.
+func LoadStateV4(b []byte) ([]Resource, error) {
+	var state stateV4
+	if err := json.Unmarshal(b, &state); err != nil {
+		return nil, fmt.Errorf("decoding the state: %v", err)
+	}
+	if state.Version != 4 {
+		return nil, fmt.Errorf("unsupported state format version %d", state.Version)
+	}
+
+	var out []Resource
+	for _, rs := range state.Resources {
+		if rs.Mode != "managed" {
+			continue
+		}
+		addr := rs.Type + "." + rs.Name
+		if rs.Module != "" {
+			addr = rs.Module + "." + addr
+		}
+		for _, inst := range rs.Instances {
+			if inst.Deposed != "" {
+				continue
+			}
+			var key string
+			switch k := inst.IndexKey.(type) {
+			case nil:
+			case float64:
+				key = fmt.Sprintf("[%d]", int64(k))
+			case string:
+				key = fmt.Sprintf("[%q]", k)
+			default:
+				return nil, fmt.Errorf("invalid index key %v of %s", k, addr)
+			}
+			res := Resource{
+				Address:               addr + key,
+				Type:                  rs.Type,
+				Provider:              rs.Provider,
+				SchemaVersion:         inst.SchemaVersion,
+				AttributesFlat:        inst.AttributesFlat,
+				Private:               inst.PrivateRaw,
+				IdentitySchemaVersion: inst.IdentitySchemaVersion,
+			}
+			if len(inst.AttributesRaw) != 0 {
+				res.AttributesJSON = inst.AttributesRaw
+			}
+			if len(inst.IdentityRaw) != 0 && string(inst.IdentityRaw) != "null" {
+				res.IdentityJSON = inst.IdentityRaw
+			}
+			out = append(out, res)
+		}
+	}
+	return out, nil
+}
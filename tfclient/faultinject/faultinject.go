@@ -0,0 +1,135 @@
+// Package faultinject wraps the gRPC client of a provider to inject faults into the calls, for testing how the
+// consumers of the provider deal with providers that hang, crash, return malformed values or fail halfway
+// through a stream.
+//
+// The wrappers are hooked into the clients via the tfclient.Option:
+//
+//	inj := faultinject.New(faultinject.Rule{RPC: "ApplyResourceChange", TypeName: "foo_thing", Kill: true})
+//	client, err := tfclient.New(tfclient.Option{
+//		Cmd:          exec.Command("terraform-provider-foo"),
+//		WrapV5Client: inj.WrapV5,
+//		WrapV6Client: inj.WrapV6,
+//	})
+package faultinject
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/magodo/terraform-client-go/tfclient/typ"
+	"google.golang.org/grpc/status"
+)
+
+// Rule matches the calls and describes the faults injected to them. The faults are applied in the order of:
+// Latency, Kill, Err, and then the ones applied to the response.
+type Rule struct {
+	// RPC is the name of the matched RPC, e.g. "PlanResourceChange". Empty matches any RPC.
+	RPC string
+
+	// TypeName is the matched type name of the resource, data source, ephemeral resource, list resource or action,
+	// the target type name for MoveResourceState, or the function name for CallFunction. Empty matches any call.
+	TypeName string
+
+	// Skip skips the first n calls matched by this rule, which are left to the subsequent rules.
+	Skip int
+
+	// Times is the number of calls that this rule applies to, after the skipped ones. Zero means unlimited.
+	Times int
+
+	// Latency delays the call. The call fails with the status of the context if it is done in the meantime.
+	Latency time.Duration
+
+	// Kill kills the provider process before forwarding the call.
+	Kill bool
+
+	// Err is returned in place of forwarding the call, typically a gRPC status error created by status.Error.
+	Err error
+
+	// Diagnostics are appended to the diagnostics of the response. For streams, they are sent as an extra event
+	// at the end, or at the point of truncation. For CallFunction, they are reported as the function error.
+	Diagnostics typ.Diagnostics
+
+	// CorruptValue replaces the values (i.e. the states, results or resources) of the response with malformed msgpack.
+	CorruptValue bool
+
+	// Truncate ends the ListResource and InvokeAction streams after TruncateAfter events.
+	Truncate      bool
+	TruncateAfter int
+}
+
+// corruptMsgPack is never used by the msgpack specification, which fails any decoding.
+var corruptMsgPack = []byte{0xc1}
+
+// Injector applies the rules to the calls of the wrapped clients. For each call, only the first matched rule applies.
+type Injector struct {
+	mu    sync.Mutex
+	rules []Rule
+	hits  []int
+}
+
+func New(rules ...Rule) *Injector {
+	return &Injector{
+		rules: rules,
+		hits:  make([]int, len(rules)),
+	}
+}
+
+// Hits returns the number of calls matched by each rule, including the skipped ones.
+func (inj *Injector) Hits() []int {
+	inj.mu.Lock()
+	defer inj.mu.Unlock()
+	return append([]int(nil), inj.hits...)
+}
+
+func (inj *Injector) match(rpc, typeName string) *Rule {
+	inj.mu.Lock()
+	defer inj.mu.Unlock()
+	for i := range inj.rules {
+		rule := &inj.rules[i]
+		if rule.RPC != "" && rule.RPC != rpc {
+			continue
+		}
+		if rule.TypeName != "" && rule.TypeName != typeName {
+			continue
+		}
+		if rule.Times != 0 && inj.hits[i] >= rule.Skip+rule.Times {
+			continue
+		}
+		inj.hits[i]++
+		if inj.hits[i] <= rule.Skip {
+			continue
+		}
+		return rule
+	}
+	return nil
+}
+
+// intercept injects the faults of the matched rule to the call, where fault applies the response related ones.
+func intercept[Req, Resp any](ctx context.Context, inj *Injector, kill func(), rpc, typeName string, req Req, call func(context.Context, Req) (*Resp, error), fault func(*Resp, *Rule)) (*Resp, error) {
+	rule := inj.match(rpc, typeName)
+	if rule == nil {
+		return call(ctx, req)
+	}
+	if rule.Latency != 0 {
+		timer := time.NewTimer(rule.Latency)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, status.FromContextError(ctx.Err()).Err()
+		case <-timer.C:
+		}
+	}
+	if rule.Kill && kill != nil {
+		kill()
+	}
+	if rule.Err != nil {
+		return nil, rule.Err
+	}
+	resp, err := call(ctx, req)
+	if err != nil || resp == nil {
+		return resp, err
+	}
+	fault(resp, rule)
+	return resp, nil
+}
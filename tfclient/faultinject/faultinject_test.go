@@ -0,0 +1,210 @@
+package faultinject
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov5"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/magodo/terraform-client-go/tfclient/tfprotov5/tf5client"
+	"github.com/magodo/terraform-client-go/tfclient/typ"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/msgpack"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	thingType    = cty.Object(map[string]cty.Type{"id": cty.String})
+	identityType = cty.Object(map[string]cty.Type{"id": cty.String})
+)
+
+// fakeV5Client implements the RPCs used by the tests, the others panic.
+type fakeV5Client struct {
+	tf5client.TFProtoV5Client
+}
+
+func (fakeV5Client) GetProviderSchema(context.Context, *tfprotov5.GetProviderSchemaRequest) (*tfprotov5.GetProviderSchemaResponse, error) {
+	schema := &tfprotov5.Schema{
+		Block: &tfprotov5.SchemaBlock{
+			Attributes: []*tfprotov5.SchemaAttribute{
+				{Name: "id", Type: tftypes.String, Optional: true},
+			},
+		},
+	}
+	return &tfprotov5.GetProviderSchemaResponse{
+		ResourceSchemas: map[string]*tfprotov5.Schema{"foo_thing": schema},
+		ListResourceSchemas: map[string]*tfprotov5.Schema{
+			"foo_thing": {Block: &tfprotov5.SchemaBlock{}},
+		},
+	}, nil
+}
+
+func (fakeV5Client) GetResourceIdentitySchemas(context.Context, *tfprotov5.GetResourceIdentitySchemasRequest) (*tfprotov5.GetResourceIdentitySchemasResponse, error) {
+	return &tfprotov5.GetResourceIdentitySchemasResponse{
+		IdentitySchemas: map[string]*tfprotov5.ResourceIdentitySchema{
+			"foo_thing": {
+				IdentityAttributes: []*tfprotov5.ResourceIdentitySchemaAttribute{
+					{Name: "id", Type: tftypes.String, RequiredForImport: true},
+				},
+			},
+		},
+	}, nil
+}
+
+func (fakeV5Client) PlanResourceChange(_ context.Context, req *tfprotov5.PlanResourceChangeRequest) (*tfprotov5.PlanResourceChangeResponse, error) {
+	return &tfprotov5.PlanResourceChangeResponse{PlannedState: req.ProposedNewState}, nil
+}
+
+func (fakeV5Client) ListResource(context.Context, *tfprotov5.ListResourceRequest) (*tfprotov5.ListResourceServerStream, error) {
+	return &tfprotov5.ListResourceServerStream{
+		Results: func(yield func(tfprotov5.ListResourceResult) bool) {
+			for _, id := range []string{"a", "b", "c"} {
+				mp, err := msgpack.Marshal(cty.ObjectVal(map[string]cty.Value{"id": cty.StringVal(id)}), identityType)
+				if err != nil {
+					panic(err)
+				}
+				if !yield(tfprotov5.ListResourceResult{
+					DisplayName: id,
+					Identity:    &tfprotov5.ResourceIdentityData{IdentityData: &tfprotov5.DynamicValue{MsgPack: mp}},
+				}) {
+					return
+				}
+			}
+		},
+	}, nil
+}
+
+func newClient(t *testing.T, inj *Injector, kill func()) *tf5client.Client {
+	t.Helper()
+	c, err := tf5client.New(nil, inj.WrapV5(fakeV5Client{}, kill), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func plan(ctx context.Context, c *tf5client.Client) (*typ.PlanResourceChangeResponse, typ.Diagnostics) {
+	config := cty.ObjectVal(map[string]cty.Value{"id": cty.StringVal("a")})
+	return c.PlanResourceChange(ctx, typ.PlanResourceChangeRequest{
+		TypeName:         "foo_thing",
+		PriorState:       cty.NullVal(thingType),
+		ProposedNewState: config,
+		Config:           config,
+	})
+}
+
+func TestMatch(t *testing.T) {
+	inj := New(
+		Rule{RPC: "PlanResourceChange", TypeName: "foo_thing", Skip: 1, Times: 2},
+		Rule{RPC: "PlanResourceChange"},
+	)
+	var got []int
+	for _, typeName := range []string{"foo_thing", "foo_thing", "bar_thing", "foo_thing", "foo_thing"} {
+		rule := inj.match("PlanResourceChange", typeName)
+		for i := range inj.rules {
+			if rule == &inj.rules[i] {
+				got = append(got, i)
+			}
+		}
+	}
+	if diff := cmp.Diff([]int{1, 0, 1, 0, 1}, got); diff != "" {
+		t.Errorf("wrong matched rules\n%s", diff)
+	}
+	if diff := cmp.Diff([]int{3, 3}, inj.Hits()); diff != "" {
+		t.Errorf("wrong hits\n%s", diff)
+	}
+	if rule := inj.match("ReadResource", "foo_thing"); rule != nil {
+		t.Errorf("unexpected match of %#v", rule)
+	}
+}
+
+func TestV5PlanResourceChange(t *testing.T) {
+	cases := []struct {
+		name     string
+		rule     Rule
+		timeout  time.Duration
+		killed   bool
+		contains string
+	}{
+		{
+			name:     "status error",
+			rule:     Rule{Err: status.Error(codes.Unavailable, "injected")},
+			contains: "Provider returned RPC error Unavailable: injected.",
+		},
+		{
+			name:     "latency",
+			rule:     Rule{Latency: time.Minute},
+			timeout:  10 * time.Millisecond,
+			contains: "Provider returned RPC error DeadlineExceeded",
+		},
+		{
+			name:     "diagnostics",
+			rule:     Rule{Diagnostics: typ.Diagnostics{{Severity: typ.Error, Summary: "injected", Attribute: cty.GetAttrPath("id")}}},
+			contains: "injected",
+		},
+		{
+			name:     "corrupt value",
+			rule:     Rule{CorruptValue: true},
+			contains: "decode dynamic value",
+		},
+		{
+			name:   "kill",
+			rule:   Rule{Kill: true},
+			killed: true,
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.rule.RPC = "PlanResourceChange"
+			killed := false
+			c := newClient(t, New(tt.rule), func() { killed = true })
+
+			ctx := context.Background()
+			if tt.timeout != 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, tt.timeout)
+				defer cancel()
+			}
+			_, diags := plan(ctx, c)
+			if killed != tt.killed {
+				t.Errorf("expect killed to be %t", tt.killed)
+			}
+			if tt.contains == "" {
+				if diags.HasErrors() {
+					t.Fatalf("unexpected error: %v", diags.Err())
+				}
+				return
+			}
+			if !diags.HasErrors() {
+				t.Fatal("expect an error")
+			}
+			if err := diags.Err().Error(); !strings.Contains(err, tt.contains) {
+				t.Errorf("expect the error %q to contain %q", err, tt.contains)
+			}
+		})
+	}
+}
+
+func TestV5ListResource(t *testing.T) {
+	c := newClient(t, New(Rule{
+		RPC:           "ListResource",
+		Truncate:      true,
+		TruncateAfter: 2,
+		Diagnostics:   typ.Diagnostics{{Severity: typ.Error, Summary: "connection reset"}},
+	}), nil)
+	resp, diags := c.ListResource(context.Background(), typ.ListResourceRequest{
+		TypeName: "foo_thing",
+		Config:   cty.ObjectVal(map[string]cty.Value{"config": cty.EmptyObjectVal}),
+		Limit:    10,
+	})
+	if diff := cmp.Diff(typ.Diagnostics{{Severity: typ.Error, Summary: "connection reset"}}, diags); diff != "" {
+		t.Errorf("wrong diagnostics\n%s", diff)
+	}
+	if n := resp.Result.GetAttr("data").LengthInt(); n != 2 {
+		t.Errorf("expect 2 results, got %d", n)
+	}
+}
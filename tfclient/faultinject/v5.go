@@ -0,0 +1,275 @@
+package faultinject
+
+import (
+	"context"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-go/tfprotov5"
+	"github.com/magodo/terraform-client-go/tfclient/tfprotov5/convert"
+	"github.com/magodo/terraform-client-go/tfclient/tfprotov5/tf5client"
+)
+
+type v5Client struct {
+	client tf5client.TFProtoV5Client
+	inj    *Injector
+	kill   func()
+}
+
+var _ tf5client.TFProtoV5Client = &v5Client{}
+
+// WrapV5 wraps the v5 client to inject faults to its calls, which has the signature of tfclient.Option.WrapV5Client.
+// The kill function is called for the rules that kill the provider process, it can be nil.
+func (inj *Injector) WrapV5(client tf5client.TFProtoV5Client, kill func()) tf5client.TFProtoV5Client {
+	return &v5Client{client: client, inj: inj, kill: kill}
+}
+
+func corruptV5() *tfprotov5.DynamicValue {
+	return &tfprotov5.DynamicValue{MsgPack: corruptMsgPack}
+}
+
+func (c *v5Client) GetMetadata(ctx context.Context, req *tfprotov5.GetMetadataRequest) (*tfprotov5.GetMetadataResponse, error) {
+	return intercept(ctx, c.inj, c.kill, "GetMetadata", "", req, c.client.GetMetadata, func(resp *tfprotov5.GetMetadataResponse, rule *Rule) {
+		resp.Diagnostics = append(resp.Diagnostics, convert.EncodeDiagnostics(rule.Diagnostics)...)
+	})
+}
+
+func (c *v5Client) GetProviderSchema(ctx context.Context, req *tfprotov5.GetProviderSchemaRequest) (*tfprotov5.GetProviderSchemaResponse, error) {
+	return intercept(ctx, c.inj, c.kill, "GetProviderSchema", "", req, c.client.GetProviderSchema, func(resp *tfprotov5.GetProviderSchemaResponse, rule *Rule) {
+		resp.Diagnostics = append(resp.Diagnostics, convert.EncodeDiagnostics(rule.Diagnostics)...)
+	})
+}
+
+func (c *v5Client) GetResourceIdentitySchemas(ctx context.Context, req *tfprotov5.GetResourceIdentitySchemasRequest) (*tfprotov5.GetResourceIdentitySchemasResponse, error) {
+	return intercept(ctx, c.inj, c.kill, "GetResourceIdentitySchemas", "", req, c.client.GetResourceIdentitySchemas, func(resp *tfprotov5.GetResourceIdentitySchemasResponse, rule *Rule) {
+		resp.Diagnostics = append(resp.Diagnostics, convert.EncodeDiagnostics(rule.Diagnostics)...)
+	})
+}
+
+func (c *v5Client) PrepareProviderConfig(ctx context.Context, req *tfprotov5.PrepareProviderConfigRequest) (*tfprotov5.PrepareProviderConfigResponse, error) {
+	return intercept(ctx, c.inj, c.kill, "PrepareProviderConfig", "", req, c.client.PrepareProviderConfig, func(resp *tfprotov5.PrepareProviderConfigResponse, rule *Rule) {
+		resp.Diagnostics = append(resp.Diagnostics, convert.EncodeDiagnostics(rule.Diagnostics)...)
+		if rule.CorruptValue {
+			resp.PreparedConfig = corruptV5()
+		}
+	})
+}
+
+func (c *v5Client) ConfigureProvider(ctx context.Context, req *tfprotov5.ConfigureProviderRequest) (*tfprotov5.ConfigureProviderResponse, error) {
+	return intercept(ctx, c.inj, c.kill, "ConfigureProvider", "", req, c.client.ConfigureProvider, func(resp *tfprotov5.ConfigureProviderResponse, rule *Rule) {
+		resp.Diagnostics = append(resp.Diagnostics, convert.EncodeDiagnostics(rule.Diagnostics)...)
+	})
+}
+
+func (c *v5Client) StopProvider(ctx context.Context, req *tfprotov5.StopProviderRequest) (*tfprotov5.StopProviderResponse, error) {
+	return intercept(ctx, c.inj, c.kill, "StopProvider", "", req, c.client.StopProvider, func(resp *tfprotov5.StopProviderResponse, rule *Rule) {
+		if rule.Diagnostics.HasErrors() {
+			resp.Error = rule.Diagnostics.Err().Error()
+		}
+	})
+}
+
+func (c *v5Client) ValidateResourceTypeConfig(ctx context.Context, req *tfprotov5.ValidateResourceTypeConfigRequest) (*tfprotov5.ValidateResourceTypeConfigResponse, error) {
+	return intercept(ctx, c.inj, c.kill, "ValidateResourceTypeConfig", req.TypeName, req, c.client.ValidateResourceTypeConfig, func(resp *tfprotov5.ValidateResourceTypeConfigResponse, rule *Rule) {
+		resp.Diagnostics = append(resp.Diagnostics, convert.EncodeDiagnostics(rule.Diagnostics)...)
+	})
+}
+
+func (c *v5Client) UpgradeResourceState(ctx context.Context, req *tfprotov5.UpgradeResourceStateRequest) (*tfprotov5.UpgradeResourceStateResponse, error) {
+	return intercept(ctx, c.inj, c.kill, "UpgradeResourceState", req.TypeName, req, c.client.UpgradeResourceState, func(resp *tfprotov5.UpgradeResourceStateResponse, rule *Rule) {
+		resp.Diagnostics = append(resp.Diagnostics, convert.EncodeDiagnostics(rule.Diagnostics)...)
+		if rule.CorruptValue {
+			resp.UpgradedState = corruptV5()
+		}
+	})
+}
+
+func (c *v5Client) UpgradeResourceIdentity(ctx context.Context, req *tfprotov5.UpgradeResourceIdentityRequest) (*tfprotov5.UpgradeResourceIdentityResponse, error) {
+	return intercept(ctx, c.inj, c.kill, "UpgradeResourceIdentity", req.TypeName, req, c.client.UpgradeResourceIdentity, func(resp *tfprotov5.UpgradeResourceIdentityResponse, rule *Rule) {
+		resp.Diagnostics = append(resp.Diagnostics, convert.EncodeDiagnostics(rule.Diagnostics)...)
+		if rule.CorruptValue {
+			resp.UpgradedIdentity = &tfprotov5.ResourceIdentityData{IdentityData: corruptV5()}
+		}
+	})
+}
+
+func (c *v5Client) ReadResource(ctx context.Context, req *tfprotov5.ReadResourceRequest) (*tfprotov5.ReadResourceResponse, error) {
+	return intercept(ctx, c.inj, c.kill, "ReadResource", req.TypeName, req, c.client.ReadResource, func(resp *tfprotov5.ReadResourceResponse, rule *Rule) {
+		resp.Diagnostics = append(resp.Diagnostics, convert.EncodeDiagnostics(rule.Diagnostics)...)
+		if rule.CorruptValue {
+			resp.NewState = corruptV5()
+		}
+	})
+}
+
+func (c *v5Client) PlanResourceChange(ctx context.Context, req *tfprotov5.PlanResourceChangeRequest) (*tfprotov5.PlanResourceChangeResponse, error) {
+	return intercept(ctx, c.inj, c.kill, "PlanResourceChange", req.TypeName, req, c.client.PlanResourceChange, func(resp *tfprotov5.PlanResourceChangeResponse, rule *Rule) {
+		resp.Diagnostics = append(resp.Diagnostics, convert.EncodeDiagnostics(rule.Diagnostics)...)
+		if rule.CorruptValue {
+			resp.PlannedState = corruptV5()
+		}
+	})
+}
+
+func (c *v5Client) ApplyResourceChange(ctx context.Context, req *tfprotov5.ApplyResourceChangeRequest) (*tfprotov5.ApplyResourceChangeResponse, error) {
+	return intercept(ctx, c.inj, c.kill, "ApplyResourceChange", req.TypeName, req, c.client.ApplyResourceChange, func(resp *tfprotov5.ApplyResourceChangeResponse, rule *Rule) {
+		resp.Diagnostics = append(resp.Diagnostics, convert.EncodeDiagnostics(rule.Diagnostics)...)
+		if rule.CorruptValue {
+			resp.NewState = corruptV5()
+		}
+	})
+}
+
+func (c *v5Client) ImportResourceState(ctx context.Context, req *tfprotov5.ImportResourceStateRequest) (*tfprotov5.ImportResourceStateResponse, error) {
+	return intercept(ctx, c.inj, c.kill, "ImportResourceState", req.TypeName, req, c.client.ImportResourceState, func(resp *tfprotov5.ImportResourceStateResponse, rule *Rule) {
+		resp.Diagnostics = append(resp.Diagnostics, convert.EncodeDiagnostics(rule.Diagnostics)...)
+		if rule.CorruptValue {
+			for _, res := range resp.ImportedResources {
+				res.State = corruptV5()
+			}
+		}
+	})
+}
+
+func (c *v5Client) MoveResourceState(ctx context.Context, req *tfprotov5.MoveResourceStateRequest) (*tfprotov5.MoveResourceStateResponse, error) {
+	return intercept(ctx, c.inj, c.kill, "MoveResourceState", req.TargetTypeName, req, c.client.MoveResourceState, func(resp *tfprotov5.MoveResourceStateResponse, rule *Rule) {
+		resp.Diagnostics = append(resp.Diagnostics, convert.EncodeDiagnostics(rule.Diagnostics)...)
+		if rule.CorruptValue {
+			resp.TargetState = corruptV5()
+		}
+	})
+}
+
+func (c *v5Client) ValidateDataSourceConfig(ctx context.Context, req *tfprotov5.ValidateDataSourceConfigRequest) (*tfprotov5.ValidateDataSourceConfigResponse, error) {
+	return intercept(ctx, c.inj, c.kill, "ValidateDataSourceConfig", req.TypeName, req, c.client.ValidateDataSourceConfig, func(resp *tfprotov5.ValidateDataSourceConfigResponse, rule *Rule) {
+		resp.Diagnostics = append(resp.Diagnostics, convert.EncodeDiagnostics(rule.Diagnostics)...)
+	})
+}
+
+func (c *v5Client) ReadDataSource(ctx context.Context, req *tfprotov5.ReadDataSourceRequest) (*tfprotov5.ReadDataSourceResponse, error) {
+	return intercept(ctx, c.inj, c.kill, "ReadDataSource", req.TypeName, req, c.client.ReadDataSource, func(resp *tfprotov5.ReadDataSourceResponse, rule *Rule) {
+		resp.Diagnostics = append(resp.Diagnostics, convert.EncodeDiagnostics(rule.Diagnostics)...)
+		if rule.CorruptValue {
+			resp.State = corruptV5()
+		}
+	})
+}
+
+func (c *v5Client) CallFunction(ctx context.Context, req *tfprotov5.CallFunctionRequest) (*tfprotov5.CallFunctionResponse, error) {
+	return intercept(ctx, c.inj, c.kill, "CallFunction", req.Name, req, c.client.CallFunction, func(resp *tfprotov5.CallFunctionResponse, rule *Rule) {
+		if len(rule.Diagnostics) != 0 {
+			var msgs []string
+			if resp.Error != nil {
+				msgs = append(msgs, resp.Error.Text)
+			}
+			for _, diag := range rule.Diagnostics {
+				msgs = append(msgs, diag.Summary)
+			}
+			resp.Error = &tfprotov5.FunctionError{Text: strings.Join(msgs, "; ")}
+		}
+		if rule.CorruptValue {
+			resp.Result = corruptV5()
+		}
+	})
+}
+
+func (c *v5Client) GetFunctions(ctx context.Context, req *tfprotov5.GetFunctionsRequest) (*tfprotov5.GetFunctionsResponse, error) {
+	return intercept(ctx, c.inj, c.kill, "GetFunctions", "", req, c.client.GetFunctions, func(resp *tfprotov5.GetFunctionsResponse, rule *Rule) {
+		resp.Diagnostics = append(resp.Diagnostics, convert.EncodeDiagnostics(rule.Diagnostics)...)
+	})
+}
+
+func (c *v5Client) ValidateEphemeralResourceConfig(ctx context.Context, req *tfprotov5.ValidateEphemeralResourceConfigRequest) (*tfprotov5.ValidateEphemeralResourceConfigResponse, error) {
+	return intercept(ctx, c.inj, c.kill, "ValidateEphemeralResourceConfig", req.TypeName, req, c.client.ValidateEphemeralResourceConfig, func(resp *tfprotov5.ValidateEphemeralResourceConfigResponse, rule *Rule) {
+		resp.Diagnostics = append(resp.Diagnostics, convert.EncodeDiagnostics(rule.Diagnostics)...)
+	})
+}
+
+func (c *v5Client) OpenEphemeralResource(ctx context.Context, req *tfprotov5.OpenEphemeralResourceRequest) (*tfprotov5.OpenEphemeralResourceResponse, error) {
+	return intercept(ctx, c.inj, c.kill, "OpenEphemeralResource", req.TypeName, req, c.client.OpenEphemeralResource, func(resp *tfprotov5.OpenEphemeralResourceResponse, rule *Rule) {
+		resp.Diagnostics = append(resp.Diagnostics, convert.EncodeDiagnostics(rule.Diagnostics)...)
+		if rule.CorruptValue {
+			resp.Result = corruptV5()
+		}
+	})
+}
+
+func (c *v5Client) RenewEphemeralResource(ctx context.Context, req *tfprotov5.RenewEphemeralResourceRequest) (*tfprotov5.RenewEphemeralResourceResponse, error) {
+	return intercept(ctx, c.inj, c.kill, "RenewEphemeralResource", req.TypeName, req, c.client.RenewEphemeralResource, func(resp *tfprotov5.RenewEphemeralResourceResponse, rule *Rule) {
+		resp.Diagnostics = append(resp.Diagnostics, convert.EncodeDiagnostics(rule.Diagnostics)...)
+	})
+}
+
+func (c *v5Client) CloseEphemeralResource(ctx context.Context, req *tfprotov5.CloseEphemeralResourceRequest) (*tfprotov5.CloseEphemeralResourceResponse, error) {
+	return intercept(ctx, c.inj, c.kill, "CloseEphemeralResource", req.TypeName, req, c.client.CloseEphemeralResource, func(resp *tfprotov5.CloseEphemeralResourceResponse, rule *Rule) {
+		resp.Diagnostics = append(resp.Diagnostics, convert.EncodeDiagnostics(rule.Diagnostics)...)
+	})
+}
+
+func (c *v5Client) ValidateListResourceConfig(ctx context.Context, req *tfprotov5.ValidateListResourceConfigRequest) (*tfprotov5.ValidateListResourceConfigResponse, error) {
+	return intercept(ctx, c.inj, c.kill, "ValidateListResourceConfig", req.TypeName, req, c.client.ValidateListResourceConfig, func(resp *tfprotov5.ValidateListResourceConfigResponse, rule *Rule) {
+		resp.Diagnostics = append(resp.Diagnostics, convert.EncodeDiagnostics(rule.Diagnostics)...)
+	})
+}
+
+func (c *v5Client) ListResource(ctx context.Context, req *tfprotov5.ListResourceRequest) (*tfprotov5.ListResourceServerStream, error) {
+	return intercept(ctx, c.inj, c.kill, "ListResource", req.TypeName, req, c.client.ListResource, func(resp *tfprotov5.ListResourceServerStream, rule *Rule) {
+		results := resp.Results
+		resp.Results = func(yield func(tfprotov5.ListResourceResult) bool) {
+			if results != nil {
+				n := 0
+				for res := range results {
+					if rule.Truncate && n >= rule.TruncateAfter {
+						break
+					}
+					n++
+					if rule.CorruptValue {
+						res.Resource = corruptV5()
+					}
+					if !yield(res) {
+						return
+					}
+				}
+			}
+			if len(rule.Diagnostics) != 0 {
+				yield(tfprotov5.ListResourceResult{Diagnostics: convert.EncodeDiagnostics(rule.Diagnostics)})
+			}
+		}
+	})
+}
+
+func (c *v5Client) ValidateActionConfig(ctx context.Context, req *tfprotov5.ValidateActionConfigRequest) (*tfprotov5.ValidateActionConfigResponse, error) {
+	return intercept(ctx, c.inj, c.kill, "ValidateActionConfig", req.ActionType, req, c.client.ValidateActionConfig, func(resp *tfprotov5.ValidateActionConfigResponse, rule *Rule) {
+		resp.Diagnostics = append(resp.Diagnostics, convert.EncodeDiagnostics(rule.Diagnostics)...)
+	})
+}
+
+func (c *v5Client) PlanAction(ctx context.Context, req *tfprotov5.PlanActionRequest) (*tfprotov5.PlanActionResponse, error) {
+	return intercept(ctx, c.inj, c.kill, "PlanAction", req.ActionType, req, c.client.PlanAction, func(resp *tfprotov5.PlanActionResponse, rule *Rule) {
+		resp.Diagnostics = append(resp.Diagnostics, convert.EncodeDiagnostics(rule.Diagnostics)...)
+	})
+}
+
+func (c *v5Client) InvokeAction(ctx context.Context, req *tfprotov5.InvokeActionRequest) (*tfprotov5.InvokeActionServerStream, error) {
+	return intercept(ctx, c.inj, c.kill, "InvokeAction", req.ActionType, req, c.client.InvokeAction, func(resp *tfprotov5.InvokeActionServerStream, rule *Rule) {
+		events := resp.Events
+		resp.Events = func(yield func(tfprotov5.InvokeActionEvent) bool) {
+			if events != nil {
+				n := 0
+				for ev := range events {
+					if rule.Truncate && n >= rule.TruncateAfter {
+						break
+					}
+					n++
+					if !yield(ev) {
+						return
+					}
+				}
+			}
+			if len(rule.Diagnostics) != 0 {
+				yield(tfprotov5.InvokeActionEvent{
+					Type: &tfprotov5.CompletedInvokeActionEventType{Diagnostics: convert.EncodeDiagnostics(rule.Diagnostics)},
+				})
+			}
+		}
+	})
+}
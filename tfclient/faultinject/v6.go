@@ -0,0 +1,275 @@
+package faultinject
+
+import (
+	"context"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/magodo/terraform-client-go/tfclient/tfprotov6/convert"
+	"github.com/magodo/terraform-client-go/tfclient/tfprotov6/tf6client"
+)
+
+type v6Client struct {
+	client tf6client.TFProtoV6Client
+	inj    *Injector
+	kill   func()
+}
+
+var _ tf6client.TFProtoV6Client = &v6Client{}
+
+// WrapV6 wraps the v6 client to inject faults to its calls, which has the signature of tfclient.Option.WrapV6Client.
+// The kill function is called for the rules that kill the provider process, it can be nil.
+func (inj *Injector) WrapV6(client tf6client.TFProtoV6Client, kill func()) tf6client.TFProtoV6Client {
+	return &v6Client{client: client, inj: inj, kill: kill}
+}
+
+func corruptV6() *tfprotov6.DynamicValue {
+	return &tfprotov6.DynamicValue{MsgPack: corruptMsgPack}
+}
+
+func (c *v6Client) GetMetadata(ctx context.Context, req *tfprotov6.GetMetadataRequest) (*tfprotov6.GetMetadataResponse, error) {
+	return intercept(ctx, c.inj, c.kill, "GetMetadata", "", req, c.client.GetMetadata, func(resp *tfprotov6.GetMetadataResponse, rule *Rule) {
+		resp.Diagnostics = append(resp.Diagnostics, convert.EncodeDiagnostics(rule.Diagnostics)...)
+	})
+}
+
+func (c *v6Client) GetProviderSchema(ctx context.Context, req *tfprotov6.GetProviderSchemaRequest) (*tfprotov6.GetProviderSchemaResponse, error) {
+	return intercept(ctx, c.inj, c.kill, "GetProviderSchema", "", req, c.client.GetProviderSchema, func(resp *tfprotov6.GetProviderSchemaResponse, rule *Rule) {
+		resp.Diagnostics = append(resp.Diagnostics, convert.EncodeDiagnostics(rule.Diagnostics)...)
+	})
+}
+
+func (c *v6Client) GetResourceIdentitySchemas(ctx context.Context, req *tfprotov6.GetResourceIdentitySchemasRequest) (*tfprotov6.GetResourceIdentitySchemasResponse, error) {
+	return intercept(ctx, c.inj, c.kill, "GetResourceIdentitySchemas", "", req, c.client.GetResourceIdentitySchemas, func(resp *tfprotov6.GetResourceIdentitySchemasResponse, rule *Rule) {
+		resp.Diagnostics = append(resp.Diagnostics, convert.EncodeDiagnostics(rule.Diagnostics)...)
+	})
+}
+
+func (c *v6Client) ValidateProviderConfig(ctx context.Context, req *tfprotov6.ValidateProviderConfigRequest) (*tfprotov6.ValidateProviderConfigResponse, error) {
+	return intercept(ctx, c.inj, c.kill, "ValidateProviderConfig", "", req, c.client.ValidateProviderConfig, func(resp *tfprotov6.ValidateProviderConfigResponse, rule *Rule) {
+		resp.Diagnostics = append(resp.Diagnostics, convert.EncodeDiagnostics(rule.Diagnostics)...)
+		if rule.CorruptValue {
+			resp.PreparedConfig = corruptV6()
+		}
+	})
+}
+
+func (c *v6Client) ConfigureProvider(ctx context.Context, req *tfprotov6.ConfigureProviderRequest) (*tfprotov6.ConfigureProviderResponse, error) {
+	return intercept(ctx, c.inj, c.kill, "ConfigureProvider", "", req, c.client.ConfigureProvider, func(resp *tfprotov6.ConfigureProviderResponse, rule *Rule) {
+		resp.Diagnostics = append(resp.Diagnostics, convert.EncodeDiagnostics(rule.Diagnostics)...)
+	})
+}
+
+func (c *v6Client) StopProvider(ctx context.Context, req *tfprotov6.StopProviderRequest) (*tfprotov6.StopProviderResponse, error) {
+	return intercept(ctx, c.inj, c.kill, "StopProvider", "", req, c.client.StopProvider, func(resp *tfprotov6.StopProviderResponse, rule *Rule) {
+		if rule.Diagnostics.HasErrors() {
+			resp.Error = rule.Diagnostics.Err().Error()
+		}
+	})
+}
+
+func (c *v6Client) ValidateResourceConfig(ctx context.Context, req *tfprotov6.ValidateResourceConfigRequest) (*tfprotov6.ValidateResourceConfigResponse, error) {
+	return intercept(ctx, c.inj, c.kill, "ValidateResourceConfig", req.TypeName, req, c.client.ValidateResourceConfig, func(resp *tfprotov6.ValidateResourceConfigResponse, rule *Rule) {
+		resp.Diagnostics = append(resp.Diagnostics, convert.EncodeDiagnostics(rule.Diagnostics)...)
+	})
+}
+
+func (c *v6Client) UpgradeResourceState(ctx context.Context, req *tfprotov6.UpgradeResourceStateRequest) (*tfprotov6.UpgradeResourceStateResponse, error) {
+	return intercept(ctx, c.inj, c.kill, "UpgradeResourceState", req.TypeName, req, c.client.UpgradeResourceState, func(resp *tfprotov6.UpgradeResourceStateResponse, rule *Rule) {
+		resp.Diagnostics = append(resp.Diagnostics, convert.EncodeDiagnostics(rule.Diagnostics)...)
+		if rule.CorruptValue {
+			resp.UpgradedState = corruptV6()
+		}
+	})
+}
+
+func (c *v6Client) UpgradeResourceIdentity(ctx context.Context, req *tfprotov6.UpgradeResourceIdentityRequest) (*tfprotov6.UpgradeResourceIdentityResponse, error) {
+	return intercept(ctx, c.inj, c.kill, "UpgradeResourceIdentity", req.TypeName, req, c.client.UpgradeResourceIdentity, func(resp *tfprotov6.UpgradeResourceIdentityResponse, rule *Rule) {
+		resp.Diagnostics = append(resp.Diagnostics, convert.EncodeDiagnostics(rule.Diagnostics)...)
+		if rule.CorruptValue {
+			resp.UpgradedIdentity = &tfprotov6.ResourceIdentityData{IdentityData: corruptV6()}
+		}
+	})
+}
+
+func (c *v6Client) ReadResource(ctx context.Context, req *tfprotov6.ReadResourceRequest) (*tfprotov6.ReadResourceResponse, error) {
+	return intercept(ctx, c.inj, c.kill, "ReadResource", req.TypeName, req, c.client.ReadResource, func(resp *tfprotov6.ReadResourceResponse, rule *Rule) {
+		resp.Diagnostics = append(resp.Diagnostics, convert.EncodeDiagnostics(rule.Diagnostics)...)
+		if rule.CorruptValue {
+			resp.NewState = corruptV6()
+		}
+	})
+}
+
+func (c *v6Client) PlanResourceChange(ctx context.Context, req *tfprotov6.PlanResourceChangeRequest) (*tfprotov6.PlanResourceChangeResponse, error) {
+	return intercept(ctx, c.inj, c.kill, "PlanResourceChange", req.TypeName, req, c.client.PlanResourceChange, func(resp *tfprotov6.PlanResourceChangeResponse, rule *Rule) {
+		resp.Diagnostics = append(resp.Diagnostics, convert.EncodeDiagnostics(rule.Diagnostics)...)
+		if rule.CorruptValue {
+			resp.PlannedState = corruptV6()
+		}
+	})
+}
+
+func (c *v6Client) ApplyResourceChange(ctx context.Context, req *tfprotov6.ApplyResourceChangeRequest) (*tfprotov6.ApplyResourceChangeResponse, error) {
+	return intercept(ctx, c.inj, c.kill, "ApplyResourceChange", req.TypeName, req, c.client.ApplyResourceChange, func(resp *tfprotov6.ApplyResourceChangeResponse, rule *Rule) {
+		resp.Diagnostics = append(resp.Diagnostics, convert.EncodeDiagnostics(rule.Diagnostics)...)
+		if rule.CorruptValue {
+			resp.NewState = corruptV6()
+		}
+	})
+}
+
+func (c *v6Client) ImportResourceState(ctx context.Context, req *tfprotov6.ImportResourceStateRequest) (*tfprotov6.ImportResourceStateResponse, error) {
+	return intercept(ctx, c.inj, c.kill, "ImportResourceState", req.TypeName, req, c.client.ImportResourceState, func(resp *tfprotov6.ImportResourceStateResponse, rule *Rule) {
+		resp.Diagnostics = append(resp.Diagnostics, convert.EncodeDiagnostics(rule.Diagnostics)...)
+		if rule.CorruptValue {
+			for _, res := range resp.ImportedResources {
+				res.State = corruptV6()
+			}
+		}
+	})
+}
+
+func (c *v6Client) MoveResourceState(ctx context.Context, req *tfprotov6.MoveResourceStateRequest) (*tfprotov6.MoveResourceStateResponse, error) {
+	return intercept(ctx, c.inj, c.kill, "MoveResourceState", req.TargetTypeName, req, c.client.MoveResourceState, func(resp *tfprotov6.MoveResourceStateResponse, rule *Rule) {
+		resp.Diagnostics = append(resp.Diagnostics, convert.EncodeDiagnostics(rule.Diagnostics)...)
+		if rule.CorruptValue {
+			resp.TargetState = corruptV6()
+		}
+	})
+}
+
+func (c *v6Client) ValidateDataResourceConfig(ctx context.Context, req *tfprotov6.ValidateDataResourceConfigRequest) (*tfprotov6.ValidateDataResourceConfigResponse, error) {
+	return intercept(ctx, c.inj, c.kill, "ValidateDataResourceConfig", req.TypeName, req, c.client.ValidateDataResourceConfig, func(resp *tfprotov6.ValidateDataResourceConfigResponse, rule *Rule) {
+		resp.Diagnostics = append(resp.Diagnostics, convert.EncodeDiagnostics(rule.Diagnostics)...)
+	})
+}
+
+func (c *v6Client) ReadDataSource(ctx context.Context, req *tfprotov6.ReadDataSourceRequest) (*tfprotov6.ReadDataSourceResponse, error) {
+	return intercept(ctx, c.inj, c.kill, "ReadDataSource", req.TypeName, req, c.client.ReadDataSource, func(resp *tfprotov6.ReadDataSourceResponse, rule *Rule) {
+		resp.Diagnostics = append(resp.Diagnostics, convert.EncodeDiagnostics(rule.Diagnostics)...)
+		if rule.CorruptValue {
+			resp.State = corruptV6()
+		}
+	})
+}
+
+func (c *v6Client) CallFunction(ctx context.Context, req *tfprotov6.CallFunctionRequest) (*tfprotov6.CallFunctionResponse, error) {
+	return intercept(ctx, c.inj, c.kill, "CallFunction", req.Name, req, c.client.CallFunction, func(resp *tfprotov6.CallFunctionResponse, rule *Rule) {
+		if len(rule.Diagnostics) != 0 {
+			var msgs []string
+			if resp.Error != nil {
+				msgs = append(msgs, resp.Error.Text)
+			}
+			for _, diag := range rule.Diagnostics {
+				msgs = append(msgs, diag.Summary)
+			}
+			resp.Error = &tfprotov6.FunctionError{Text: strings.Join(msgs, "; ")}
+		}
+		if rule.CorruptValue {
+			resp.Result = corruptV6()
+		}
+	})
+}
+
+func (c *v6Client) GetFunctions(ctx context.Context, req *tfprotov6.GetFunctionsRequest) (*tfprotov6.GetFunctionsResponse, error) {
+	return intercept(ctx, c.inj, c.kill, "GetFunctions", "", req, c.client.GetFunctions, func(resp *tfprotov6.GetFunctionsResponse, rule *Rule) {
+		resp.Diagnostics = append(resp.Diagnostics, convert.EncodeDiagnostics(rule.Diagnostics)...)
+	})
+}
+
+func (c *v6Client) ValidateEphemeralResourceConfig(ctx context.Context, req *tfprotov6.ValidateEphemeralResourceConfigRequest) (*tfprotov6.ValidateEphemeralResourceConfigResponse, error) {
+	return intercept(ctx, c.inj, c.kill, "ValidateEphemeralResourceConfig", req.TypeName, req, c.client.ValidateEphemeralResourceConfig, func(resp *tfprotov6.ValidateEphemeralResourceConfigResponse, rule *Rule) {
+		resp.Diagnostics = append(resp.Diagnostics, convert.EncodeDiagnostics(rule.Diagnostics)...)
+	})
+}
+
+func (c *v6Client) OpenEphemeralResource(ctx context.Context, req *tfprotov6.OpenEphemeralResourceRequest) (*tfprotov6.OpenEphemeralResourceResponse, error) {
+	return intercept(ctx, c.inj, c.kill, "OpenEphemeralResource", req.TypeName, req, c.client.OpenEphemeralResource, func(resp *tfprotov6.OpenEphemeralResourceResponse, rule *Rule) {
+		resp.Diagnostics = append(resp.Diagnostics, convert.EncodeDiagnostics(rule.Diagnostics)...)
+		if rule.CorruptValue {
+			resp.Result = corruptV6()
+		}
+	})
+}
+
+func (c *v6Client) RenewEphemeralResource(ctx context.Context, req *tfprotov6.RenewEphemeralResourceRequest) (*tfprotov6.RenewEphemeralResourceResponse, error) {
+	return intercept(ctx, c.inj, c.kill, "RenewEphemeralResource", req.TypeName, req, c.client.RenewEphemeralResource, func(resp *tfprotov6.RenewEphemeralResourceResponse, rule *Rule) {
+		resp.Diagnostics = append(resp.Diagnostics, convert.EncodeDiagnostics(rule.Diagnostics)...)
+	})
+}
+
+func (c *v6Client) CloseEphemeralResource(ctx context.Context, req *tfprotov6.CloseEphemeralResourceRequest) (*tfprotov6.CloseEphemeralResourceResponse, error) {
+	return intercept(ctx, c.inj, c.kill, "CloseEphemeralResource", req.TypeName, req, c.client.CloseEphemeralResource, func(resp *tfprotov6.CloseEphemeralResourceResponse, rule *Rule) {
+		resp.Diagnostics = append(resp.Diagnostics, convert.EncodeDiagnostics(rule.Diagnostics)...)
+	})
+}
+
+func (c *v6Client) ValidateListResourceConfig(ctx context.Context, req *tfprotov6.ValidateListResourceConfigRequest) (*tfprotov6.ValidateListResourceConfigResponse, error) {
+	return intercept(ctx, c.inj, c.kill, "ValidateListResourceConfig", req.TypeName, req, c.client.ValidateListResourceConfig, func(resp *tfprotov6.ValidateListResourceConfigResponse, rule *Rule) {
+		resp.Diagnostics = append(resp.Diagnostics, convert.EncodeDiagnostics(rule.Diagnostics)...)
+	})
+}
+
+func (c *v6Client) ListResource(ctx context.Context, req *tfprotov6.ListResourceRequest) (*tfprotov6.ListResourceServerStream, error) {
+	return intercept(ctx, c.inj, c.kill, "ListResource", req.TypeName, req, c.client.ListResource, func(resp *tfprotov6.ListResourceServerStream, rule *Rule) {
+		results := resp.Results
+		resp.Results = func(yield func(tfprotov6.ListResourceResult) bool) {
+			if results != nil {
+				n := 0
+				for res := range results {
+					if rule.Truncate && n >= rule.TruncateAfter {
+						break
+					}
+					n++
+					if rule.CorruptValue {
+						res.Resource = corruptV6()
+					}
+					if !yield(res) {
+						return
+					}
+				}
+			}
+			if len(rule.Diagnostics) != 0 {
+				yield(tfprotov6.ListResourceResult{Diagnostics: convert.EncodeDiagnostics(rule.Diagnostics)})
+			}
+		}
+	})
+}
+
+func (c *v6Client) ValidateActionConfig(ctx context.Context, req *tfprotov6.ValidateActionConfigRequest) (*tfprotov6.ValidateActionConfigResponse, error) {
+	return intercept(ctx, c.inj, c.kill, "ValidateActionConfig", req.ActionType, req, c.client.ValidateActionConfig, func(resp *tfprotov6.ValidateActionConfigResponse, rule *Rule) {
+		resp.Diagnostics = append(resp.Diagnostics, convert.EncodeDiagnostics(rule.Diagnostics)...)
+	})
+}
+
+func (c *v6Client) PlanAction(ctx context.Context, req *tfprotov6.PlanActionRequest) (*tfprotov6.PlanActionResponse, error) {
+	return intercept(ctx, c.inj, c.kill, "PlanAction", req.ActionType, req, c.client.PlanAction, func(resp *tfprotov6.PlanActionResponse, rule *Rule) {
+		resp.Diagnostics = append(resp.Diagnostics, convert.EncodeDiagnostics(rule.Diagnostics)...)
+	})
+}
+
+func (c *v6Client) InvokeAction(ctx context.Context, req *tfprotov6.InvokeActionRequest) (*tfprotov6.InvokeActionServerStream, error) {
+	return intercept(ctx, c.inj, c.kill, "InvokeAction", req.ActionType, req, c.client.InvokeAction, func(resp *tfprotov6.InvokeActionServerStream, rule *Rule) {
+		events := resp.Events
+		resp.Events = func(yield func(tfprotov6.InvokeActionEvent) bool) {
+			if events != nil {
+				n := 0
+				for ev := range events {
+					if rule.Truncate && n >= rule.TruncateAfter {
+						break
+					}
+					n++
+					if !yield(ev) {
+						return
+					}
+				}
+			}
+			if len(rule.Diagnostics) != 0 {
+				yield(tfprotov6.InvokeActionEvent{
+					Type: &tfprotov6.CompletedInvokeActionEventType{Diagnostics: convert.EncodeDiagnostics(rule.Diagnostics)},
+				})
+			}
+		}
+	})
+}
@@ -0,0 +1,1089 @@
+// Package gateway exposes a provider client as a HTTP/JSON API, so that it can be consumed by other
+// languages.
+//
+// Each client method is mapped to a POST endpoint, whose request and response bodies are JSON objects.
+// The values are encoded in JSON as is defined by the implied types of the provider schema. As JSON has
+// no notion of unknown values, they are written as null, and their paths are listed in the
+// "unknown_paths" field of the body, keyed by the value field name. The same field is honored in the
+// request body, e.g. to pass the planned state back to the apply endpoint.
+//
+// The responses carry the provider diagnostics in the "diagnostics" field. A non-2xx status code is only
+// returned for an invalid request, e.g. a malformed body or an unknown type name, along with an "error"
+// field.
+//
+// The ListResource and InvokeAction endpoints stream their results as newline delimited JSON, where each
+// line is an object with a "type" field. The stream always ends with a "done" line with the diagnostics.
+//
+// The OpenAPI document of the endpoints, generated from the provider schema, is served at
+// "/openapi.json".
+//
+// As the gateway drives a (likely configured) provider, it guards against the requests from the browsers: The Host
+// header must be a loopback host (against the DNS rebinding), unless Option.AllowAnyHost is set, the POST requests
+// must have the "application/json" content type (against the requests without CORS preflight), and the bearer token
+// in the Authorization header must match Option.Token, if set. The request body is limited to MaxBodyBytes.
+package gateway
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	tfjson "github.com/hashicorp/terraform-json"
+	"github.com/magodo/terraform-client-go/tfclient"
+	"github.com/magodo/terraform-client-go/tfclient/configschema"
+	"github.com/magodo/terraform-client-go/tfclient/objchange"
+	"github.com/magodo/terraform-client-go/tfclient/providerschema"
+	"github.com/magodo/terraform-client-go/tfclient/typ"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+)
+
+// MaxBodyBytes is the maximum size of the request body.
+const MaxBodyBytes = 32 << 20
+
+// Option is the option of the Handler.
+type Option struct {
+	// Token is the bearer token required in the Authorization header of every request, if not empty.
+	Token string
+
+	// AllowAnyHost skips checking that the Host header is a loopback host, e.g. for serving on a unix socket,
+	// whose clients may use an arbitrary host.
+	AllowAnyHost bool
+}
+
+// Handler is the http.Handler that serves the gateway of a provider client.
+type Handler struct {
+	client       tfclient.Client
+	schema       *typ.GetProviderSchemaResponse
+	providerAddr string
+	opts         Option
+	mux          *http.ServeMux
+}
+
+var _ http.Handler = &Handler{}
+
+// New creates the gateway handler for the client, whose schema is the one returned by its
+// GetProviderSchema. The provider address (e.g. registry.terraform.io/hashicorp/azurerm) is used to
+// key the schema document served at "/schema".
+func New(client tfclient.Client, schema *typ.GetProviderSchemaResponse, providerAddr string, opts Option) *Handler {
+	h := &Handler{
+		client:       client,
+		schema:       schema,
+		providerAddr: providerAddr,
+		opts:         opts,
+		mux:          http.NewServeMux(),
+	}
+	h.routes()
+	return h
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !h.opts.AllowAnyHost && !isLoopbackHost(r.Host) {
+		writeError(w, httpError{status: http.StatusForbidden, msg: fmt.Sprintf("host %q is not allowed", r.Host)})
+		return
+	}
+	if h.opts.Token != "" {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(h.opts.Token)) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			writeError(w, httpError{status: http.StatusUnauthorized, msg: "invalid or missing bearer token"})
+			return
+		}
+	}
+	h.mux.ServeHTTP(w, r)
+}
+
+// isLoopbackHost tells whether the host (with an optional port) of the Host header is a loopback host.
+func isLoopbackHost(hostport string) bool {
+	host := hostport
+	if h, _, err := net.SplitHostPort(hostport); err == nil {
+		host = h
+	}
+	host = strings.TrimSuffix(strings.TrimPrefix(host, "["), "]")
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+func (h *Handler) routes() {
+	h.mux.HandleFunc("GET /schema", h.getSchema)
+	h.mux.HandleFunc("GET /openapi.json", h.getOpenAPI)
+
+	handle(h, "POST /provider/validate", h.validateProviderConfig)
+	handle(h, "POST /provider/configure", h.configureProvider)
+	handle(h, "POST /provider/stop", h.stop)
+
+	handle(h, "POST /resources/{type}/validate", h.validateResourceConfig)
+	handle(h, "POST /resources/{type}/read", h.readResource)
+	handle(h, "POST /resources/{type}/plan", h.planResourceChange)
+	handle(h, "POST /resources/{type}/apply", h.applyResourceChange)
+	handle(h, "POST /resources/{type}/import", h.importResourceState)
+	handle(h, "POST /resources/{type}/move", h.moveResourceState)
+	handle(h, "POST /resources/{type}/upgrade-state", h.upgradeResourceState)
+	handle(h, "POST /resources/{type}/upgrade-identity", h.upgradeResourceIdentity)
+
+	handle(h, "POST /data-sources/{type}/validate", h.validateDataResourceConfig)
+	handle(h, "POST /data-sources/{type}/read", h.readDataSource)
+
+	handle(h, "POST /ephemeral-resources/{type}/validate", h.validateEphemeralResourceConfig)
+	handle(h, "POST /ephemeral-resources/{type}/open", h.openEphemeralResource)
+	handle(h, "POST /ephemeral-resources/{type}/renew", h.renewEphemeralResource)
+	handle(h, "POST /ephemeral-resources/{type}/close", h.closeEphemeralResource)
+
+	handle(h, "POST /list-resources/{type}/validate", h.validateListResourceConfig)
+	handle(h, "POST /list-resources/{type}/list", h.listResource)
+
+	handle(h, "POST /actions/{type}/validate", h.validateActionConfig)
+	handle(h, "POST /actions/{type}/plan", h.planAction)
+	handle(h, "POST /actions/{type}/invoke", h.invokeAction)
+
+	handle(h, "POST /functions/{name}/call", h.callFunction)
+}
+
+// httpError is an error with the HTTP status code.
+type httpError struct {
+	status int
+	msg    string
+}
+
+func (e httpError) Error() string {
+	return e.msg
+}
+
+func badRequest(format string, a ...any) error {
+	return httpError{status: http.StatusBadRequest, msg: fmt.Sprintf(format, a...)}
+}
+
+func notFound(format string, a ...any) error {
+	return httpError{status: http.StatusNotFound, msg: fmt.Sprintf(format, a...)}
+}
+
+// handle registers the handler function of the pattern, which decodes the request body into Req, and
+// encodes the returned response as JSON. A nil response means the function has written the response.
+func handle[Req any](h *Handler, pattern string, f func(w http.ResponseWriter, r *http.Request, req *Req) (any, error)) {
+	h.mux.HandleFunc(pattern, func(w http.ResponseWriter, r *http.Request) {
+		// Only the requests that can't be sent by the browsers without the CORS preflight are accepted.
+		if mt, _, err := mime.ParseMediaType(r.Header.Get("Content-Type")); err != nil || mt != "application/json" {
+			writeError(w, httpError{status: http.StatusUnsupportedMediaType, msg: `the content type must be "application/json"`})
+			return
+		}
+		r.Body = http.MaxBytesReader(w, r.Body, MaxBodyBytes)
+		var req Req
+		if r.ContentLength != 0 {
+			dec := json.NewDecoder(r.Body)
+			dec.DisallowUnknownFields()
+			if err := dec.Decode(&req); err != nil {
+				var merr *http.MaxBytesError
+				if errors.As(err, &merr) {
+					writeError(w, httpError{status: http.StatusRequestEntityTooLarge, msg: err.Error()})
+					return
+				}
+				writeError(w, badRequest("decoding the request body: %v", err))
+				return
+			}
+		}
+		resp, err := f(w, r, &req)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		if resp != nil {
+			writeJSON(w, http.StatusOK, resp)
+		}
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	// nolint:errcheck
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	var herr httpError
+	if errors.As(err, &herr) {
+		status = herr.status
+	}
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
+
+// result is the common fields of the responses.
+type result struct {
+	Diagnostics  []Diagnostic `json:"diagnostics"`
+	UnknownPaths UnknownPaths `json:"unknown_paths,omitempty"`
+}
+
+func newResult(diags typ.Diagnostics, enc *encoder) result {
+	return result{
+		Diagnostics:  toDiagnostics(diags),
+		UnknownPaths: enc.unknowns,
+	}
+}
+
+func deferredReason(d *typ.Deferred) string {
+	if d == nil {
+		return ""
+	}
+	return string(d.Reason)
+}
+
+func lookupSchema(m map[string]tfjson.Schema, kind, name string) (tfjson.Schema, error) {
+	sch, ok := m[name]
+	if !ok {
+		return sch, notFound("no %s named %q", kind, name)
+	}
+	return sch, nil
+}
+
+func identityType(sch tfjson.Schema) cty.Type {
+	return configschema.SchemaNestedAttributeTypeImpliedType(sch.Identity)
+}
+
+func (h *Handler) getSchema(w http.ResponseWriter, r *http.Request) {
+	b, err := providerschema.Marshal(h.providerAddr, h.schema)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	// nolint:errcheck
+	w.Write(b)
+}
+
+func (h *Handler) getOpenAPI(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, OpenAPI(h.schema))
+}
+
+// Provider
+
+type ConfigRequest struct {
+	Config       json.RawMessage `json:"config"`
+	UnknownPaths UnknownPaths    `json:"unknown_paths,omitempty"`
+}
+
+type DiagnosticsResponse struct {
+	result
+}
+
+func (h *Handler) validateProviderConfig(w http.ResponseWriter, r *http.Request, req *ConfigRequest) (any, error) {
+	dec := decoder{unknowns: req.UnknownPaths}
+	config := dec.block("config", req.Config, h.schema.Provider.Block)
+	if dec.err != nil {
+		return nil, badRequest("%v", dec.err)
+	}
+	_, diags := h.client.ValidateProviderConfig(r.Context(), typ.ValidateProviderConfigRequest{Config: config})
+	return DiagnosticsResponse{result: newResult(diags, &encoder{})}, nil
+}
+
+type ConfigureProviderRequest struct {
+	Config           json.RawMessage `json:"config"`
+	TerraformVersion string          `json:"terraform_version,omitempty"`
+}
+
+func (h *Handler) configureProvider(w http.ResponseWriter, r *http.Request, req *ConfigureProviderRequest) (any, error) {
+	var dec decoder
+	config := dec.block("config", req.Config, h.schema.Provider.Block)
+	if dec.err != nil {
+		return nil, badRequest("%v", dec.err)
+	}
+	_, diags := h.client.ConfigureProvider(r.Context(), typ.ConfigureProviderRequest{
+		Config:           config,
+		TerraformVersion: req.TerraformVersion,
+	})
+	return DiagnosticsResponse{result: newResult(diags, &encoder{})}, nil
+}
+
+type Empty struct{}
+
+func (h *Handler) stop(w http.ResponseWriter, r *http.Request, req *Empty) (any, error) {
+	var diags typ.Diagnostics
+	if err := h.client.Stop(r.Context()); err != nil {
+		diags = typ.ErrorDiagnostics("stopping the provider", err)
+	}
+	return DiagnosticsResponse{result: newResult(diags, &encoder{})}, nil
+}
+
+// Resources
+
+func (h *Handler) validateResourceConfig(w http.ResponseWriter, r *http.Request, req *ConfigRequest) (any, error) {
+	typeName := r.PathValue("type")
+	if _, err := lookupSchema(h.schema.ResourceTypes, "resource", typeName); err != nil {
+		return nil, err
+	}
+	dec := decoder{unknowns: req.UnknownPaths}
+	config := dec.block("config", req.Config, h.schema.ResourceTypes[typeName].Block)
+	if dec.err != nil {
+		return nil, badRequest("%v", dec.err)
+	}
+	_, diags := h.client.ValidateResourceConfig(r.Context(), typ.ValidateResourceConfigRequest{
+		TypeName: typeName,
+		Config:   config,
+	})
+	return DiagnosticsResponse{result: newResult(diags, &encoder{})}, nil
+}
+
+type ReadResourceRequest struct {
+	State        json.RawMessage `json:"state"`
+	Private      []byte          `json:"private,omitempty"`
+	Identity     json.RawMessage `json:"identity,omitempty"`
+	UnknownPaths UnknownPaths    `json:"unknown_paths,omitempty"`
+}
+
+type ReadResourceResponse struct {
+	NewState json.RawMessage `json:"new_state"`
+	Private  []byte          `json:"private,omitempty"`
+	Identity json.RawMessage `json:"identity,omitempty"`
+	Deferred string          `json:"deferred,omitempty"`
+	result
+}
+
+func (h *Handler) readResource(w http.ResponseWriter, r *http.Request, req *ReadResourceRequest) (any, error) {
+	typeName := r.PathValue("type")
+	sch, err := lookupSchema(h.schema.ResourceTypes, "resource", typeName)
+	if err != nil {
+		return nil, err
+	}
+	ty := h.schema.ResourceTypesCty[typeName]
+	dec := decoder{unknowns: req.UnknownPaths}
+	state := dec.block("state", req.State, sch.Block)
+	identity := dec.identity("identity", req.Identity, sch)
+	if dec.err != nil {
+		return nil, badRequest("%v", dec.err)
+	}
+	resp, diags := h.client.ReadResource(r.Context(), typ.ReadResourceRequest{
+		TypeName:        typeName,
+		PriorState:      state,
+		Private:         req.Private,
+		CurrentIdentity: identity,
+	})
+	if resp == nil {
+		return DiagnosticsResponse{result: newResult(diags, &encoder{})}, nil
+	}
+	var enc encoder
+	out := ReadResourceResponse{
+		NewState: enc.value("new_state", resp.NewState, ty),
+		Private:  resp.Private,
+		Identity: enc.value("identity", resp.Identity, identityType(sch)),
+		Deferred: deferredReason(resp.Deferred),
+	}
+	if enc.err != nil {
+		return nil, enc.err
+	}
+	out.result = newResult(diags, &enc)
+	return out, nil
+}
+
+type PlanResourceChangeRequest struct {
+	PriorState json.RawMessage `json:"prior_state"`
+	Config     json.RawMessage `json:"config"`
+	// ProposedNewState is optional, which defaults to the one computed from the prior state and the config
+	// as Terraform does.
+	ProposedNewState json.RawMessage `json:"proposed_new_state,omitempty"`
+	PriorPrivate     []byte          `json:"prior_private,omitempty"`
+	PriorIdentity    json.RawMessage `json:"prior_identity,omitempty"`
+	UnknownPaths     UnknownPaths    `json:"unknown_paths,omitempty"`
+}
+
+type PlanResourceChangeResponse struct {
+	PlannedState    json.RawMessage `json:"planned_state"`
+	RequiresReplace []Path          `json:"requires_replace,omitempty"`
+	PlannedPrivate  []byte          `json:"planned_private,omitempty"`
+	PlannedIdentity json.RawMessage `json:"planned_identity,omitempty"`
+	Deferred        string          `json:"deferred,omitempty"`
+	result
+}
+
+func (h *Handler) planResourceChange(w http.ResponseWriter, r *http.Request, req *PlanResourceChangeRequest) (any, error) {
+	typeName := r.PathValue("type")
+	sch, err := lookupSchema(h.schema.ResourceTypes, "resource", typeName)
+	if err != nil {
+		return nil, err
+	}
+	ty := h.schema.ResourceTypesCty[typeName]
+	dec := decoder{unknowns: req.UnknownPaths}
+	prior := dec.block("prior_state", req.PriorState, sch.Block)
+	config := dec.block("config", req.Config, sch.Block)
+	identity := dec.identity("prior_identity", req.PriorIdentity, sch)
+	proposed := cty.NullVal(ty)
+	if len(req.ProposedNewState) != 0 {
+		proposed = dec.block("proposed_new_state", req.ProposedNewState, sch.Block)
+	} else if dec.err == nil && !config.IsNull() {
+		proposed = objchange.ProposedNew(sch.Block, prior, config)
+	}
+	if dec.err != nil {
+		return nil, badRequest("%v", dec.err)
+	}
+
+	resp, diags := h.client.PlanResourceChange(r.Context(), typ.PlanResourceChangeRequest{
+		TypeName:         typeName,
+		PriorState:       prior,
+		ProposedNewState: proposed,
+		Config:           config,
+		PriorPrivate:     req.PriorPrivate,
+		PriorIdentity:    identity,
+	})
+	if resp == nil {
+		return DiagnosticsResponse{result: newResult(diags, &encoder{})}, nil
+	}
+	var enc encoder
+	out := PlanResourceChangeResponse{
+		PlannedState:    enc.value("planned_state", resp.PlannedState, ty),
+		PlannedPrivate:  resp.PlannedPrivate,
+		PlannedIdentity: enc.value("planned_identity", resp.PlannedIdentity, identityType(sch)),
+		Deferred:        deferredReason(resp.Deferred),
+	}
+	for _, p := range resp.RequiresReplace {
+		out.RequiresReplace = append(out.RequiresReplace, toPath(p))
+	}
+	if enc.err != nil {
+		return nil, enc.err
+	}
+	out.result = newResult(diags, &enc)
+	return out, nil
+}
+
+type ApplyResourceChangeRequest struct {
+	PriorState      json.RawMessage `json:"prior_state"`
+	PlannedState    json.RawMessage `json:"planned_state"`
+	Config          json.RawMessage `json:"config"`
+	PlannedPrivate  []byte          `json:"planned_private,omitempty"`
+	PlannedIdentity json.RawMessage `json:"planned_identity,omitempty"`
+	UnknownPaths    UnknownPaths    `json:"unknown_paths,omitempty"`
+}
+
+type ApplyResourceChangeResponse struct {
+	NewState    json.RawMessage `json:"new_state"`
+	Private     []byte          `json:"private,omitempty"`
+	NewIdentity json.RawMessage `json:"new_identity,omitempty"`
+	result
+}
+
+func (h *Handler) applyResourceChange(w http.ResponseWriter, r *http.Request, req *ApplyResourceChangeRequest) (any, error) {
+	typeName := r.PathValue("type")
+	sch, err := lookupSchema(h.schema.ResourceTypes, "resource", typeName)
+	if err != nil {
+		return nil, err
+	}
+	ty := h.schema.ResourceTypesCty[typeName]
+	dec := decoder{unknowns: req.UnknownPaths}
+	prior := dec.block("prior_state", req.PriorState, sch.Block)
+	planned := dec.block("planned_state", req.PlannedState, sch.Block)
+	config := dec.block("config", req.Config, sch.Block)
+	identity := dec.identity("planned_identity", req.PlannedIdentity, sch)
+	if dec.err != nil {
+		return nil, badRequest("%v", dec.err)
+	}
+
+	resp, diags := h.client.ApplyResourceChange(r.Context(), typ.ApplyResourceChangeRequest{
+		TypeName:        typeName,
+		PriorState:      prior,
+		PlannedState:    planned,
+		Config:          config,
+		PlannedPrivate:  req.PlannedPrivate,
+		PlannedIdentity: identity,
+	})
+	if resp == nil {
+		return DiagnosticsResponse{result: newResult(diags, &encoder{})}, nil
+	}
+	var enc encoder
+	out := ApplyResourceChangeResponse{
+		NewState:    enc.value("new_state", resp.NewState, ty),
+		Private:     resp.Private,
+		NewIdentity: enc.value("new_identity", resp.NewIdentity, identityType(sch)),
+	}
+	if enc.err != nil {
+		return nil, enc.err
+	}
+	out.result = newResult(diags, &enc)
+	return out, nil
+}
+
+type ImportResourceStateRequest struct {
+	ID       string          `json:"id,omitempty"`
+	Identity json.RawMessage `json:"identity,omitempty"`
+}
+
+type ImportedResource struct {
+	TypeName string          `json:"type_name"`
+	State    json.RawMessage `json:"state"`
+	Private  []byte          `json:"private,omitempty"`
+	Identity json.RawMessage `json:"identity,omitempty"`
+}
+
+type ImportResourceStateResponse struct {
+	ImportedResources []ImportedResource `json:"imported_resources"`
+	Deferred          string             `json:"deferred,omitempty"`
+	result
+}
+
+func (h *Handler) importResourceState(w http.ResponseWriter, r *http.Request, req *ImportResourceStateRequest) (any, error) {
+	typeName := r.PathValue("type")
+	sch, err := lookupSchema(h.schema.ResourceTypes, "resource", typeName)
+	if err != nil {
+		return nil, err
+	}
+	var dec decoder
+	identity := dec.identity("identity", req.Identity, sch)
+	if dec.err != nil {
+		return nil, badRequest("%v", dec.err)
+	}
+
+	resp, diags := h.client.ImportResourceState(r.Context(), typ.ImportResourceStateRequest{
+		TypeName: typeName,
+		ID:       req.ID,
+		Identity: identity,
+	})
+	if resp == nil {
+		return DiagnosticsResponse{result: newResult(diags, &encoder{})}, nil
+	}
+	var enc encoder
+	out := ImportResourceStateResponse{
+		ImportedResources: []ImportedResource{},
+		Deferred:          deferredReason(resp.Deferred),
+	}
+	for i, res := range resp.ImportedResources {
+		resSch, ok := h.schema.ResourceTypes[res.TypeName]
+		if !ok {
+			return nil, fmt.Errorf("the provider imported an unknown resource type %q", res.TypeName)
+		}
+		out.ImportedResources = append(out.ImportedResources, ImportedResource{
+			TypeName: res.TypeName,
+			State:    enc.value(fmt.Sprintf("imported_resources.%d.state", i), res.State, h.schema.ResourceTypesCty[res.TypeName]),
+			Private:  res.Private,
+			Identity: enc.value(fmt.Sprintf("imported_resources.%d.identity", i), res.Identity, identityType(resSch)),
+		})
+	}
+	if enc.err != nil {
+		return nil, enc.err
+	}
+	out.result = newResult(diags, &enc)
+	return out, nil
+}
+
+type MoveResourceStateRequest struct {
+	SourceProviderAddress string `json:"source_provider_address"`
+	SourceTypeName        string `json:"source_type_name"`
+	SourceSchemaVersion   int64  `json:"source_schema_version"`
+	// SourceState and SourceIdentity are the raw JSON of the source resource, as is stored in the state file.
+	SourceState    json.RawMessage `json:"source_state"`
+	SourcePrivate  []byte          `json:"source_private,omitempty"`
+	SourceIdentity json.RawMessage `json:"source_identity,omitempty"`
+}
+
+type MoveResourceStateResponse struct {
+	TargetState    json.RawMessage `json:"target_state"`
+	TargetPrivate  []byte          `json:"target_private,omitempty"`
+	TargetIdentity json.RawMessage `json:"target_identity,omitempty"`
+	result
+}
+
+func (h *Handler) moveResourceState(w http.ResponseWriter, r *http.Request, req *MoveResourceStateRequest) (any, error) {
+	typeName := r.PathValue("type")
+	sch, err := lookupSchema(h.schema.ResourceTypes, "resource", typeName)
+	if err != nil {
+		return nil, err
+	}
+	resp, diags := h.client.MoveResourceState(r.Context(), typ.MoveResourceStateRequest{
+		SourceProviderAddress: req.SourceProviderAddress,
+		SourceTypeName:        req.SourceTypeName,
+		SourceSchemaVersion:   req.SourceSchemaVersion,
+		SourceStateJSON:       req.SourceState,
+		SourcePrivate:         req.SourcePrivate,
+		TargetTypeName:        typeName,
+		SourceIdentity:        req.SourceIdentity,
+	})
+	if resp == nil {
+		return DiagnosticsResponse{result: newResult(diags, &encoder{})}, nil
+	}
+	var enc encoder
+	out := MoveResourceStateResponse{
+		TargetState:    enc.value("target_state", resp.TargetState, h.schema.ResourceTypesCty[typeName]),
+		TargetPrivate:  resp.TargetPrivate,
+		TargetIdentity: enc.value("target_identity", resp.TargetIdentity, identityType(sch)),
+	}
+	if enc.err != nil {
+		return nil, enc.err
+	}
+	out.result = newResult(diags, &enc)
+	return out, nil
+}
+
+type UpgradeResourceStateRequest struct {
+	Version int64 `json:"version"`
+	// RawState is the raw JSON of the resource state, as is stored in the state file.
+	RawState json.RawMessage `json:"raw_state"`
+}
+
+type UpgradeResourceStateResponse struct {
+	UpgradedState json.RawMessage `json:"upgraded_state"`
+	result
+}
+
+func (h *Handler) upgradeResourceState(w http.ResponseWriter, r *http.Request, req *UpgradeResourceStateRequest) (any, error) {
+	typeName := r.PathValue("type")
+	if _, err := lookupSchema(h.schema.ResourceTypes, "resource", typeName); err != nil {
+		return nil, err
+	}
+	resp, diags := h.client.UpgradeResourceState(r.Context(), typ.UpgradeResourceStateRequest{
+		TypeName:     typeName,
+		Version:      req.Version,
+		RawStateJSON: req.RawState,
+	})
+	if resp == nil {
+		return DiagnosticsResponse{result: newResult(diags, &encoder{})}, nil
+	}
+	var enc encoder
+	out := UpgradeResourceStateResponse{
+		UpgradedState: enc.value("upgraded_state", resp.UpgradedState, h.schema.ResourceTypesCty[typeName]),
+	}
+	if enc.err != nil {
+		return nil, enc.err
+	}
+	out.result = newResult(diags, &enc)
+	return out, nil
+}
+
+type UpgradeResourceIdentityRequest struct {
+	Version int64 `json:"version"`
+	// RawIdentity is the raw JSON of the resource identity, as is stored in the state file.
+	RawIdentity json.RawMessage `json:"raw_identity"`
+}
+
+type UpgradeResourceIdentityResponse struct {
+	UpgradedIdentity json.RawMessage `json:"upgraded_identity"`
+	result
+}
+
+func (h *Handler) upgradeResourceIdentity(w http.ResponseWriter, r *http.Request, req *UpgradeResourceIdentityRequest) (any, error) {
+	typeName := r.PathValue("type")
+	sch, err := lookupSchema(h.schema.ResourceTypes, "resource", typeName)
+	if err != nil {
+		return nil, err
+	}
+	resp, diags := h.client.UpgradeResourceIdentity(r.Context(), typ.UpgradeResourceIdentityRequest{
+		TypeName:        typeName,
+		Version:         req.Version,
+		RawIdentityJSON: req.RawIdentity,
+	})
+	if resp == nil {
+		return DiagnosticsResponse{result: newResult(diags, &encoder{})}, nil
+	}
+	var enc encoder
+	out := UpgradeResourceIdentityResponse{
+		UpgradedIdentity: enc.value("upgraded_identity", resp.UpgradedIdentity, identityType(sch)),
+	}
+	if enc.err != nil {
+		return nil, enc.err
+	}
+	out.result = newResult(diags, &enc)
+	return out, nil
+}
+
+// Data sources
+
+func (h *Handler) validateDataResourceConfig(w http.ResponseWriter, r *http.Request, req *ConfigRequest) (any, error) {
+	typeName := r.PathValue("type")
+	if _, err := lookupSchema(h.schema.DataSources, "data source", typeName); err != nil {
+		return nil, err
+	}
+	dec := decoder{unknowns: req.UnknownPaths}
+	config := dec.block("config", req.Config, h.schema.DataSources[typeName].Block)
+	if dec.err != nil {
+		return nil, badRequest("%v", dec.err)
+	}
+	_, diags := h.client.ValidateDataResourceConfig(r.Context(), typ.ValidateDataResourceConfigRequest{
+		TypeName: typeName,
+		Config:   config,
+	})
+	return DiagnosticsResponse{result: newResult(diags, &encoder{})}, nil
+}
+
+type ReadDataSourceResponse struct {
+	State    json.RawMessage `json:"state"`
+	Deferred string          `json:"deferred,omitempty"`
+	result
+}
+
+func (h *Handler) readDataSource(w http.ResponseWriter, r *http.Request, req *ConfigRequest) (any, error) {
+	typeName := r.PathValue("type")
+	sch, err := lookupSchema(h.schema.DataSources, "data source", typeName)
+	if err != nil {
+		return nil, err
+	}
+	ty := h.schema.DataSourcesCty[typeName]
+	dec := decoder{unknowns: req.UnknownPaths}
+	config := dec.block("config", req.Config, sch.Block)
+	if dec.err != nil {
+		return nil, badRequest("%v", dec.err)
+	}
+	resp, diags := h.client.ReadDataSource(r.Context(), typ.ReadDataSourceRequest{
+		TypeName: typeName,
+		Config:   config,
+	})
+	if resp == nil {
+		return DiagnosticsResponse{result: newResult(diags, &encoder{})}, nil
+	}
+	var enc encoder
+	out := ReadDataSourceResponse{
+		State:    enc.value("state", resp.State, ty),
+		Deferred: deferredReason(resp.Deferred),
+	}
+	if enc.err != nil {
+		return nil, enc.err
+	}
+	out.result = newResult(diags, &enc)
+	return out, nil
+}
+
+// Ephemeral resources
+
+func (h *Handler) validateEphemeralResourceConfig(w http.ResponseWriter, r *http.Request, req *ConfigRequest) (any, error) {
+	typeName := r.PathValue("type")
+	if _, err := lookupSchema(h.schema.EphemeralResourceTypes, "ephemeral resource", typeName); err != nil {
+		return nil, err
+	}
+	dec := decoder{unknowns: req.UnknownPaths}
+	config := dec.block("config", req.Config, h.schema.EphemeralResourceTypes[typeName].Block)
+	if dec.err != nil {
+		return nil, badRequest("%v", dec.err)
+	}
+	diags := h.client.ValidateEphemeralResourceConfig(r.Context(), typ.ValidateEphemeralResourceConfigRequest{
+		TypeName: typeName,
+		Config:   config,
+	})
+	return DiagnosticsResponse{result: newResult(diags, &encoder{})}, nil
+}
+
+type OpenEphemeralResourceResponse struct {
+	Result   json.RawMessage `json:"result"`
+	Private  []byte          `json:"private,omitempty"`
+	RenewAt  *time.Time      `json:"renew_at,omitempty"`
+	Deferred string          `json:"deferred,omitempty"`
+	result
+}
+
+func (h *Handler) openEphemeralResource(w http.ResponseWriter, r *http.Request, req *ConfigRequest) (any, error) {
+	typeName := r.PathValue("type")
+	sch, err := lookupSchema(h.schema.EphemeralResourceTypes, "ephemeral resource", typeName)
+	if err != nil {
+		return nil, err
+	}
+	ty := h.schema.EphemeralResourceTypesCty[typeName]
+	dec := decoder{unknowns: req.UnknownPaths}
+	config := dec.block("config", req.Config, sch.Block)
+	if dec.err != nil {
+		return nil, badRequest("%v", dec.err)
+	}
+	resp, diags := h.client.OpenEphemeralResource(r.Context(), typ.OpenEphemeralResourceRequest{
+		TypeName: typeName,
+		Config:   config,
+	})
+	if resp == nil {
+		return DiagnosticsResponse{result: newResult(diags, &encoder{})}, nil
+	}
+	var enc encoder
+	out := OpenEphemeralResourceResponse{
+		Result:   enc.value("result", resp.Result, ty),
+		Private:  resp.Private,
+		Deferred: deferredReason(resp.Deferred),
+	}
+	if !resp.RenewAt.IsZero() {
+		out.RenewAt = &resp.RenewAt
+	}
+	if enc.err != nil {
+		return nil, enc.err
+	}
+	out.result = newResult(diags, &enc)
+	return out, nil
+}
+
+type PrivateRequest struct {
+	Private []byte `json:"private,omitempty"`
+}
+
+type RenewEphemeralResourceResponse struct {
+	Private []byte     `json:"private,omitempty"`
+	RenewAt *time.Time `json:"renew_at,omitempty"`
+	result
+}
+
+func (h *Handler) renewEphemeralResource(w http.ResponseWriter, r *http.Request, req *PrivateRequest) (any, error) {
+	typeName := r.PathValue("type")
+	if _, err := lookupSchema(h.schema.EphemeralResourceTypes, "ephemeral resource", typeName); err != nil {
+		return nil, err
+	}
+	resp, diags := h.client.RenewEphemeralResource(r.Context(), typ.RenewEphemeralResourceRequest{
+		TypeName: typeName,
+		Private:  req.Private,
+	})
+	if resp == nil {
+		return DiagnosticsResponse{result: newResult(diags, &encoder{})}, nil
+	}
+	out := RenewEphemeralResourceResponse{
+		Private: resp.Private,
+		result:  newResult(diags, &encoder{}),
+	}
+	if !resp.RenewAt.IsZero() {
+		out.RenewAt = &resp.RenewAt
+	}
+	return out, nil
+}
+
+func (h *Handler) closeEphemeralResource(w http.ResponseWriter, r *http.Request, req *PrivateRequest) (any, error) {
+	typeName := r.PathValue("type")
+	if _, err := lookupSchema(h.schema.EphemeralResourceTypes, "ephemeral resource", typeName); err != nil {
+		return nil, err
+	}
+	diags := h.client.CloseEphemeralResource(r.Context(), typ.CloseEphemeralResourceRequest{
+		TypeName: typeName,
+		Private:  req.Private,
+	})
+	return DiagnosticsResponse{result: newResult(diags, &encoder{})}, nil
+}
+
+// List resources
+
+func (h *Handler) validateListResourceConfig(w http.ResponseWriter, r *http.Request, req *ConfigRequest) (any, error) {
+	typeName := r.PathValue("type")
+	if _, err := lookupSchema(h.schema.ListResourceTypes, "list resource", typeName); err != nil {
+		return nil, err
+	}
+	dec := decoder{unknowns: req.UnknownPaths}
+	config := dec.block("config", req.Config, h.schema.ListResourceTypes[typeName].Block)
+	if dec.err != nil {
+		return nil, badRequest("%v", dec.err)
+	}
+	diags := h.client.ValidateListResourceConfig(r.Context(), typ.ValidateListResourceConfigRequest{
+		TypeName: typeName,
+		Config:   cty.ObjectVal(map[string]cty.Value{"config": config}),
+	})
+	return DiagnosticsResponse{result: newResult(diags, &encoder{})}, nil
+}
+
+type ListResourceRequest struct {
+	Config          json.RawMessage `json:"config"`
+	IncludeResource bool            `json:"include_resource,omitempty"`
+	Limit           int64           `json:"limit,omitempty"`
+}
+
+// DefaultListLimit is the limit of the list resource results, when it is not specified in the request.
+const DefaultListLimit = 100
+
+// StreamEvent is a line of the NDJSON stream.
+type StreamEvent struct {
+	// Type is one of "result" (list), "progress" and "completed" (action), and "done".
+	Type         string          `json:"type"`
+	Result       json.RawMessage `json:"result,omitempty"`
+	Message      string          `json:"message,omitempty"`
+	Diagnostics  []Diagnostic    `json:"diagnostics,omitempty"`
+	UnknownPaths UnknownPaths    `json:"unknown_paths,omitempty"`
+}
+
+// streamWriter writes the NDJSON stream.
+type streamWriter struct {
+	w   http.ResponseWriter
+	enc *json.Encoder
+}
+
+func newStreamWriter(w http.ResponseWriter) *streamWriter {
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.WriteHeader(http.StatusOK)
+	return &streamWriter{w: w, enc: json.NewEncoder(w)}
+}
+
+func (s *streamWriter) write(evt StreamEvent) {
+	// nolint:errcheck
+	s.enc.Encode(evt)
+	if f, ok := s.w.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (s *streamWriter) done(diags typ.Diagnostics) {
+	s.write(StreamEvent{Type: "done", Diagnostics: toDiagnostics(diags)})
+}
+
+func (h *Handler) listResource(w http.ResponseWriter, r *http.Request, req *ListResourceRequest) (any, error) {
+	typeName := r.PathValue("type")
+	if _, err := lookupSchema(h.schema.ListResourceTypes, "list resource", typeName); err != nil {
+		return nil, err
+	}
+	var dec decoder
+	config := dec.block("config", req.Config, h.schema.ListResourceTypes[typeName].Block)
+	if dec.err != nil {
+		return nil, badRequest("%v", dec.err)
+	}
+	limit := req.Limit
+	if limit == 0 {
+		limit = DefaultListLimit
+	}
+	resp, diags := h.client.ListResource(r.Context(), typ.ListResourceRequest{
+		TypeName:              typeName,
+		Config:                cty.ObjectVal(map[string]cty.Value{"config": config}),
+		IncludeResourceObject: req.IncludeResource,
+		Limit:                 limit,
+	})
+
+	sw := newStreamWriter(w)
+	if resp.Result != cty.NilVal && !resp.Result.IsNull() && resp.Result.IsKnown() && resp.Result.Type().IsObjectType() && resp.Result.Type().HasAttribute("data") {
+		for it := resp.Result.GetAttr("data").ElementIterator(); it.Next(); {
+			_, v := it.Element()
+			var enc encoder
+			b := enc.value("result", v, v.Type())
+			if enc.err != nil {
+				diags = append(diags, typ.ErrorDiagnostics("encoding the list result", enc.err)...)
+				break
+			}
+			sw.write(StreamEvent{Type: "result", Result: b, UnknownPaths: enc.unknowns})
+		}
+	}
+	sw.done(diags)
+	return nil, nil
+}
+
+// Actions
+
+func (h *Handler) validateActionConfig(w http.ResponseWriter, r *http.Request, req *ConfigRequest) (any, error) {
+	typeName := r.PathValue("type")
+	if _, err := lookupSchema(h.schema.Actions, "action", typeName); err != nil {
+		return nil, err
+	}
+	dec := decoder{unknowns: req.UnknownPaths}
+	config := dec.block("config", req.Config, h.schema.Actions[typeName].Block)
+	if dec.err != nil {
+		return nil, badRequest("%v", dec.err)
+	}
+	diags := h.client.ValidateActionConfig(r.Context(), typ.ValidateActionConfigRequest{
+		TypeName: typeName,
+		Config:   config,
+	})
+	return DiagnosticsResponse{result: newResult(diags, &encoder{})}, nil
+}
+
+type PlanActionResponse struct {
+	Deferred string `json:"deferred,omitempty"`
+	result
+}
+
+func (h *Handler) planAction(w http.ResponseWriter, r *http.Request, req *ConfigRequest) (any, error) {
+	typeName := r.PathValue("type")
+	if _, err := lookupSchema(h.schema.Actions, "action", typeName); err != nil {
+		return nil, err
+	}
+	dec := decoder{unknowns: req.UnknownPaths}
+	config := dec.block("config", req.Config, h.schema.Actions[typeName].Block)
+	if dec.err != nil {
+		return nil, badRequest("%v", dec.err)
+	}
+	resp, diags := h.client.PlanAction(r.Context(), typ.PlanActionRequest{
+		ActionType:         typeName,
+		ProposedActionData: config,
+	})
+	return PlanActionResponse{
+		Deferred: deferredReason(resp.Deferred),
+		result:   newResult(diags, &encoder{}),
+	}, nil
+}
+
+func (h *Handler) invokeAction(w http.ResponseWriter, r *http.Request, req *ConfigRequest) (any, error) {
+	typeName := r.PathValue("type")
+	if _, err := lookupSchema(h.schema.Actions, "action", typeName); err != nil {
+		return nil, err
+	}
+	var dec decoder
+	config := dec.block("config", req.Config, h.schema.Actions[typeName].Block)
+	if dec.err != nil {
+		return nil, badRequest("%v", dec.err)
+	}
+	resp, diags := h.client.InvokeAction(r.Context(), typ.InvokeActionRequest{
+		ActionType:        typeName,
+		PlannedActionData: config,
+	})
+
+	sw := newStreamWriter(w)
+	if resp.Events != nil {
+		for evt := range resp.Events {
+			switch evt := evt.(type) {
+			case typ.InvokeActionEvent_Progress:
+				sw.write(StreamEvent{Type: "progress", Message: evt.Message})
+			case typ.InvokeActionEvent_Completed:
+				sw.write(StreamEvent{Type: "completed", Diagnostics: toDiagnostics(evt.Diagnostics)})
+			}
+		}
+	}
+	sw.done(diags)
+	return nil, nil
+}
+
+// Functions
+
+type CallFunctionRequest struct {
+	Arguments []json.RawMessage `json:"arguments"`
+}
+
+type CallFunctionResponse struct {
+	Result json.RawMessage `json:"result"`
+	// Error is the function error, with the index of the argument that caused it, if any.
+	Error         string `json:"error,omitempty"`
+	ErrorArgument *int   `json:"error_argument,omitempty"`
+	result
+}
+
+func (h *Handler) callFunction(w http.ResponseWriter, r *http.Request, req *CallFunctionRequest) (any, error) {
+	name := r.PathValue("name")
+	decl, ok := h.schema.Functions[name]
+	if !ok {
+		return nil, notFound("no function named %q", name)
+	}
+	var dec decoder
+	var args []cty.Value
+	for i, arg := range req.Arguments {
+		var ty cty.Type
+		switch {
+		case i < len(decl.Parameters):
+			ty = decl.Parameters[i].Type
+		case decl.VariadicParameter != nil:
+			ty = decl.VariadicParameter.Type
+		default:
+			return nil, badRequest("too many arguments, expect %d", len(decl.Parameters))
+		}
+		args = append(args, dec.value(fmt.Sprintf("arguments.%d", i), arg, ty))
+	}
+	if dec.err != nil {
+		return nil, badRequest("%v", dec.err)
+	}
+
+	resp, diags := h.client.CallFunction(r.Context(), typ.CallFunctionRequest{
+		FunctionName: name,
+		Arguments:    args,
+	})
+	if resp == nil {
+		return DiagnosticsResponse{result: newResult(diags, &encoder{})}, nil
+	}
+	var enc encoder
+	out := CallFunctionResponse{}
+	if resp.Err != nil {
+		out.Error = resp.Err.Error()
+		var argErr function.ArgError
+		if errors.As(resp.Err, &argErr) {
+			out.ErrorArgument = &argErr.Index
+		}
+	} else {
+		out.Result = enc.value("result", resp.Result, decl.ReturnType)
+	}
+	if enc.err != nil {
+		return nil, enc.err
+	}
+	out.result = newResult(diags, &enc)
+	return out, nil
+}
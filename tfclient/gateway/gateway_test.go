@@ -0,0 +1,303 @@
+package gateway
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	tfjson "github.com/hashicorp/terraform-json"
+	"github.com/magodo/terraform-client-go/tfclient"
+	"github.com/magodo/terraform-client-go/tfclient/configschema"
+	"github.com/magodo/terraform-client-go/tfclient/typ"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// fakeClient implements the RPCs used by the tests, the others panic.
+type fakeClient struct {
+	tfclient.Client
+}
+
+func (fakeClient) PlanResourceChange(_ context.Context, req typ.PlanResourceChangeRequest) (*typ.PlanResourceChangeResponse, typ.Diagnostics) {
+	attrs := req.ProposedNewState.AsValueMap()
+	attrs["id"] = cty.UnknownVal(cty.String)
+	return &typ.PlanResourceChangeResponse{
+		PlannedState:    cty.ObjectVal(attrs),
+		RequiresReplace: []cty.Path{cty.GetAttrPath("name")},
+	}, typ.Diagnostics{{Severity: typ.Warning, Summary: "planned"}}
+}
+
+func (fakeClient) ApplyResourceChange(_ context.Context, req typ.ApplyResourceChangeRequest) (*typ.ApplyResourceChangeResponse, typ.Diagnostics) {
+	attrs := req.PlannedState.AsValueMap()
+	if attrs["id"].IsKnown() {
+		return nil, typ.Diagnostics{{Severity: typ.Error, Summary: "id is expected to be unknown"}}
+	}
+	attrs["id"] = cty.StringVal("id-" + attrs["name"].AsString())
+	return &typ.ApplyResourceChangeResponse{NewState: cty.ObjectVal(attrs)}, nil
+}
+
+func (fakeClient) ListResource(_ context.Context, req typ.ListResourceRequest) (typ.ListResourceResponse, typ.Diagnostics) {
+	var results []cty.Value
+	for i := range req.Limit {
+		results = append(results, cty.ObjectVal(map[string]cty.Value{
+			"display_name": cty.StringVal(fmt.Sprintf("res%d", i)),
+		}))
+	}
+	return typ.ListResourceResponse{Result: cty.ObjectVal(map[string]cty.Value{
+		"data":   cty.TupleVal(results),
+		"config": req.Config,
+	})}, nil
+}
+
+func (fakeClient) CallFunction(_ context.Context, req typ.CallFunctionRequest) (*typ.CallFunctionResponse, typ.Diagnostics) {
+	return &typ.CallFunctionResponse{Result: cty.StringVal(strings.ToUpper(req.Arguments[0].AsString()))}, nil
+}
+
+func (fakeClient) Stop(context.Context) error {
+	return nil
+}
+
+func newTestServer(t *testing.T) *httptest.Server {
+	block := &tfjson.SchemaBlock{
+		Attributes: map[string]*tfjson.SchemaAttribute{
+			"id":   {AttributeType: cty.String, Computed: true},
+			"name": {AttributeType: cty.String, Required: true},
+			"tags": {AttributeType: cty.Map(cty.String), Optional: true},
+		},
+	}
+	listBlock := &tfjson.SchemaBlock{
+		Attributes: map[string]*tfjson.SchemaAttribute{
+			"filter": {AttributeType: cty.String, Optional: true},
+		},
+	}
+	schema := &typ.GetProviderSchemaResponse{
+		ProviderCty:          cty.EmptyObject,
+		ResourceTypes:        map[string]tfjson.Schema{"foo_thing": {Block: block}},
+		ResourceTypesCty:     map[string]cty.Type{"foo_thing": configschema.SchemaBlockImpliedType(block)},
+		ListResourceTypes:    map[string]tfjson.Schema{"foo_thing": {Block: listBlock}},
+		ListResourceTypesCty: map[string]cty.Type{"foo_thing": configschema.SchemaBlockImpliedType(listBlock)},
+		Functions: map[string]typ.FunctionDecl{
+			"upper": {
+				Parameters: []typ.FunctionParam{{Name: "input", Type: cty.String}},
+				ReturnType: cty.String,
+			},
+		},
+	}
+	srv := httptest.NewServer(New(fakeClient{}, schema, "registry.terraform.io/hashicorp/foo", Option{}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func post(t *testing.T, srv *httptest.Server, path, body string) (int, string) {
+	t.Helper()
+	resp, err := http.Post(srv.URL+path, "application/json", strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var sb strings.Builder
+	sc := bufio.NewScanner(resp.Body)
+	for sc.Scan() {
+		sb.WriteString(sc.Text() + "\n")
+	}
+	return resp.StatusCode, sb.String()
+}
+
+func TestPlanApply(t *testing.T) {
+	srv := newTestServer(t)
+
+	status, body := post(t, srv, "/resources/foo_thing/plan", `{"prior_state": null, "config": {"id": null, "name": "a", "tags": null}}`)
+	if status != http.StatusOK {
+		t.Fatalf("plan: unexpected status %d: %s", status, body)
+	}
+	var plan struct {
+		PlannedState    json.RawMessage `json:"planned_state"`
+		RequiresReplace []Path          `json:"requires_replace"`
+		Diagnostics     []Diagnostic    `json:"diagnostics"`
+		UnknownPaths    UnknownPaths    `json:"unknown_paths"`
+	}
+	if err := json.Unmarshal([]byte(body), &plan); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(UnknownPaths{"planned_state": {{"id"}}}, plan.UnknownPaths); diff != "" {
+		t.Errorf("wrong unknown paths\n%s", diff)
+	}
+	if diff := cmp.Diff([]Path{{"name"}}, plan.RequiresReplace); diff != "" {
+		t.Errorf("wrong requires_replace\n%s", diff)
+	}
+	if diff := cmp.Diff([]Diagnostic{{Severity: "warning", Summary: "planned"}}, plan.Diagnostics); diff != "" {
+		t.Errorf("wrong diagnostics\n%s", diff)
+	}
+
+	// Pass the planned state back to apply, along with its unknown paths
+	unknowns, _ := json.Marshal(plan.UnknownPaths)
+	status, body = post(t, srv, "/resources/foo_thing/apply", fmt.Sprintf(`{"planned_state": %s, "config": {"id": null, "name": "a", "tags": null}, "unknown_paths": %s}`, plan.PlannedState, unknowns))
+	if status != http.StatusOK {
+		t.Fatalf("apply: unexpected status %d: %s", status, body)
+	}
+	want := `{"new_state":{"id":"id-a","name":"a","tags":null},"diagnostics":[]}` + "\n"
+	if body != want {
+		t.Errorf("apply: wrong response\ngot:  %s\nwant: %s", body, want)
+	}
+}
+
+func TestListStream(t *testing.T) {
+	srv := newTestServer(t)
+	status, body := post(t, srv, "/list-resources/foo_thing/list", `{"config": {"filter": null}, "limit": 2}`)
+	if status != http.StatusOK {
+		t.Fatalf("unexpected status %d: %s", status, body)
+	}
+	want := `{"type":"result","result":{"display_name":"res0"}}
+{"type":"result","result":{"display_name":"res1"}}
+{"type":"done"}
+`
+	if body != want {
+		t.Errorf("wrong response\ngot:\n%s\nwant:\n%s", body, want)
+	}
+}
+
+func TestCallFunction(t *testing.T) {
+	srv := newTestServer(t)
+	status, body := post(t, srv, "/functions/upper/call", `{"arguments": ["abc"]}`)
+	if status != http.StatusOK {
+		t.Fatalf("unexpected status %d: %s", status, body)
+	}
+	if want := `{"result":"ABC","diagnostics":[]}` + "\n"; body != want {
+		t.Errorf("wrong response\ngot:  %s\nwant: %s", body, want)
+	}
+}
+
+func TestInvalidRequest(t *testing.T) {
+	srv := newTestServer(t)
+	cases := []struct {
+		path   string
+		body   string
+		status int
+	}{
+		{"/resources/bar_thing/plan", `{}`, http.StatusNotFound},
+		{"/functions/lower/call", `{}`, http.StatusNotFound},
+		{"/resources/foo_thing/plan", `{"unknown_field": 1}`, http.StatusBadRequest},
+		{"/resources/foo_thing/plan", `{"config": {"name": [1]}}`, http.StatusBadRequest},
+		{"/functions/upper/call", `{"arguments": ["a", "b"]}`, http.StatusBadRequest},
+	}
+	for _, c := range cases {
+		status, body := post(t, srv, c.path, c.body)
+		if status != c.status {
+			t.Errorf("%s %s: got status %d, want %d: %s", c.path, c.body, status, c.status, body)
+		}
+	}
+}
+
+func TestOpenAPI(t *testing.T) {
+	srv := newTestServer(t)
+	resp, err := http.Get(srv.URL + "/openapi.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var doc struct {
+		Paths      map[string]json.RawMessage `json:"paths"`
+		Components struct {
+			Schemas map[string]json.RawMessage `json:"schemas"`
+		} `json:"components"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&doc); err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range []string{"/resources/foo_thing/plan", "/list-resources/foo_thing/list", "/functions/upper/call", "/provider/configure"} {
+		if _, ok := doc.Paths[p]; !ok {
+			t.Errorf("missing path %s", p)
+		}
+	}
+	for _, s := range []string{"resource.foo_thing", "list_resource.foo_thing", "Diagnostic", "UnknownPaths"} {
+		if _, ok := doc.Components.Schemas[s]; !ok {
+			t.Errorf("missing schema %s", s)
+		}
+	}
+}
+
+func TestUnknownPathsRoundTrip(t *testing.T) {
+	ty := cty.Object(map[string]cty.Type{
+		"a": cty.List(cty.Object(map[string]cty.Type{"b": cty.String})),
+		"m": cty.Map(cty.Number),
+		"s": cty.Set(cty.String),
+	})
+	v := cty.ObjectVal(map[string]cty.Value{
+		"a": cty.ListVal([]cty.Value{
+			cty.ObjectVal(map[string]cty.Value{"b": cty.StringVal("x")}),
+			cty.ObjectVal(map[string]cty.Value{"b": cty.UnknownVal(cty.String)}),
+		}),
+		"m": cty.MapVal(map[string]cty.Value{"k": cty.UnknownVal(cty.Number)}),
+		"s": cty.SetVal([]cty.Value{cty.StringVal("x"), cty.UnknownVal(cty.String)}),
+	})
+
+	var enc encoder
+	b := enc.value("v", v, ty)
+	if enc.err != nil {
+		t.Fatal(enc.err)
+	}
+	if diff := cmp.Diff(UnknownPaths{"v": {{"a", int64(1), "b"}, {"m", "k"}, {"s"}}}, enc.unknowns); diff != "" {
+		t.Errorf("wrong unknown paths\n%s", diff)
+	}
+
+	// Round trip through JSON, so that the index steps are numbers
+	pb, _ := json.Marshal(enc.unknowns)
+	var unknowns UnknownPaths
+	if err := json.Unmarshal(pb, &unknowns); err != nil {
+		t.Fatal(err)
+	}
+	dec := decoder{unknowns: unknowns}
+	got := dec.value("v", b, ty)
+	if dec.err != nil {
+		t.Fatal(dec.err)
+	}
+	// The set is unknown as a whole, as its elements are not addressable
+	want := cty.ObjectVal(map[string]cty.Value{
+		"a": v.GetAttr("a"),
+		"m": v.GetAttr("m"),
+		"s": cty.UnknownVal(cty.Set(cty.String)),
+	})
+	if !got.RawEquals(want) {
+		t.Errorf("wrong result\ngot:  %#v\nwant: %#v", got, want)
+	}
+}
+
+func TestRequestGuards(t *testing.T) {
+	schema := &typ.GetProviderSchemaResponse{ProviderCty: cty.EmptyObject}
+	h := New(fakeClient{}, schema, "registry.terraform.io/hashicorp/foo", Option{Token: "secret"})
+
+	cases := []struct {
+		name        string
+		host        string
+		contentType string
+		token       string
+		body        string
+		status      int
+	}{
+		{name: "ok", host: "127.0.0.1:8080", contentType: "application/json", token: "secret", body: `{}`, status: http.StatusOK},
+		{name: "localhost", host: "localhost:8080", contentType: "application/json; charset=utf-8", token: "secret", body: `{}`, status: http.StatusOK},
+		{name: "rebound host", host: "attacker.example.com", contentType: "application/json", token: "secret", body: `{}`, status: http.StatusForbidden},
+		{name: "missing token", host: "127.0.0.1", contentType: "application/json", body: `{}`, status: http.StatusUnauthorized},
+		{name: "wrong token", host: "127.0.0.1", contentType: "application/json", token: "guess", body: `{}`, status: http.StatusUnauthorized},
+		{name: "text plain", host: "127.0.0.1", contentType: "text/plain", token: "secret", body: `{}`, status: http.StatusUnsupportedMediaType},
+		{name: "too large", host: "127.0.0.1", contentType: "application/json", token: "secret", body: `{"config": "` + strings.Repeat("a", MaxBodyBytes) + `"}`, status: http.StatusRequestEntityTooLarge},
+	}
+	for _, c := range cases {
+		req := httptest.NewRequest(http.MethodPost, "/provider/stop", strings.NewReader(c.body))
+		req.Host = c.host
+		req.Header.Set("Content-Type", c.contentType)
+		if c.token != "" {
+			req.Header.Set("Authorization", "Bearer "+c.token)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		if w.Code != c.status {
+			t.Errorf("%s: got status %d, want %d: %s", c.name, w.Code, c.status, w.Body.String())
+		}
+	}
+}
@@ -0,0 +1,373 @@
+package gateway
+
+import (
+	"sort"
+
+	"github.com/magodo/terraform-client-go/tfclient/typ"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// OpenAPIVersion is the version of the OpenAPI specification that the generated document conforms to.
+const OpenAPIVersion = "3.0.3"
+
+type jsonObject = map[string]any
+
+// OpenAPI generates the OpenAPI document of the gateway endpoints from the provider schema.
+func OpenAPI(schema *typ.GetProviderSchemaResponse) jsonObject {
+	g := openAPIGen{
+		paths:   jsonObject{},
+		schemas: commonSchemas(),
+	}
+
+	g.schemas["provider"] = typeSchema(schema.ProviderCty)
+	g.post("/provider/validate", "Validate the provider config", configRequest(ref("provider")), withResult(nil))
+	g.post("/provider/configure", "Configure the provider", object(jsonObject{
+		"config":            ref("provider"),
+		"terraform_version": jsonObject{"type": "string"},
+	}), withResult(nil))
+	g.post("/provider/stop", "Stop the provider", object(nil), withResult(nil))
+
+	for _, name := range sortedKeys(schema.ResourceTypes) {
+		sch := schema.ResourceTypes[name]
+		s := "resource." + name
+		id := s + ".identity"
+		g.schemas[s] = typeSchema(schema.ResourceTypesCty[name])
+		g.schemas[id] = typeSchema(identityType(sch))
+		p := "/resources/" + name
+		g.post(p+"/validate", "Validate the config of "+name, configRequest(ref(s)), withResult(nil))
+		g.post(p+"/read", "Read "+name, object(jsonObject{
+			"state":         ref(s),
+			"private":       bytesSchema,
+			"identity":      ref(id),
+			"unknown_paths": ref("UnknownPaths"),
+		}, "state"), withResult(jsonObject{
+			"new_state": ref(s),
+			"private":   bytesSchema,
+			"identity":  ref(id),
+			"deferred":  deferredSchema,
+		}))
+		g.post(p+"/plan", "Plan the change of "+name, object(jsonObject{
+			"prior_state":        ref(s),
+			"config":             ref(s),
+			"proposed_new_state": ref(s),
+			"prior_private":      bytesSchema,
+			"prior_identity":     ref(id),
+			"unknown_paths":      ref("UnknownPaths"),
+		}), withResult(jsonObject{
+			"planned_state":    ref(s),
+			"requires_replace": jsonObject{"type": "array", "items": ref("Path")},
+			"planned_private":  bytesSchema,
+			"planned_identity": ref(id),
+			"deferred":         deferredSchema,
+		}))
+		g.post(p+"/apply", "Apply the change of "+name, object(jsonObject{
+			"prior_state":      ref(s),
+			"planned_state":    ref(s),
+			"config":           ref(s),
+			"planned_private":  bytesSchema,
+			"planned_identity": ref(id),
+			"unknown_paths":    ref("UnknownPaths"),
+		}), withResult(jsonObject{
+			"new_state":    ref(s),
+			"private":      bytesSchema,
+			"new_identity": ref(id),
+		}))
+		g.post(p+"/import", "Import "+name+" by either the id or the identity", object(jsonObject{
+			"id":       jsonObject{"type": "string"},
+			"identity": ref(id),
+		}), withResult(jsonObject{
+			"imported_resources": jsonObject{"type": "array", "items": object(jsonObject{
+				"type_name": jsonObject{"type": "string"},
+				"state":     jsonObject{"description": "The state of the imported resource, whose schema depends on the type_name"},
+				"private":   bytesSchema,
+				"identity":  jsonObject{"description": "The identity of the imported resource, whose schema depends on the type_name"},
+			})},
+			"deferred": deferredSchema,
+		}))
+		g.post(p+"/move", "Move a resource state to "+name, object(jsonObject{
+			"source_provider_address": jsonObject{"type": "string"},
+			"source_type_name":        jsonObject{"type": "string"},
+			"source_schema_version":   jsonObject{"type": "integer"},
+			"source_state":            jsonObject{"description": "The raw source state, as is stored in the state file"},
+			"source_private":          bytesSchema,
+			"source_identity":         jsonObject{"description": "The raw source identity, as is stored in the state file"},
+		}, "source_provider_address", "source_type_name", "source_state"), withResult(jsonObject{
+			"target_state":    ref(s),
+			"target_private":  bytesSchema,
+			"target_identity": ref(id),
+		}))
+		g.post(p+"/upgrade-state", "Upgrade the state of "+name, object(jsonObject{
+			"version":   jsonObject{"type": "integer"},
+			"raw_state": jsonObject{"description": "The raw state, as is stored in the state file"},
+		}, "raw_state"), withResult(jsonObject{
+			"upgraded_state": ref(s),
+		}))
+		g.post(p+"/upgrade-identity", "Upgrade the identity of "+name, object(jsonObject{
+			"version":      jsonObject{"type": "integer"},
+			"raw_identity": jsonObject{"description": "The raw identity, as is stored in the state file"},
+		}, "raw_identity"), withResult(jsonObject{
+			"upgraded_identity": ref(id),
+		}))
+	}
+
+	for _, name := range sortedKeys(schema.DataSources) {
+		s := "data_source." + name
+		g.schemas[s] = typeSchema(schema.DataSourcesCty[name])
+		p := "/data-sources/" + name
+		g.post(p+"/validate", "Validate the config of "+name, configRequest(ref(s)), withResult(nil))
+		g.post(p+"/read", "Read "+name, configRequest(ref(s)), withResult(jsonObject{
+			"state":    ref(s),
+			"deferred": deferredSchema,
+		}))
+	}
+
+	for _, name := range sortedKeys(schema.EphemeralResourceTypes) {
+		s := "ephemeral_resource." + name
+		g.schemas[s] = typeSchema(schema.EphemeralResourceTypesCty[name])
+		p := "/ephemeral-resources/" + name
+		privateRequest := object(jsonObject{"private": bytesSchema})
+		g.post(p+"/validate", "Validate the config of "+name, configRequest(ref(s)), withResult(nil))
+		g.post(p+"/open", "Open "+name, configRequest(ref(s)), withResult(jsonObject{
+			"result":   ref(s),
+			"private":  bytesSchema,
+			"renew_at": jsonObject{"type": "string", "format": "date-time"},
+			"deferred": deferredSchema,
+		}))
+		g.post(p+"/renew", "Renew "+name, privateRequest, withResult(jsonObject{
+			"private":  bytesSchema,
+			"renew_at": jsonObject{"type": "string", "format": "date-time"},
+		}))
+		g.post(p+"/close", "Close "+name, privateRequest, withResult(nil))
+	}
+
+	for _, name := range sortedKeys(schema.ListResourceTypes) {
+		s := "list_resource." + name
+		g.schemas[s] = typeSchema(schema.ListResourceTypesCty[name])
+		p := "/list-resources/" + name
+		g.post(p+"/validate", "Validate the config of "+name, configRequest(ref(s)), withResult(nil))
+		g.stream(p+"/list", "List "+name+", where each result is streamed as a \"result\" event", object(jsonObject{
+			"config":           ref(s),
+			"include_resource": jsonObject{"type": "boolean"},
+			"limit":            jsonObject{"type": "integer", "default": DefaultListLimit},
+		}))
+	}
+
+	for _, name := range sortedKeys(schema.Actions) {
+		s := "action." + name
+		g.schemas[s] = typeSchema(schema.ActionsCty[name])
+		p := "/actions/" + name
+		g.post(p+"/validate", "Validate the config of "+name, configRequest(ref(s)), withResult(nil))
+		g.post(p+"/plan", "Plan "+name, configRequest(ref(s)), withResult(jsonObject{
+			"deferred": deferredSchema,
+		}))
+		g.stream(p+"/invoke", "Invoke "+name+", where the progresses are streamed as \"progress\" events", configRequest(ref(s)))
+	}
+
+	for _, name := range sortedKeys(schema.Functions) {
+		decl := schema.Functions[name]
+		var items []any
+		for _, p := range decl.Parameters {
+			ps := typeSchema(p.Type)
+			ps["title"] = p.Name
+			items = append(items, ps)
+		}
+		args := jsonObject{"type": "array", "minItems": len(decl.Parameters)}
+		if len(items) != 0 {
+			// OpenAPI 3.0 has no tuple validation, the positional schemas are listed as alternatives.
+			args["items"] = jsonObject{"anyOf": items}
+		}
+		if decl.VariadicParameter == nil {
+			args["maxItems"] = len(decl.Parameters)
+		} else {
+			vs := typeSchema(decl.VariadicParameter.Type)
+			vs["title"] = decl.VariadicParameter.Name
+			args["items"] = jsonObject{"anyOf": append(items, vs)}
+		}
+		op := g.post("/functions/"+name+"/call", "Call "+name, object(jsonObject{"arguments": args}), withResult(jsonObject{
+			"result":         typeSchema(decl.ReturnType),
+			"error":          jsonObject{"type": "string"},
+			"error_argument": jsonObject{"type": "integer"},
+		}))
+		if decl.Description != "" {
+			op["description"] = decl.Description
+		}
+	}
+
+	g.paths["/schema"] = jsonObject{"get": jsonObject{
+		"summary":   "Get the provider schema, in the format of `terraform providers schema -json`",
+		"responses": jsonObject{"200": jsonObject{"description": "OK", "content": jsonObject{"application/json": jsonObject{"schema": jsonObject{"type": "object"}}}}},
+	}}
+	g.paths["/openapi.json"] = jsonObject{"get": jsonObject{
+		"summary":   "Get this OpenAPI document",
+		"responses": jsonObject{"200": jsonObject{"description": "OK", "content": jsonObject{"application/json": jsonObject{"schema": jsonObject{"type": "object"}}}}},
+	}}
+
+	return jsonObject{
+		"openapi": OpenAPIVersion,
+		"info": jsonObject{
+			"title":   "Terraform provider gateway",
+			"version": "1.0",
+		},
+		"paths":      g.paths,
+		"components": jsonObject{"schemas": g.schemas},
+	}
+}
+
+type openAPIGen struct {
+	paths   jsonObject
+	schemas jsonObject
+}
+
+var errorResponses = jsonObject{
+	"400": jsonObject{"description": "Invalid request", "content": jsonObject{"application/json": jsonObject{"schema": ref("Error")}}},
+	"404": jsonObject{"description": "Unknown type or function", "content": jsonObject{"application/json": jsonObject{"schema": ref("Error")}}},
+}
+
+func (g *openAPIGen) operation(path, summary string, req jsonObject, resp jsonObject) jsonObject {
+	responses := jsonObject{"200": resp}
+	for k, v := range errorResponses {
+		responses[k] = v
+	}
+	op := jsonObject{
+		"summary":     summary,
+		"requestBody": jsonObject{"content": jsonObject{"application/json": jsonObject{"schema": req}}},
+		"responses":   responses,
+	}
+	g.paths[path] = jsonObject{"post": op}
+	return op
+}
+
+func (g *openAPIGen) post(path, summary string, req, resp jsonObject) jsonObject {
+	return g.operation(path, summary, req, jsonObject{
+		"description": "OK",
+		"content":     jsonObject{"application/json": jsonObject{"schema": resp}},
+	})
+}
+
+func (g *openAPIGen) stream(path, summary string, req jsonObject) jsonObject {
+	return g.operation(path, summary, req, jsonObject{
+		"description": "A stream of newline delimited events, ending with a \"done\" event",
+		"content":     jsonObject{"application/x-ndjson": jsonObject{"schema": ref("StreamEvent")}},
+	})
+}
+
+func ref(name string) jsonObject {
+	return jsonObject{"$ref": "#/components/schemas/" + name}
+}
+
+func object(props jsonObject, required ...string) jsonObject {
+	if props == nil {
+		props = jsonObject{}
+	}
+	out := jsonObject{"type": "object", "properties": props}
+	if len(required) != 0 {
+		out["required"] = required
+	}
+	return out
+}
+
+func configRequest(config jsonObject) jsonObject {
+	return object(jsonObject{
+		"config":        config,
+		"unknown_paths": ref("UnknownPaths"),
+	})
+}
+
+// withResult returns the response object schema with the common result fields.
+func withResult(props jsonObject) jsonObject {
+	if props == nil {
+		props = jsonObject{}
+	}
+	props["diagnostics"] = jsonObject{"type": "array", "items": ref("Diagnostic")}
+	props["unknown_paths"] = ref("UnknownPaths")
+	return object(props, "diagnostics")
+}
+
+var bytesSchema = jsonObject{"type": "string", "format": "byte"}
+
+var deferredSchema = jsonObject{"type": "string", "description": "The reason of the deferral, if any"}
+
+func commonSchemas() jsonObject {
+	return jsonObject{
+		"Path": jsonObject{
+			"type":        "array",
+			"description": "A path into a value, where a string step is an attribute name or a map key, and an integer step is a list or tuple index",
+			"items":       jsonObject{"oneOf": []any{jsonObject{"type": "string"}, jsonObject{"type": "integer"}}},
+		},
+		"UnknownPaths": jsonObject{
+			"type":                 "object",
+			"description":          "The paths of the unknown values, which are written as null, keyed by the value field name",
+			"additionalProperties": jsonObject{"type": "array", "items": ref("Path")},
+		},
+		"Diagnostic": object(jsonObject{
+			"severity":  jsonObject{"type": "string", "enum": []string{"error", "warning"}},
+			"summary":   jsonObject{"type": "string"},
+			"detail":    jsonObject{"type": "string"},
+			"attribute": ref("Path"),
+		}, "severity", "summary"),
+		"Error": object(jsonObject{
+			"error": jsonObject{"type": "string"},
+		}, "error"),
+		"StreamEvent": object(jsonObject{
+			"type":          jsonObject{"type": "string", "enum": []string{"result", "progress", "completed", "done"}},
+			"result":        jsonObject{"description": "The list result, with the display_name, state and identity"},
+			"message":       jsonObject{"type": "string"},
+			"diagnostics":   jsonObject{"type": "array", "items": ref("Diagnostic")},
+			"unknown_paths": ref("UnknownPaths"),
+		}, "type"),
+	}
+}
+
+// typeSchema returns the JSON schema of the JSON encoding of the cty type.
+func typeSchema(ty cty.Type) jsonObject {
+	var out jsonObject
+	switch {
+	case ty == cty.DynamicPseudoType:
+		return jsonObject{
+			"description": `A dynamic value, encoded as {"value": <value>, "type": <type>}`,
+			"type":        "object",
+			"properties":  jsonObject{"value": jsonObject{}, "type": jsonObject{}},
+		}
+	case ty == cty.String:
+		out = jsonObject{"type": "string"}
+	case ty == cty.Number:
+		out = jsonObject{"type": "number"}
+	case ty == cty.Bool:
+		out = jsonObject{"type": "boolean"}
+	case ty.IsListType():
+		out = jsonObject{"type": "array", "items": typeSchema(ty.ElementType())}
+	case ty.IsSetType():
+		out = jsonObject{"type": "array", "uniqueItems": true, "items": typeSchema(ty.ElementType())}
+	case ty.IsTupleType():
+		var items []any
+		for _, ety := range ty.TupleElementTypes() {
+			items = append(items, typeSchema(ety))
+		}
+		n := len(items)
+		out = jsonObject{"type": "array", "minItems": n, "maxItems": n}
+		if n != 0 {
+			out["items"] = jsonObject{"anyOf": items}
+		}
+	case ty.IsMapType():
+		out = jsonObject{"type": "object", "additionalProperties": typeSchema(ty.ElementType())}
+	case ty.IsObjectType():
+		props := jsonObject{}
+		for name, aty := range ty.AttributeTypes() {
+			props[name] = typeSchema(aty)
+		}
+		out = jsonObject{"type": "object", "properties": props}
+	default:
+		out = jsonObject{}
+	}
+	out["nullable"] = true
+	return out
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	var out []string
+	for k := range m {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+	return out
+}
@@ -0,0 +1,259 @@
+package gateway
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	tfjson "github.com/hashicorp/terraform-json"
+	"github.com/magodo/terraform-client-go/tfclient/configschema"
+	"github.com/magodo/terraform-client-go/tfclient/typ"
+	"github.com/zclconf/go-cty/cty"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
+)
+
+// Path is a path into a value in JSON, where a string step is an attribute name or a map key, and a
+// number step is a list or tuple index.
+type Path []any
+
+// UnknownPaths maps the name of a value field to the paths of the unknown values inside it.
+type UnknownPaths map[string][]Path
+
+// Diagnostic is the JSON form of typ.Diagnostic.
+type Diagnostic struct {
+	Severity  string `json:"severity"`
+	Summary   string `json:"summary"`
+	Detail    string `json:"detail,omitempty"`
+	Attribute Path   `json:"attribute,omitempty"`
+}
+
+func toDiagnostics(diags typ.Diagnostics) []Diagnostic {
+	out := []Diagnostic{}
+	for _, d := range diags {
+		sev := "error"
+		if d.Severity == typ.Warning {
+			sev = "warning"
+		}
+		out = append(out, Diagnostic{
+			Severity:  sev,
+			Summary:   d.Summary,
+			Detail:    d.Detail,
+			Attribute: toPath(d.Attribute),
+		})
+	}
+	return out
+}
+
+func toPath(p cty.Path) Path {
+	if len(p) == 0 {
+		return nil
+	}
+	out := Path{}
+	for _, step := range p {
+		switch step := step.(type) {
+		case cty.GetAttrStep:
+			out = append(out, step.Name)
+		case cty.IndexStep:
+			switch {
+			case step.Key.Type() == cty.String:
+				out = append(out, step.Key.AsString())
+			case step.Key.Type() == cty.Number:
+				i, _ := step.Key.AsBigFloat().Int64()
+				out = append(out, i)
+			default:
+				// Set elements have no addressable key, stop at the set itself.
+				return out
+			}
+		}
+	}
+	return out
+}
+
+// encoder encodes cty values into JSON, with the unknown values written as null and recorded in the
+// unknown paths.
+type encoder struct {
+	unknowns UnknownPaths
+	err      error
+}
+
+func (e *encoder) value(name string, v cty.Value, ty cty.Type) json.RawMessage {
+	if e.err != nil {
+		return nil
+	}
+	if v == cty.NilVal {
+		// Absent value, which is omitted from the optional fields
+		return nil
+	}
+	v, _ = v.UnmarkDeep()
+	var paths []Path
+	v = nullUnknowns(v, Path{}, &paths)
+	if len(paths) != 0 {
+		if e.unknowns == nil {
+			e.unknowns = UnknownPaths{}
+		}
+		e.unknowns[name] = paths
+	}
+	b, err := ctyjson.Marshal(v, ty)
+	if err != nil {
+		e.err = fmt.Errorf("encoding %s: %v", name, err)
+		return nil
+	}
+	return b
+}
+
+// nullUnknowns replaces the unknown values with null and records their paths. A set that contains
+// unknown values is recorded as a whole, as its elements are not addressable.
+func nullUnknowns(v cty.Value, path Path, paths *[]Path) cty.Value {
+	if !v.IsKnown() || (v.Type().IsSetType() && !v.IsWhollyKnown()) {
+		*paths = append(*paths, append(Path{}, path...))
+		return cty.NullVal(v.Type())
+	}
+	if v.IsNull() {
+		return v
+	}
+	ty := v.Type()
+	switch {
+	case ty.IsObjectType():
+		if len(ty.AttributeTypes()) == 0 {
+			return v
+		}
+		attrs := map[string]cty.Value{}
+		for it := v.ElementIterator(); it.Next(); {
+			k, av := it.Element()
+			attrs[k.AsString()] = nullUnknowns(av, append(path, k.AsString()), paths)
+		}
+		return cty.ObjectVal(attrs)
+	case ty.IsMapType():
+		if v.LengthInt() == 0 {
+			return v
+		}
+		elems := map[string]cty.Value{}
+		for it := v.ElementIterator(); it.Next(); {
+			k, ev := it.Element()
+			elems[k.AsString()] = nullUnknowns(ev, append(path, k.AsString()), paths)
+		}
+		return cty.MapVal(elems)
+	case ty.IsListType(), ty.IsTupleType():
+		if v.LengthInt() == 0 {
+			return v
+		}
+		var elems []cty.Value
+		for i, ev := range v.AsValueSlice() {
+			elems = append(elems, nullUnknowns(ev, append(path, int64(i)), paths))
+		}
+		if ty.IsListType() {
+			return cty.ListVal(elems)
+		}
+		return cty.TupleVal(elems)
+	default:
+		return v
+	}
+}
+
+// decoder decodes JSON into cty values, with the values at the unknown paths set as unknown.
+type decoder struct {
+	unknowns UnknownPaths
+	err      error
+}
+
+func (d *decoder) value(name string, b json.RawMessage, ty cty.Type) cty.Value {
+	return d.decode(name, b, func(b []byte) (cty.Value, error) {
+		return ctyjson.Unmarshal(b, ty)
+	})
+}
+
+// block decodes the value of the block, where the optional attributes and the nested blocks can be omitted, and the
+// values of compatible types are converted.
+func (d *decoder) block(name string, b json.RawMessage, sch *tfjson.SchemaBlock) cty.Value {
+	return d.decode(name, b, func(b []byte) (cty.Value, error) {
+		return configschema.SchemaBlockUnmarshalJSON(sch, b)
+	})
+}
+
+// identity decodes the resource identity, where the optional attributes can be omitted.
+func (d *decoder) identity(name string, b json.RawMessage, sch tfjson.Schema) cty.Value {
+	return d.decode(name, b, func(b []byte) (cty.Value, error) {
+		return configschema.SchemaNestedAttributeTypeUnmarshalJSON(sch.Identity, b)
+	})
+}
+
+func (d *decoder) decode(name string, b json.RawMessage, unmarshal func([]byte) (cty.Value, error)) cty.Value {
+	if d.err != nil {
+		return cty.NilVal
+	}
+	if len(bytes.TrimSpace(b)) == 0 {
+		b = json.RawMessage("null")
+	}
+	v, err := unmarshal(b)
+	if err != nil {
+		err = errors.New(typ.FormatError(err))
+		d.err = fmt.Errorf("decoding %s: %v", name, err)
+		return cty.NilVal
+	}
+	for _, p := range d.unknowns[name] {
+		v, err = setUnknown(v, p)
+		if err != nil {
+			d.err = fmt.Errorf("decoding %s: %v", name, err)
+			return cty.NilVal
+		}
+	}
+	return v
+}
+
+// setUnknown sets the value at the path as unknown.
+func setUnknown(v cty.Value, path Path) (cty.Value, error) {
+	if len(path) == 0 {
+		return cty.UnknownVal(v.Type()), nil
+	}
+	if v.IsNull() || !v.IsKnown() {
+		return cty.NilVal, fmt.Errorf("unknown path %v goes through a null or unknown value", path)
+	}
+	ty := v.Type()
+	switch {
+	case ty.IsObjectType(), ty.IsMapType():
+		key, ok := path[0].(string)
+		if !ok {
+			return cty.NilVal, fmt.Errorf("invalid path step %v, expect a string", path[0])
+		}
+		elems := v.AsValueMap()
+		ev, ok := elems[key]
+		if !ok {
+			return cty.NilVal, fmt.Errorf("invalid path step %q, no such attribute or key", key)
+		}
+		ev, err := setUnknown(ev, path[1:])
+		if err != nil {
+			return cty.NilVal, err
+		}
+		elems[key] = ev
+		if ty.IsObjectType() {
+			return cty.ObjectVal(elems), nil
+		}
+		return cty.MapVal(elems), nil
+	case ty.IsListType(), ty.IsTupleType():
+		var idx int
+		switch step := path[0].(type) {
+		case float64:
+			idx = int(step)
+		case int64:
+			idx = int(step)
+		default:
+			return cty.NilVal, fmt.Errorf("invalid path step %v, expect a number", path[0])
+		}
+		elems := v.AsValueSlice()
+		if idx < 0 || idx >= len(elems) {
+			return cty.NilVal, fmt.Errorf("invalid path step %d, index out of range", idx)
+		}
+		ev, err := setUnknown(elems[idx], path[1:])
+		if err != nil {
+			return cty.NilVal, err
+		}
+		elems[idx] = ev
+		if ty.IsListType() {
+			return cty.ListVal(elems), nil
+		}
+		return cty.TupleVal(elems), nil
+	default:
+		return cty.NilVal, fmt.Errorf("invalid path step %v into %s", path[0], ty.FriendlyName())
+	}
+}
@@ -0,0 +1,285 @@
+// This is derived from github.com/hashicorp/terraform/internal/genconfig/generate_config.go (v1.13.0-alpha20250521)
+
+// Package genconfig generates the HCL configuration of a resource from its state, in the
+// similar way as `terraform plan -generate-config-out` does.
+package genconfig
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	tfjson "github.com/hashicorp/terraform-json"
+	"github.com/zclconf/go-cty/cty"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
+)
+
+// GenerateResource generates the resource block of the given resource type and name, whose
+// body is generated by GenerateResourceContents.
+func GenerateResource(resourceType, name string, schema *tfjson.SchemaBlock, state cty.Value) ([]byte, error) {
+	if !hclsyntax.ValidIdentifier(name) {
+		return nil, fmt.Errorf("invalid resource name %q", name)
+	}
+	body, err := GenerateResourceContents(schema, state)
+	if err != nil {
+		return nil, err
+	}
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "resource %q %q {\n", resourceType, name)
+	buf.Write(body)
+	buf.WriteString("}\n")
+	return hclwrite.Format([]byte(buf.String())), nil
+}
+
+// GenerateResourceContents generates the body of a resource block from the state, which
+// conforms to the implied type of the schema.
+//
+// The computed-only attributes, as well as the null optional attributes, are left out.
+// The sensitive values are replaced by `null`, followed by a comment. The strings holding
+// a JSON object or array are written as a `jsonencode` call.
+func GenerateResourceContents(schema *tfjson.SchemaBlock, state cty.Value) ([]byte, error) {
+	if schema == nil {
+		return nil, fmt.Errorf("nil schema")
+	}
+	if !state.Type().IsObjectType() {
+		return nil, fmt.Errorf("expect an object value, got %s", state.Type().FriendlyName())
+	}
+	var buf strings.Builder
+	if err := writeConfigBlock(&buf, schema, state, 2); err != nil {
+		return nil, err
+	}
+	return hclwrite.Format([]byte(buf.String())), nil
+}
+
+func writeConfigBlock(buf *strings.Builder, schema *tfjson.SchemaBlock, val cty.Value, indent int) error {
+	if err := writeConfigAttributes(buf, schema.Attributes, val, indent); err != nil {
+		return err
+	}
+	return writeConfigBlocks(buf, schema.NestedBlocks, val, indent)
+}
+
+func writeConfigAttributes(buf *strings.Builder, attrs map[string]*tfjson.SchemaAttribute, val cty.Value, indent int) error {
+	for _, name := range sortedKeys(attrs) {
+		attrS := attrs[name]
+
+		// Exclude computed-only attributes
+		if !attrS.Required && !attrS.Optional {
+			continue
+		}
+
+		var attrVal cty.Value
+		if !val.IsNull() && val.Type().HasAttribute(name) {
+			attrVal = val.GetAttr(name)
+		} else {
+			attrVal = cty.NullVal(attrType(attrS))
+		}
+
+		// Exclude the null optional attributes, which is the default
+		if attrS.Optional && attrVal.IsNull() {
+			continue
+		}
+
+		if attrS.Sensitive || attrVal.IsMarked() {
+			writeAttrName(buf, name, indent)
+			buf.WriteString("null # sensitive\n")
+			continue
+		}
+
+		if attrS.AttributeNestedType != nil {
+			writeAttrName(buf, name, indent)
+			if err := writeConfigNestedTypeAttribute(buf, attrS.AttributeNestedType, attrVal, indent); err != nil {
+				return fmt.Errorf("writing attribute %q: %v", name, err)
+			}
+			buf.WriteString("\n")
+			continue
+		}
+
+		writeAttrName(buf, name, indent)
+		if err := writeValue(buf, attrVal); err != nil {
+			return fmt.Errorf("writing attribute %q: %v", name, err)
+		}
+		buf.WriteString("\n")
+	}
+	return nil
+}
+
+func writeConfigNestedTypeAttribute(buf *strings.Builder, nt *tfjson.SchemaNestedAttributeType, val cty.Value, indent int) error {
+	if val.IsNull() {
+		buf.WriteString("null")
+		return nil
+	}
+	if !val.IsKnown() {
+		buf.WriteString("null # unknown")
+		return nil
+	}
+	if val.IsMarked() {
+		buf.WriteString("null # sensitive")
+		return nil
+	}
+
+	switch nt.NestingMode {
+	case tfjson.SchemaNestingModeSingle, tfjson.SchemaNestingModeGroup:
+		buf.WriteString("{\n")
+		if err := writeConfigAttributes(buf, nt.Attributes, val, indent+2); err != nil {
+			return err
+		}
+		buf.WriteString(strings.Repeat(" ", indent) + "}")
+	case tfjson.SchemaNestingModeList, tfjson.SchemaNestingModeSet:
+		buf.WriteString("[\n")
+		for it := val.ElementIterator(); it.Next(); {
+			_, ev := it.Element()
+			buf.WriteString(strings.Repeat(" ", indent+2))
+			if err := writeConfigNestedTypeAttribute(buf, &tfjson.SchemaNestedAttributeType{
+				NestingMode: tfjson.SchemaNestingModeSingle,
+				Attributes:  nt.Attributes,
+			}, ev, indent+2); err != nil {
+				return err
+			}
+			buf.WriteString(",\n")
+		}
+		buf.WriteString(strings.Repeat(" ", indent) + "]")
+	case tfjson.SchemaNestingModeMap:
+		buf.WriteString("{\n")
+		for it := val.ElementIterator(); it.Next(); {
+			k, ev := it.Element()
+			buf.WriteString(strings.Repeat(" ", indent+2))
+			buf.Write(hclwrite.TokensForValue(k).Bytes())
+			buf.WriteString(" = ")
+			if err := writeConfigNestedTypeAttribute(buf, &tfjson.SchemaNestedAttributeType{
+				NestingMode: tfjson.SchemaNestingModeSingle,
+				Attributes:  nt.Attributes,
+			}, ev, indent+2); err != nil {
+				return err
+			}
+			buf.WriteString("\n")
+		}
+		buf.WriteString(strings.Repeat(" ", indent) + "}")
+	default:
+		return fmt.Errorf("unsupported nesting mode %q", nt.NestingMode)
+	}
+	return nil
+}
+
+func writeConfigBlocks(buf *strings.Builder, blocks map[string]*tfjson.SchemaBlockType, val cty.Value, indent int) error {
+	for _, name := range sortedKeys(blocks) {
+		blockS := blocks[name]
+		if val.IsNull() || !val.Type().HasAttribute(name) {
+			continue
+		}
+		blockVal := val.GetAttr(name)
+		if blockVal.IsNull() || !blockVal.IsKnown() {
+			continue
+		}
+		if err := writeConfigNestedBlock(buf, name, blockS, blockVal, indent); err != nil {
+			return fmt.Errorf("writing block %q: %v", name, err)
+		}
+	}
+	return nil
+}
+
+func writeConfigNestedBlock(buf *strings.Builder, name string, blockS *tfjson.SchemaBlockType, val cty.Value, indent int) error {
+	block := blockS.Block
+	if block == nil {
+		block = &tfjson.SchemaBlock{}
+	}
+
+	writeBlock := func(label string, val cty.Value) error {
+		buf.WriteString(strings.Repeat(" ", indent))
+		buf.WriteString(name)
+		if label != "" {
+			fmt.Fprintf(buf, " %q", label)
+		}
+		buf.WriteString(" {")
+		if val.IsMarked() {
+			buf.WriteString(" # sensitive\n")
+			buf.WriteString(strings.Repeat(" ", indent) + "}\n")
+			return nil
+		}
+		buf.WriteString("\n")
+		if err := writeConfigBlock(buf, block, val, indent+2); err != nil {
+			return err
+		}
+		buf.WriteString(strings.Repeat(" ", indent) + "}\n")
+		return nil
+	}
+
+	switch blockS.NestingMode {
+	case tfjson.SchemaNestingModeSingle, tfjson.SchemaNestingModeGroup:
+		return writeBlock("", val)
+	case tfjson.SchemaNestingModeList, tfjson.SchemaNestingModeSet:
+		for it := val.ElementIterator(); it.Next(); {
+			_, ev := it.Element()
+			if err := writeBlock("", ev); err != nil {
+				return err
+			}
+		}
+		return nil
+	case tfjson.SchemaNestingModeMap:
+		for it := val.ElementIterator(); it.Next(); {
+			k, ev := it.Element()
+			if err := writeBlock(k.AsString(), ev); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported nesting mode %q", blockS.NestingMode)
+	}
+}
+
+func writeAttrName(buf *strings.Builder, name string, indent int) {
+	buf.WriteString(strings.Repeat(" ", indent))
+	buf.WriteString(name)
+	buf.WriteString(" = ")
+}
+
+// writeValue writes the value as an HCL expression.
+func writeValue(buf *strings.Builder, val cty.Value) error {
+	if !val.IsWhollyKnown() {
+		buf.WriteString("null # unknown")
+		return nil
+	}
+	if val.ContainsMarked() {
+		buf.WriteString("null # sensitive")
+		return nil
+	}
+
+	// If the value is a string storing a JSON value, represent it in a Terraform native way
+	// and encapsulate it in `jsonencode`, as it is the idiomatic representation.
+	if !val.IsNull() && val.Type() == cty.String && json.Valid([]byte(val.AsString())) {
+		b := []byte(val.AsString())
+		ty, err := ctyjson.ImpliedType(b)
+		if err == nil && (ty.IsObjectType() || ty.IsTupleType()) {
+			jv, err := ctyjson.Unmarshal(b, ty)
+			if err == nil {
+				buf.WriteString("jsonencode(")
+				buf.Write(hclwrite.TokensForValue(jv).Bytes())
+				buf.WriteString(")")
+				return nil
+			}
+		}
+	}
+
+	buf.Write(hclwrite.TokensForValue(val).Bytes())
+	return nil
+}
+
+func attrType(attrS *tfjson.SchemaAttribute) cty.Type {
+	if attrS.AttributeNestedType != nil {
+		// The exact type doesn't matter, as the value is only used for null checking.
+		return cty.DynamicPseudoType
+	}
+	return attrS.AttributeType
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
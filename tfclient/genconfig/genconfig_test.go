@@ -0,0 +1,104 @@
+package genconfig
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	tfjson "github.com/hashicorp/terraform-json"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestGenerateResource(t *testing.T) {
+	schema := &tfjson.SchemaBlock{
+		Attributes: map[string]*tfjson.SchemaAttribute{
+			"id":       {AttributeType: cty.String, Computed: true},
+			"name":     {AttributeType: cty.String, Required: true},
+			"location": {AttributeType: cty.String, Optional: true, Computed: true},
+			"tags":     {AttributeType: cty.Map(cty.String), Optional: true},
+			"password": {AttributeType: cty.String, Optional: true, Sensitive: true},
+			"policy":   {AttributeType: cty.String, Optional: true},
+			"note":     {AttributeType: cty.String, Optional: true},
+			"rules": {
+				Optional: true,
+				AttributeNestedType: &tfjson.SchemaNestedAttributeType{
+					NestingMode: tfjson.SchemaNestingModeList,
+					Attributes: map[string]*tfjson.SchemaAttribute{
+						"port":   {AttributeType: cty.Number, Required: true},
+						"status": {AttributeType: cty.String, Computed: true},
+					},
+				},
+			},
+		},
+		NestedBlocks: map[string]*tfjson.SchemaBlockType{
+			"network": {
+				NestingMode: tfjson.SchemaNestingModeList,
+				Block: &tfjson.SchemaBlock{
+					Attributes: map[string]*tfjson.SchemaAttribute{
+						"subnet": {AttributeType: cty.String, Required: true},
+						"ip":     {AttributeType: cty.String, Computed: true},
+					},
+				},
+			},
+			"timeouts": {
+				NestingMode: tfjson.SchemaNestingModeSingle,
+				Block: &tfjson.SchemaBlock{
+					Attributes: map[string]*tfjson.SchemaAttribute{
+						"create": {AttributeType: cty.String, Optional: true},
+					},
+				},
+			},
+		},
+	}
+
+	state := cty.ObjectVal(map[string]cty.Value{
+		"id":       cty.StringVal("/foo/1"),
+		"name":     cty.StringVal("foo"),
+		"location": cty.StringVal("westus"),
+		"tags":     cty.MapVal(map[string]cty.Value{"env": cty.StringVal("dev")}),
+		"password": cty.StringVal("secret"),
+		"policy":   cty.StringVal(`{"a":["b"]}`),
+		"note":     cty.NullVal(cty.String),
+		"rules": cty.ListVal([]cty.Value{
+			cty.ObjectVal(map[string]cty.Value{
+				"port":   cty.NumberIntVal(80),
+				"status": cty.StringVal("ok"),
+			}),
+		}),
+		"network": cty.ListVal([]cty.Value{
+			cty.ObjectVal(map[string]cty.Value{
+				"subnet": cty.StringVal("a"),
+				"ip":     cty.StringVal("10.0.0.1"),
+			}),
+		}),
+		"timeouts": cty.NullVal(cty.Object(map[string]cty.Type{"create": cty.String})),
+	})
+
+	got, err := GenerateResource("foo_resource", "test", schema, state)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := `resource "foo_resource" "test" {
+  location = "westus"
+  name     = "foo"
+  password = null # sensitive
+  policy = jsonencode({
+    a = ["b"]
+  })
+  rules = [
+    {
+      port = 80
+    },
+  ]
+  tags = {
+    env = "dev"
+  }
+  network {
+    subnet = "a"
+  }
+}
+`
+	if diff := cmp.Diff(want, string(got)); diff != "" {
+		t.Fatalf("unexpected output (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,135 @@
+package tfclient
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-go/tfprotov5"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/magodo/terraform-client-go/tfclient/tfprotov5/tf5client"
+	"github.com/magodo/terraform-client-go/tfclient/tfprotov6/tf6client"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// NewInProcess creates a normalized client of the provider server running in the same process, which is called
+// directly, without a subprocess or gRPC. The server is either a tfprotov5.ProviderServer or a
+// tfprotov6.ProviderServer, e.g. built by the providerserver package of terraform-plugin-framework. If the server
+// doesn't implement the list resource or action methods, calling them returns an Unimplemented error.
+//
+// The client is wrapped the same as New, by the MarkSensitive, StaticValidation and SkipLegacyNormalization of the
+// opts, while the other options are ignored. Closing the client has no effect on the server.
+func NewInProcess(server any, opts Option) (Client, error) {
+	var (
+		client Client
+		err    error
+	)
+	switch server := server.(type) {
+	case tfprotov5.ProviderServer:
+		client, err = tf5client.New(nil, inProcessV5Server(server), nil)
+	case tfprotov6.ProviderServer:
+		client, err = tf6client.New(nil, inProcessV6Server(server), nil)
+	default:
+		return nil, fmt.Errorf("unsupported provider server type %T", server)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return wrapClient(client, opts), nil
+}
+
+type v5Server struct {
+	tfprotov5.ProviderServer
+	tfprotov5.ListResourceServer
+	tfprotov5.ActionServer
+}
+
+func inProcessV5Server(server tfprotov5.ProviderServer) tf5client.TFProtoV5Client {
+	if c, ok := server.(tf5client.TFProtoV5Client); ok {
+		return c
+	}
+	s := v5Server{
+		ProviderServer:     server,
+		ListResourceServer: unimplementedV5ListResourceServer{},
+		ActionServer:       unimplementedV5ActionServer{},
+	}
+	if ls, ok := server.(tfprotov5.ListResourceServer); ok {
+		s.ListResourceServer = ls
+	}
+	if as, ok := server.(tfprotov5.ActionServer); ok {
+		s.ActionServer = as
+	}
+	return s
+}
+
+type unimplementedV5ListResourceServer struct{}
+
+func (unimplementedV5ListResourceServer) ValidateListResourceConfig(context.Context, *tfprotov5.ValidateListResourceConfigRequest) (*tfprotov5.ValidateListResourceConfigResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "ValidateListResourceConfig is not implemented")
+}
+
+func (unimplementedV5ListResourceServer) ListResource(context.Context, *tfprotov5.ListResourceRequest) (*tfprotov5.ListResourceServerStream, error) {
+	return nil, status.Error(codes.Unimplemented, "ListResource is not implemented")
+}
+
+type unimplementedV5ActionServer struct{}
+
+func (unimplementedV5ActionServer) ValidateActionConfig(context.Context, *tfprotov5.ValidateActionConfigRequest) (*tfprotov5.ValidateActionConfigResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "ValidateActionConfig is not implemented")
+}
+
+func (unimplementedV5ActionServer) PlanAction(context.Context, *tfprotov5.PlanActionRequest) (*tfprotov5.PlanActionResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "PlanAction is not implemented")
+}
+
+func (unimplementedV5ActionServer) InvokeAction(context.Context, *tfprotov5.InvokeActionRequest) (*tfprotov5.InvokeActionServerStream, error) {
+	return nil, status.Error(codes.Unimplemented, "InvokeAction is not implemented")
+}
+
+type v6Server struct {
+	tfprotov6.ProviderServer
+	tfprotov6.ListResourceServer
+	tfprotov6.ActionServer
+}
+
+func inProcessV6Server(server tfprotov6.ProviderServer) tf6client.TFProtoV6Client {
+	if c, ok := server.(tf6client.TFProtoV6Client); ok {
+		return c
+	}
+	s := v6Server{
+		ProviderServer:     server,
+		ListResourceServer: unimplementedV6ListResourceServer{},
+		ActionServer:       unimplementedV6ActionServer{},
+	}
+	if ls, ok := server.(tfprotov6.ListResourceServer); ok {
+		s.ListResourceServer = ls
+	}
+	if as, ok := server.(tfprotov6.ActionServer); ok {
+		s.ActionServer = as
+	}
+	return s
+}
+
+type unimplementedV6ListResourceServer struct{}
+
+func (unimplementedV6ListResourceServer) ValidateListResourceConfig(context.Context, *tfprotov6.ValidateListResourceConfigRequest) (*tfprotov6.ValidateListResourceConfigResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "ValidateListResourceConfig is not implemented")
+}
+
+func (unimplementedV6ListResourceServer) ListResource(context.Context, *tfprotov6.ListResourceRequest) (*tfprotov6.ListResourceServerStream, error) {
+	return nil, status.Error(codes.Unimplemented, "ListResource is not implemented")
+}
+
+type unimplementedV6ActionServer struct{}
+
+func (unimplementedV6ActionServer) ValidateActionConfig(context.Context, *tfprotov6.ValidateActionConfigRequest) (*tfprotov6.ValidateActionConfigResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "ValidateActionConfig is not implemented")
+}
+
+func (unimplementedV6ActionServer) PlanAction(context.Context, *tfprotov6.PlanActionRequest) (*tfprotov6.PlanActionResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "PlanAction is not implemented")
+}
+
+func (unimplementedV6ActionServer) InvokeAction(context.Context, *tfprotov6.InvokeActionRequest) (*tfprotov6.InvokeActionServerStream, error) {
+	return nil, status.Error(codes.Unimplemented, "InvokeAction is not implemented")
+}
@@ -0,0 +1,95 @@
+package tfclient
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-go/tfprotov5"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/magodo/terraform-client-go/tfclient/marks"
+	"github.com/magodo/terraform-client-go/tfclient/typ"
+	"github.com/zclconf/go-cty/cty"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// inProcessFakeServer implements the provider server methods used by the tests, the others panic.
+type inProcessFakeServer struct {
+	tfprotov5.ProviderServer
+}
+
+func (inProcessFakeServer) GetProviderSchema(context.Context, *tfprotov5.GetProviderSchemaRequest) (*tfprotov5.GetProviderSchemaResponse, error) {
+	return &tfprotov5.GetProviderSchemaResponse{
+		ResourceSchemas: map[string]*tfprotov5.Schema{
+			"foo_thing": {
+				Block: &tfprotov5.SchemaBlock{
+					Attributes: []*tfprotov5.SchemaAttribute{
+						{Name: "id", Type: tftypes.String, Required: true},
+						{Name: "secret", Type: tftypes.String, Optional: true, Sensitive: true},
+					},
+				},
+			},
+		},
+	}, nil
+}
+
+func (inProcessFakeServer) GetResourceIdentitySchemas(context.Context, *tfprotov5.GetResourceIdentitySchemasRequest) (*tfprotov5.GetResourceIdentitySchemasResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "")
+}
+
+func (inProcessFakeServer) ReadResource(_ context.Context, req *tfprotov5.ReadResourceRequest) (*tfprotov5.ReadResourceResponse, error) {
+	return &tfprotov5.ReadResourceResponse{NewState: req.CurrentState}, nil
+}
+
+func TestNewInProcess(t *testing.T) {
+	c, err := NewInProcess(inProcessFakeServer{}, Option{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	state := cty.ObjectVal(map[string]cty.Value{"id": cty.StringVal("1"), "secret": cty.StringVal("s")})
+	resp, diags := c.ReadResource(context.Background(), typ.ReadResourceRequest{
+		TypeName:   "foo_thing",
+		PriorState: state,
+	})
+	if diags.HasErrors() {
+		t.Fatal(diags.Err())
+	}
+	if !resp.NewState.RawEquals(state) {
+		t.Errorf("wrong state: %#v", resp.NewState)
+	}
+
+	if _, err := NewInProcess(struct{}{}, Option{}); err == nil {
+		t.Errorf("expect error for the unsupported server")
+	}
+
+	// The options wrap the client the same as New.
+	c, err = NewInProcess(inProcessFakeServer{}, Option{MarkSensitive: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	resp, diags = c.ReadResource(context.Background(), typ.ReadResourceRequest{
+		TypeName:   "foo_thing",
+		PriorState: state,
+	})
+	if diags.HasErrors() {
+		t.Fatal(diags.Err())
+	}
+	if !resp.NewState.GetAttr("secret").HasMark(marks.Sensitive) {
+		t.Errorf("expect the secret to be marked as sensitive: %#v", resp.NewState)
+	}
+}
+
+func TestInProcessV5ServerUnimplemented(t *testing.T) {
+	s := inProcessV5Server(inProcessFakeServer{})
+	_, err := s.ListResource(context.Background(), &tfprotov5.ListResourceRequest{})
+	if status.Code(err) != codes.Unimplemented {
+		t.Errorf("expect Unimplemented error, got %v", err)
+	}
+	_, err = s.PlanAction(context.Background(), &tfprotov5.PlanActionRequest{})
+	if status.Code(err) != codes.Unimplemented {
+		t.Errorf("expect Unimplemented error, got %v", err)
+	}
+}
@@ -0,0 +1,50 @@
+package tfclient
+
+import (
+	"context"
+
+	"github.com/magodo/terraform-client-go/tfclient/objchange"
+	"github.com/magodo/terraform-client-go/tfclient/typ"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// NormalizeLegacySDK wraps the client, so that the planned state and the new state returned from the
+// PlanResourceChange and ApplyResourceChange are normalized by objchange.NormalizeObjectFromLegacySDK, if the
+// response has LegacyTypeSystem set, the same as Terraform does. This avoids, e.g., the null versus empty
+// mismatches of the nested blocks returned by the providers built with the legacy SDK.
+func NormalizeLegacySDK(c Client) Client {
+	return &legacyClient{Client: c}
+}
+
+type legacyClient struct {
+	Client
+}
+
+// normalize normalizes the value by the schema of the resource type, if found.
+func (c *legacyClient) normalize(typeName string, v cty.Value) cty.Value {
+	schema, diags := c.Client.GetProviderSchema()
+	if diags.HasErrors() || v == cty.NilVal {
+		return v
+	}
+	sch, ok := schema.ResourceTypes[typeName]
+	if !ok || sch.Block == nil {
+		return v
+	}
+	return objchange.NormalizeObjectFromLegacySDK(v, sch.Block)
+}
+
+func (c *legacyClient) PlanResourceChange(ctx context.Context, req typ.PlanResourceChangeRequest) (*typ.PlanResourceChangeResponse, typ.Diagnostics) {
+	resp, diags := c.Client.PlanResourceChange(ctx, req)
+	if resp != nil && resp.LegacyTypeSystem {
+		resp.PlannedState = c.normalize(req.TypeName, resp.PlannedState)
+	}
+	return resp, diags
+}
+
+func (c *legacyClient) ApplyResourceChange(ctx context.Context, req typ.ApplyResourceChangeRequest) (*typ.ApplyResourceChangeResponse, typ.Diagnostics) {
+	resp, diags := c.Client.ApplyResourceChange(ctx, req)
+	if resp != nil && resp.LegacyTypeSystem {
+		resp.NewState = c.normalize(req.TypeName, resp.NewState)
+	}
+	return resp, diags
+}
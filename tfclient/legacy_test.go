@@ -0,0 +1,66 @@
+package tfclient
+
+import (
+	"context"
+	"testing"
+
+	tfjson "github.com/hashicorp/terraform-json"
+	"github.com/magodo/terraform-client-go/tfclient/typ"
+	"github.com/zclconf/go-cty/cty"
+)
+
+type legacyFakeClient struct {
+	Client
+	legacy bool
+}
+
+func (c legacyFakeClient) GetProviderSchema() (*typ.GetProviderSchemaResponse, typ.Diagnostics) {
+	return &typ.GetProviderSchemaResponse{
+		ResourceTypes: map[string]tfjson.Schema{
+			"foo_thing": {
+				Block: &tfjson.SchemaBlock{
+					NestedBlocks: map[string]*tfjson.SchemaBlockType{
+						"rule": {
+							NestingMode: tfjson.SchemaNestingModeList,
+							Block: &tfjson.SchemaBlock{
+								Attributes: map[string]*tfjson.SchemaAttribute{"port": {AttributeType: cty.Number, Optional: true}},
+							},
+						},
+					},
+				},
+			},
+		},
+	}, nil
+}
+
+func (c legacyFakeClient) PlanResourceChange(_ context.Context, req typ.PlanResourceChangeRequest) (*typ.PlanResourceChangeResponse, typ.Diagnostics) {
+	return &typ.PlanResourceChangeResponse{
+		PlannedState: cty.ObjectVal(map[string]cty.Value{
+			"rule": cty.NullVal(cty.List(cty.Object(map[string]cty.Type{"port": cty.Number}))),
+		}),
+		LegacyTypeSystem: c.legacy,
+	}, nil
+}
+
+func TestNormalizeLegacySDK(t *testing.T) {
+	ruleTy := cty.Object(map[string]cty.Type{"port": cty.Number})
+
+	resp, diags := NormalizeLegacySDK(legacyFakeClient{legacy: true}).PlanResourceChange(context.Background(), typ.PlanResourceChangeRequest{TypeName: "foo_thing"})
+	if diags.HasErrors() {
+		t.Fatal(diags.Err())
+	}
+	want := cty.ObjectVal(map[string]cty.Value{"rule": cty.ListValEmpty(ruleTy)})
+	if !resp.PlannedState.RawEquals(want) {
+		t.Errorf("wrong planned state: %#v", resp.PlannedState)
+	}
+
+	// The responses of the non-legacy providers are left as is.
+	resp, diags = NormalizeLegacySDK(legacyFakeClient{}).PlanResourceChange(context.Background(), typ.PlanResourceChangeRequest{TypeName: "foo_thing"})
+	if diags.HasErrors() {
+		t.Fatal(diags.Err())
+	}
+	want = cty.ObjectVal(map[string]cty.Value{"rule": cty.NullVal(cty.List(ruleTy))})
+	if !resp.PlannedState.RawEquals(want) {
+		t.Errorf("wrong planned state: %#v", resp.PlannedState)
+	}
+}
@@ -0,0 +1,29 @@
+// This is derived from github.com/hashicorp/terraform/internal/lang/marks/marks.go (v1.13.0-alpha20250521)
+
+// Package marks defines the cty value marks used by this module.
+package marks
+
+import "github.com/zclconf/go-cty/cty"
+
+// valueMark is a private type used to make the marks of this package distinct from the others.
+type valueMark string
+
+func (m valueMark) GoString() string {
+	return "marks." + string(m)
+}
+
+// Sensitive indicates that this value is marked as sensitive, e.g. by the schema.
+const Sensitive = valueMark("Sensitive")
+
+// Contains returns true if the value or any of its nested values has the mark.
+func Contains(val cty.Value, mark valueMark) bool {
+	ret := false
+	cty.Walk(val, func(_ cty.Path, v cty.Value) (bool, error) {
+		if v.HasMark(mark) {
+			ret = true
+			return false, nil
+		}
+		return true, nil
+	})
+	return ret
+}
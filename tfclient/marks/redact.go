@@ -0,0 +1,38 @@
+package marks
+
+import "github.com/zclconf/go-cty/cty"
+
+// SensitiveValue is the placeholder of the redacted sensitive values.
+const SensitiveValue = "(sensitive value)"
+
+// RedactSensitive replaces the values marked by Sensitive with the SensitiveValue string, and removes the
+// other marks. As the type of a redacted value changes, the collections containing it are converted to the
+// structural types, i.e. the lists and sets to tuples, and the maps to objects.
+func RedactSensitive(v cty.Value) cty.Value {
+	if v.HasMark(Sensitive) {
+		return cty.StringVal(SensitiveValue)
+	}
+	v, _ = v.Unmark()
+	if !Contains(v, Sensitive) {
+		v, _ = v.UnmarkDeep()
+		return v
+	}
+	// Only the known and non-null collections and structural values can contain marked values.
+	ty := v.Type()
+	switch {
+	case ty.IsObjectType() || ty.IsMapType():
+		attrs := map[string]cty.Value{}
+		for it := v.ElementIterator(); it.Next(); {
+			k, ev := it.Element()
+			attrs[k.AsString()] = RedactSensitive(ev)
+		}
+		return cty.ObjectVal(attrs)
+	default:
+		var elems []cty.Value
+		for it := v.ElementIterator(); it.Next(); {
+			_, ev := it.Element()
+			elems = append(elems, RedactSensitive(ev))
+		}
+		return cty.TupleVal(elems)
+	}
+}
@@ -0,0 +1,390 @@
+package tfclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	tfjson "github.com/hashicorp/terraform-json"
+	"github.com/magodo/terraform-client-go/tfclient/typ"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// Mux presents several clients of the same provider, e.g. a provider split into a SDKv2 and a framework binary,
+// as one client. This is the client side counterpart of the terraform-plugin-mux.
+//
+// The GetProviderSchema of the returned client merges the resource, data source, ephemeral resource, list resource,
+// action and function schemas of the clients, while the provider (meta) schema is the one of the first client, which
+// is expected to be the same for all the clients. The calls of a type (or function) are routed to the client that
+// owns the type. The provider config is validated and configured by every client, and Stop and Close are fanned out
+// to every client.
+//
+// A type name defined by more than one client, and a provider schema differing from the first client's, are reported
+// as error diagnostics, and the type is routed to the first client defining it. As the returned client is still
+// usable, the same diagnostics are returned from its GetProviderSchema as warnings, following the diagnostics of the
+// clients' GetProviderSchema.
+func Mux(clients ...Client) (Client, typ.Diagnostics) {
+	c := &muxClient{
+		clients: clients,
+		schema: typ.GetProviderSchemaResponse{
+			ResourceTypes:             map[string]tfjson.Schema{},
+			ResourceTypesCty:          map[string]cty.Type{},
+			DataSources:               map[string]tfjson.Schema{},
+			DataSourcesCty:            map[string]cty.Type{},
+			EphemeralResourceTypes:    map[string]tfjson.Schema{},
+			EphemeralResourceTypesCty: map[string]cty.Type{},
+			ListResourceTypes:         map[string]tfjson.Schema{},
+			ListResourceTypesCty:      map[string]cty.Type{},
+			Actions:                   map[string]tfjson.Schema{},
+			ActionsCty:                map[string]cty.Type{},
+			Functions:                 map[string]typ.FunctionDecl{},
+		},
+		resources:          map[string]int{},
+		dataSources:        map[string]int{},
+		ephemeralResources: map[string]int{},
+		listResources:      map[string]int{},
+		actions:            map[string]int{},
+		functions:          map[string]int{},
+	}
+	if len(clients) == 0 {
+		c.diags = typ.Diagnostics{{
+			Severity: typ.Error,
+			Summary:  "No provider to mux",
+			Detail:   "At least one client is required.",
+		}}
+		return c, c.diags
+	}
+
+	// conflicts are the diagnostics of the conflicting schemas of the clients.
+	var conflicts typ.Diagnostics
+	for i, client := range clients {
+		resp, diags := client.GetProviderSchema()
+		c.diags = append(c.diags, diags...)
+		if resp == nil {
+			continue
+		}
+		if i == 0 {
+			c.schema.Provider = resp.Provider
+			c.schema.ProviderCty = resp.ProviderCty
+			c.schema.ProviderMeta = resp.ProviderMeta
+			c.schema.ProviderMetaCty = resp.ProviderMetaCty
+			c.schema.ServerCapabilities = resp.ServerCapabilities
+		} else {
+			if !resp.ProviderCty.Equals(c.schema.ProviderCty) {
+				conflicts = append(conflicts, typ.Diagnostic{
+					Severity: typ.Error,
+					Summary:  "Provider schema mismatch",
+					Detail:   fmt.Sprintf("The provider schema of client %d differs from the one of the first client.", i),
+				})
+			}
+			// PlanDestroy and MoveResourceState are advertised if any client supports them, as the calls are
+			// routed to the owning client, while GetProviderSchemaOptional requires all the clients to support it.
+			c.schema.ServerCapabilities.PlanDestroy = c.schema.ServerCapabilities.PlanDestroy || resp.ServerCapabilities.PlanDestroy
+			c.schema.ServerCapabilities.GetProviderSchemaOptional = c.schema.ServerCapabilities.GetProviderSchemaOptional && resp.ServerCapabilities.GetProviderSchemaOptional
+			c.schema.ServerCapabilities.MoveResourceState = c.schema.ServerCapabilities.MoveResourceState || resp.ServerCapabilities.MoveResourceState
+		}
+
+		conflicts = append(conflicts, muxSchemas(i, "resource type", c.resources, c.schema.ResourceTypes, c.schema.ResourceTypesCty, resp.ResourceTypes, resp.ResourceTypesCty)...)
+		conflicts = append(conflicts, muxSchemas(i, "data source", c.dataSources, c.schema.DataSources, c.schema.DataSourcesCty, resp.DataSources, resp.DataSourcesCty)...)
+		conflicts = append(conflicts, muxSchemas(i, "ephemeral resource type", c.ephemeralResources, c.schema.EphemeralResourceTypes, c.schema.EphemeralResourceTypesCty, resp.EphemeralResourceTypes, resp.EphemeralResourceTypesCty)...)
+		conflicts = append(conflicts, muxSchemas(i, "list resource type", c.listResources, c.schema.ListResourceTypes, c.schema.ListResourceTypesCty, resp.ListResourceTypes, resp.ListResourceTypesCty)...)
+		conflicts = append(conflicts, muxSchemas(i, "action type", c.actions, c.schema.Actions, c.schema.ActionsCty, resp.Actions, resp.ActionsCty)...)
+		for name, decl := range resp.Functions {
+			if _, ok := c.functions[name]; ok {
+				conflicts = append(conflicts, duplicateTypeDiagnostic("function", name))
+				continue
+			}
+			c.functions[name] = i
+			c.schema.Functions[name] = decl
+		}
+	}
+	diags := append(slices.Clone(c.diags), conflicts...)
+	for _, d := range conflicts {
+		d.Severity = typ.Warning
+		c.diags = append(c.diags, d)
+	}
+	return c, diags
+}
+
+// muxSchemas merges the schemas of the client into the muxed schemas, and records the client as the owner of
+// the types.
+func muxSchemas(idx int, kind string, owners map[string]int, schemas map[string]tfjson.Schema, types map[string]cty.Type, clientSchemas map[string]tfjson.Schema, clientTypes map[string]cty.Type) typ.Diagnostics {
+	var diags typ.Diagnostics
+	for name, schema := range clientSchemas {
+		if _, ok := owners[name]; ok {
+			diags = append(diags, duplicateTypeDiagnostic(kind, name))
+			continue
+		}
+		owners[name] = idx
+		schemas[name] = schema
+		types[name] = clientTypes[name]
+	}
+	return diags
+}
+
+func duplicateTypeDiagnostic(kind, name string) typ.Diagnostic {
+	return typ.Diagnostic{
+		Severity: typ.Error,
+		Summary:  fmt.Sprintf("Duplicate %s", kind),
+		Detail:   fmt.Sprintf("The %s %q is defined by more than one provider, only the first one is used.", kind, name),
+	}
+}
+
+type muxClient struct {
+	clients []Client
+	schema  typ.GetProviderSchemaResponse
+	diags   typ.Diagnostics
+
+	// The following maps the type (or function) names to the index of the owning client.
+	resources          map[string]int
+	dataSources        map[string]int
+	ephemeralResources map[string]int
+	listResources      map[string]int
+	actions            map[string]int
+	functions          map[string]int
+}
+
+var _ Client = &muxClient{}
+
+// route returns the client owning the name, or an error diagnostic if no client owns it.
+func (c *muxClient) route(owners map[string]int, kind, name string) (Client, typ.Diagnostics) {
+	if i, ok := owners[name]; ok {
+		return c.clients[i], nil
+	}
+	return nil, typ.ErrorDiagnostics("no schema", fmt.Errorf("unknown %s %q", kind, name))
+}
+
+func (c *muxClient) GetProviderSchema() (*typ.GetProviderSchemaResponse, typ.Diagnostics) {
+	return &c.schema, c.diags
+}
+
+func (c *muxClient) GetResourceIdentitySchemas(ctx context.Context) (*typ.GetResourceIdentitySchemasResponse, typ.Diagnostics) {
+	ret := &typ.GetResourceIdentitySchemasResponse{IdentityTypes: map[string]typ.IdentitySchema{}}
+	var diags typ.Diagnostics
+	for i, client := range c.clients {
+		resp, cdiags := client.GetResourceIdentitySchemas(ctx)
+		diags = append(diags, cdiags...)
+		if resp == nil {
+			continue
+		}
+		for name, schema := range resp.IdentityTypes {
+			// Only take the identity schema from the client that owns the resource type.
+			if owner, ok := c.resources[name]; !ok || owner != i {
+				continue
+			}
+			ret.IdentityTypes[name] = schema
+		}
+	}
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	return ret, diags
+}
+
+func (c *muxClient) ValidateProviderConfig(ctx context.Context, req typ.ValidateProviderConfigRequest) (*typ.ValidateProviderConfigResponse, typ.Diagnostics) {
+	var (
+		ret   *typ.ValidateProviderConfigResponse
+		diags typ.Diagnostics
+	)
+	for i, client := range c.clients {
+		resp, cdiags := client.ValidateProviderConfig(ctx, req)
+		diags = append(diags, cdiags...)
+		if i == 0 {
+			ret = resp
+		}
+	}
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	return ret, diags
+}
+
+func (c *muxClient) ValidateResourceConfig(ctx context.Context, req typ.ValidateResourceConfigRequest) (*typ.ValidateResourceConfigResponse, typ.Diagnostics) {
+	client, diags := c.route(c.resources, "resource type", req.TypeName)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	return client.ValidateResourceConfig(ctx, req)
+}
+
+func (c *muxClient) ValidateDataResourceConfig(ctx context.Context, req typ.ValidateDataResourceConfigRequest) (*typ.ValidateDataResourceConfigResponse, typ.Diagnostics) {
+	client, diags := c.route(c.dataSources, "data source", req.TypeName)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	return client.ValidateDataResourceConfig(ctx, req)
+}
+
+func (c *muxClient) ValidateEphemeralResourceConfig(ctx context.Context, req typ.ValidateEphemeralResourceConfigRequest) typ.Diagnostics {
+	client, diags := c.route(c.ephemeralResources, "ephemeral resource type", req.TypeName)
+	if diags.HasErrors() {
+		return diags
+	}
+	return client.ValidateEphemeralResourceConfig(ctx, req)
+}
+
+func (c *muxClient) ValidateListResourceConfig(ctx context.Context, req typ.ValidateListResourceConfigRequest) typ.Diagnostics {
+	client, diags := c.route(c.listResources, "list resource type", req.TypeName)
+	if diags.HasErrors() {
+		return diags
+	}
+	return client.ValidateListResourceConfig(ctx, req)
+}
+
+func (c *muxClient) ValidateActionConfig(ctx context.Context, req typ.ValidateActionConfigRequest) typ.Diagnostics {
+	client, diags := c.route(c.actions, "action type", req.TypeName)
+	if diags.HasErrors() {
+		return diags
+	}
+	return client.ValidateActionConfig(ctx, req)
+}
+
+func (c *muxClient) UpgradeResourceState(ctx context.Context, req typ.UpgradeResourceStateRequest) (*typ.UpgradeResourceStateResponse, typ.Diagnostics) {
+	client, diags := c.route(c.resources, "resource type", req.TypeName)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	return client.UpgradeResourceState(ctx, req)
+}
+
+func (c *muxClient) UpgradeResourceIdentity(ctx context.Context, req typ.UpgradeResourceIdentityRequest) (*typ.UpgradeResourceIdentityResponse, typ.Diagnostics) {
+	client, diags := c.route(c.resources, "resource type", req.TypeName)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	return client.UpgradeResourceIdentity(ctx, req)
+}
+
+func (c *muxClient) ConfigureProvider(ctx context.Context, req typ.ConfigureProviderRequest) (*typ.ConfigureProviderResponse, typ.Diagnostics) {
+	var diags typ.Diagnostics
+	for _, client := range c.clients {
+		_, cdiags := client.ConfigureProvider(ctx, req)
+		diags = append(diags, cdiags...)
+	}
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	return &typ.ConfigureProviderResponse{}, diags
+}
+
+func (c *muxClient) Stop(ctx context.Context) error {
+	var errs []error
+	for _, client := range c.clients {
+		if err := client.Stop(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (c *muxClient) ReadResource(ctx context.Context, req typ.ReadResourceRequest) (*typ.ReadResourceResponse, typ.Diagnostics) {
+	client, diags := c.route(c.resources, "resource type", req.TypeName)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	return client.ReadResource(ctx, req)
+}
+
+func (c *muxClient) PlanResourceChange(ctx context.Context, req typ.PlanResourceChangeRequest) (*typ.PlanResourceChangeResponse, typ.Diagnostics) {
+	client, diags := c.route(c.resources, "resource type", req.TypeName)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	return client.PlanResourceChange(ctx, req)
+}
+
+func (c *muxClient) ApplyResourceChange(ctx context.Context, req typ.ApplyResourceChangeRequest) (*typ.ApplyResourceChangeResponse, typ.Diagnostics) {
+	client, diags := c.route(c.resources, "resource type", req.TypeName)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	return client.ApplyResourceChange(ctx, req)
+}
+
+func (c *muxClient) ImportResourceState(ctx context.Context, req typ.ImportResourceStateRequest) (*typ.ImportResourceStateResponse, typ.Diagnostics) {
+	client, diags := c.route(c.resources, "resource type", req.TypeName)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	return client.ImportResourceState(ctx, req)
+}
+
+// MoveResourceState is routed by the target type name, as it is the target resource that does the move.
+func (c *muxClient) MoveResourceState(ctx context.Context, req typ.MoveResourceStateRequest) (*typ.MoveResourceStateResponse, typ.Diagnostics) {
+	client, diags := c.route(c.resources, "resource type", req.TargetTypeName)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	return client.MoveResourceState(ctx, req)
+}
+
+func (c *muxClient) ReadDataSource(ctx context.Context, req typ.ReadDataSourceRequest) (*typ.ReadDataSourceResponse, typ.Diagnostics) {
+	client, diags := c.route(c.dataSources, "data source", req.TypeName)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	return client.ReadDataSource(ctx, req)
+}
+
+func (c *muxClient) OpenEphemeralResource(ctx context.Context, req typ.OpenEphemeralResourceRequest) (*typ.OpenEphemeralResourceResponse, typ.Diagnostics) {
+	client, diags := c.route(c.ephemeralResources, "ephemeral resource type", req.TypeName)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	return client.OpenEphemeralResource(ctx, req)
+}
+
+func (c *muxClient) RenewEphemeralResource(ctx context.Context, req typ.RenewEphemeralResourceRequest) (*typ.RenewEphemeralResourceResponse, typ.Diagnostics) {
+	client, diags := c.route(c.ephemeralResources, "ephemeral resource type", req.TypeName)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	return client.RenewEphemeralResource(ctx, req)
+}
+
+func (c *muxClient) CloseEphemeralResource(ctx context.Context, req typ.CloseEphemeralResourceRequest) typ.Diagnostics {
+	client, diags := c.route(c.ephemeralResources, "ephemeral resource type", req.TypeName)
+	if diags.HasErrors() {
+		return diags
+	}
+	return client.CloseEphemeralResource(ctx, req)
+}
+
+func (c *muxClient) CallFunction(ctx context.Context, req typ.CallFunctionRequest) (*typ.CallFunctionResponse, typ.Diagnostics) {
+	client, diags := c.route(c.functions, "function", req.FunctionName)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	return client.CallFunction(ctx, req)
+}
+
+func (c *muxClient) ListResource(ctx context.Context, req typ.ListResourceRequest) (typ.ListResourceResponse, typ.Diagnostics) {
+	client, diags := c.route(c.listResources, "list resource type", req.TypeName)
+	if diags.HasErrors() {
+		return typ.ListResourceResponse{}, diags
+	}
+	return client.ListResource(ctx, req)
+}
+
+func (c *muxClient) PlanAction(ctx context.Context, req typ.PlanActionRequest) (typ.PlanActionResponse, typ.Diagnostics) {
+	client, diags := c.route(c.actions, "action type", req.ActionType)
+	if diags.HasErrors() {
+		return typ.PlanActionResponse{}, diags
+	}
+	return client.PlanAction(ctx, req)
+}
+
+func (c *muxClient) InvokeAction(ctx context.Context, req typ.InvokeActionRequest) (typ.InvokeActionResponse, typ.Diagnostics) {
+	client, diags := c.route(c.actions, "action type", req.ActionType)
+	if diags.HasErrors() {
+		return typ.InvokeActionResponse{}, diags
+	}
+	return client.InvokeAction(ctx, req)
+}
+
+func (c *muxClient) Close() {
+	for _, client := range c.clients {
+		client.Close()
+	}
+}
@@ -0,0 +1,111 @@
+package tfclient
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	tfjson "github.com/hashicorp/terraform-json"
+	"github.com/magodo/terraform-client-go/tfclient/typ"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// muxFakeClient implements the methods used by the tests, the others panic.
+type muxFakeClient struct {
+	Client
+	name       string
+	resources  []string
+	stopErr    error
+	configured bool
+	closed     bool
+}
+
+func (c *muxFakeClient) GetProviderSchema() (*typ.GetProviderSchemaResponse, typ.Diagnostics) {
+	resp := &typ.GetProviderSchemaResponse{
+		ResourceTypes:    map[string]tfjson.Schema{},
+		ResourceTypesCty: map[string]cty.Type{},
+		Functions:        map[string]typ.FunctionDecl{c.name + "_func": {}},
+	}
+	for _, name := range c.resources {
+		resp.ResourceTypes[name] = tfjson.Schema{Block: &tfjson.SchemaBlock{}}
+		resp.ResourceTypesCty[name] = cty.EmptyObject
+	}
+	return resp, nil
+}
+
+func (c *muxFakeClient) ReadResource(_ context.Context, req typ.ReadResourceRequest) (*typ.ReadResourceResponse, typ.Diagnostics) {
+	return &typ.ReadResourceResponse{Private: []byte(c.name)}, nil
+}
+
+func (c *muxFakeClient) ConfigureProvider(context.Context, typ.ConfigureProviderRequest) (*typ.ConfigureProviderResponse, typ.Diagnostics) {
+	c.configured = true
+	return &typ.ConfigureProviderResponse{}, nil
+}
+
+func (c *muxFakeClient) Stop(context.Context) error {
+	return c.stopErr
+}
+
+func (c *muxFakeClient) Close() {
+	c.closed = true
+}
+
+func TestMux(t *testing.T) {
+	ctx := context.Background()
+	sdk := &muxFakeClient{name: "sdk", resources: []string{"foo_a"}}
+	fw := &muxFakeClient{name: "fw", resources: []string{"foo_b"}, stopErr: errors.New("boom")}
+	c, diags := Mux(sdk, fw)
+	if len(diags) != 0 {
+		t.Fatal(diags.Err())
+	}
+
+	schResp, _ := c.GetProviderSchema()
+	if len(schResp.ResourceTypes) != 2 || len(schResp.ResourceTypesCty) != 2 || len(schResp.Functions) != 2 {
+		t.Errorf("wrong merged schema: %#v", schResp)
+	}
+
+	for typeName, want := range map[string]string{"foo_a": "sdk", "foo_b": "fw"} {
+		resp, diags := c.ReadResource(ctx, typ.ReadResourceRequest{TypeName: typeName})
+		if diags.HasErrors() {
+			t.Fatal(diags.Err())
+		}
+		if string(resp.Private) != want {
+			t.Errorf("%s: routed to %q, expect %q", typeName, resp.Private, want)
+		}
+	}
+	if _, diags := c.ReadResource(ctx, typ.ReadResourceRequest{TypeName: "foo_c"}); !diags.HasErrors() {
+		t.Errorf("expect error for the unknown resource type")
+	}
+
+	if _, diags := c.ConfigureProvider(ctx, typ.ConfigureProviderRequest{}); diags.HasErrors() {
+		t.Fatal(diags.Err())
+	}
+	if !sdk.configured || !fw.configured {
+		t.Errorf("every client should be configured")
+	}
+	if err := c.Stop(ctx); err == nil || err.Error() != "boom" {
+		t.Errorf("wrong stop error: %v", err)
+	}
+	c.Close()
+	if !sdk.closed || !fw.closed {
+		t.Errorf("every client should be closed")
+	}
+}
+
+func TestMuxDuplicate(t *testing.T) {
+	first := &muxFakeClient{name: "first", resources: []string{"foo_a"}}
+	second := &muxFakeClient{name: "second", resources: []string{"foo_a"}}
+	c, diags := Mux(first, second)
+	if len(diags) != 1 || !diags.HasErrors() {
+		t.Fatalf("wrong diagnostics: %v", diags)
+	}
+	// The returned client stays usable, with the conflicts reported as warnings.
+	if _, sdiags := c.GetProviderSchema(); len(sdiags) != 1 || sdiags.HasErrors() {
+		t.Errorf("wrong schema diagnostics: %v", sdiags)
+	}
+
+	resp, _ := c.ReadResource(context.Background(), typ.ReadResourceRequest{TypeName: "foo_a"})
+	if string(resp.Private) != "first" {
+		t.Errorf("duplicate type should be routed to the first client")
+	}
+}
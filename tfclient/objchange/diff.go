@@ -0,0 +1,92 @@
+package objchange
+
+import (
+	"slices"
+	"sort"
+
+	"github.com/zclconf/go-cty/cty"
+)
+
+// ValueDiff is a difference between two values at the path.
+type ValueDiff struct {
+	Path          cty.Path
+	Before, After cty.Value
+}
+
+// ValueDiffs returns the differences between two values, at the deepest paths where the structures of both
+// values still match. The marks are ignored in comparison, but are kept in the returned values, including the
+// marks of their containing values. The set elements are compared as a whole as they have no paths.
+func ValueDiffs(before, after cty.Value) []ValueDiff {
+	ub, _ := before.UnmarkDeep()
+	ua, _ := after.UnmarkDeep()
+	diffs := valueDiffs(nil, ub, ua)
+	for i, d := range diffs {
+		// Applying the path to the marked value results in the value with the marks along the path.
+		if v, err := d.Path.Apply(before); err == nil {
+			diffs[i].Before = v
+		}
+		if v, err := d.Path.Apply(after); err == nil {
+			diffs[i].After = v
+		}
+	}
+	return diffs
+}
+
+func valueDiffs(path cty.Path, before, after cty.Value) []ValueDiff {
+	diff := []ValueDiff{{Path: path, Before: before, After: after}}
+
+	ty := before.Type()
+	if !ty.Equals(after.Type()) {
+		return diff
+	}
+	if before.IsNull() || after.IsNull() || !before.IsKnown() || !after.IsKnown() {
+		if before.RawEquals(after) {
+			return nil
+		}
+		return diff
+	}
+
+	switch {
+	case ty.IsObjectType():
+		var diffs []ValueDiff
+		for _, name := range sortedKeys(ty.AttributeTypes()) {
+			diffs = append(diffs, valueDiffs(path.GetAttr(name), before.GetAttr(name), after.GetAttr(name))...)
+		}
+		return diffs
+	case ty.IsListType() || ty.IsTupleType():
+		if before.LengthInt() != after.LengthInt() {
+			return diff
+		}
+		var diffs []ValueDiff
+		for i := 0; i < before.LengthInt(); i++ {
+			idx := cty.NumberIntVal(int64(i))
+			diffs = append(diffs, valueDiffs(path.Index(idx), before.Index(idx), after.Index(idx))...)
+		}
+		return diffs
+	case ty.IsMapType():
+		keys := sortedKeys(before.AsValueMap())
+		if !slices.Equal(keys, sortedKeys(after.AsValueMap())) {
+			return diff
+		}
+		var diffs []ValueDiff
+		for _, k := range keys {
+			key := cty.StringVal(k)
+			diffs = append(diffs, valueDiffs(path.Index(key), before.Index(key), after.Index(key))...)
+		}
+		return diffs
+	default:
+		if before.RawEquals(after) {
+			return nil
+		}
+		return diff
+	}
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
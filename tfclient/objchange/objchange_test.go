@@ -0,0 +1,327 @@
+package objchange
+
+import (
+	"testing"
+
+	tfjson "github.com/hashicorp/terraform-json"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// Mimic TestProposedNew
+func TestProposedNew(t *testing.T) {
+	tests := map[string]struct {
+		Schema *tfjson.SchemaBlock
+		Prior  cty.Value
+		Config cty.Value
+		Want   cty.Value
+	}{
+		"empty": {
+			&tfjson.SchemaBlock{},
+			cty.EmptyObjectVal,
+			cty.EmptyObjectVal,
+			cty.EmptyObjectVal,
+		},
+		"no prior": {
+			&tfjson.SchemaBlock{
+				Attributes: map[string]*tfjson.SchemaAttribute{
+					"foo": {AttributeType: cty.String, Optional: true},
+					"bar": {AttributeType: cty.String, Computed: true},
+				},
+				NestedBlocks: map[string]*tfjson.SchemaBlockType{
+					"baz": {
+						NestingMode: tfjson.SchemaNestingModeSingle,
+						Block: &tfjson.SchemaBlock{
+							Attributes: map[string]*tfjson.SchemaAttribute{
+								"boz": {AttributeType: cty.String, Optional: true, Computed: true},
+							},
+						},
+					},
+				},
+			},
+			cty.NullVal(cty.DynamicPseudoType),
+			cty.ObjectVal(map[string]cty.Value{
+				"foo": cty.StringVal("hello"),
+				"bar": cty.NullVal(cty.String),
+				"baz": cty.ObjectVal(map[string]cty.Value{
+					"boz": cty.StringVal("world"),
+				}),
+			}),
+			cty.ObjectVal(map[string]cty.Value{
+				"foo": cty.StringVal("hello"),
+				"bar": cty.NullVal(cty.String),
+				"baz": cty.ObjectVal(map[string]cty.Value{
+					"boz": cty.StringVal("world"),
+				}),
+			}),
+		},
+		"prior attributes": {
+			&tfjson.SchemaBlock{
+				Attributes: map[string]*tfjson.SchemaAttribute{
+					"foo": {AttributeType: cty.String, Optional: true},
+					"bar": {AttributeType: cty.String, Computed: true},
+					"baz": {AttributeType: cty.String, Optional: true, Computed: true},
+					"boz": {AttributeType: cty.String, Optional: true, Computed: true},
+				},
+			},
+			cty.ObjectVal(map[string]cty.Value{
+				"foo": cty.StringVal("bonjour"),
+				"bar": cty.StringVal("petit dejeuner"),
+				"baz": cty.StringVal("grande dejeuner"),
+				"boz": cty.StringVal("a la monde"),
+			}),
+			cty.ObjectVal(map[string]cty.Value{
+				"foo": cty.StringVal("hello"),
+				"bar": cty.NullVal(cty.String),
+				"baz": cty.NullVal(cty.String),
+				"boz": cty.StringVal("world"),
+			}),
+			cty.ObjectVal(map[string]cty.Value{
+				"foo": cty.StringVal("hello"),
+				"bar": cty.StringVal("petit dejeuner"),
+				"baz": cty.StringVal("grande dejeuner"),
+				"boz": cty.StringVal("world"),
+			}),
+		},
+		"nested list": {
+			&tfjson.SchemaBlock{
+				NestedBlocks: map[string]*tfjson.SchemaBlockType{
+					"foo": {
+						NestingMode: tfjson.SchemaNestingModeList,
+						Block: &tfjson.SchemaBlock{
+							Attributes: map[string]*tfjson.SchemaAttribute{
+								"bar": {AttributeType: cty.String, Optional: true},
+								"baz": {AttributeType: cty.String, Computed: true},
+							},
+						},
+					},
+				},
+			},
+			cty.ObjectVal(map[string]cty.Value{
+				"foo": cty.ListVal([]cty.Value{
+					cty.ObjectVal(map[string]cty.Value{
+						"bar": cty.StringVal("beep"),
+						"baz": cty.StringVal("boop"),
+					}),
+				}),
+			}),
+			cty.ObjectVal(map[string]cty.Value{
+				"foo": cty.ListVal([]cty.Value{
+					cty.ObjectVal(map[string]cty.Value{
+						"bar": cty.StringVal("bap"),
+						"baz": cty.NullVal(cty.String),
+					}),
+					cty.ObjectVal(map[string]cty.Value{
+						"bar": cty.StringVal("blep"),
+						"baz": cty.NullVal(cty.String),
+					}),
+				}),
+			}),
+			cty.ObjectVal(map[string]cty.Value{
+				"foo": cty.ListVal([]cty.Value{
+					cty.ObjectVal(map[string]cty.Value{
+						"bar": cty.StringVal("bap"),
+						"baz": cty.StringVal("boop"),
+					}),
+					cty.ObjectVal(map[string]cty.Value{
+						"bar": cty.StringVal("blep"),
+						"baz": cty.NullVal(cty.String),
+					}),
+				}),
+			}),
+		},
+		"nested set": {
+			&tfjson.SchemaBlock{
+				NestedBlocks: map[string]*tfjson.SchemaBlockType{
+					"foo": {
+						NestingMode: tfjson.SchemaNestingModeSet,
+						Block: &tfjson.SchemaBlock{
+							Attributes: map[string]*tfjson.SchemaAttribute{
+								"bar": {AttributeType: cty.String, Optional: true},
+								"baz": {AttributeType: cty.String, Computed: true},
+							},
+						},
+					},
+				},
+			},
+			cty.ObjectVal(map[string]cty.Value{
+				"foo": cty.SetVal([]cty.Value{
+					cty.ObjectVal(map[string]cty.Value{
+						"bar": cty.StringVal("beep"),
+						"baz": cty.StringVal("boop"),
+					}),
+					cty.ObjectVal(map[string]cty.Value{
+						"bar": cty.StringVal("blep"),
+						"baz": cty.StringVal("boot"),
+					}),
+				}),
+			}),
+			cty.ObjectVal(map[string]cty.Value{
+				"foo": cty.SetVal([]cty.Value{
+					cty.ObjectVal(map[string]cty.Value{
+						"bar": cty.StringVal("blep"),
+						"baz": cty.NullVal(cty.String),
+					}),
+					cty.ObjectVal(map[string]cty.Value{
+						"bar": cty.StringVal("bosh"),
+						"baz": cty.NullVal(cty.String),
+					}),
+				}),
+			}),
+			cty.ObjectVal(map[string]cty.Value{
+				"foo": cty.SetVal([]cty.Value{
+					cty.ObjectVal(map[string]cty.Value{
+						"bar": cty.StringVal("blep"),
+						"baz": cty.StringVal("boot"),
+					}),
+					cty.ObjectVal(map[string]cty.Value{
+						"bar": cty.StringVal("bosh"),
+						"baz": cty.NullVal(cty.String),
+					}),
+				}),
+			}),
+		},
+		"nested attribute single": {
+			&tfjson.SchemaBlock{
+				Attributes: map[string]*tfjson.SchemaAttribute{
+					"foo": {
+						Optional: true,
+						AttributeNestedType: &tfjson.SchemaNestedAttributeType{
+							NestingMode: tfjson.SchemaNestingModeSingle,
+							Attributes: map[string]*tfjson.SchemaAttribute{
+								"bar": {AttributeType: cty.String, Optional: true},
+								"baz": {AttributeType: cty.String, Computed: true},
+							},
+						},
+					},
+				},
+			},
+			cty.ObjectVal(map[string]cty.Value{
+				"foo": cty.ObjectVal(map[string]cty.Value{
+					"bar": cty.StringVal("beep"),
+					"baz": cty.StringVal("boop"),
+				}),
+			}),
+			cty.ObjectVal(map[string]cty.Value{
+				"foo": cty.ObjectVal(map[string]cty.Value{
+					"bar": cty.StringVal("bap"),
+					"baz": cty.NullVal(cty.String),
+				}),
+			}),
+			cty.ObjectVal(map[string]cty.Value{
+				"foo": cty.ObjectVal(map[string]cty.Value{
+					"bar": cty.StringVal("bap"),
+					"baz": cty.StringVal("boop"),
+				}),
+			}),
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			got := ProposedNew(test.Schema, test.Prior, test.Config)
+			if !got.RawEquals(test.Want) {
+				t.Errorf("wrong result\ngot:  %#v\nwant: %#v", got, test.Want)
+			}
+		})
+	}
+}
+
+func TestNormalizeObjectFromLegacySDK(t *testing.T) {
+	block := &tfjson.SchemaBlock{
+		Attributes: map[string]*tfjson.SchemaAttribute{
+			"id": {AttributeType: cty.String, Computed: true},
+		},
+		NestedBlocks: map[string]*tfjson.SchemaBlockType{
+			"list": {
+				NestingMode: tfjson.SchemaNestingModeList,
+				Block: &tfjson.SchemaBlock{
+					Attributes: map[string]*tfjson.SchemaAttribute{"a": {AttributeType: cty.String, Optional: true}},
+				},
+			},
+			"set": {
+				NestingMode: tfjson.SchemaNestingModeSet,
+				Block: &tfjson.SchemaBlock{
+					Attributes: map[string]*tfjson.SchemaAttribute{"a": {AttributeType: cty.String, Optional: true}},
+				},
+			},
+			"single": {
+				NestingMode: tfjson.SchemaNestingModeSingle,
+				Block: &tfjson.SchemaBlock{
+					Attributes: map[string]*tfjson.SchemaAttribute{"a": {AttributeType: cty.String, Optional: true}},
+				},
+			},
+		},
+	}
+	aTy := cty.Object(map[string]cty.Type{"a": cty.String})
+
+	tests := map[string]struct {
+		Input cty.Value
+		Want  cty.Value
+	}{
+		"null blocks": {
+			cty.ObjectVal(map[string]cty.Value{
+				"id":     cty.StringVal("1"),
+				"list":   cty.NullVal(cty.List(aTy)),
+				"set":    cty.NullVal(cty.Set(aTy)),
+				"single": cty.NullVal(aTy),
+			}),
+			cty.ObjectVal(map[string]cty.Value{
+				"id":     cty.StringVal("1"),
+				"list":   cty.ListValEmpty(aTy),
+				"set":    cty.SetValEmpty(aTy),
+				"single": cty.NullVal(aTy),
+			}),
+		},
+		"unknown blocks": {
+			cty.ObjectVal(map[string]cty.Value{
+				"id":     cty.UnknownVal(cty.String),
+				"list":   cty.UnknownVal(cty.List(aTy)),
+				"set":    cty.UnknownVal(cty.Set(aTy)),
+				"single": cty.UnknownVal(aTy),
+			}),
+			cty.ObjectVal(map[string]cty.Value{
+				"id":     cty.UnknownVal(cty.String),
+				"list":   cty.ListVal([]cty.Value{cty.ObjectVal(map[string]cty.Value{"a": cty.UnknownVal(cty.String)})}),
+				"set":    cty.SetVal([]cty.Value{cty.ObjectVal(map[string]cty.Value{"a": cty.UnknownVal(cty.String)})}),
+				"single": cty.ObjectVal(map[string]cty.Value{"a": cty.UnknownVal(cty.String)}),
+			}),
+		},
+		"known blocks": {
+			cty.ObjectVal(map[string]cty.Value{
+				"id":     cty.StringVal("1"),
+				"list":   cty.ListVal([]cty.Value{cty.ObjectVal(map[string]cty.Value{"a": cty.StringVal("x")})}),
+				"set":    cty.SetVal([]cty.Value{cty.ObjectVal(map[string]cty.Value{"a": cty.StringVal("y")})}),
+				"single": cty.ObjectVal(map[string]cty.Value{"a": cty.NullVal(cty.String)}),
+			}),
+			cty.ObjectVal(map[string]cty.Value{
+				"id":     cty.StringVal("1"),
+				"list":   cty.ListVal([]cty.Value{cty.ObjectVal(map[string]cty.Value{"a": cty.StringVal("x")})}),
+				"set":    cty.SetVal([]cty.Value{cty.ObjectVal(map[string]cty.Value{"a": cty.StringVal("y")})}),
+				"single": cty.ObjectVal(map[string]cty.Value{"a": cty.NullVal(cty.String)}),
+			}),
+		},
+		"marks kept": {
+			cty.ObjectVal(map[string]cty.Value{
+				"id":     cty.StringVal("1").Mark("sensitive"),
+				"list":   cty.NullVal(cty.List(aTy)),
+				"set":    cty.NullVal(cty.Set(aTy)),
+				"single": cty.NullVal(aTy),
+			}),
+			cty.ObjectVal(map[string]cty.Value{
+				"id":     cty.StringVal("1").Mark("sensitive"),
+				"list":   cty.ListValEmpty(aTy),
+				"set":    cty.SetValEmpty(aTy),
+				"single": cty.NullVal(aTy),
+			}),
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			got := NormalizeObjectFromLegacySDK(test.Input, block)
+			if !got.RawEquals(test.Want) {
+				t.Errorf("wrong result\ngot:  %#v\nwant: %#v", got, test.Want)
+			}
+		})
+	}
+}
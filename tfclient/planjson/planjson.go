@@ -0,0 +1,445 @@
+// Package planjson converts the results of PlanResourceChange to the github.com/hashicorp/terraform-json documents,
+// in the same encoding as the JSON output of `terraform show -json`.
+package planjson
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	tfjson "github.com/hashicorp/terraform-json"
+	"github.com/magodo/terraform-client-go/tfclient/configschema"
+	"github.com/magodo/terraform-client-go/tfclient/diffrender"
+	"github.com/magodo/terraform-client-go/tfclient/marks"
+	"github.com/magodo/terraform-client-go/tfclient/typ"
+	"github.com/zclconf/go-cty/cty"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
+)
+
+// FormatVersion is the format version of the plan documents built by Plan.
+const FormatVersion = "1.2"
+
+// Change is a planned change of a managed resource instance.
+type Change struct {
+	// ModuleAddress is the address of the module containing the resource, e.g. `module.a`. Empty for the root module.
+	ModuleAddress string
+	// Name is the resource name. Defaults to "this".
+	Name string
+	// Index is the instance key, which is either an int or a string. Nil for no instance key.
+	Index any
+	// ProviderName is the provider source address, e.g. `registry.terraform.io/hashicorp/foo`.
+	ProviderName string
+	// CreateBeforeDestroy makes the replacement to be "create" then "delete".
+	CreateBeforeDestroy bool
+
+	Schema   tfjson.Schema
+	Request  typ.PlanResourceChangeRequest
+	Response *typ.PlanResourceChangeResponse
+}
+
+// Address returns the resource instance address.
+func (c Change) Address() string {
+	name := c.Name
+	if name == "" {
+		name = "this"
+	}
+	addr := c.Request.TypeName + "." + name
+	if c.ModuleAddress != "" {
+		addr = c.ModuleAddress + "." + addr
+	}
+	switch idx := c.Index.(type) {
+	case nil:
+	case string:
+		addr += fmt.Sprintf("[%q]", idx)
+	default:
+		addr += fmt.Sprintf("[%v]", idx)
+	}
+	return addr
+}
+
+// ResourceChange converts the change. The values marked as sensitive, either by the marks or by the schema, are
+// recorded in the "before_sensitive" and "after_sensitive". The unknown values are omitted from the "after", but
+// recorded in the "after_unknown".
+func ResourceChange(c Change) (*tfjson.ResourceChange, error) {
+	if c.Response == nil {
+		return nil, fmt.Errorf("nil response")
+	}
+	name := c.Name
+	if name == "" {
+		name = "this"
+	}
+	ty := configschema.SchemaBlockImpliedType(c.Schema.Block)
+	before, after := c.Request.PriorState, c.Response.PlannedState
+	if before == cty.NilVal {
+		before = cty.NullVal(ty)
+	}
+	if after == cty.NilVal {
+		after = cty.NullVal(ty)
+	}
+
+	change := &tfjson.Change{
+		Actions: actions(diffrender.ChangeAction(before, after, c.Response.RequiresReplace), c.CreateBeforeDestroy),
+	}
+	var err error
+	if change.Before, err = encodeValue(before, false); err != nil {
+		return nil, fmt.Errorf("encoding the before value: %v", err)
+	}
+	if change.After, err = encodeValue(after, true); err != nil {
+		return nil, fmt.Errorf("encoding the after value: %v", err)
+	}
+	afterUnknown := cty.EmptyObjectVal
+	if unmarked, _ := after.UnmarkDeep(); !unmarked.IsWhollyKnown() {
+		afterUnknown = unknownAsBool(unmarked)
+	}
+	if change.AfterUnknown, err = encodeValue(afterUnknown, false); err != nil {
+		return nil, fmt.Errorf("encoding the after unknown value: %v", err)
+	}
+	if change.BeforeSensitive, err = encodeValue(sensitiveAsBool(c.Schema.Block, before), false); err != nil {
+		return nil, fmt.Errorf("encoding the before sensitive value: %v", err)
+	}
+	if change.AfterSensitive, err = encodeValue(sensitiveAsBool(c.Schema.Block, after), false); err != nil {
+		return nil, fmt.Errorf("encoding the after sensitive value: %v", err)
+	}
+	for _, p := range c.Response.RequiresReplace {
+		path, err := encodePath(p)
+		if err != nil {
+			return nil, fmt.Errorf("encoding the replace path: %v", err)
+		}
+		change.ReplacePaths = append(change.ReplacePaths, path)
+	}
+	if id := c.Request.PriorIdentity; id != cty.NilVal && !id.IsNull() {
+		if change.BeforeIdentity, err = encodeValue(id, false); err != nil {
+			return nil, fmt.Errorf("encoding the before identity: %v", err)
+		}
+	}
+	if id := c.Response.PlannedIdentity; id != cty.NilVal && !id.IsNull() {
+		if change.AfterIdentity, err = encodeValue(id, true); err != nil {
+			return nil, fmt.Errorf("encoding the after identity: %v", err)
+		}
+	}
+
+	var index any
+	switch idx := c.Index.(type) {
+	case nil:
+	case string:
+		index = idx
+	default:
+		index = json.Number(fmt.Sprint(idx))
+	}
+
+	return &tfjson.ResourceChange{
+		Address:       c.Address(),
+		ModuleAddress: c.ModuleAddress,
+		Mode:          tfjson.ManagedResourceMode,
+		Type:          c.Request.TypeName,
+		Name:          name,
+		Index:         index,
+		ProviderName:  c.ProviderName,
+		Change:        change,
+	}, nil
+}
+
+// actions returns the actions of terraform-json for the action.
+func actions(act diffrender.Action, createBeforeDestroy bool) tfjson.Actions {
+	switch act {
+	case diffrender.Create:
+		return tfjson.Actions{tfjson.ActionCreate}
+	case diffrender.Update:
+		return tfjson.Actions{tfjson.ActionUpdate}
+	case diffrender.Delete:
+		return tfjson.Actions{tfjson.ActionDelete}
+	case diffrender.Replace:
+		if createBeforeDestroy {
+			return tfjson.Actions{tfjson.ActionCreate, tfjson.ActionDelete}
+		}
+		return tfjson.Actions{tfjson.ActionDelete, tfjson.ActionCreate}
+	default:
+		return tfjson.Actions{tfjson.ActionNoop}
+	}
+}
+
+// Plan builds a plan document of the resource changes, whose planned values are the after values of the changes
+// that are not deleting the resources.
+func Plan(changes []*tfjson.ResourceChange) (*tfjson.Plan, error) {
+	root := &tfjson.StateModule{}
+	modules := map[string]*tfjson.StateModule{"": root}
+	for _, rc := range changes {
+		if rc.Change == nil || rc.Change.Actions.Delete() {
+			continue
+		}
+		var values map[string]any
+		if rc.Change.After != nil {
+			var ok bool
+			values, ok = rc.Change.After.(map[string]any)
+			if !ok {
+				return nil, fmt.Errorf("the after value of %s is not an object", rc.Address)
+			}
+		}
+		sensitive, err := json.Marshal(rc.Change.AfterSensitive)
+		if err != nil {
+			return nil, fmt.Errorf("encoding the after sensitive value of %s: %v", rc.Address, err)
+		}
+		mod := stateModule(modules, rc.ModuleAddress)
+		mod.Resources = append(mod.Resources, &tfjson.StateResource{
+			Address:         rc.Address,
+			Mode:            rc.Mode,
+			Type:            rc.Type,
+			Name:            rc.Name,
+			Index:           rc.Index,
+			ProviderName:    rc.ProviderName,
+			AttributeValues: values,
+			SensitiveValues: sensitive,
+		})
+	}
+
+	return &tfjson.Plan{
+		FormatVersion:   FormatVersion,
+		PlannedValues:   &tfjson.StateValues{RootModule: root},
+		ResourceChanges: changes,
+	}, nil
+}
+
+// stateModule returns the module of the address, which is created together with its ancestors if absent.
+func stateModule(modules map[string]*tfjson.StateModule, addr string) *tfjson.StateModule {
+	if mod, ok := modules[addr]; ok {
+		return mod
+	}
+	var parentAddr string
+	if i := strings.LastIndex(addr, ".module."); i != -1 {
+		parentAddr = addr[:i]
+	}
+	parent := stateModule(modules, parentAddr)
+	mod := &tfjson.StateModule{Address: addr}
+	parent.ChildModules = append(parent.ChildModules, mod)
+	modules[addr] = mod
+	return mod
+}
+
+// encodeValue encodes the value to the decoded JSON form, where the numbers are json.Number.
+// The unknown values are omitted if omitUnknown is set, otherwise they are not allowed.
+func encodeValue(v cty.Value, omitUnknown bool) (any, error) {
+	v, _ = v.UnmarkDeep()
+	if omitUnknown && !v.IsWhollyKnown() {
+		v = omitUnknowns(v)
+		if v == cty.NilVal {
+			return nil, nil
+		}
+	}
+	b, err := ctyjson.Marshal(v, v.Type())
+	if err != nil {
+		return nil, err
+	}
+	return decodeJSON(b)
+}
+
+func decodeJSON(b []byte) (any, error) {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	var out any
+	if err := dec.Decode(&out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// encodePath encodes the path as an array of the steps, where an attribute step is the name, and an index step is
+// the key.
+func encodePath(path cty.Path) ([]any, error) {
+	steps := []any{}
+	for _, step := range path {
+		switch s := step.(type) {
+		case cty.IndexStep:
+			b, err := ctyjson.Marshal(s.Key, s.Key.Type())
+			if err != nil {
+				return nil, err
+			}
+			key, err := decodeJSON(b)
+			if err != nil {
+				return nil, err
+			}
+			steps = append(steps, key)
+		case cty.GetAttrStep:
+			steps = append(steps, s.Name)
+		default:
+			return nil, fmt.Errorf("unsupported path step %#v", step)
+		}
+	}
+	return steps, nil
+}
+
+// sensitiveAsBool marks the value by the schema, then converts it to the sensitivity structure of the JSON output.
+func sensitiveAsBool(schema *tfjson.SchemaBlock, v cty.Value) cty.Value {
+	return SensitiveAsBool(v.MarkWithPaths(configschema.SchemaBlockValueMarks(schema, v, nil)))
+}
+
+// This is derived from github.com/hashicorp/terraform/internal/command/jsonplan/plan.go (v1.13.0-alpha20250521)
+
+// omitUnknowns recursively walks the src cty.Value and returns a new cty.Value,
+// omitting any unknowns.
+//
+// The result also normalizes some types: all sequence types are turned into
+// tuple types and all mapping types are converted to object types, since we
+// assume the result of this is just going to be serialized as JSON (and thus
+// lose those distinctions) anyway.
+func omitUnknowns(val cty.Value) cty.Value {
+	ty := val.Type()
+	switch {
+	case val.IsNull():
+		return val
+	case !val.IsKnown():
+		return cty.NilVal
+	case ty.IsPrimitiveType():
+		return val
+	case ty.IsListType() || ty.IsTupleType() || ty.IsSetType():
+		var vals []cty.Value
+		it := val.ElementIterator()
+		for it.Next() {
+			_, v := it.Element()
+			newVal := omitUnknowns(v)
+			if newVal != cty.NilVal {
+				vals = append(vals, newVal)
+			} else if newVal == cty.NilVal {
+				// element order is how we correlate unknownness, so we must
+				// replace unknowns with nulls
+				vals = append(vals, cty.NullVal(v.Type()))
+			}
+		}
+		// We use tuple types always here, because the work we did above
+		// may have caused the individual elements to have different types,
+		// and we're doing this work to produce JSON anyway and JSON marshalling
+		// represents all of these sequence types as an array.
+		return cty.TupleVal(vals)
+	case ty.IsMapType() || ty.IsObjectType():
+		vals := make(map[string]cty.Value)
+		it := val.ElementIterator()
+		for it.Next() {
+			k, v := it.Element()
+			newVal := omitUnknowns(v)
+			if newVal != cty.NilVal {
+				vals[k.AsString()] = newVal
+			}
+		}
+		// We use object types always here, because the work we did above
+		// may have caused the individual elements to have different types,
+		// and we're doing this work to produce JSON anyway and JSON marshalling
+		// represents both of these mapping types as an object.
+		return cty.ObjectVal(vals)
+	default:
+		// Should never happen, since the above should cover all types
+		panic(fmt.Sprintf("omitUnknowns cannot handle %#v", val))
+	}
+}
+
+// unknownAsBool recursively walks the src cty.Value and returns a new
+// cty.Value, with the known values replaced by false and the unknown ones by true.
+// The false values of the mapping types are omitted for more compact serialization.
+//
+// As with omitUnknowns, the result normalizes the sequence types into tuples
+// and the mapping types into objects.
+func unknownAsBool(val cty.Value) cty.Value {
+	ty := val.Type()
+	switch {
+	case val.IsNull():
+		return cty.False
+	case !val.IsKnown():
+		return cty.True
+	case ty.IsPrimitiveType():
+		return cty.False
+	case ty.IsListType() || ty.IsTupleType() || ty.IsSetType():
+		length := val.LengthInt()
+		if length == 0 {
+			// If there are no elements then we can't have unknowns
+			return cty.EmptyTupleVal
+		}
+		vals := make([]cty.Value, 0, length)
+		it := val.ElementIterator()
+		for it.Next() {
+			_, v := it.Element()
+			vals = append(vals, unknownAsBool(v))
+		}
+		return cty.TupleVal(vals)
+	case ty.IsMapType() || ty.IsObjectType():
+		vals := make(map[string]cty.Value)
+		it := val.ElementIterator()
+		for it.Next() {
+			k, v := it.Element()
+			vAsBool := unknownAsBool(v)
+			// Omit all of the "false"s for known values for more compact
+			// serialization
+			if !vAsBool.RawEquals(cty.False) {
+				vals[k.AsString()] = vAsBool
+			}
+		}
+		return cty.ObjectVal(vals)
+	default:
+		// Should never happen, since the above should cover all types
+		panic(fmt.Sprintf("unknownAsBool cannot handle %#v", val))
+	}
+}
+
+// This is derived from github.com/hashicorp/terraform/internal/command/jsonstate/state.go (v1.13.0-alpha20250521)
+
+// SensitiveAsBool returns the sensitivity structure of the value in the JSON output, where the values marked as
+// sensitive are true. The false values of the mapping types are omitted for more compact serialization.
+func SensitiveAsBool(val cty.Value) cty.Value {
+	if val.HasMark(marks.Sensitive) {
+		return cty.True
+	}
+	val, _ = val.Unmark()
+
+	ty := val.Type()
+	switch {
+	case val.IsNull(), ty.IsPrimitiveType(), ty.Equals(cty.DynamicPseudoType):
+		return cty.False
+	case ty.IsListType() || ty.IsTupleType() || ty.IsSetType():
+		if !val.IsKnown() {
+			// If the collection is unknown we can't say anything about the
+			// sensitivity of its contents
+			return cty.EmptyTupleVal
+		}
+		length := val.LengthInt()
+		if length == 0 {
+			// If there are no elements then we can't have sensitive values
+			return cty.EmptyTupleVal
+		}
+		vals := make([]cty.Value, 0, length)
+		it := val.ElementIterator()
+		for it.Next() {
+			_, v := it.Element()
+			vals = append(vals, SensitiveAsBool(v))
+		}
+		// The above transform may have changed the types of some of the
+		// elements, so we'll always use a tuple here in case we've now made
+		// different elements have different types. Our ultimate goal is to
+		// marshal to JSON anyway, and all of these sequence types are
+		// indistinguishable in JSON.
+		return cty.TupleVal(vals)
+	case ty.IsMapType() || ty.IsObjectType():
+		if !val.IsKnown() {
+			// If the map/object is unknown we can't say anything about the
+			// sensitivity of its attributes
+			return cty.EmptyObjectVal
+		}
+		vals := make(map[string]cty.Value)
+		it := val.ElementIterator()
+		for it.Next() {
+			k, v := it.Element()
+			s := SensitiveAsBool(v)
+			// Omit all of the "false"s for non-sensitive values for more
+			// compact serialization
+			if !s.RawEquals(cty.False) {
+				vals[k.AsString()] = s
+			}
+		}
+		// The above transform may have changed the types of some of the
+		// elements, so we'll always use an object here in case we've now made
+		// different elements have different types. Our ultimate goal is to
+		// marshal to JSON anyway, and all of these mapping types are
+		// indistinguishable in JSON.
+		return cty.ObjectVal(vals)
+	default:
+		// Should never happen, since the above should cover all types
+		panic(fmt.Sprintf("sensitiveAsBool cannot handle %#v", val))
+	}
+}
@@ -0,0 +1,157 @@
+package planjson
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	tfjson "github.com/hashicorp/terraform-json"
+	"github.com/magodo/terraform-client-go/tfclient/typ"
+	"github.com/zclconf/go-cty/cty"
+)
+
+var thingSchema = tfjson.Schema{
+	Block: &tfjson.SchemaBlock{
+		Attributes: map[string]*tfjson.SchemaAttribute{
+			"id":       {AttributeType: cty.String, Computed: true},
+			"name":     {AttributeType: cty.String, Required: true},
+			"password": {AttributeType: cty.String, Optional: true, Sensitive: true},
+			"tags":     {AttributeType: cty.Map(cty.String), Optional: true},
+		},
+	},
+}
+
+func thing(id, name, password cty.Value, tags map[string]cty.Value) cty.Value {
+	tagsVal := cty.NullVal(cty.Map(cty.String))
+	if tags != nil {
+		tagsVal = cty.MapVal(tags)
+	}
+	return cty.ObjectVal(map[string]cty.Value{
+		"id":       id,
+		"name":     name,
+		"password": password,
+		"tags":     tagsVal,
+	})
+}
+
+func toJSON(t *testing.T, v any) string {
+	t.Helper()
+	b, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+func TestResourceChange(t *testing.T) {
+	prior := thing(cty.StringVal("1"), cty.StringVal("a"), cty.StringVal("secret"), map[string]cty.Value{"k": cty.StringVal("v")})
+
+	cases := []struct {
+		name   string
+		change Change
+		want   string
+	}{
+		{
+			name: "create",
+			change: Change{
+				ModuleAddress: "module.m",
+				Name:          "a",
+				Index:         0,
+				ProviderName:  "registry.terraform.io/hashicorp/foo",
+				Schema:        thingSchema,
+				Request:       typ.PlanResourceChangeRequest{TypeName: "foo_thing", PriorState: cty.NullVal(prior.Type())},
+				Response: &typ.PlanResourceChangeResponse{
+					PlannedState: thing(cty.UnknownVal(cty.String), cty.StringVal("a"), cty.StringVal("secret"), map[string]cty.Value{"k": cty.UnknownVal(cty.String)}),
+				},
+			},
+			want: `{"address":"module.m.foo_thing.a[0]","module_address":"module.m","mode":"managed","type":"foo_thing","name":"a","index":0,"provider_name":"registry.terraform.io/hashicorp/foo",` +
+				`"change":{"actions":["create"],"before":null,"after":{"name":"a","password":"secret","tags":{}},"after_unknown":{"id":true,"tags":{"k":true}},` +
+				`"before_sensitive":false,"after_sensitive":{"password":true,"tags":{}}}}`,
+		},
+		{
+			name: "replace",
+			change: Change{
+				Index:   "x",
+				Schema:  thingSchema,
+				Request: typ.PlanResourceChangeRequest{TypeName: "foo_thing", PriorState: prior},
+				Response: &typ.PlanResourceChangeResponse{
+					PlannedState:    thing(cty.UnknownVal(cty.String), cty.StringVal("b"), cty.NullVal(cty.String), nil),
+					RequiresReplace: []cty.Path{cty.GetAttrPath("name"), cty.GetAttrPath("tags").IndexString("k")},
+				},
+				CreateBeforeDestroy: true,
+			},
+			want: `{"address":"foo_thing.this[\"x\"]","mode":"managed","type":"foo_thing","name":"this","index":"x",` +
+				`"change":{"actions":["create","delete"],"before":{"id":"1","name":"a","password":"secret","tags":{"k":"v"}},"after":{"name":"b","password":null,"tags":null},` +
+				`"after_unknown":{"id":true},"before_sensitive":{"password":true,"tags":{}},"after_sensitive":{"password":true},"replace_paths":[["name"],["tags","k"]]}}`,
+		},
+		{
+			name: "delete",
+			change: Change{
+				Schema:   thingSchema,
+				Request:  typ.PlanResourceChangeRequest{TypeName: "foo_thing", PriorState: prior},
+				Response: &typ.PlanResourceChangeResponse{PlannedState: cty.NullVal(prior.Type())},
+			},
+			want: `{"address":"foo_thing.this","mode":"managed","type":"foo_thing","name":"this",` +
+				`"change":{"actions":["delete"],"before":{"id":"1","name":"a","password":"secret","tags":{"k":"v"}},"after_unknown":{},"before_sensitive":{"password":true,"tags":{}},"after_sensitive":false}}`,
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			rc, err := ResourceChange(tt.change)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tt.want, toJSON(t, rc)); diff != "" {
+				t.Errorf("wrong resource change\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestPlan(t *testing.T) {
+	prior := thing(cty.StringVal("1"), cty.StringVal("a"), cty.NullVal(cty.String), nil)
+	var changes []*tfjson.ResourceChange
+	for _, c := range []Change{
+		{
+			ModuleAddress: "module.a.module.b",
+			Name:          "kept",
+			Request:       typ.PlanResourceChangeRequest{TypeName: "foo_thing", PriorState: prior},
+			Response:      &typ.PlanResourceChangeResponse{PlannedState: prior},
+		},
+		{
+			Name:     "deleted",
+			Request:  typ.PlanResourceChangeRequest{TypeName: "foo_thing", PriorState: prior},
+			Response: &typ.PlanResourceChangeResponse{PlannedState: cty.NullVal(prior.Type())},
+		},
+	} {
+		c.Schema = thingSchema
+		rc, err := ResourceChange(c)
+		if err != nil {
+			t.Fatal(err)
+		}
+		changes = append(changes, rc)
+	}
+
+	plan, err := Plan(changes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := plan.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	want := `{"root_module":{"child_modules":[{"address":"module.a","child_modules":[{"resources":[{"address":"module.a.module.b.foo_thing.kept","mode":"managed","type":"foo_thing","name":"kept",` +
+		`"schema_version":0,"values":{"id":"1","name":"a","password":null,"tags":null},"sensitive_values":{"password":true}}],"address":"module.a.module.b"}]}]}}`
+	if diff := cmp.Diff(want, toJSON(t, plan.PlannedValues)); diff != "" {
+		t.Errorf("wrong planned values\n%s", diff)
+	}
+
+	// The plan document can be decoded by terraform-json.
+	var decoded tfjson.Plan
+	if err := json.Unmarshal([]byte(toJSON(t, plan)), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if len(decoded.ResourceChanges) != 2 || !decoded.ResourceChanges[1].Change.Actions.Delete() {
+		t.Errorf("wrong decoded resource changes: %#v", decoded.ResourceChanges)
+	}
+}
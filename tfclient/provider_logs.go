@@ -0,0 +1,95 @@
+package tfclient
+
+import (
+	"context"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/magodo/terraform-client-go/tfclient/providerlog"
+	"github.com/magodo/terraform-client-go/tfclient/typ"
+)
+
+// CaptureLogs wraps the client, so that the provider's logs collected by the capture during the ReadResource,
+// PlanResourceChange, ApplyResourceChange, ImportResourceState and ReadDataSource calls are attached to the
+// ProviderLogs of their responses. If the call fails with a nil response, an empty response is returned instead
+// to carry the logs. As the stderr is read asynchronously, a call of a provider logging at the trace level waits for
+// its late logs for up to the grace period of the capture, and the logs arriving after that are not attached.
+//
+// The capture must be the one receiving the stderr of the provider, which is set up by New when the
+// Option.CaptureProviderLogs is set, in which case the client is already wrapped.
+func CaptureLogs(c Client, capture *providerlog.Capture) Client {
+	return &logCaptureClient{Client: c, capture: capture}
+}
+
+type logCaptureClient struct {
+	Client
+	capture *providerlog.Capture
+}
+
+func (c *logCaptureClient) ReadResource(ctx context.Context, req typ.ReadResourceRequest) (*typ.ReadResourceResponse, typ.Diagnostics) {
+	call := c.capture.Begin("ReadResource", req.TypeName)
+	resp, diags := c.Client.ReadResource(ctx, req)
+	logs := c.capture.End(call)
+	if resp == nil {
+		resp = &typ.ReadResourceResponse{}
+	}
+	resp.ProviderLogs = logs
+	return resp, diags
+}
+
+func (c *logCaptureClient) PlanResourceChange(ctx context.Context, req typ.PlanResourceChangeRequest) (*typ.PlanResourceChangeResponse, typ.Diagnostics) {
+	call := c.capture.Begin("PlanResourceChange", req.TypeName)
+	resp, diags := c.Client.PlanResourceChange(ctx, req)
+	logs := c.capture.End(call)
+	if resp == nil {
+		resp = &typ.PlanResourceChangeResponse{}
+	}
+	resp.ProviderLogs = logs
+	return resp, diags
+}
+
+func (c *logCaptureClient) ApplyResourceChange(ctx context.Context, req typ.ApplyResourceChangeRequest) (*typ.ApplyResourceChangeResponse, typ.Diagnostics) {
+	call := c.capture.Begin("ApplyResourceChange", req.TypeName)
+	resp, diags := c.Client.ApplyResourceChange(ctx, req)
+	logs := c.capture.End(call)
+	if resp == nil {
+		resp = &typ.ApplyResourceChangeResponse{}
+	}
+	resp.ProviderLogs = logs
+	return resp, diags
+}
+
+func (c *logCaptureClient) ImportResourceState(ctx context.Context, req typ.ImportResourceStateRequest) (*typ.ImportResourceStateResponse, typ.Diagnostics) {
+	call := c.capture.Begin("ImportResourceState", req.TypeName)
+	resp, diags := c.Client.ImportResourceState(ctx, req)
+	logs := c.capture.End(call)
+	if resp == nil {
+		resp = &typ.ImportResourceStateResponse{}
+	}
+	resp.ProviderLogs = logs
+	return resp, diags
+}
+
+func (c *logCaptureClient) ReadDataSource(ctx context.Context, req typ.ReadDataSourceRequest) (*typ.ReadDataSourceResponse, typ.Diagnostics) {
+	call := c.capture.Begin("ReadDataSource", req.TypeName)
+	resp, diags := c.Client.ReadDataSource(ctx, req)
+	logs := c.capture.End(call)
+	if resp == nil {
+		resp = &typ.ReadDataSourceResponse{}
+	}
+	resp.ProviderLogs = logs
+	return resp, diags
+}
+
+// stderrMutedLogger is the logger of go-plugin when the provider's logs are captured, which drops the provider's
+// stderr that go-plugin logs by the logger named after the plugin executable, as they are logged by the capture.
+type stderrMutedLogger struct {
+	hclog.Logger
+	name string
+}
+
+func (l stderrMutedLogger) Named(name string) hclog.Logger {
+	if name == l.name {
+		return hclog.NewNullLogger()
+	}
+	return l.Logger.Named(name)
+}
@@ -0,0 +1,59 @@
+package tfclient
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/magodo/terraform-client-go/tfclient/providerlog"
+	"github.com/magodo/terraform-client-go/tfclient/typ"
+)
+
+// logFakeClient writes a log line to the capture during the calls, as the provider does to its stderr.
+type logFakeClient struct {
+	Client
+	capture *providerlog.Capture
+}
+
+func (c logFakeClient) ReadResource(_ context.Context, req typ.ReadResourceRequest) (*typ.ReadResourceResponse, typ.Diagnostics) {
+	fmt.Fprintf(c.capture, `{"@level":"info","@message":"reading","tf_req_id":"1","tf_rpc":"ReadResource","tf_resource_type":%q}`+"\n", req.TypeName)
+	return &typ.ReadResourceResponse{}, nil
+}
+
+func (c logFakeClient) ReadDataSource(_ context.Context, req typ.ReadDataSourceRequest) (*typ.ReadDataSourceResponse, typ.Diagnostics) {
+	fmt.Fprintf(c.capture, `{"@level":"error","@message":"failed","tf_req_id":"2","tf_rpc":"ReadDataSource","tf_data_source_type":%q}`+"\n", req.TypeName)
+	return nil, typ.Diagnostics{{Severity: typ.Error, Summary: "failed"}}
+}
+
+func TestCaptureLogs(t *testing.T) {
+	capture := providerlog.NewCapture(hclog.NewNullLogger())
+	c := CaptureLogs(logFakeClient{capture: capture}, capture)
+
+	resp, diags := c.ReadResource(context.Background(), typ.ReadResourceRequest{TypeName: "foo_thing"})
+	if diags.HasErrors() {
+		t.Fatal(diags.Err())
+	}
+	if len(resp.ProviderLogs) != 1 || resp.ProviderLogs[0].Message != "reading" {
+		t.Errorf("wrong logs: %#v", resp.ProviderLogs)
+	}
+
+	// The logs are attached to an empty response on failure.
+	dresp, diags := c.ReadDataSource(context.Background(), typ.ReadDataSourceRequest{TypeName: "foo_data"})
+	if !diags.HasErrors() {
+		t.Fatal("expect error")
+	}
+	if len(dresp.ProviderLogs) != 1 || dresp.ProviderLogs[0].Level != hclog.Error {
+		t.Errorf("wrong logs: %#v", dresp.ProviderLogs)
+	}
+}
+
+func TestStderrMutedLogger(t *testing.T) {
+	l := stderrMutedLogger{Logger: hclog.New(&hclog.LoggerOptions{Name: "plugin"}), name: "terraform-provider-foo"}
+	if l.Named("terraform-provider-foo").IsError() {
+		t.Errorf("the stderr logger should be muted")
+	}
+	if got := l.Named("other").Name(); got != "plugin.other" {
+		t.Errorf("wrong logger name %q", got)
+	}
+}
@@ -0,0 +1,268 @@
+// Package providerlog parses the structured logs that the providers built on terraform-plugin-log write to stderr,
+// and correlates them with the in-flight RPCs.
+//
+// The providers only write the logs when the log level is set in their environment, e.g. by the TF_LOG or
+// TF_LOG_PROVIDER environment variable.
+package providerlog
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+)
+
+// The fields set by terraform-plugin-go on the logs of a request.
+const (
+	KeyRequestID = "tf_req_id"
+	KeyRPC       = "tf_rpc"
+)
+
+// servedMessage is the message of the last log of a request, which is logged by terraform-plugin-go at the trace
+// level.
+const servedMessage = "Served request"
+
+// DefaultGracePeriod is the default Capture.GracePeriod.
+const DefaultGracePeriod = 100 * time.Millisecond
+
+// typeNameKeys are the fields of the type name being operated on, only one of which is set for a request.
+var typeNameKeys = []string{
+	"tf_resource_type",
+	"tf_data_source_type",
+	"tf_ephemeral_resource_type",
+	"tf_list_resource_type",
+	"tf_action_type",
+}
+
+// Entry is a structured log line of the provider.
+type Entry struct {
+	Timestamp time.Time
+	Level     hclog.Level
+	Module    string
+	Message   string
+
+	// RequestID, RPC and TypeName are the request specific fields, which are empty for the logs not written
+	// during a request. These fields are also kept in the Fields.
+	RequestID string
+	RPC       string
+	TypeName  string
+
+	// Fields are the fields other than the timestamp, the level, the module and the message.
+	Fields map[string]any
+}
+
+// Parse parses the JSON log line written by hclog, which is used by terraform-plugin-log. It returns false if the
+// line is not such a line.
+func Parse(line []byte) (Entry, bool) {
+	var raw map[string]any
+	if err := json.Unmarshal(line, &raw); err != nil {
+		return Entry{}, false
+	}
+	msg, ok := raw["@message"].(string)
+	if !ok {
+		return Entry{}, false
+	}
+	e := Entry{
+		Message: msg,
+		Level:   hclog.Debug,
+		Fields:  map[string]any{},
+	}
+	if v, ok := raw["@level"].(string); ok {
+		if lvl := hclog.LevelFromString(v); lvl != hclog.NoLevel {
+			e.Level = lvl
+		}
+	}
+	if v, ok := raw["@timestamp"].(string); ok {
+		e.Timestamp, _ = time.Parse(time.RFC3339Nano, v)
+	}
+	if v, ok := raw["@module"].(string); ok {
+		e.Module = v
+	}
+	for k, v := range raw {
+		if strings.HasPrefix(k, "@") {
+			continue
+		}
+		e.Fields[k] = v
+	}
+	e.RequestID, _ = e.Fields[KeyRequestID].(string)
+	e.RPC, _ = e.Fields[KeyRPC].(string)
+	for _, k := range typeNameKeys {
+		if v, ok := e.Fields[k].(string); ok {
+			e.TypeName = v
+			break
+		}
+	}
+	return e, true
+}
+
+// Capture is an io.Writer of the provider's stderr, e.g. the plugin.ClientConfig.Stderr. Each line is logged by
+// the logger at the level of the entry, and the entries written during the calls started by Begin are collected
+// for them.
+//
+// As the request ID of a call is generated by the provider, the first request ID seen for an RPC and a type name is
+// bound to the earliest in-flight call of them that isn't bound yet.
+//
+// The stderr is read asynchronously, so the entries of a call can arrive after the call returns. Once the provider is
+// known to log at the trace level, i.e. a "Served request" entry has been seen, the call is kept in-flight until its
+// "Served request" entry arrives, or until the grace period passes. The collection is thus best-effort: the entries
+// arriving later than that, or after the call returns when the provider doesn't log at the trace level, are only
+// logged.
+type Capture struct {
+	logger hclog.Logger
+
+	// GracePeriod is how long End waits for the late entries of the call. It is DefaultGracePeriod by NewCapture,
+	// and must not be changed once the capture is used.
+	GracePeriod time.Duration
+
+	mu    sync.Mutex
+	buf   []byte
+	calls []*Call
+	// traced is whether any "Served request" entry has been seen, i.e. the provider is logging at the trace level.
+	traced bool
+}
+
+// NewCapture creates a Capture that logs the lines by the logger.
+func NewCapture(logger hclog.Logger) *Capture {
+	return &Capture{logger: logger, GracePeriod: DefaultGracePeriod}
+}
+
+// Call is an in-flight call started by Capture.Begin.
+type Call struct {
+	rpc       string
+	typeName  string
+	requestID string
+	entries   []Entry
+
+	// served is closed once the "Served request" entry of the call is seen.
+	served chan struct{}
+}
+
+// Begin starts collecting the entries of a call of the RPC on the type name, which is empty for the RPCs that are
+// not operating on a type.
+func (c *Capture) Begin(rpc, typeName string) *Call {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	call := &Call{rpc: rpc, typeName: typeName, served: make(chan struct{})}
+	c.calls = append(c.calls, call)
+	return call
+}
+
+// End stops collecting the entries of the call, and returns the collected entries. If the provider is logging at the
+// trace level, it waits for the late entries of the call, until the call is served or the grace period passes.
+func (c *Capture) End(call *Call) []Entry {
+	c.mu.Lock()
+	traced := c.traced
+	c.mu.Unlock()
+	if traced {
+		timer := time.NewTimer(c.GracePeriod)
+		select {
+		case <-call.served:
+		case <-timer.C:
+		}
+		timer.Stop()
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i, ic := range c.calls {
+		if ic == call {
+			c.calls = append(c.calls[:i], c.calls[i+1:]...)
+			break
+		}
+	}
+	return call.entries
+}
+
+func (c *Capture) Write(p []byte) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.buf = append(c.buf, p...)
+	for {
+		idx := bytes.IndexByte(c.buf, '\n')
+		if idx == -1 {
+			break
+		}
+		line := bytes.TrimRight(c.buf[:idx], "\r")
+		if len(line) != 0 {
+			c.handleLine(line)
+		}
+		c.buf = c.buf[idx+1:]
+	}
+	return len(p), nil
+}
+
+func (c *Capture) handleLine(line []byte) {
+	e, ok := Parse(line)
+	if !ok {
+		c.logger.Log(inferLevel(string(line)), string(line))
+		return
+	}
+
+	logger := c.logger
+	if e.Module != "" {
+		logger = logger.Named(e.Module)
+	}
+	var keys []string
+	for k := range e.Fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var args []any
+	for _, k := range keys {
+		args = append(args, k, e.Fields[k])
+	}
+	logger.Log(e.Level, e.Message, args...)
+
+	if e.RequestID != "" && e.Message == servedMessage {
+		c.traced = true
+	}
+	if call := c.callOf(e); call != nil {
+		call.entries = append(call.entries, e)
+		if e.Message == servedMessage {
+			select {
+			case <-call.served:
+			default:
+				close(call.served)
+			}
+		}
+	}
+}
+
+// callOf returns the in-flight call of the entry, binding the request ID to a call if necessary.
+func (c *Capture) callOf(e Entry) *Call {
+	if e.RequestID == "" {
+		return nil
+	}
+	for _, call := range c.calls {
+		if call.requestID == e.RequestID {
+			return call
+		}
+	}
+	for _, call := range c.calls {
+		if call.requestID == "" && call.rpc == e.RPC && (e.TypeName == "" || call.typeName == e.TypeName) {
+			call.requestID = e.RequestID
+			return call
+		}
+	}
+	return nil
+}
+
+// inferLevel infers the level of the unstructured line from the commonly used prefixes, the same as go-plugin.
+func inferLevel(line string) hclog.Level {
+	switch {
+	case strings.HasPrefix(line, "[TRACE]"):
+		return hclog.Trace
+	case strings.HasPrefix(line, "[INFO]"):
+		return hclog.Info
+	case strings.HasPrefix(line, "[WARN]"):
+		return hclog.Warn
+	case strings.HasPrefix(line, "[ERROR]"), strings.HasPrefix(line, "panic: "), strings.HasPrefix(line, "fatal error: "):
+		return hclog.Error
+	default:
+		return hclog.Debug
+	}
+}
@@ -0,0 +1,123 @@
+package providerlog
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+)
+
+func logLine(level, msg, reqID, rpc, resourceType string) string {
+	return fmt.Sprintf(`{"@level":%q,"@message":%q,"@module":"provider","@timestamp":"2024-01-02T03:04:05.000000Z","tf_req_id":%q,"tf_rpc":%q,"tf_resource_type":%q}`+"\n", level, msg, reqID, rpc, resourceType)
+}
+
+func TestParse(t *testing.T) {
+	e, ok := Parse([]byte(logLine("warn", "hello", "1", "ReadResource", "foo_thing")))
+	if !ok {
+		t.Fatal("expect the line to be parsed")
+	}
+	if e.Level != hclog.Warn || e.Message != "hello" || e.Module != "provider" || e.RequestID != "1" || e.RPC != "ReadResource" || e.TypeName != "foo_thing" {
+		t.Errorf("wrong entry: %#v", e)
+	}
+	if e.Timestamp.Year() != 2024 {
+		t.Errorf("wrong timestamp: %v", e.Timestamp)
+	}
+	if _, ok := e.Fields["@message"]; ok {
+		t.Errorf("the fields shouldn't contain the hclog specific ones")
+	}
+
+	if _, ok := Parse([]byte("[DEBUG] plain text")); ok {
+		t.Errorf("expect the plain text not to be parsed")
+	}
+}
+
+func TestCapture(t *testing.T) {
+	var out bytes.Buffer
+	capture := NewCapture(hclog.New(&hclog.LoggerOptions{Output: &out, Level: hclog.Info}))
+
+	foo := capture.Begin("ReadResource", "foo_thing")
+	bar := capture.Begin("ReadResource", "bar_thing")
+
+	// The lines can be written in pieces.
+	line := logLine("info", "reading foo", "1", "ReadResource", "foo_thing")
+	capture.Write([]byte(line[:10]))
+	capture.Write([]byte(line[10:]))
+	capture.Write([]byte(logLine("debug", "reading bar", "2", "ReadResource", "bar_thing")))
+	capture.Write([]byte(logLine("error", "foo failed", "1", "ReadResource", "foo_thing")))
+	capture.Write([]byte("[WARN] plain text\n"))
+
+	fooLogs := capture.End(foo)
+	if len(fooLogs) != 2 || fooLogs[0].Message != "reading foo" || fooLogs[1].Message != "foo failed" {
+		t.Errorf("wrong foo logs: %#v", fooLogs)
+	}
+	// The lines after the call ends are not collected.
+	capture.Write([]byte(logLine("info", "foo again", "1", "ReadResource", "foo_thing")))
+	if len(fooLogs) != 2 {
+		t.Errorf("wrong foo logs: %#v", fooLogs)
+	}
+	barLogs := capture.End(bar)
+	if len(barLogs) != 1 || barLogs[0].Message != "reading bar" {
+		t.Errorf("wrong bar logs: %#v", barLogs)
+	}
+
+	// The debug line is not logged by the info level logger.
+	got := out.String()
+	for _, want := range []string{"[INFO]  provider: reading foo", "[ERROR] provider: foo failed", "[WARN]  [WARN] plain text", "foo again"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("missing %q in the log:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "reading bar") {
+		t.Errorf("unexpected debug log:\n%s", got)
+	}
+}
+
+func TestCaptureLateEntries(t *testing.T) {
+	capture := NewCapture(hclog.NewNullLogger())
+	capture.GracePeriod = time.Minute
+
+	// Without any served entry, the provider is not known to log at the trace level, End doesn't wait.
+	call := capture.Begin("ConfigureProvider", "")
+	capture.Write([]byte(logLine("info", "configuring", "0", "ConfigureProvider", "")))
+	start := time.Now()
+	if logs := capture.End(call); len(logs) != 1 || logs[0].Message != "configuring" {
+		t.Errorf("wrong logs: %#v", logs)
+	}
+	if d := time.Since(start); d > 10*time.Second {
+		t.Errorf("End waits for %s without the provider logging at the trace level", d)
+	}
+
+	// The provider is known to log at the trace level once a served entry is seen.
+	capture.Write([]byte(logLine("trace", "Served request", "0", "ConfigureProvider", "")))
+
+	call = capture.Begin("ReadResource", "foo_thing")
+	done := make(chan []Entry)
+	go func() {
+		done <- capture.End(call)
+	}()
+	// The entries written after End is called are still collected, until the call is served.
+	capture.Write([]byte(logLine("info", "reading foo", "1", "ReadResource", "foo_thing")))
+	capture.Write([]byte(logLine("trace", "Served request", "1", "ReadResource", "foo_thing")))
+
+	select {
+	case logs := <-done:
+		if len(logs) != 2 || logs[0].Message != "reading foo" || logs[1].Message != "Served request" {
+			t.Errorf("wrong logs: %#v", logs)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("End doesn't return once the call is served")
+	}
+
+	// Without the served entry of the call, End returns after the grace period.
+	capture = NewCapture(hclog.NewNullLogger())
+	capture.GracePeriod = 10 * time.Millisecond
+	capture.Write([]byte(logLine("trace", "Served request", "0", "ConfigureProvider", "")))
+	call = capture.Begin("ReadResource", "foo_thing")
+	capture.Write([]byte(logLine("info", "reading foo", "2", "ReadResource", "foo_thing")))
+	if logs := capture.End(call); len(logs) != 1 || logs[0].Message != "reading foo" {
+		t.Errorf("wrong logs: %#v", logs)
+	}
+}
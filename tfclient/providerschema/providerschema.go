@@ -0,0 +1,307 @@
+// Package providerschema converts the provider schema returned by the normalized client
+// from/to the JSON document produced by `terraform providers schema -json`.
+//
+// The document is a github.com/hashicorp/terraform-json.ProviderSchemas, which covers the
+// provider, resource, data source, ephemeral resource, list resource, action, function and
+// resource identity schemas.
+package providerschema
+
+import (
+	"encoding/json"
+	"fmt"
+
+	tfjson "github.com/hashicorp/terraform-json"
+	"github.com/magodo/terraform-client-go/tfclient/configschema"
+	"github.com/magodo/terraform-client-go/tfclient/typ"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// FormatVersion is the format version of the document produced by Marshal.
+// This is the same as the one used by `terraform providers schema -json`.
+const FormatVersion = "1.0"
+
+// The following types mirror the JSON layout of tfjson.ProviderSchemas. They are
+// needed as the tfjson.Schema carries the identity schema in untagged fields, which
+// is not part of the `terraform providers schema -json` output.
+
+type providerSchemas struct {
+	FormatVersion string                     `json:"format_version"`
+	Schemas       map[string]*providerSchema `json:"provider_schemas,omitempty"`
+}
+
+type providerSchema struct {
+	ConfigSchema             *schema                              `json:"provider,omitempty"`
+	ResourceSchemas          map[string]*schema                   `json:"resource_schemas,omitempty"`
+	DataSourceSchemas        map[string]*schema                   `json:"data_source_schemas,omitempty"`
+	EphemeralResourceSchemas map[string]*schema                   `json:"ephemeral_resource_schemas,omitempty"`
+	Functions                map[string]*tfjson.FunctionSignature `json:"functions,omitempty"`
+	ResourceIdentitySchemas  map[string]*tfjson.IdentitySchema    `json:"resource_identity_schemas,omitempty"`
+	ListResourceSchemas      map[string]*schema                   `json:"list_resource_schemas,omitempty"`
+	ActionSchemas            map[string]*tfjson.ActionSchema      `json:"action_schemas,omitempty"`
+}
+
+type schema struct {
+	Version uint64              `json:"version"`
+	Block   *tfjson.SchemaBlock `json:"block,omitempty"`
+}
+
+// Marshal encodes the provider schema as a `terraform providers schema -json` document,
+// keyed by the provider source address (e.g. registry.terraform.io/hashicorp/azurerm).
+func Marshal(providerAddr string, resp *typ.GetProviderSchemaResponse) ([]byte, error) {
+	doc := providerSchemas{
+		FormatVersion: FormatVersion,
+		Schemas: map[string]*providerSchema{
+			providerAddr: toJSONProviderSchema(resp),
+		},
+	}
+	return json.Marshal(doc)
+}
+
+func toJSONProviderSchema(resp *typ.GetProviderSchemaResponse) *providerSchema {
+	ps := &providerSchema{
+		ConfigSchema: toJSONSchema(resp.Provider),
+	}
+
+	if len(resp.ResourceTypes) != 0 {
+		ps.ResourceSchemas = map[string]*schema{}
+		for name, sch := range resp.ResourceTypes {
+			ps.ResourceSchemas[name] = toJSONSchema(sch)
+			if sch.Identity != nil {
+				if ps.ResourceIdentitySchemas == nil {
+					ps.ResourceIdentitySchemas = map[string]*tfjson.IdentitySchema{}
+				}
+				ps.ResourceIdentitySchemas[name] = toJSONIdentitySchema(sch.IdentityVersion, sch.Identity)
+			}
+		}
+	}
+	if len(resp.DataSources) != 0 {
+		ps.DataSourceSchemas = map[string]*schema{}
+		for name, sch := range resp.DataSources {
+			ps.DataSourceSchemas[name] = toJSONSchema(sch)
+		}
+	}
+	if len(resp.EphemeralResourceTypes) != 0 {
+		ps.EphemeralResourceSchemas = map[string]*schema{}
+		for name, sch := range resp.EphemeralResourceTypes {
+			ps.EphemeralResourceSchemas[name] = toJSONSchema(sch)
+		}
+	}
+	if len(resp.ListResourceTypes) != 0 {
+		ps.ListResourceSchemas = map[string]*schema{}
+		for name, sch := range resp.ListResourceTypes {
+			ps.ListResourceSchemas[name] = toJSONSchema(sch)
+		}
+	}
+	if len(resp.Actions) != 0 {
+		ps.ActionSchemas = map[string]*tfjson.ActionSchema{}
+		for name, sch := range resp.Actions {
+			ps.ActionSchemas[name] = &tfjson.ActionSchema{Block: sch.Block}
+		}
+	}
+	if len(resp.Functions) != 0 {
+		ps.Functions = map[string]*tfjson.FunctionSignature{}
+		for name, decl := range resp.Functions {
+			ps.Functions[name] = toJSONFunctionSignature(decl)
+		}
+	}
+	return ps
+}
+
+func toJSONSchema(sch tfjson.Schema) *schema {
+	block := sch.Block
+	if block == nil {
+		block = &tfjson.SchemaBlock{}
+	}
+	return &schema{
+		Version: sch.Version,
+		Block:   block,
+	}
+}
+
+func toJSONIdentitySchema(version int64, body *tfjson.SchemaNestedAttributeType) *tfjson.IdentitySchema {
+	ret := &tfjson.IdentitySchema{
+		Version:    uint64(version),
+		Attributes: map[string]*tfjson.IdentityAttribute{},
+	}
+	for name, attr := range body.Attributes {
+		ret.Attributes[name] = &tfjson.IdentityAttribute{
+			IdentityType:      attr.AttributeType,
+			Description:       attr.Description,
+			RequiredForImport: attr.Required,
+			OptionalForImport: attr.Optional,
+		}
+	}
+	return ret
+}
+
+func toJSONFunctionSignature(decl typ.FunctionDecl) *tfjson.FunctionSignature {
+	sig := &tfjson.FunctionSignature{
+		Description:        decl.Description,
+		Summary:            decl.Summary,
+		DeprecationMessage: decl.DeprecationMessage,
+		ReturnType:         decl.ReturnType,
+	}
+	for _, param := range decl.Parameters {
+		sig.Parameters = append(sig.Parameters, toJSONFunctionParameter(param))
+	}
+	if decl.VariadicParameter != nil {
+		sig.VariadicParameter = toJSONFunctionParameter(*decl.VariadicParameter)
+	}
+	return sig
+}
+
+func toJSONFunctionParameter(param typ.FunctionParam) *tfjson.FunctionParameter {
+	return &tfjson.FunctionParameter{
+		Name:        param.Name,
+		Description: param.Description,
+		IsNullable:  param.AllowNullValue,
+		Type:        param.Type,
+	}
+}
+
+// Unmarshal decodes a `terraform providers schema -json` document, and returns the schema
+// of the provider identified by providerAddr, with all the cty types filled in.
+// The providerAddr can be empty if the document contains exactly one provider.
+func Unmarshal(b []byte, providerAddr string) (*typ.GetProviderSchemaResponse, error) {
+	var doc tfjson.ProviderSchemas
+	if err := json.Unmarshal(b, &doc); err != nil {
+		return nil, err
+	}
+
+	if providerAddr == "" {
+		if len(doc.Schemas) != 1 {
+			return nil, fmt.Errorf("expect exactly one provider in the schema document when no provider address is specified, got=%d", len(doc.Schemas))
+		}
+		for addr := range doc.Schemas {
+			providerAddr = addr
+		}
+	}
+
+	ps, ok := doc.Schemas[providerAddr]
+	if !ok {
+		return nil, fmt.Errorf("provider %q not found in the schema document", providerAddr)
+	}
+	return FromProviderSchema(ps), nil
+}
+
+// FromProviderSchema converts a tfjson.ProviderSchema to the schema response of the normalized
+// client, with all the cty types filled in.
+//
+// The information not carried by the JSON document (e.g. the server capabilities, the
+// description kind and unknown value allowance of function parameters) is left as zero value.
+func FromProviderSchema(ps *tfjson.ProviderSchema) *typ.GetProviderSchemaResponse {
+	resp := &typ.GetProviderSchemaResponse{
+		ResourceTypes:          map[string]tfjson.Schema{},
+		DataSources:            map[string]tfjson.Schema{},
+		Functions:              map[string]typ.FunctionDecl{},
+		EphemeralResourceTypes: map[string]tfjson.Schema{},
+		ListResourceTypes:      map[string]tfjson.Schema{},
+		Actions:                map[string]tfjson.Schema{},
+	}
+
+	if ps.ConfigSchema != nil {
+		resp.Provider = fromJSONSchema(ps.ConfigSchema)
+	}
+	for name, sch := range ps.ResourceSchemas {
+		rs := fromJSONSchema(sch)
+		if id, ok := ps.ResourceIdentitySchemas[name]; ok && id != nil {
+			rs.IdentityVersion = int64(id.Version)
+			rs.Identity = fromJSONIdentitySchema(id)
+		}
+		resp.ResourceTypes[name] = rs
+	}
+	for name, sch := range ps.DataSourceSchemas {
+		resp.DataSources[name] = fromJSONSchema(sch)
+	}
+	for name, sch := range ps.EphemeralResourceSchemas {
+		resp.EphemeralResourceTypes[name] = fromJSONSchema(sch)
+	}
+	for name, sch := range ps.ListResourceSchemas {
+		resp.ListResourceTypes[name] = fromJSONSchema(sch)
+	}
+	for name, sch := range ps.ActionSchemas {
+		block := sch.Block
+		if block == nil {
+			block = &tfjson.SchemaBlock{}
+		}
+		resp.Actions[name] = tfjson.Schema{Block: block}
+	}
+	for name, sig := range ps.Functions {
+		resp.Functions[name] = fromJSONFunctionSignature(sig)
+	}
+
+	FillImpliedTypes(resp)
+	return resp
+}
+
+func fromJSONSchema(sch *tfjson.Schema) tfjson.Schema {
+	block := sch.Block
+	if block == nil {
+		block = &tfjson.SchemaBlock{}
+	}
+	return tfjson.Schema{
+		Version: sch.Version,
+		Block:   block,
+	}
+}
+
+func fromJSONIdentitySchema(id *tfjson.IdentitySchema) *tfjson.SchemaNestedAttributeType {
+	obj := &tfjson.SchemaNestedAttributeType{
+		Attributes:  make(map[string]*tfjson.SchemaAttribute),
+		NestingMode: tfjson.SchemaNestingModeSingle,
+	}
+	for name, attr := range id.Attributes {
+		obj.Attributes[name] = &tfjson.SchemaAttribute{
+			AttributeType: attr.IdentityType,
+			Description:   attr.Description,
+			Required:      attr.RequiredForImport,
+			Optional:      attr.OptionalForImport,
+		}
+	}
+	return obj
+}
+
+func fromJSONFunctionSignature(sig *tfjson.FunctionSignature) typ.FunctionDecl {
+	decl := typ.FunctionDecl{
+		ReturnType:         sig.ReturnType,
+		Description:        sig.Description,
+		Summary:            sig.Summary,
+		DeprecationMessage: sig.DeprecationMessage,
+	}
+	for _, param := range sig.Parameters {
+		decl.Parameters = append(decl.Parameters, fromJSONFunctionParameter(param))
+	}
+	if sig.VariadicParameter != nil {
+		param := fromJSONFunctionParameter(sig.VariadicParameter)
+		decl.VariadicParameter = &param
+	}
+	return decl
+}
+
+func fromJSONFunctionParameter(param *tfjson.FunctionParameter) typ.FunctionParam {
+	return typ.FunctionParam{
+		Name:           param.Name,
+		Description:    param.Description,
+		AllowNullValue: param.IsNullable,
+		Type:           param.Type,
+	}
+}
+
+// FillImpliedTypes (re)computes all the cty types of the schema response from its schemas.
+func FillImpliedTypes(resp *typ.GetProviderSchemaResponse) {
+	resp.ProviderCty = configschema.SchemaBlockImpliedType(resp.Provider.Block)
+	resp.ProviderMetaCty = configschema.SchemaBlockImpliedType(resp.ProviderMeta.Block)
+	resp.ResourceTypesCty = impliedTypes(resp.ResourceTypes)
+	resp.DataSourcesCty = impliedTypes(resp.DataSources)
+	resp.EphemeralResourceTypesCty = impliedTypes(resp.EphemeralResourceTypes)
+	resp.ListResourceTypesCty = impliedTypes(resp.ListResourceTypes)
+	resp.ActionsCty = impliedTypes(resp.Actions)
+}
+
+func impliedTypes(schemas map[string]tfjson.Schema) map[string]cty.Type {
+	ret := make(map[string]cty.Type, len(schemas))
+	for name, sch := range schemas {
+		ret[name] = configschema.SchemaBlockImpliedType(sch.Block)
+	}
+	return ret
+}
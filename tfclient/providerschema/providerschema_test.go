@@ -0,0 +1,122 @@
+package providerschema
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	tfjson "github.com/hashicorp/terraform-json"
+	"github.com/magodo/terraform-client-go/tfclient/typ"
+	"github.com/zclconf/go-cty-debug/ctydebug"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func testSchema() *typ.GetProviderSchemaResponse {
+	block := &tfjson.SchemaBlock{
+		Attributes: map[string]*tfjson.SchemaAttribute{
+			"id": {
+				AttributeType:   cty.String,
+				Computed:        true,
+				DescriptionKind: tfjson.SchemaDescriptionKindPlain,
+			},
+			"name": {
+				AttributeType:   cty.String,
+				Required:        true,
+				DescriptionKind: tfjson.SchemaDescriptionKindPlain,
+			},
+		},
+		NestedBlocks: map[string]*tfjson.SchemaBlockType{
+			"timeouts": {
+				NestingMode: tfjson.SchemaNestingModeSingle,
+				Block: &tfjson.SchemaBlock{
+					Attributes: map[string]*tfjson.SchemaAttribute{
+						"create": {
+							AttributeType:   cty.String,
+							Optional:        true,
+							DescriptionKind: tfjson.SchemaDescriptionKindPlain,
+						},
+					},
+					DescriptionKind: tfjson.SchemaDescriptionKindPlain,
+				},
+			},
+		},
+		DescriptionKind: tfjson.SchemaDescriptionKindPlain,
+	}
+
+	resp := &typ.GetProviderSchemaResponse{
+		Provider: tfjson.Schema{Block: &tfjson.SchemaBlock{DescriptionKind: tfjson.SchemaDescriptionKindPlain}},
+		ResourceTypes: map[string]tfjson.Schema{
+			"foo_resource": {
+				Version:         1,
+				Block:           block,
+				IdentityVersion: 2,
+				Identity: &tfjson.SchemaNestedAttributeType{
+					NestingMode: tfjson.SchemaNestingModeSingle,
+					Attributes: map[string]*tfjson.SchemaAttribute{
+						"id": {
+							AttributeType: cty.String,
+							Required:      true,
+						},
+					},
+				},
+			},
+		},
+		DataSources: map[string]tfjson.Schema{
+			"foo_data": {Block: block},
+		},
+		EphemeralResourceTypes: map[string]tfjson.Schema{
+			"foo_ephemeral": {Block: block},
+		},
+		ListResourceTypes: map[string]tfjson.Schema{
+			"foo_resource": {Block: block},
+		},
+		Actions: map[string]tfjson.Schema{
+			"foo_action": {Block: block},
+		},
+		Functions: map[string]typ.FunctionDecl{
+			"foo_func": {
+				Parameters: []typ.FunctionParam{
+					{Name: "a", Type: cty.String, AllowNullValue: true},
+				},
+				VariadicParameter: &typ.FunctionParam{Name: "b", Type: cty.Number},
+				ReturnType:        cty.List(cty.String),
+				Summary:           "summary",
+			},
+		},
+	}
+	FillImpliedTypes(resp)
+	return resp
+}
+
+func TestMarshalUnmarshal(t *testing.T) {
+	const addr = "registry.terraform.io/hashicorp/foo"
+	want := testSchema()
+
+	b, err := Marshal(addr, want)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The document must be a valid tfjson.ProviderSchemas.
+	var doc tfjson.ProviderSchemas
+	if err := json.Unmarshal(b, &doc); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := doc.Schemas[addr].ResourceIdentitySchemas["foo_resource"]; !ok {
+		t.Fatalf("missing identity schema in %s", string(b))
+	}
+
+	for _, addr := range []string{addr, ""} {
+		got, err := Unmarshal(b, addr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(want, got, ctydebug.CmpOptions); diff != "" {
+			t.Fatalf("unexpected diff (-want +got):\n%s", diff)
+		}
+	}
+
+	if _, err := Unmarshal(b, "registry.terraform.io/hashicorp/bar"); err == nil {
+		t.Fatal("expect error for unknown provider address")
+	}
+}
@@ -0,0 +1,392 @@
+// Package proxy implements a transparent proxy between Terraform core and a provider.
+//
+// The proxy implements the provider server of the same protocol version as the provider, forwards
+// each call to the provider via the RawClient, and logs every request and response in decoded form,
+// together with its timing. The values of the sensitive attributes are redacted, and the raw states
+// that can't be decoded by the schema are only logged by their size, unless Option.ShowSensitive is set.
+package proxy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/go-plugin"
+	tfjson "github.com/hashicorp/terraform-json"
+	"github.com/magodo/terraform-client-go/tfclient"
+	"github.com/magodo/terraform-client-go/tfclient/configschema"
+	"github.com/magodo/terraform-client-go/tfclient/marks"
+	"github.com/magodo/terraform-client-go/tfclient/tfprotov5/tf5server"
+	"github.com/magodo/terraform-client-go/tfclient/tfprotov6/tf6server"
+	"github.com/magodo/terraform-client-go/tfclient/typ"
+	"github.com/zclconf/go-cty/cty"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
+	"github.com/zclconf/go-cty/cty/msgpack"
+)
+
+// Option is the option of the proxy.
+type Option struct {
+	// ShowSensitive logs the values of the attributes marked as sensitive by the schema, and the raw
+	// states that can't be decoded by the schema verbatim, which are redacted by default.
+	ShowSensitive bool
+}
+
+// Serve serves the proxy of the provider behind the raw client over go-plugin, until the ctx is done.
+// The reattach config of the proxy is sent to the reattachCh once it is up, which can be passed to
+// ReattachEnv to build the TF_REATTACH_PROVIDERS value.
+func Serve(ctx context.Context, client *tfclient.RawClient, logger hclog.Logger, reattachCh chan *plugin.ReattachConfig, opts Option) error {
+	test := &plugin.ServeTestConfig{
+		Context:          ctx,
+		ReattachConfigCh: reattachCh,
+	}
+	switch {
+	case client.AsV5Client() != nil:
+		p, err := NewV5(client.AsV5Client(), logger, opts)
+		if err != nil {
+			return err
+		}
+		tf5server.Serve(p, tf5server.ServeOption{Logger: logger, Test: test})
+	case client.AsV6Client() != nil:
+		p, err := NewV6(client.AsV6Client(), logger, opts)
+		if err != nil {
+			return err
+		}
+		tf6server.Serve(p, tf6server.ServeOption{Logger: logger, Test: test})
+	default:
+		return fmt.Errorf("the raw client has neither a v5 nor a v6 client")
+	}
+	return nil
+}
+
+// ReattachEnv returns the value of the TF_REATTACH_PROVIDERS environment variable, for Terraform to
+// attach to the provider of the name via the reattach config.
+func ReattachEnv(name string, config *plugin.ReattachConfig) (string, error) {
+	// The go-plugin ReattachConfig is not JSON friendly, this follows the format expected by Terraform.
+	type reattachConfigAddr struct {
+		Network string
+		String  string
+	}
+	type reattachConfig struct {
+		Protocol        string
+		ProtocolVersion int
+		Pid             int
+		Test            bool
+		Addr            reattachConfigAddr
+	}
+	b, err := json.Marshal(map[string]reattachConfig{
+		name: {
+			Protocol:        string(config.Protocol),
+			ProtocolVersion: config.ProtocolVersion,
+			Pid:             config.Pid,
+			Test:            config.Test,
+			Addr: reattachConfigAddr{
+				Network: config.Addr.Network(),
+				String:  config.Addr.String(),
+			},
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// recorder logs the calls, each is identified by a sequence number so that the request and response
+// can be correlated when calls are concurrent.
+type recorder struct {
+	logger        hclog.Logger
+	showSensitive bool
+	seq           atomic.Int64
+}
+
+type call struct {
+	logger hclog.Logger
+	start  time.Time
+}
+
+// begin logs the request of the rpc, and returns the call to log its response.
+func (r *recorder) begin(rpc string, args ...any) *call {
+	c := &call{
+		logger: r.logger.With("rpc", rpc, "id", r.seq.Add(1)),
+		start:  time.Now(),
+	}
+	c.logger.Info("request", args...)
+	return c
+}
+
+// end logs the response, with the time elapsed since the request.
+func (c *call) end(args ...any) {
+	c.logger.Info("response", append([]any{"duration", time.Since(c.start)}, args...)...)
+}
+
+// fail logs the error of a call that failed in the transport.
+func (c *call) fail(err error) {
+	c.logger.Error("response", "duration", time.Since(c.start), "error", err)
+}
+
+// event logs an event of a streaming response, with the time elapsed since the request.
+func (c *call) event(args ...any) {
+	c.logger.Info("event", append([]any{"elapsed", time.Since(c.start)}, args...)...)
+}
+
+// diagArgs returns the log arguments of the diagnostics, which is empty if there is no diagnostic.
+func diagArgs(diags typ.Diagnostics) []any {
+	if len(diags) == 0 {
+		return nil
+	}
+	var out []string
+	for _, d := range diags {
+		sev := "error"
+		if d.Severity == typ.Warning {
+			sev = "warning"
+		}
+		s := sev + ": " + d.Summary
+		if d.Detail != "" {
+			s += ": " + d.Detail
+		}
+		if len(d.Attribute) != 0 {
+			s += " (at " + typ.FormatCtyPath(d.Attribute) + ")"
+		}
+		out = append(out, s)
+	}
+	return []any{"diagnostics", out}
+}
+
+// decodeValue decodes the msgpack or JSON encoded value. If the type is not known, e.g. for a type name
+// absent in the schema, the type is implied from the encoded value.
+func decodeValue(mp, js []byte, ty cty.Type) (cty.Value, error) {
+	var err error
+	switch {
+	case len(mp) > 0:
+		if ty == cty.NilType {
+			if ty, err = msgpack.ImpliedType(mp); err != nil {
+				return cty.NilVal, err
+			}
+		}
+		return msgpack.Unmarshal(mp, ty)
+	case len(js) > 0:
+		if ty == cty.NilType {
+			if ty, err = ctyjson.ImpliedType(js); err != nil {
+				return cty.NilVal, err
+			}
+		}
+		return ctyjson.Unmarshal(js, ty)
+	default:
+		return cty.NullVal(cty.DynamicPseudoType), nil
+	}
+}
+
+// value decodes the value of the type and formats it.
+func (r *recorder) value(mp, js []byte, ty cty.Type) string {
+	v, err := decodeValue(mp, js, ty)
+	if err != nil {
+		return fmt.Sprintf("<undecodable: %v>", err)
+	}
+	return formatValue(v)
+}
+
+// blockValue decodes the value of the schema block and formats it, the values of the sensitive attributes
+// are redacted unless showSensitive is set. If the block is nil, the type is implied from the encoded value.
+func (r *recorder) blockValue(mp, js []byte, b *tfjson.SchemaBlock) string {
+	if b == nil {
+		return r.value(mp, js, cty.NilType)
+	}
+	v, err := decodeValue(mp, js, configschema.SchemaBlockImpliedType(b))
+	if err != nil {
+		return fmt.Sprintf("<undecodable: %v>", err)
+	}
+	if !r.showSensitive {
+		v = v.MarkWithPaths(configschema.SchemaBlockValueMarks(b, v, nil))
+	}
+	return formatValue(v)
+}
+
+// identityValue is the same as blockValue, but for the value of the identity schema.
+func (r *recorder) identityValue(mp, js []byte, o *tfjson.SchemaNestedAttributeType) string {
+	if o == nil {
+		return r.value(mp, js, cty.NilType)
+	}
+	v, err := decodeValue(mp, js, configschema.SchemaNestedAttributeTypeImpliedType(o))
+	if err != nil {
+		return fmt.Sprintf("<undecodable: %v>", err)
+	}
+	if !r.showSensitive {
+		v = v.MarkWithPaths(configschema.SchemaNestedAttributeTypeValueMarks(o, v, nil))
+	}
+	return formatValue(v)
+}
+
+// schemaBlock returns the block of the schema, which is empty if the schema has no block.
+func schemaBlock(s tfjson.Schema) *tfjson.SchemaBlock {
+	if s.Block == nil {
+		return &tfjson.SchemaBlock{}
+	}
+	return s.Block
+}
+
+// typeBlock returns the block of the schema of the type name, or nil if the type name is absent.
+func typeBlock(schemas map[string]tfjson.Schema, name string) *tfjson.SchemaBlock {
+	s, ok := schemas[name]
+	if !ok {
+		return nil
+	}
+	return schemaBlock(s)
+}
+
+// formatValue formats the value in a compact, HCL like syntax, in one line. Unknown values are
+// written as "(unknown)", and the values marked as sensitive are written as "(sensitive value)".
+func formatValue(v cty.Value) string {
+	var sb strings.Builder
+	writeValue(&sb, v)
+	return sb.String()
+}
+
+func writeValue(sb *strings.Builder, v cty.Value) {
+	if v.HasMark(marks.Sensitive) {
+		sb.WriteString("(sensitive value)")
+		return
+	}
+	v, _ = v.Unmark()
+	if !v.IsKnown() {
+		sb.WriteString("(unknown)")
+		return
+	}
+	if v.IsNull() {
+		sb.WriteString("null")
+		return
+	}
+	ty := v.Type()
+	switch {
+	case ty == cty.String:
+		sb.WriteString(strconv.Quote(v.AsString()))
+	case ty == cty.Number:
+		sb.WriteString(v.AsBigFloat().Text('f', -1))
+	case ty == cty.Bool:
+		sb.WriteString(strconv.FormatBool(v.True()))
+	case ty.IsListType(), ty.IsSetType(), ty.IsTupleType():
+		sb.WriteString("[")
+		i := 0
+		for it := v.ElementIterator(); it.Next(); i++ {
+			if i > 0 {
+				sb.WriteString(", ")
+			}
+			_, ev := it.Element()
+			writeValue(sb, ev)
+		}
+		sb.WriteString("]")
+	case ty.IsMapType(), ty.IsObjectType():
+		if v.LengthInt() == 0 {
+			sb.WriteString("{}")
+			return
+		}
+		sb.WriteString("{ ")
+		i := 0
+		for it := v.ElementIterator(); it.Next(); i++ {
+			if i > 0 {
+				sb.WriteString(", ")
+			}
+			k, ev := it.Element()
+			if ty.IsObjectType() && hclIdentifier(k.AsString()) {
+				sb.WriteString(k.AsString())
+			} else {
+				sb.WriteString(strconv.Quote(k.AsString()))
+			}
+			sb.WriteString(" = ")
+			writeValue(sb, ev)
+		}
+		sb.WriteString(" }")
+	case ty.IsCapsuleType():
+		sb.WriteString("<" + ty.FriendlyName() + ">")
+	default:
+		sb.WriteString(v.GoString())
+	}
+}
+
+func hclIdentifier(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i, r := range s {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r == '_':
+		case i > 0 && (r >= '0' && r <= '9' || r == '-'):
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// formatPrivate formats the private data, which is opaque to Terraform but is JSON for most of the
+// providers.
+func formatPrivate(b []byte) string {
+	if len(b) == 0 {
+		return ""
+	}
+	if json.Valid(b) {
+		return string(b)
+	}
+	return fmt.Sprintf("<%d bytes>", len(b))
+}
+
+// rawValue formats the raw state, which is either JSON or the legacy flatmap, by decoding it against the
+// type and marking it by the marks function, unless the sensitive values are shown. The raw state that
+// can't be decoded, e.g. the legacy flatmap or the one of another schema version, is only written by its
+// size, as it might contain sensitive values.
+func (r *recorder) rawValue(js []byte, flatmap map[string]string, ty cty.Type, marks func(cty.Value) []cty.PathValueMarks) string {
+	if len(js) == 0 && flatmap == nil {
+		return "null"
+	}
+	if len(js) > 0 && ty != cty.NilType {
+		if v, err := ctyjson.Unmarshal(js, ty); err == nil {
+			if !r.showSensitive {
+				v = v.MarkWithPaths(marks(v))
+			}
+			return formatValue(v)
+		}
+	}
+	if r.showSensitive {
+		return formatRawState(js, flatmap)
+	}
+	n := len(js)
+	for k, v := range flatmap {
+		n += len(k) + len(v)
+	}
+	return fmt.Sprintf("<%d bytes>", n)
+}
+
+// rawBlockValue formats the raw state of the block, which is nil if the schema is unknown.
+func (r *recorder) rawBlockValue(js []byte, flatmap map[string]string, b *tfjson.SchemaBlock) string {
+	if b == nil {
+		return r.rawValue(js, flatmap, cty.NilType, nil)
+	}
+	return r.rawValue(js, flatmap, configschema.SchemaBlockImpliedType(b), func(v cty.Value) []cty.PathValueMarks {
+		return configschema.SchemaBlockValueMarks(b, v, nil)
+	})
+}
+
+// rawIdentityValue formats the raw identity of the identity schema, which is nil if the schema is unknown.
+func (r *recorder) rawIdentityValue(js []byte, flatmap map[string]string, o *tfjson.SchemaNestedAttributeType) string {
+	if o == nil {
+		return r.rawValue(js, flatmap, cty.NilType, nil)
+	}
+	return r.rawValue(js, flatmap, configschema.SchemaNestedAttributeTypeImpliedType(o), func(v cty.Value) []cty.PathValueMarks {
+		return configschema.SchemaNestedAttributeTypeValueMarks(o, v, nil)
+	})
+}
+
+// formatRawState formats the raw state, which is either JSON or the legacy flatmap.
+func formatRawState(js []byte, flatmap map[string]string) string {
+	if len(js) > 0 {
+		return string(js)
+	}
+	if flatmap != nil {
+		return fmt.Sprintf("%v", flatmap)
+	}
+	return "null"
+}
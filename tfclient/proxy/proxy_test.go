@@ -0,0 +1,296 @@
+package proxy
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/go-plugin"
+	tfjson "github.com/hashicorp/terraform-json"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov5"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/magodo/terraform-client-go/tfclient"
+	"github.com/magodo/terraform-client-go/tfclient/configschema"
+	"github.com/magodo/terraform-client-go/tfclient/tfprotov5/tf5client"
+	"github.com/magodo/terraform-client-go/tfclient/tfprotov5/tf5server"
+	"github.com/magodo/terraform-client-go/tfclient/typ"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/msgpack"
+)
+
+var thingType = cty.Object(map[string]cty.Type{
+	"id":   cty.String,
+	"name": cty.String,
+})
+
+// fakeV5Client implements the RPCs used by the tests, the others panic.
+type fakeV5Client struct {
+	tf5client.TFProtoV5Client
+}
+
+func (fakeV5Client) GetProviderSchema(context.Context, *tfprotov5.GetProviderSchemaRequest) (*tfprotov5.GetProviderSchemaResponse, error) {
+	return &tfprotov5.GetProviderSchemaResponse{
+		ResourceSchemas: map[string]*tfprotov5.Schema{
+			"foo_thing": {
+				Block: &tfprotov5.SchemaBlock{
+					Attributes: []*tfprotov5.SchemaAttribute{
+						{Name: "id", Type: tftypes.String, Computed: true},
+						{Name: "name", Type: tftypes.String, Required: true},
+					},
+				},
+			},
+		},
+	}, nil
+}
+
+func (fakeV5Client) GetResourceIdentitySchemas(context.Context, *tfprotov5.GetResourceIdentitySchemasRequest) (*tfprotov5.GetResourceIdentitySchemasResponse, error) {
+	return &tfprotov5.GetResourceIdentitySchemasResponse{}, nil
+}
+
+func (fakeV5Client) PlanResourceChange(_ context.Context, req *tfprotov5.PlanResourceChangeRequest) (*tfprotov5.PlanResourceChangeResponse, error) {
+	proposed, err := msgpack.Unmarshal(req.ProposedNewState.MsgPack, thingType)
+	if err != nil {
+		return nil, err
+	}
+	attrs := proposed.AsValueMap()
+	attrs["id"] = cty.UnknownVal(cty.String)
+	mp, err := msgpack.Marshal(cty.ObjectVal(attrs), thingType)
+	if err != nil {
+		return nil, err
+	}
+	return &tfprotov5.PlanResourceChangeResponse{
+		PlannedState: &tfprotov5.DynamicValue{MsgPack: mp},
+		Diagnostics: []*tfprotov5.Diagnostic{
+			{Severity: tfprotov5.DiagnosticSeverityWarning, Summary: "planned"},
+		},
+	}, nil
+}
+
+func TestV5PlanResourceChange(t *testing.T) {
+	var buf bytes.Buffer
+	logger := hclog.New(&hclog.LoggerOptions{Output: &buf, JSONFormat: true})
+	p, err := NewV5(fakeV5Client{}, logger, Option{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dv := func(v cty.Value) *tfprotov5.DynamicValue {
+		mp, err := msgpack.Marshal(v, thingType)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return &tfprotov5.DynamicValue{MsgPack: mp}
+	}
+	config := cty.ObjectVal(map[string]cty.Value{
+		"id":   cty.NullVal(cty.String),
+		"name": cty.StringVal("a"),
+	})
+	resp, err := p.PlanResourceChange(context.Background(), &tfprotov5.PlanResourceChangeRequest{
+		TypeName:         "foo_thing",
+		PriorState:       dv(cty.NullVal(thingType)),
+		ProposedNewState: dv(config),
+		Config:           dv(config),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.PlannedState == nil {
+		t.Fatal("the response is not forwarded")
+	}
+
+	var logs []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var m map[string]any
+		if err := json.Unmarshal([]byte(line), &m); err != nil {
+			t.Fatalf("invalid log line %q: %v", line, err)
+		}
+		logs = append(logs, m)
+	}
+	if len(logs) != 2 {
+		t.Fatalf("expect 2 log lines, got %d:\n%s", len(logs), buf.String())
+	}
+
+	req, res := logs[0], logs[1]
+	for _, k := range []string{"@timestamp", "@level", "duration"} {
+		delete(req, k)
+		if _, ok := res[k]; !ok && k == "duration" {
+			t.Errorf("missing duration in the response log")
+		}
+		delete(res, k)
+	}
+	wantReq := map[string]any{
+		"@message":            "request",
+		"rpc":                 "PlanResourceChange",
+		"id":                  float64(1),
+		"type_name":           "foo_thing",
+		"prior_state":         "null",
+		"proposed_new_state":  `{ id = null, name = "a" }`,
+		"config":              `{ id = null, name = "a" }`,
+		"prior_identity":      "null",
+		"prior_private":       "",
+		"provider_meta":       "null",
+		"client_capabilities": "<nil>",
+	}
+	if diff := cmp.Diff(wantReq, req); diff != "" {
+		t.Errorf("wrong request log\n%s", diff)
+	}
+	wantRes := map[string]any{
+		"@message":           "response",
+		"rpc":                "PlanResourceChange",
+		"id":                 float64(1),
+		"planned_state":      `{ id = (unknown), name = "a" }`,
+		"planned_identity":   "null",
+		"requires_replace":   nil,
+		"planned_private":    "",
+		"legacy_type_system": false,
+		"diagnostics":        []any{"warning: planned"},
+	}
+	if diff := cmp.Diff(wantRes, res); diff != "" {
+		t.Errorf("wrong response log\n%s", diff)
+	}
+}
+
+// TestServe serves the proxy over go-plugin, and calls it as Terraform does via the reattach config.
+func TestServe(t *testing.T) {
+	var buf bytes.Buffer
+	logger := hclog.New(&hclog.LoggerOptions{Output: &buf, JSONFormat: true})
+	p, err := NewV5(fakeV5Client{}, logger, Option{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	reattachCh := make(chan *plugin.ReattachConfig)
+	closeCh := make(chan struct{})
+	go func() {
+		tf5server.Serve(p, tf5server.ServeOption{
+			Logger: hclog.NewNullLogger(),
+			Test:   &plugin.ServeTestConfig{Context: ctx, ReattachConfigCh: reattachCh, CloseCh: closeCh},
+		})
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-closeCh
+	})
+
+	reattach := <-reattachCh
+	env, err := ReattachEnv("registry.terraform.io/hashicorp/foo", reattach)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(env, `{"registry.terraform.io/hashicorp/foo":{"Protocol":"grpc","ProtocolVersion":5,`) {
+		t.Errorf("unexpected reattach env %s", env)
+	}
+
+	c, err := tfclient.New(tfclient.Option{Reattach: reattach, Logger: hclog.NewNullLogger()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	config := cty.ObjectVal(map[string]cty.Value{
+		"id":   cty.NullVal(cty.String),
+		"name": cty.StringVal("a"),
+	})
+	resp, diags := c.PlanResourceChange(context.Background(), typ.PlanResourceChangeRequest{
+		TypeName:         "foo_thing",
+		PriorState:       cty.NullVal(thingType),
+		ProposedNewState: config,
+		Config:           config,
+	})
+	if diff := cmp.Diff(typ.Diagnostics{{Severity: typ.Warning, Summary: "planned"}}, diags); diff != "" {
+		t.Errorf("wrong diagnostics\n%s", diff)
+	}
+	want := cty.ObjectVal(map[string]cty.Value{
+		"id":   cty.UnknownVal(cty.String),
+		"name": cty.StringVal("a"),
+	})
+	if resp == nil || !resp.PlannedState.RawEquals(want) {
+		t.Fatalf("wrong planned state: %#v", resp)
+	}
+	if !strings.Contains(buf.String(), `"planned_state":"{ id = (unknown), name = \"a\" }"`) {
+		t.Errorf("the planned state is not logged:\n%s", buf.String())
+	}
+}
+
+func TestFormatValue(t *testing.T) {
+	v := cty.ObjectVal(map[string]cty.Value{
+		"list": cty.ListVal([]cty.Value{cty.NumberIntVal(1), cty.NumberFloatVal(1.5)}),
+		"map":  cty.MapVal(map[string]cty.Value{"a-b c": cty.True}),
+		"obj":  cty.EmptyObjectVal,
+		"set":  cty.UnknownVal(cty.Set(cty.String)),
+	})
+	want := `{ list = [1, 1.5], map = { "a-b c" = true }, obj = {}, set = (unknown) }`
+	if got := formatValue(v); got != want {
+		t.Errorf("got:  %s\nwant: %s", got, want)
+	}
+}
+
+func TestBlockValue(t *testing.T) {
+	b := &tfjson.SchemaBlock{
+		Attributes: map[string]*tfjson.SchemaAttribute{
+			"name":     {AttributeType: cty.String, Required: true},
+			"password": {AttributeType: cty.String, Optional: true, Sensitive: true},
+		},
+	}
+	mp, err := msgpack.Marshal(cty.ObjectVal(map[string]cty.Value{
+		"name":     cty.StringVal("a"),
+		"password": cty.StringVal("secret"),
+	}), configschema.SchemaBlockImpliedType(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		showSensitive bool
+		want          string
+	}{
+		{false, `{ name = "a", password = (sensitive value) }`},
+		{true, `{ name = "a", password = "secret" }`},
+	} {
+		r := &recorder{showSensitive: tt.showSensitive}
+		if got := r.blockValue(mp, nil, b); got != tt.want {
+			t.Errorf("showSensitive=%t\ngot:  %s\nwant: %s", tt.showSensitive, got, tt.want)
+		}
+	}
+}
+
+func TestRawBlockValue(t *testing.T) {
+	b := &tfjson.SchemaBlock{
+		Attributes: map[string]*tfjson.SchemaAttribute{
+			"name":     {AttributeType: cty.String, Required: true},
+			"password": {AttributeType: cty.String, Optional: true, Sensitive: true},
+		},
+	}
+	js := []byte(`{"name":"a","password":"secret"}`)
+	legacy := []byte(`{"name":"a","password":"secret","removed":"x"}`)
+	flatmap := map[string]string{"name": "a", "password": "secret"}
+
+	for _, tt := range []struct {
+		name          string
+		js            []byte
+		flatmap       map[string]string
+		b             *tfjson.SchemaBlock
+		showSensitive bool
+		want          string
+	}{
+		{name: "null", b: b, want: "null"},
+		{name: "json", js: js, b: b, want: `{ name = "a", password = (sensitive value) }`},
+		{name: "json shown", js: js, b: b, showSensitive: true, want: `{ name = "a", password = "secret" }`},
+		{name: "undecodable", js: legacy, b: b, want: "<46 bytes>"},
+		{name: "undecodable shown", js: legacy, b: b, showSensitive: true, want: string(legacy)},
+		{name: "no schema", js: js, want: "<32 bytes>"},
+		{name: "flatmap", flatmap: flatmap, b: b, want: "<19 bytes>"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &recorder{showSensitive: tt.showSensitive}
+			if got := r.rawBlockValue(tt.js, tt.flatmap, tt.b); got != tt.want {
+				t.Errorf("got:  %s\nwant: %s", got, tt.want)
+			}
+		})
+	}
+}
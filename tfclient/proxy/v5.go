@@ -0,0 +1,615 @@
+package proxy
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/go-hclog"
+	tfjson "github.com/hashicorp/terraform-json"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov5"
+	"github.com/magodo/terraform-client-go/tfclient/tfprotov5/convert"
+	"github.com/magodo/terraform-client-go/tfclient/tfprotov5/tf5client"
+	"github.com/magodo/terraform-client-go/tfclient/typ"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// V5 is the proxy of a protocol v5 provider.
+type V5 struct {
+	client tf5client.TFProtoV5Client
+	schema *typ.GetProviderSchemaResponse
+	rec    *recorder
+}
+
+var _ tfprotov5.ProviderServer = &V5{}
+var _ tfprotov5.ListResourceServer = &V5{}
+var _ tfprotov5.ActionServer = &V5{}
+
+// NewV5 creates the proxy of the provider behind the client. The provider schema is fetched in advance,
+// for decoding the values of the calls.
+func NewV5(client tf5client.TFProtoV5Client, logger hclog.Logger, opts Option) (*V5, error) {
+	if logger == nil {
+		logger = hclog.Default()
+	}
+	c, err := tf5client.New(nil, client, nil)
+	if err != nil {
+		return nil, fmt.Errorf("fetching the provider schema: %v", err)
+	}
+	schema, diags := c.GetProviderSchema()
+	if diags.HasErrors() {
+		return nil, fmt.Errorf("fetching the provider schema: %v", diags.Err())
+	}
+	return &V5{
+		client: client,
+		schema: schema,
+		rec:    &recorder{logger: logger, showSensitive: opts.ShowSensitive},
+	}, nil
+}
+
+func (p *V5) value(v *tfprotov5.DynamicValue, ty cty.Type) string {
+	if v == nil {
+		return "null"
+	}
+	return p.rec.value(v.MsgPack, v.JSON, ty)
+}
+
+func (p *V5) blockValue(v *tfprotov5.DynamicValue, b *tfjson.SchemaBlock) string {
+	if v == nil {
+		return "null"
+	}
+	return p.rec.blockValue(v.MsgPack, v.JSON, b)
+}
+
+func (p *V5) identity(v *tfprotov5.ResourceIdentityData, typeName string) string {
+	if v == nil || v.IdentityData == nil {
+		return "null"
+	}
+	return p.rec.identityValue(v.IdentityData.MsgPack, v.IdentityData.JSON, p.schema.ResourceTypes[typeName].Identity)
+}
+
+func (p *V5) rawState(v *tfprotov5.RawState, b *tfjson.SchemaBlock) string {
+	if v == nil {
+		return "null"
+	}
+	return p.rec.rawBlockValue(v.JSON, v.Flatmap, b)
+}
+
+func (p *V5) rawIdentity(v *tfprotov5.RawState, typeName string) string {
+	if v == nil {
+		return "null"
+	}
+	return p.rec.rawIdentityValue(v.JSON, v.Flatmap, p.schema.ResourceTypes[typeName].Identity)
+}
+
+func (p *V5) deferred(d *tfprotov5.Deferred) []any {
+	if d == nil {
+		return nil
+	}
+	return []any{"deferred", d.Reason.String()}
+}
+
+func (p *V5) GetMetadata(ctx context.Context, req *tfprotov5.GetMetadataRequest) (*tfprotov5.GetMetadataResponse, error) {
+	c := p.rec.begin("GetMetadata")
+	resp, err := p.client.GetMetadata(ctx, req)
+	if err != nil {
+		c.fail(err)
+		return nil, err
+	}
+	c.end(append([]any{
+		"resources", len(resp.Resources),
+		"data_sources", len(resp.DataSources),
+		"ephemeral_resources", len(resp.EphemeralResources),
+		"list_resources", len(resp.ListResources),
+		"actions", len(resp.Actions),
+		"functions", len(resp.Functions),
+	}, diagArgs(convert.DecodeDiagnostics(resp.Diagnostics))...)...)
+	return resp, nil
+}
+
+func (p *V5) GetProviderSchema(ctx context.Context, req *tfprotov5.GetProviderSchemaRequest) (*tfprotov5.GetProviderSchemaResponse, error) {
+	c := p.rec.begin("GetProviderSchema")
+	resp, err := p.client.GetProviderSchema(ctx, req)
+	if err != nil {
+		c.fail(err)
+		return nil, err
+	}
+	c.end(append([]any{
+		"resources", len(resp.ResourceSchemas),
+		"data_sources", len(resp.DataSourceSchemas),
+		"ephemeral_resources", len(resp.EphemeralResourceSchemas),
+		"list_resources", len(resp.ListResourceSchemas),
+		"actions", len(resp.ActionSchemas),
+		"functions", len(resp.Functions),
+	}, diagArgs(convert.DecodeDiagnostics(resp.Diagnostics))...)...)
+	return resp, nil
+}
+
+func (p *V5) GetResourceIdentitySchemas(ctx context.Context, req *tfprotov5.GetResourceIdentitySchemasRequest) (*tfprotov5.GetResourceIdentitySchemasResponse, error) {
+	c := p.rec.begin("GetResourceIdentitySchemas")
+	resp, err := p.client.GetResourceIdentitySchemas(ctx, req)
+	if err != nil {
+		c.fail(err)
+		return nil, err
+	}
+	c.end(append([]any{"identity_schemas", len(resp.IdentitySchemas)}, diagArgs(convert.DecodeDiagnostics(resp.Diagnostics))...)...)
+	return resp, nil
+}
+
+func (p *V5) PrepareProviderConfig(ctx context.Context, req *tfprotov5.PrepareProviderConfigRequest) (*tfprotov5.PrepareProviderConfigResponse, error) {
+	c := p.rec.begin("PrepareProviderConfig", "config", p.blockValue(req.Config, schemaBlock(p.schema.Provider)))
+	resp, err := p.client.PrepareProviderConfig(ctx, req)
+	if err != nil {
+		c.fail(err)
+		return nil, err
+	}
+	c.end(append([]any{"prepared_config", p.blockValue(resp.PreparedConfig, schemaBlock(p.schema.Provider))}, diagArgs(convert.DecodeDiagnostics(resp.Diagnostics))...)...)
+	return resp, nil
+}
+
+func (p *V5) ConfigureProvider(ctx context.Context, req *tfprotov5.ConfigureProviderRequest) (*tfprotov5.ConfigureProviderResponse, error) {
+	c := p.rec.begin("ConfigureProvider",
+		"terraform_version", req.TerraformVersion,
+		"config", p.blockValue(req.Config, schemaBlock(p.schema.Provider)),
+		"client_capabilities", fmt.Sprintf("%+v", req.ClientCapabilities),
+	)
+	resp, err := p.client.ConfigureProvider(ctx, req)
+	if err != nil {
+		c.fail(err)
+		return nil, err
+	}
+	c.end(diagArgs(convert.DecodeDiagnostics(resp.Diagnostics))...)
+	return resp, nil
+}
+
+func (p *V5) StopProvider(ctx context.Context, req *tfprotov5.StopProviderRequest) (*tfprotov5.StopProviderResponse, error) {
+	c := p.rec.begin("StopProvider")
+	resp, err := p.client.StopProvider(ctx, req)
+	if err != nil {
+		c.fail(err)
+		return nil, err
+	}
+	c.end("error", resp.Error)
+	return resp, nil
+}
+
+func (p *V5) ValidateResourceTypeConfig(ctx context.Context, req *tfprotov5.ValidateResourceTypeConfigRequest) (*tfprotov5.ValidateResourceTypeConfigResponse, error) {
+	c := p.rec.begin("ValidateResourceTypeConfig",
+		"type_name", req.TypeName,
+		"config", p.blockValue(req.Config, typeBlock(p.schema.ResourceTypes, req.TypeName)),
+		"client_capabilities", fmt.Sprintf("%+v", req.ClientCapabilities),
+	)
+	resp, err := p.client.ValidateResourceTypeConfig(ctx, req)
+	if err != nil {
+		c.fail(err)
+		return nil, err
+	}
+	c.end(diagArgs(convert.DecodeDiagnostics(resp.Diagnostics))...)
+	return resp, nil
+}
+
+func (p *V5) UpgradeResourceState(ctx context.Context, req *tfprotov5.UpgradeResourceStateRequest) (*tfprotov5.UpgradeResourceStateResponse, error) {
+	c := p.rec.begin("UpgradeResourceState",
+		"type_name", req.TypeName,
+		"version", req.Version,
+		"raw_state", p.rawState(req.RawState, typeBlock(p.schema.ResourceTypes, req.TypeName)),
+	)
+	resp, err := p.client.UpgradeResourceState(ctx, req)
+	if err != nil {
+		c.fail(err)
+		return nil, err
+	}
+	c.end(append([]any{"upgraded_state", p.blockValue(resp.UpgradedState, typeBlock(p.schema.ResourceTypes, req.TypeName))}, diagArgs(convert.DecodeDiagnostics(resp.Diagnostics))...)...)
+	return resp, nil
+}
+
+func (p *V5) UpgradeResourceIdentity(ctx context.Context, req *tfprotov5.UpgradeResourceIdentityRequest) (*tfprotov5.UpgradeResourceIdentityResponse, error) {
+	c := p.rec.begin("UpgradeResourceIdentity",
+		"type_name", req.TypeName,
+		"version", req.Version,
+		"raw_identity", p.rawIdentity(req.RawIdentity, req.TypeName),
+	)
+	resp, err := p.client.UpgradeResourceIdentity(ctx, req)
+	if err != nil {
+		c.fail(err)
+		return nil, err
+	}
+	c.end(append([]any{"upgraded_identity", p.identity(resp.UpgradedIdentity, req.TypeName)}, diagArgs(convert.DecodeDiagnostics(resp.Diagnostics))...)...)
+	return resp, nil
+}
+
+func (p *V5) ReadResource(ctx context.Context, req *tfprotov5.ReadResourceRequest) (*tfprotov5.ReadResourceResponse, error) {
+	b := typeBlock(p.schema.ResourceTypes, req.TypeName)
+	c := p.rec.begin("ReadResource",
+		"type_name", req.TypeName,
+		"current_state", p.blockValue(req.CurrentState, b),
+		"current_identity", p.identity(req.CurrentIdentity, req.TypeName),
+		"private", formatPrivate(req.Private),
+		"provider_meta", p.blockValue(req.ProviderMeta, schemaBlock(p.schema.ProviderMeta)),
+		"client_capabilities", fmt.Sprintf("%+v", req.ClientCapabilities),
+	)
+	resp, err := p.client.ReadResource(ctx, req)
+	if err != nil {
+		c.fail(err)
+		return nil, err
+	}
+	args := []any{
+		"new_state", p.blockValue(resp.NewState, b),
+		"new_identity", p.identity(resp.NewIdentity, req.TypeName),
+		"private", formatPrivate(resp.Private),
+	}
+	args = append(args, p.deferred(resp.Deferred)...)
+	c.end(append(args, diagArgs(convert.DecodeDiagnostics(resp.Diagnostics))...)...)
+	return resp, nil
+}
+
+func (p *V5) PlanResourceChange(ctx context.Context, req *tfprotov5.PlanResourceChangeRequest) (*tfprotov5.PlanResourceChangeResponse, error) {
+	b := typeBlock(p.schema.ResourceTypes, req.TypeName)
+	c := p.rec.begin("PlanResourceChange",
+		"type_name", req.TypeName,
+		"prior_state", p.blockValue(req.PriorState, b),
+		"proposed_new_state", p.blockValue(req.ProposedNewState, b),
+		"config", p.blockValue(req.Config, b),
+		"prior_identity", p.identity(req.PriorIdentity, req.TypeName),
+		"prior_private", formatPrivate(req.PriorPrivate),
+		"provider_meta", p.blockValue(req.ProviderMeta, schemaBlock(p.schema.ProviderMeta)),
+		"client_capabilities", fmt.Sprintf("%+v", req.ClientCapabilities),
+	)
+	resp, err := p.client.PlanResourceChange(ctx, req)
+	if err != nil {
+		c.fail(err)
+		return nil, err
+	}
+	var requiresReplace []string
+	for _, path := range resp.RequiresReplace {
+		requiresReplace = append(requiresReplace, typ.FormatCtyPath(convert.DecodeAttributePath(path)))
+	}
+	args := []any{
+		"planned_state", p.blockValue(resp.PlannedState, b),
+		"planned_identity", p.identity(resp.PlannedIdentity, req.TypeName),
+		"requires_replace", requiresReplace,
+		"planned_private", formatPrivate(resp.PlannedPrivate),
+		"legacy_type_system", resp.UnsafeToUseLegacyTypeSystem,
+	}
+	args = append(args, p.deferred(resp.Deferred)...)
+	c.end(append(args, diagArgs(convert.DecodeDiagnostics(resp.Diagnostics))...)...)
+	return resp, nil
+}
+
+func (p *V5) ApplyResourceChange(ctx context.Context, req *tfprotov5.ApplyResourceChangeRequest) (*tfprotov5.ApplyResourceChangeResponse, error) {
+	b := typeBlock(p.schema.ResourceTypes, req.TypeName)
+	c := p.rec.begin("ApplyResourceChange",
+		"type_name", req.TypeName,
+		"prior_state", p.blockValue(req.PriorState, b),
+		"planned_state", p.blockValue(req.PlannedState, b),
+		"config", p.blockValue(req.Config, b),
+		"planned_identity", p.identity(req.PlannedIdentity, req.TypeName),
+		"planned_private", formatPrivate(req.PlannedPrivate),
+		"provider_meta", p.blockValue(req.ProviderMeta, schemaBlock(p.schema.ProviderMeta)),
+	)
+	resp, err := p.client.ApplyResourceChange(ctx, req)
+	if err != nil {
+		c.fail(err)
+		return nil, err
+	}
+	c.end(append([]any{
+		"new_state", p.blockValue(resp.NewState, b),
+		"new_identity", p.identity(resp.NewIdentity, req.TypeName),
+		"private", formatPrivate(resp.Private),
+		"legacy_type_system", resp.UnsafeToUseLegacyTypeSystem,
+	}, diagArgs(convert.DecodeDiagnostics(resp.Diagnostics))...)...)
+	return resp, nil
+}
+
+func (p *V5) ImportResourceState(ctx context.Context, req *tfprotov5.ImportResourceStateRequest) (*tfprotov5.ImportResourceStateResponse, error) {
+	c := p.rec.begin("ImportResourceState",
+		"type_name", req.TypeName,
+		"id", req.ID,
+		"identity", p.identity(req.Identity, req.TypeName),
+		"client_capabilities", fmt.Sprintf("%+v", req.ClientCapabilities),
+	)
+	resp, err := p.client.ImportResourceState(ctx, req)
+	if err != nil {
+		c.fail(err)
+		return nil, err
+	}
+	var imported []string
+	for _, res := range resp.ImportedResources {
+		imported = append(imported, fmt.Sprintf("%s: state=%s identity=%s private=%s",
+			res.TypeName,
+			p.blockValue(res.State, typeBlock(p.schema.ResourceTypes, res.TypeName)),
+			p.identity(res.Identity, res.TypeName),
+			formatPrivate(res.Private),
+		))
+	}
+	args := []any{"imported_resources", imported}
+	args = append(args, p.deferred(resp.Deferred)...)
+	c.end(append(args, diagArgs(convert.DecodeDiagnostics(resp.Diagnostics))...)...)
+	return resp, nil
+}
+
+func (p *V5) MoveResourceState(ctx context.Context, req *tfprotov5.MoveResourceStateRequest) (*tfprotov5.MoveResourceStateResponse, error) {
+	c := p.rec.begin("MoveResourceState",
+		"source_provider_address", req.SourceProviderAddress,
+		"source_type_name", req.SourceTypeName,
+		"source_schema_version", req.SourceSchemaVersion,
+		// The source is of another resource type, probably of another provider, whose schema is unknown.
+		"source_state", p.rawState(req.SourceState, nil),
+		"source_identity_schema_version", req.SourceIdentitySchemaVersion,
+		"source_identity", p.rawIdentity(req.SourceIdentity, ""),
+		"source_private", formatPrivate(req.SourcePrivate),
+		"target_type_name", req.TargetTypeName,
+	)
+	resp, err := p.client.MoveResourceState(ctx, req)
+	if err != nil {
+		c.fail(err)
+		return nil, err
+	}
+	c.end(append([]any{
+		"target_state", p.blockValue(resp.TargetState, typeBlock(p.schema.ResourceTypes, req.TargetTypeName)),
+		"target_identity", p.identity(resp.TargetIdentity, req.TargetTypeName),
+		"target_private", formatPrivate(resp.TargetPrivate),
+	}, diagArgs(convert.DecodeDiagnostics(resp.Diagnostics))...)...)
+	return resp, nil
+}
+
+func (p *V5) ValidateDataSourceConfig(ctx context.Context, req *tfprotov5.ValidateDataSourceConfigRequest) (*tfprotov5.ValidateDataSourceConfigResponse, error) {
+	c := p.rec.begin("ValidateDataSourceConfig",
+		"type_name", req.TypeName,
+		"config", p.blockValue(req.Config, typeBlock(p.schema.DataSources, req.TypeName)),
+	)
+	resp, err := p.client.ValidateDataSourceConfig(ctx, req)
+	if err != nil {
+		c.fail(err)
+		return nil, err
+	}
+	c.end(diagArgs(convert.DecodeDiagnostics(resp.Diagnostics))...)
+	return resp, nil
+}
+
+func (p *V5) ReadDataSource(ctx context.Context, req *tfprotov5.ReadDataSourceRequest) (*tfprotov5.ReadDataSourceResponse, error) {
+	b := typeBlock(p.schema.DataSources, req.TypeName)
+	c := p.rec.begin("ReadDataSource",
+		"type_name", req.TypeName,
+		"config", p.blockValue(req.Config, b),
+		"provider_meta", p.blockValue(req.ProviderMeta, schemaBlock(p.schema.ProviderMeta)),
+		"client_capabilities", fmt.Sprintf("%+v", req.ClientCapabilities),
+	)
+	resp, err := p.client.ReadDataSource(ctx, req)
+	if err != nil {
+		c.fail(err)
+		return nil, err
+	}
+	args := []any{"state", p.blockValue(resp.State, b)}
+	args = append(args, p.deferred(resp.Deferred)...)
+	c.end(append(args, diagArgs(convert.DecodeDiagnostics(resp.Diagnostics))...)...)
+	return resp, nil
+}
+
+func (p *V5) CallFunction(ctx context.Context, req *tfprotov5.CallFunctionRequest) (*tfprotov5.CallFunctionResponse, error) {
+	decl := p.schema.Functions[req.Name]
+	var args []string
+	for i, arg := range req.Arguments {
+		var ty cty.Type
+		switch {
+		case i < len(decl.Parameters):
+			ty = decl.Parameters[i].Type
+		case decl.VariadicParameter != nil:
+			ty = decl.VariadicParameter.Type
+		}
+		args = append(args, p.value(arg, ty))
+	}
+	c := p.rec.begin("CallFunction", "name", req.Name, "arguments", args)
+	resp, err := p.client.CallFunction(ctx, req)
+	if err != nil {
+		c.fail(err)
+		return nil, err
+	}
+	if resp.Error != nil {
+		ferr := resp.Error.Text
+		if resp.Error.FunctionArgument != nil {
+			ferr = fmt.Sprintf("argument %d: %s", *resp.Error.FunctionArgument, ferr)
+		}
+		c.end("error", ferr)
+		return resp, nil
+	}
+	c.end("result", p.value(resp.Result, decl.ReturnType))
+	return resp, nil
+}
+
+func (p *V5) GetFunctions(ctx context.Context, req *tfprotov5.GetFunctionsRequest) (*tfprotov5.GetFunctionsResponse, error) {
+	c := p.rec.begin("GetFunctions")
+	resp, err := p.client.GetFunctions(ctx, req)
+	if err != nil {
+		c.fail(err)
+		return nil, err
+	}
+	c.end(append([]any{"functions", len(resp.Functions)}, diagArgs(convert.DecodeDiagnostics(resp.Diagnostics))...)...)
+	return resp, nil
+}
+
+func (p *V5) ValidateEphemeralResourceConfig(ctx context.Context, req *tfprotov5.ValidateEphemeralResourceConfigRequest) (*tfprotov5.ValidateEphemeralResourceConfigResponse, error) {
+	c := p.rec.begin("ValidateEphemeralResourceConfig",
+		"type_name", req.TypeName,
+		"config", p.blockValue(req.Config, typeBlock(p.schema.EphemeralResourceTypes, req.TypeName)),
+	)
+	resp, err := p.client.ValidateEphemeralResourceConfig(ctx, req)
+	if err != nil {
+		c.fail(err)
+		return nil, err
+	}
+	c.end(diagArgs(convert.DecodeDiagnostics(resp.Diagnostics))...)
+	return resp, nil
+}
+
+func (p *V5) OpenEphemeralResource(ctx context.Context, req *tfprotov5.OpenEphemeralResourceRequest) (*tfprotov5.OpenEphemeralResourceResponse, error) {
+	b := typeBlock(p.schema.EphemeralResourceTypes, req.TypeName)
+	c := p.rec.begin("OpenEphemeralResource",
+		"type_name", req.TypeName,
+		"config", p.blockValue(req.Config, b),
+		"client_capabilities", fmt.Sprintf("%+v", req.ClientCapabilities),
+	)
+	resp, err := p.client.OpenEphemeralResource(ctx, req)
+	if err != nil {
+		c.fail(err)
+		return nil, err
+	}
+	args := []any{
+		"result", p.blockValue(resp.Result, b),
+		"private", formatPrivate(resp.Private),
+		"renew_at", resp.RenewAt,
+	}
+	args = append(args, p.deferred(resp.Deferred)...)
+	c.end(append(args, diagArgs(convert.DecodeDiagnostics(resp.Diagnostics))...)...)
+	return resp, nil
+}
+
+func (p *V5) RenewEphemeralResource(ctx context.Context, req *tfprotov5.RenewEphemeralResourceRequest) (*tfprotov5.RenewEphemeralResourceResponse, error) {
+	c := p.rec.begin("RenewEphemeralResource",
+		"type_name", req.TypeName,
+		"private", formatPrivate(req.Private),
+	)
+	resp, err := p.client.RenewEphemeralResource(ctx, req)
+	if err != nil {
+		c.fail(err)
+		return nil, err
+	}
+	c.end(append([]any{
+		"private", formatPrivate(resp.Private),
+		"renew_at", resp.RenewAt,
+	}, diagArgs(convert.DecodeDiagnostics(resp.Diagnostics))...)...)
+	return resp, nil
+}
+
+func (p *V5) CloseEphemeralResource(ctx context.Context, req *tfprotov5.CloseEphemeralResourceRequest) (*tfprotov5.CloseEphemeralResourceResponse, error) {
+	c := p.rec.begin("CloseEphemeralResource",
+		"type_name", req.TypeName,
+		"private", formatPrivate(req.Private),
+	)
+	resp, err := p.client.CloseEphemeralResource(ctx, req)
+	if err != nil {
+		c.fail(err)
+		return nil, err
+	}
+	c.end(diagArgs(convert.DecodeDiagnostics(resp.Diagnostics))...)
+	return resp, nil
+}
+
+func (p *V5) ValidateListResourceConfig(ctx context.Context, req *tfprotov5.ValidateListResourceConfigRequest) (*tfprotov5.ValidateListResourceConfigResponse, error) {
+	c := p.rec.begin("ValidateListResourceConfig",
+		"type_name", req.TypeName,
+		"config", p.blockValue(req.Config, typeBlock(p.schema.ListResourceTypes, req.TypeName)),
+		"include_resource_object", p.value(req.IncludeResourceObject, cty.Bool),
+		"limit", p.value(req.Limit, cty.Number),
+	)
+	resp, err := p.client.ValidateListResourceConfig(ctx, req)
+	if err != nil {
+		c.fail(err)
+		return nil, err
+	}
+	c.end(diagArgs(convert.DecodeDiagnostics(resp.Diagnostics))...)
+	return resp, nil
+}
+
+func (p *V5) ListResource(ctx context.Context, req *tfprotov5.ListResourceRequest) (*tfprotov5.ListResourceServerStream, error) {
+	c := p.rec.begin("ListResource",
+		"type_name", req.TypeName,
+		"config", p.blockValue(req.Config, typeBlock(p.schema.ListResourceTypes, req.TypeName)),
+		"include_resource", req.IncludeResource,
+		"limit", req.Limit,
+	)
+	stream, err := p.client.ListResource(ctx, req)
+	if err != nil {
+		c.fail(err)
+		return nil, err
+	}
+	if stream == nil || stream.Results == nil {
+		c.end("results", 0)
+		return stream, nil
+	}
+	results := stream.Results
+	stream.Results = func(yield func(tfprotov5.ListResourceResult) bool) {
+		n := 0
+		defer func() { c.end("results", n) }()
+		for res := range results {
+			n++
+			c.event(append([]any{
+				"display_name", res.DisplayName,
+				"resource", p.blockValue(res.Resource, typeBlock(p.schema.ResourceTypes, req.TypeName)),
+				"identity", p.identity(res.Identity, req.TypeName),
+			}, diagArgs(convert.DecodeDiagnostics(res.Diagnostics))...)...)
+			if !yield(res) {
+				return
+			}
+		}
+	}
+	return stream, nil
+}
+
+func (p *V5) ValidateActionConfig(ctx context.Context, req *tfprotov5.ValidateActionConfigRequest) (*tfprotov5.ValidateActionConfigResponse, error) {
+	c := p.rec.begin("ValidateActionConfig",
+		"action_type", req.ActionType,
+		"config", p.blockValue(req.Config, typeBlock(p.schema.Actions, req.ActionType)),
+	)
+	resp, err := p.client.ValidateActionConfig(ctx, req)
+	if err != nil {
+		c.fail(err)
+		return nil, err
+	}
+	c.end(diagArgs(convert.DecodeDiagnostics(resp.Diagnostics))...)
+	return resp, nil
+}
+
+func (p *V5) PlanAction(ctx context.Context, req *tfprotov5.PlanActionRequest) (*tfprotov5.PlanActionResponse, error) {
+	c := p.rec.begin("PlanAction",
+		"action_type", req.ActionType,
+		"config", p.blockValue(req.Config, typeBlock(p.schema.Actions, req.ActionType)),
+		"client_capabilities", fmt.Sprintf("%+v", req.ClientCapabilities),
+	)
+	resp, err := p.client.PlanAction(ctx, req)
+	if err != nil {
+		c.fail(err)
+		return nil, err
+	}
+	c.end(append(p.deferred(resp.Deferred), diagArgs(convert.DecodeDiagnostics(resp.Diagnostics))...)...)
+	return resp, nil
+}
+
+func (p *V5) InvokeAction(ctx context.Context, req *tfprotov5.InvokeActionRequest) (*tfprotov5.InvokeActionServerStream, error) {
+	c := p.rec.begin("InvokeAction",
+		"action_type", req.ActionType,
+		"config", p.blockValue(req.Config, typeBlock(p.schema.Actions, req.ActionType)),
+		"client_capabilities", fmt.Sprintf("%+v", req.ClientCapabilities),
+	)
+	stream, err := p.client.InvokeAction(ctx, req)
+	if err != nil {
+		c.fail(err)
+		return nil, err
+	}
+	if stream == nil || stream.Events == nil {
+		c.end("events", 0)
+		return stream, nil
+	}
+	events := stream.Events
+	stream.Events = func(yield func(tfprotov5.InvokeActionEvent) bool) {
+		n := 0
+		defer func() { c.end("events", n) }()
+		for ev := range events {
+			n++
+			switch t := ev.Type.(type) {
+			case tfprotov5.ProgressInvokeActionEventType:
+				c.event("progress", t.Message)
+			case *tfprotov5.ProgressInvokeActionEventType:
+				c.event("progress", t.Message)
+			case tfprotov5.CompletedInvokeActionEventType:
+				c.event(append([]any{"completed", true}, diagArgs(convert.DecodeDiagnostics(t.Diagnostics))...)...)
+			case *tfprotov5.CompletedInvokeActionEventType:
+				c.event(append([]any{"completed", true}, diagArgs(convert.DecodeDiagnostics(t.Diagnostics))...)...)
+			default:
+				c.event("type", fmt.Sprintf("%T", t))
+			}
+			if !yield(ev) {
+				return
+			}
+		}
+	}
+	return stream, nil
+}
@@ -2,7 +2,9 @@ package tfclient
 
 import (
 	"github.com/hashicorp/go-plugin"
+	"github.com/magodo/terraform-client-go/tfclient/providerlog"
 	"github.com/magodo/terraform-client-go/tfclient/tfprotov5/tf5client"
+	"github.com/magodo/terraform-client-go/tfclient/tfprotov6/tf5to6client"
 	"github.com/magodo/terraform-client-go/tfclient/tfprotov6/tf6client"
 )
 
@@ -12,18 +14,37 @@ type RawClient struct {
 	// Either one of below will be non nil
 	v5client tf5client.TFProtoV5Client
 	v6client tf6client.TFProtoV6Client
+
+	logCapture *providerlog.Capture
+}
+
+// ProviderLogs returns the capture of the provider's logs if the Option.CaptureProviderLogs is set, otherwise
+// return nil. The calls made by the raw clients can be correlated with the logs by Capture.Begin and Capture.End.
+func (c *RawClient) ProviderLogs() *providerlog.Capture {
+	return c.logCapture
 }
 
-// AsV5Client returns the v5 client if the linked provider is running in protocol v5, otherwise return nil
+// AsV5Client returns the v5 client if the linked provider is running in protocol v5, otherwise return nil.
+// The values can be converted from/to cty by the tftypesconv package.
 func (c *RawClient) AsV5Client() tf5client.TFProtoV5Client {
 	return c.v5client
 }
 
-// AsV6Client returns the v6 client if the linked provider is running in protocol v6, otherwise return nil
+// AsV6Client returns the v6 client if the linked provider is running in protocol v6, otherwise return nil.
+// The values can be converted from/to cty by the tftypesconv package.
 func (c *RawClient) AsV6Client() tf6client.TFProtoV6Client {
 	return c.v6client
 }
 
+// V6Client returns the v6 client regardless of the protocol version of the linked provider. A provider running in
+// protocol v5 is upgraded to v6 by the tf5to6client package.
+func (c *RawClient) V6Client() tf6client.TFProtoV6Client {
+	if c.v6client != nil {
+		return c.v6client
+	}
+	return tf5to6client.New(c.v5client)
+}
+
 // Kill ends the executing subprocess (if it is running) and perform any cleanup
 // tasks necessary such as capturing any remaining logs and so on.
 //
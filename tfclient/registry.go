@@ -0,0 +1,319 @@
+package tfclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/magodo/terraform-client-go/tfclient/typ"
+)
+
+// DefaultProviderRegistryHost is the registry host assumed for the provider source addresses
+// that don't specify one.
+const DefaultProviderRegistryHost = "registry.terraform.io"
+
+// NormalizeProviderSource returns the fully qualified form of the provider source address,
+// i.e. "<host>/<namespace>/<type>", in lower case. The host defaults to DefaultProviderRegistryHost,
+// and the namespace defaults to "hashicorp".
+func NormalizeProviderSource(source string) (string, error) {
+	parts := strings.Split(strings.ToLower(source), "/")
+	for _, p := range parts {
+		if p == "" {
+			return "", fmt.Errorf("invalid provider source address %q", source)
+		}
+	}
+	switch len(parts) {
+	case 1:
+		return DefaultProviderRegistryHost + "/hashicorp/" + parts[0], nil
+	case 2:
+		return DefaultProviderRegistryHost + "/" + parts[0] + "/" + parts[1], nil
+	case 3:
+		return strings.Join(parts, "/"), nil
+	default:
+		return "", fmt.Errorf("invalid provider source address %q", source)
+	}
+}
+
+// Registry manages multiple providers, keyed by their source addresses. Each provider can have
+// multiple configurations distinguished by alias, where the default configuration has an empty alias.
+//
+// For the providers started from the executables, each configuration runs in its own process, which
+// is started lazily on the first use. The reattached providers only support the default configuration,
+// as there is only one running provider process.
+//
+// Registry is safe for concurrent use.
+type Registry struct {
+	logger hclog.Logger
+	// newClient starts a client, which is New unless replaced by the tests.
+	newClient func(Option) (Client, error)
+
+	mu        sync.Mutex
+	providers map[string]*registeredProvider
+}
+
+type registeredProvider struct {
+	addr  string
+	ptype string
+	opts  Option
+
+	// schema is fetched by the first started instance, and is reused by the later ones.
+	schema    *typ.GetProviderSchemaResponse
+	instances map[string]*instance
+}
+
+// instance is a provider configuration. It is started by the first caller of Registry.Client without holding the
+// lock of the registry, the concurrent callers of the same configuration wait for the done channel instead.
+type instance struct {
+	done   chan struct{}
+	client Client
+	err    error
+}
+
+// NewRegistry creates an empty registry.
+func NewRegistry(logger hclog.Logger) *Registry {
+	if logger == nil {
+		logger = hclog.Default()
+	}
+	return &Registry{
+		logger:    logger,
+		newClient: New,
+		providers: map[string]*registeredProvider{},
+	}
+}
+
+// Register registers a provider by its source address. The opts is used to create the clients of the
+// provider, where the Cmd (if any) is only used as a template and won't be started itself.
+func (r *Registry) Register(source string, opts Option) error {
+	addr, err := NormalizeProviderSource(source)
+	if err != nil {
+		return err
+	}
+	if (opts.Cmd == nil) == (opts.Reattach == nil) {
+		return fmt.Errorf("provider %s: exactly one of Cmd and Reattach must be specified", addr)
+	}
+	if opts.Logger == nil {
+		opts.Logger = r.logger.Named(addr)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.providers[addr]; ok {
+		return fmt.Errorf("provider %s already registered", addr)
+	}
+	r.providers[addr] = &registeredProvider{
+		addr:      addr,
+		ptype:     addr[strings.LastIndex(addr, "/")+1:],
+		opts:      opts,
+		schema:    opts.ProviderSchema,
+		instances: map[string]*instance{},
+	}
+	return nil
+}
+
+// RegisterReattach registers all the providers specified in the TF_REATTACH_PROVIDERS format. The opts
+// is used as a template of the options for each provider, whose Cmd and Reattach fields are overridden.
+func (r *Registry) RegisterReattach(in string, opts Option) error {
+	m, err := ParseReattachProviders(in)
+	if err != nil {
+		return err
+	}
+	for source, reattach := range m {
+		opts := opts
+		opts.Cmd = nil
+		opts.Reattach = reattach
+		if err := r.Register(source, opts); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Providers returns the sorted source addresses of the registered providers.
+func (r *Registry) Providers() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var out []string
+	for addr := range r.providers {
+		out = append(out, addr)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// ProviderForType returns the source address of the provider owning the resource, data source,
+// ephemeral resource, list resource or action type. The provider is determined by the type name
+// prefix (i.e. the part before the first underscore), which is expected to be the provider type
+// of exactly one registered provider.
+func (r *Registry) ProviderForType(typeName string) (string, error) {
+	ptype, _, _ := strings.Cut(typeName, "_")
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var matches []string
+	for addr, p := range r.providers {
+		if p.ptype == ptype {
+			matches = append(matches, addr)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return "", fmt.Errorf("no registered provider for %q", typeName)
+	case 1:
+		return matches[0], nil
+	default:
+		sort.Strings(matches)
+		return "", fmt.Errorf("ambiguous providers for %q: %s, please specify the provider explicitly", typeName, strings.Join(matches, ", "))
+	}
+}
+
+// Client returns the client of the provider configuration specified by the source address and alias,
+// starting the provider if it is not started yet.
+func (r *Registry) Client(source, alias string) (Client, error) {
+	addr, err := NormalizeProviderSource(source)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	p, ok := r.providers[addr]
+	if !ok {
+		r.mu.Unlock()
+		return nil, fmt.Errorf("provider %s not registered", addr)
+	}
+	if inst, ok := p.instances[alias]; ok {
+		r.mu.Unlock()
+		<-inst.done
+		return inst.client, inst.err
+	}
+	if alias != "" && p.opts.Reattach != nil {
+		r.mu.Unlock()
+		return nil, fmt.Errorf("provider %s: aliases are not supported for the reattached provider", addr)
+	}
+	inst := &instance{done: make(chan struct{})}
+	p.instances[alias] = inst
+	opts := p.opts
+	opts.ProviderSchema = p.schema
+	r.mu.Unlock()
+
+	defer close(inst.done)
+	if opts.Cmd != nil {
+		opts.Cmd = cloneCmd(opts.Cmd)
+	}
+	if alias != "" {
+		opts.Logger = opts.Logger.With("alias", alias)
+	}
+	c, err := r.newClient(opts)
+	if err != nil {
+		// The failed instance is removed, so that it is started again by the next call.
+		inst.err = fmt.Errorf("starting provider %s: %v", addr, err)
+		r.mu.Lock()
+		if p.instances[alias] == inst {
+			delete(p.instances, alias)
+		}
+		r.mu.Unlock()
+		return nil, inst.err
+	}
+	inst.client = c
+	if opts.ProviderSchema == nil {
+		if schema, diags := c.GetProviderSchema(); !diags.HasErrors() {
+			r.mu.Lock()
+			if p.schema == nil {
+				p.schema = schema
+			}
+			r.mu.Unlock()
+		}
+	}
+	return c, nil
+}
+
+// ClientForType returns the client of the provider configuration owning the type, which is determined
+// by ProviderForType.
+func (r *Registry) ClientForType(typeName, alias string) (Client, error) {
+	addr, err := r.ProviderForType(typeName)
+	if err != nil {
+		return nil, err
+	}
+	return r.Client(addr, alias)
+}
+
+// Configure configures the provider configuration specified by the source address and alias.
+func (r *Registry) Configure(ctx context.Context, source, alias string, req typ.ConfigureProviderRequest) (*typ.ConfigureProviderResponse, typ.Diagnostics) {
+	c, err := r.Client(source, alias)
+	if err != nil {
+		return nil, typ.ErrorDiagnostics("Failed to get the provider client", err)
+	}
+	return c.ConfigureProvider(ctx, req)
+}
+
+// Stop calls Stop on all the started provider configurations.
+func (r *Registry) Stop(ctx context.Context) error {
+	var errs []error
+	for _, c := range r.clients() {
+		if err := c.Stop(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Close shuts down all the started provider configurations. The registry can be reused after closing,
+// where the providers will be started again on demand.
+func (r *Registry) Close() {
+	r.mu.Lock()
+	var instances []*instance
+	for _, p := range r.providers {
+		for _, inst := range p.instances {
+			instances = append(instances, inst)
+		}
+		p.instances = map[string]*instance{}
+	}
+	r.mu.Unlock()
+
+	// The instances being started are closed once they are started.
+	var wg sync.WaitGroup
+	for _, inst := range instances {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-inst.done
+			if inst.client != nil {
+				inst.client.Close()
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// clients returns all the started clients, the instances being started are skipped.
+func (r *Registry) clients() []Client {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var out []Client
+	for _, p := range r.providers {
+		for _, inst := range p.instances {
+			select {
+			case <-inst.done:
+				if inst.client != nil {
+					out = append(out, inst.client)
+				}
+			default:
+			}
+		}
+	}
+	return out
+}
+
+// cloneCmd returns an unstarted copy of the command, as a command can't be started more than once.
+func cloneCmd(cmd *exec.Cmd) *exec.Cmd {
+	ncmd := exec.Command(cmd.Path)
+	ncmd.Args = append([]string(nil), cmd.Args...)
+	ncmd.Env = append([]string(nil), cmd.Env...)
+	ncmd.Dir = cmd.Dir
+	ncmd.SysProcAttr = cmd.SysProcAttr
+	return ncmd
+}
@@ -0,0 +1,167 @@
+package tfclient
+
+import (
+	"io"
+	"os/exec"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/go-hclog"
+	"github.com/magodo/terraform-client-go/tfclient/typ"
+)
+
+func TestNormalizeProviderSource(t *testing.T) {
+	cases := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{in: "azurerm", want: "registry.terraform.io/hashicorp/azurerm"},
+		{in: "Azure/azapi", want: "registry.terraform.io/azure/azapi"},
+		{in: "example.com/foo/bar", want: "example.com/foo/bar"},
+		{in: "a/b/c/d", wantErr: true},
+		{in: "hashicorp/", wantErr: true},
+	}
+	for _, tt := range cases {
+		got, err := NormalizeProviderSource(tt.in)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("%q: expect error", tt.in)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: %v", tt.in, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%q: want %q, got %q", tt.in, tt.want, got)
+		}
+	}
+}
+
+func TestRegistryRouting(t *testing.T) {
+	r := NewRegistry(nil)
+	if err := r.Register("hashicorp/aws", Option{Cmd: exec.Command("terraform-provider-aws")}); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Register("registry.terraform.io/hashicorp/azurerm", Option{Cmd: exec.Command("terraform-provider-azurerm")}); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Register("aws", Option{Cmd: exec.Command("terraform-provider-aws")}); err == nil {
+		t.Fatal("expect error for duplicated registration")
+	}
+	if err := r.RegisterReattach(`{"hashicorp/kubernetes": {"Protocol": "grpc", "ProtocolVersion": 5, "Pid": 1, "Addr": {"Network": "unix", "String": "/tmp/plugin"}}}`, Option{}); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		"registry.terraform.io/hashicorp/aws",
+		"registry.terraform.io/hashicorp/azurerm",
+		"registry.terraform.io/hashicorp/kubernetes",
+	}
+	if diff := cmp.Diff(want, r.Providers()); diff != "" {
+		t.Fatalf("unexpected providers (-want +got):\n%s", diff)
+	}
+
+	for typeName, want := range map[string]string{
+		"aws_instance":           "registry.terraform.io/hashicorp/aws",
+		"azurerm_resource_group": "registry.terraform.io/hashicorp/azurerm",
+		"kubernetes_namespace":   "registry.terraform.io/hashicorp/kubernetes",
+	} {
+		got, err := r.ProviderForType(typeName)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != want {
+			t.Errorf("%s: want %s, got %s", typeName, want, got)
+		}
+	}
+
+	if _, err := r.ProviderForType("google_compute_instance"); err == nil {
+		t.Fatal("expect error for unregistered provider")
+	}
+	if _, err := r.Client("kubernetes", "alias"); err == nil {
+		t.Fatal("expect error for alias of a reattached provider")
+	}
+
+	if err := r.Register("example.com/foo/aws", Option{Cmd: exec.Command("terraform-provider-aws")}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.ProviderForType("aws_instance"); err == nil {
+		t.Fatal("expect error for ambiguous providers")
+	}
+}
+
+// registryFakeClient is the client started by the registry in the tests.
+type registryFakeClient struct {
+	Client
+	closed *atomic.Int32
+}
+
+func (registryFakeClient) GetProviderSchema() (*typ.GetProviderSchemaResponse, typ.Diagnostics) {
+	return &typ.GetProviderSchemaResponse{}, nil
+}
+
+func (c registryFakeClient) Close() {
+	c.closed.Add(1)
+}
+
+func TestRegistryConcurrentStart(t *testing.T) {
+	r := NewRegistry(hclog.New(&hclog.LoggerOptions{Output: io.Discard}))
+	if err := r.Register("hashicorp/aws", Option{Cmd: exec.Command("terraform-provider-aws")}); err != nil {
+		t.Fatal(err)
+	}
+
+	var started, closed atomic.Int32
+	blocked, release := make(chan struct{}), make(chan struct{})
+	r.newClient = func(opts Option) (Client, error) {
+		started.Add(1)
+		// The default configuration is only started after the aliased one is returned.
+		if len(opts.Logger.ImpliedArgs()) == 0 {
+			close(blocked)
+			<-release
+		}
+		return registryFakeClient{closed: &closed}, nil
+	}
+
+	var wg sync.WaitGroup
+	clients := make([]Client, 3)
+	for i := range 2 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c, err := r.Client("hashicorp/aws", "")
+			if err != nil {
+				t.Error(err)
+			}
+			clients[i] = c
+		}()
+	}
+
+	// Starting the aliased configuration is not blocked by the one being started.
+	<-blocked
+	c, err := r.Client("hashicorp/aws", "west")
+	if err != nil {
+		t.Fatal(err)
+	}
+	clients[2] = c
+	close(release)
+	wg.Wait()
+
+	for i, c := range clients {
+		if c == nil {
+			t.Errorf("client %d is nil", i)
+		}
+	}
+	// The default configuration is started once and shared.
+	if n := started.Load(); n != 2 {
+		t.Errorf("expect 2 started clients, got %d", n)
+	}
+	r.Close()
+	if n := closed.Load(); n != 2 {
+		t.Errorf("expect 2 closed clients, got %d", n)
+	}
+}
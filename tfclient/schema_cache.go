@@ -0,0 +1,223 @@
+package tfclient
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	tfjson "github.com/hashicorp/terraform-json"
+	"github.com/magodo/terraform-client-go/tfclient/providerschema"
+	"github.com/magodo/terraform-client-go/tfclient/typ"
+)
+
+// schemaCacheFormatVersion is the version of the schema cache entry format. Bump it whenever
+// the format changes, so that the stale entries are not read.
+const schemaCacheFormatVersion = 2
+
+// schemaCacheProviderAddr is the provider address used as the key in the cached schema document.
+// The actual address doesn't matter as each entry only contains one provider.
+const schemaCacheProviderAddr = "cached"
+
+// DefaultSchemaCacheDir returns the default directory of the schema cache, which resides in the
+// user cache directory.
+func DefaultSchemaCacheDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "terraform-client-go", "schemas"), nil
+}
+
+// schemaCache is an on-disk cache of provider schemas. Each entry is keyed by the hash of the
+// provider executable, or by the reattach address and protocol version. Therefore, a new build
+// of the provider naturally invalidates the existing entry.
+type schemaCache struct {
+	dir string
+	key string
+}
+
+type schemaCacheEntry struct {
+	FormatVersion      int                    `json:"format_version"`
+	Schema             json.RawMessage        `json:"schema"`
+	ProviderMeta       *tfjson.SchemaBlock    `json:"provider_meta,omitempty"`
+	ProviderMetaVer    uint64                 `json:"provider_meta_version,omitempty"`
+	ServerCapabilities typ.ServerCapabilities `json:"server_capabilities"`
+
+	// Functions records the fields of the functions that are not carried by the schema document.
+	Functions map[string]schemaCacheFunction `json:"functions,omitempty"`
+}
+
+type schemaCacheFunction struct {
+	DescriptionKind   tfjson.SchemaDescriptionKind `json:"description_kind,omitempty"`
+	Parameters        []schemaCacheFunctionParam   `json:"parameters,omitempty"`
+	VariadicParameter *schemaCacheFunctionParam    `json:"variadic_parameter,omitempty"`
+}
+
+type schemaCacheFunctionParam struct {
+	AllowUnknownValues bool                         `json:"allow_unknown_values,omitempty"`
+	DescriptionKind    tfjson.SchemaDescriptionKind `json:"description_kind,omitempty"`
+}
+
+func toSchemaCacheFunction(decl typ.FunctionDecl) schemaCacheFunction {
+	f := schemaCacheFunction{
+		DescriptionKind: decl.DescriptionKind,
+	}
+	for _, param := range decl.Parameters {
+		f.Parameters = append(f.Parameters, schemaCacheFunctionParam{
+			AllowUnknownValues: param.AllowUnknownValues,
+			DescriptionKind:    param.DescriptionKind,
+		})
+	}
+	if vp := decl.VariadicParameter; vp != nil {
+		f.VariadicParameter = &schemaCacheFunctionParam{
+			AllowUnknownValues: vp.AllowUnknownValues,
+			DescriptionKind:    vp.DescriptionKind,
+		}
+	}
+	return f
+}
+
+// apply sets the fields recorded in the cache entry to the function decoded from the schema document.
+func (f schemaCacheFunction) apply(decl *typ.FunctionDecl) {
+	decl.DescriptionKind = f.DescriptionKind
+	for i, param := range f.Parameters {
+		if i >= len(decl.Parameters) {
+			break
+		}
+		decl.Parameters[i].AllowUnknownValues = param.AllowUnknownValues
+		decl.Parameters[i].DescriptionKind = param.DescriptionKind
+	}
+	if vp := f.VariadicParameter; vp != nil && decl.VariadicParameter != nil {
+		decl.VariadicParameter.AllowUnknownValues = vp.AllowUnknownValues
+		decl.VariadicParameter.DescriptionKind = vp.DescriptionKind
+	}
+}
+
+func newSchemaCache(opts Option) (*schemaCache, error) {
+	dir := opts.SchemaCacheDir
+	if dir == "" {
+		var err error
+		dir, err = DefaultSchemaCacheDir()
+		if err != nil {
+			return nil, fmt.Errorf("finding the user cache directory: %v", err)
+		}
+	}
+
+	h := sha256.New()
+	switch {
+	case opts.Reattach != nil:
+		if opts.Reattach.Addr == nil {
+			return nil, errors.New("reattach config has no address")
+		}
+		fmt.Fprintf(h, "reattach\x00%s\x00%s\x00%d", opts.Reattach.Addr.Network(), opts.Reattach.Addr.String(), opts.Reattach.ProtocolVersion)
+	case opts.Cmd != nil:
+		f, err := os.Open(opts.Cmd.Path)
+		if err != nil {
+			return nil, fmt.Errorf("opening the provider executable: %v", err)
+		}
+		defer f.Close()
+		io.WriteString(h, "exec\x00")
+		if _, err := io.Copy(h, f); err != nil {
+			return nil, fmt.Errorf("hashing the provider executable: %v", err)
+		}
+	default:
+		return nil, errors.New("neither Cmd nor Reattach is specified")
+	}
+
+	return &schemaCache{
+		dir: dir,
+		key: hex.EncodeToString(h.Sum(nil)),
+	}, nil
+}
+
+func (c *schemaCache) path() string {
+	return filepath.Join(c.dir, c.key+".json")
+}
+
+// load returns the cached schema, or nil if there is no (valid) cache entry.
+func (c *schemaCache) load() (*typ.GetProviderSchemaResponse, error) {
+	b, err := os.ReadFile(c.path())
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var entry schemaCacheEntry
+	if err := json.Unmarshal(b, &entry); err != nil {
+		return nil, fmt.Errorf("decoding the schema cache entry %s: %v", c.path(), err)
+	}
+	if entry.FormatVersion != schemaCacheFormatVersion {
+		return nil, nil
+	}
+
+	schema, err := providerschema.Unmarshal(entry.Schema, schemaCacheProviderAddr)
+	if err != nil {
+		return nil, fmt.Errorf("decoding the cached schema %s: %v", c.path(), err)
+	}
+	if entry.ProviderMeta != nil {
+		schema.ProviderMeta = tfjson.Schema{
+			Version: entry.ProviderMetaVer,
+			Block:   entry.ProviderMeta,
+		}
+	}
+	schema.ServerCapabilities = entry.ServerCapabilities
+	for name, f := range entry.Functions {
+		if decl, ok := schema.Functions[name]; ok {
+			f.apply(&decl)
+			schema.Functions[name] = decl
+		}
+	}
+	providerschema.FillImpliedTypes(schema)
+	return schema, nil
+}
+
+// store writes the schema to the cache. It is safe for concurrent writers, including the ones
+// from other processes, as the entry is written to a temporary file and then renamed in place.
+func (c *schemaCache) store(schema *typ.GetProviderSchemaResponse) error {
+	doc, err := providerschema.Marshal(schemaCacheProviderAddr, schema)
+	if err != nil {
+		return err
+	}
+	entry := schemaCacheEntry{
+		FormatVersion:      schemaCacheFormatVersion,
+		Schema:             doc,
+		ProviderMeta:       schema.ProviderMeta.Block,
+		ProviderMetaVer:    schema.ProviderMeta.Version,
+		ServerCapabilities: schema.ServerCapabilities,
+	}
+	if len(schema.Functions) != 0 {
+		entry.Functions = map[string]schemaCacheFunction{}
+		for name, decl := range schema.Functions {
+			entry.Functions[name] = toSchemaCacheFunction(decl)
+		}
+	}
+	b, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(c.dir, 0755); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(c.dir, c.key+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.Write(b); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), c.path())
+}
@@ -0,0 +1,123 @@
+package tfclient
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	tfjson "github.com/hashicorp/terraform-json"
+	"github.com/magodo/terraform-client-go/tfclient/providerschema"
+	"github.com/magodo/terraform-client-go/tfclient/typ"
+	"github.com/zclconf/go-cty-debug/ctydebug"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestSchemaCache(t *testing.T) {
+	dir := t.TempDir()
+	bin := filepath.Join(dir, "terraform-provider-foo")
+	if err := os.WriteFile(bin, []byte("v1"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	opts := Option{
+		Cmd:            exec.Command(bin),
+		SchemaCacheDir: filepath.Join(dir, "cache"),
+	}
+
+	cache, err := newSchemaCache(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := cache.load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != nil {
+		t.Fatalf("expect cache miss, got %#v", got)
+	}
+
+	want := &typ.GetProviderSchemaResponse{
+		Provider: tfjson.Schema{
+			Block: &tfjson.SchemaBlock{
+				Attributes: map[string]*tfjson.SchemaAttribute{
+					"token": {AttributeType: cty.String, Optional: true, Sensitive: true},
+				},
+			},
+		},
+		ProviderMeta: tfjson.Schema{
+			Block: &tfjson.SchemaBlock{
+				Attributes: map[string]*tfjson.SchemaAttribute{
+					"module_name": {AttributeType: cty.String, Optional: true},
+				},
+			},
+		},
+		ResourceTypes: map[string]tfjson.Schema{
+			"foo_resource": {
+				Version: 1,
+				Block: &tfjson.SchemaBlock{
+					Attributes: map[string]*tfjson.SchemaAttribute{
+						"id": {AttributeType: cty.String, Computed: true},
+					},
+				},
+			},
+		},
+		DataSources:            map[string]tfjson.Schema{},
+		EphemeralResourceTypes: map[string]tfjson.Schema{},
+		ListResourceTypes:      map[string]tfjson.Schema{},
+		Actions:                map[string]tfjson.Schema{},
+		Functions: map[string]typ.FunctionDecl{
+			"parse_id": {
+				Parameters: []typ.FunctionParam{
+					{
+						Name:               "id",
+						Type:               cty.String,
+						AllowUnknownValues: true,
+						Description:        "The **resource** ID.",
+						DescriptionKind:    tfjson.SchemaDescriptionKindMarkdown,
+					},
+				},
+				VariadicParameter: &typ.FunctionParam{
+					Name:            "segments",
+					Type:            cty.String,
+					AllowNullValue:  true,
+					Description:     "The segments.",
+					DescriptionKind: tfjson.SchemaDescriptionKindPlain,
+				},
+				ReturnType:      cty.Map(cty.String),
+				Description:     "Parses the **resource** ID.",
+				DescriptionKind: tfjson.SchemaDescriptionKindMarkdown,
+				Summary:         "Parse ID",
+			},
+		},
+		ServerCapabilities: typ.ServerCapabilities{PlanDestroy: true},
+	}
+	providerschema.FillImpliedTypes(want)
+
+	if err := cache.store(want); err != nil {
+		t.Fatal(err)
+	}
+	got, err = cache.load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(want, got, ctydebug.CmpOptions); diff != "" {
+		t.Fatalf("unexpected diff (-want +got):\n%s", diff)
+	}
+
+	// Changing the provider executable invalidates the cache
+	if err := os.WriteFile(bin, []byte("v2"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	cache, err = newSchemaCache(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err = cache.load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != nil {
+		t.Fatalf("expect cache miss after the executable changed, got %#v", got)
+	}
+}
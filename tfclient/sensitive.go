@@ -0,0 +1,133 @@
+package tfclient
+
+import (
+	"context"
+
+	tfjson "github.com/hashicorp/terraform-json"
+	"github.com/magodo/terraform-client-go/tfclient/configschema"
+	"github.com/magodo/terraform-client-go/tfclient/typ"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// MarkSensitive wraps the client, so that the resource, data source and ephemeral resource values returned from the
+// provider are marked by marks.Sensitive, according to the Sensitive flags of the attributes, nested attributes and
+// blocks in the schema. The marked values can be sent back to the provider, as the marks are removed before encoding.
+func MarkSensitive(c Client) Client {
+	return &sensitiveClient{Client: c}
+}
+
+type sensitiveClient struct {
+	Client
+}
+
+// mark marks the value by the schema of the type, if found.
+func (c *sensitiveClient) mark(schemas map[string]tfjson.Schema, typeName string, v cty.Value) cty.Value {
+	sch, ok := schemas[typeName]
+	if !ok || sch.Block == nil || v == cty.NilVal {
+		return v
+	}
+	return v.MarkWithPaths(configschema.SchemaBlockValueMarks(sch.Block, v, nil))
+}
+
+func (c *sensitiveClient) resourceTypes() map[string]tfjson.Schema {
+	schema, diags := c.Client.GetProviderSchema()
+	if diags.HasErrors() {
+		return nil
+	}
+	return schema.ResourceTypes
+}
+
+func (c *sensitiveClient) UpgradeResourceState(ctx context.Context, req typ.UpgradeResourceStateRequest) (*typ.UpgradeResourceStateResponse, typ.Diagnostics) {
+	resp, diags := c.Client.UpgradeResourceState(ctx, req)
+	if resp != nil {
+		resp.UpgradedState = c.mark(c.resourceTypes(), req.TypeName, resp.UpgradedState)
+	}
+	return resp, diags
+}
+
+func (c *sensitiveClient) ReadResource(ctx context.Context, req typ.ReadResourceRequest) (*typ.ReadResourceResponse, typ.Diagnostics) {
+	resp, diags := c.Client.ReadResource(ctx, req)
+	if resp != nil {
+		resp.NewState = c.mark(c.resourceTypes(), req.TypeName, resp.NewState)
+	}
+	return resp, diags
+}
+
+func (c *sensitiveClient) PlanResourceChange(ctx context.Context, req typ.PlanResourceChangeRequest) (*typ.PlanResourceChangeResponse, typ.Diagnostics) {
+	resp, diags := c.Client.PlanResourceChange(ctx, req)
+	if resp != nil {
+		resp.PlannedState = c.mark(c.resourceTypes(), req.TypeName, resp.PlannedState)
+	}
+	return resp, diags
+}
+
+func (c *sensitiveClient) ApplyResourceChange(ctx context.Context, req typ.ApplyResourceChangeRequest) (*typ.ApplyResourceChangeResponse, typ.Diagnostics) {
+	resp, diags := c.Client.ApplyResourceChange(ctx, req)
+	if resp != nil {
+		resp.NewState = c.mark(c.resourceTypes(), req.TypeName, resp.NewState)
+	}
+	return resp, diags
+}
+
+func (c *sensitiveClient) ImportResourceState(ctx context.Context, req typ.ImportResourceStateRequest) (*typ.ImportResourceStateResponse, typ.Diagnostics) {
+	resp, diags := c.Client.ImportResourceState(ctx, req)
+	if resp != nil {
+		schemas := c.resourceTypes()
+		for i, res := range resp.ImportedResources {
+			resp.ImportedResources[i].State = c.mark(schemas, res.TypeName, res.State)
+		}
+	}
+	return resp, diags
+}
+
+func (c *sensitiveClient) MoveResourceState(ctx context.Context, req typ.MoveResourceStateRequest) (*typ.MoveResourceStateResponse, typ.Diagnostics) {
+	resp, diags := c.Client.MoveResourceState(ctx, req)
+	if resp != nil {
+		resp.TargetState = c.mark(c.resourceTypes(), req.TargetTypeName, resp.TargetState)
+	}
+	return resp, diags
+}
+
+func (c *sensitiveClient) ReadDataSource(ctx context.Context, req typ.ReadDataSourceRequest) (*typ.ReadDataSourceResponse, typ.Diagnostics) {
+	resp, diags := c.Client.ReadDataSource(ctx, req)
+	if resp != nil {
+		if schema, sdiags := c.Client.GetProviderSchema(); !sdiags.HasErrors() {
+			resp.State = c.mark(schema.DataSources, req.TypeName, resp.State)
+		}
+	}
+	return resp, diags
+}
+
+func (c *sensitiveClient) OpenEphemeralResource(ctx context.Context, req typ.OpenEphemeralResourceRequest) (*typ.OpenEphemeralResourceResponse, typ.Diagnostics) {
+	resp, diags := c.Client.OpenEphemeralResource(ctx, req)
+	if resp != nil {
+		if schema, sdiags := c.Client.GetProviderSchema(); !sdiags.HasErrors() {
+			resp.Result = c.mark(schema.EphemeralResourceTypes, req.TypeName, resp.Result)
+		}
+	}
+	return resp, diags
+}
+
+// ListResource marks the resource objects of the results, i.e. the "state" of each element of the "data".
+func (c *sensitiveClient) ListResource(ctx context.Context, req typ.ListResourceRequest) (typ.ListResourceResponse, typ.Diagnostics) {
+	resp, diags := c.Client.ListResource(ctx, req)
+	sch, ok := c.resourceTypes()[req.TypeName]
+	if !ok || sch.Block == nil || resp.Result == cty.NilVal || !resp.Result.Type().IsObjectType() || !resp.Result.Type().HasAttribute("data") {
+		return resp, diags
+	}
+	data := resp.Result.GetAttr("data")
+	if data.IsNull() || !data.IsKnown() {
+		return resp, diags
+	}
+	var pvm []cty.PathValueMarks
+	for it := data.ElementIterator(); it.Next(); {
+		idx, ev := it.Element()
+		if !ev.Type().IsObjectType() || !ev.Type().HasAttribute("state") {
+			continue
+		}
+		path := cty.GetAttrPath("data").Index(idx).GetAttr("state")
+		pvm = append(pvm, configschema.SchemaBlockValueMarks(sch.Block, ev.GetAttr("state"), path)...)
+	}
+	resp.Result = resp.Result.MarkWithPaths(pvm)
+	return resp, diags
+}
@@ -0,0 +1,77 @@
+package tfclient
+
+import (
+	"context"
+	"testing"
+
+	tfjson "github.com/hashicorp/terraform-json"
+	"github.com/magodo/terraform-client-go/tfclient/marks"
+	"github.com/magodo/terraform-client-go/tfclient/typ"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/msgpack"
+)
+
+type sensitiveFakeClient struct {
+	Client
+	state cty.Value
+}
+
+func (c sensitiveFakeClient) GetProviderSchema() (*typ.GetProviderSchemaResponse, typ.Diagnostics) {
+	return &typ.GetProviderSchemaResponse{
+		ResourceTypes: map[string]tfjson.Schema{
+			"foo_thing": {
+				Block: &tfjson.SchemaBlock{
+					Attributes: map[string]*tfjson.SchemaAttribute{
+						"id":       {AttributeType: cty.String, Computed: true},
+						"password": {AttributeType: cty.String, Optional: true, Sensitive: true},
+					},
+				},
+			},
+		},
+	}, nil
+}
+
+func (c sensitiveFakeClient) ReadResource(_ context.Context, req typ.ReadResourceRequest) (*typ.ReadResourceResponse, typ.Diagnostics) {
+	return &typ.ReadResourceResponse{NewState: c.state}, nil
+}
+
+func (c sensitiveFakeClient) ReadDataSource(_ context.Context, req typ.ReadDataSourceRequest) (*typ.ReadDataSourceResponse, typ.Diagnostics) {
+	return &typ.ReadDataSourceResponse{State: c.state}, nil
+}
+
+func TestMarkSensitive(t *testing.T) {
+	state := cty.ObjectVal(map[string]cty.Value{
+		"id":       cty.StringVal("1"),
+		"password": cty.StringVal("secret"),
+	})
+	c := MarkSensitive(sensitiveFakeClient{state: state})
+
+	resp, diags := c.ReadResource(context.Background(), typ.ReadResourceRequest{TypeName: "foo_thing"})
+	if diags.HasErrors() {
+		t.Fatal(diags.Err())
+	}
+	if !resp.NewState.GetAttr("password").HasMark(marks.Sensitive) {
+		t.Errorf("password should be marked as sensitive")
+	}
+	if resp.NewState.GetAttr("id").HasMark(marks.Sensitive) {
+		t.Errorf("id should not be marked as sensitive")
+	}
+
+	// The marked value can't be encoded as is, which is unmarked before sending back to the provider.
+	if _, err := msgpack.Marshal(resp.NewState, state.Type()); err == nil {
+		t.Errorf("expect error encoding the marked value")
+	}
+	unmarked, _ := resp.NewState.UnmarkDeep()
+	if !unmarked.RawEquals(state) {
+		t.Errorf("wrong unmarked state: %#v", unmarked)
+	}
+
+	// Unknown types are left as is.
+	dsResp, diags := c.ReadDataSource(context.Background(), typ.ReadDataSourceRequest{TypeName: "foo_thing"})
+	if diags.HasErrors() {
+		t.Fatal(diags.Err())
+	}
+	if marks.Contains(dsResp.State, marks.Sensitive) {
+		t.Errorf("data source state should not be marked")
+	}
+}
@@ -0,0 +1,77 @@
+package tfclient
+
+import (
+	"context"
+
+	tfjson "github.com/hashicorp/terraform-json"
+	"github.com/magodo/terraform-client-go/tfclient/typ"
+	"github.com/magodo/terraform-client-go/tfclient/validate"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// ValidateStatically wraps the client, so that the ValidateResourceConfig, ValidateDataResourceConfig and
+// ConfigureProvider validate the config against the schema (see validate.Config) before calling the provider.
+// If the static validation reports any error, the provider is not called, and an empty response is returned
+// together with the diagnostics. Otherwise, the warnings of the static validation are prepended to the diagnostics
+// returned from the provider.
+func ValidateStatically(c Client) Client {
+	return &staticValidationClient{Client: c}
+}
+
+type staticValidationClient struct {
+	Client
+}
+
+// validate validates the config by the schema of the type, if found.
+func (c *staticValidationClient) validate(schema func(*typ.GetProviderSchemaResponse) *tfjson.Schema, config cty.Value) typ.Diagnostics {
+	resp, diags := c.Client.GetProviderSchema()
+	if diags.HasErrors() || resp == nil {
+		return nil
+	}
+	sch := schema(resp)
+	if sch == nil {
+		return nil
+	}
+	return validate.Config(sch.Block, config)
+}
+
+func (c *staticValidationClient) ValidateResourceConfig(ctx context.Context, req typ.ValidateResourceConfigRequest) (*typ.ValidateResourceConfigResponse, typ.Diagnostics) {
+	diags := c.validate(func(resp *typ.GetProviderSchemaResponse) *tfjson.Schema {
+		return lookupSchema(resp.ResourceTypes, req.TypeName)
+	}, req.Config)
+	if diags.HasErrors() {
+		return &typ.ValidateResourceConfigResponse{}, diags
+	}
+	resp, pdiags := c.Client.ValidateResourceConfig(ctx, req)
+	return resp, append(diags, pdiags...)
+}
+
+func (c *staticValidationClient) ValidateDataResourceConfig(ctx context.Context, req typ.ValidateDataResourceConfigRequest) (*typ.ValidateDataResourceConfigResponse, typ.Diagnostics) {
+	diags := c.validate(func(resp *typ.GetProviderSchemaResponse) *tfjson.Schema {
+		return lookupSchema(resp.DataSources, req.TypeName)
+	}, req.Config)
+	if diags.HasErrors() {
+		return &typ.ValidateDataResourceConfigResponse{}, diags
+	}
+	resp, pdiags := c.Client.ValidateDataResourceConfig(ctx, req)
+	return resp, append(diags, pdiags...)
+}
+
+func (c *staticValidationClient) ConfigureProvider(ctx context.Context, req typ.ConfigureProviderRequest) (*typ.ConfigureProviderResponse, typ.Diagnostics) {
+	diags := c.validate(func(resp *typ.GetProviderSchemaResponse) *tfjson.Schema {
+		return &resp.Provider
+	}, req.Config)
+	if diags.HasErrors() {
+		return &typ.ConfigureProviderResponse{}, diags
+	}
+	resp, pdiags := c.Client.ConfigureProvider(ctx, req)
+	return resp, append(diags, pdiags...)
+}
+
+func lookupSchema(schemas map[string]tfjson.Schema, typeName string) *tfjson.Schema {
+	sch, ok := schemas[typeName]
+	if !ok {
+		return nil
+	}
+	return &sch
+}
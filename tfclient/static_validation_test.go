@@ -0,0 +1,84 @@
+package tfclient
+
+import (
+	"context"
+	"testing"
+
+	tfjson "github.com/hashicorp/terraform-json"
+	"github.com/magodo/terraform-client-go/tfclient/typ"
+	"github.com/zclconf/go-cty/cty"
+)
+
+type staticValidationFakeClient struct {
+	Client
+	called *int
+}
+
+func (c staticValidationFakeClient) GetProviderSchema() (*typ.GetProviderSchemaResponse, typ.Diagnostics) {
+	return &typ.GetProviderSchemaResponse{
+		ResourceTypes: map[string]tfjson.Schema{
+			"foo_thing": {
+				Block: &tfjson.SchemaBlock{
+					Attributes: map[string]*tfjson.SchemaAttribute{
+						"name":   {AttributeType: cty.String, Required: true},
+						"legacy": {AttributeType: cty.String, Optional: true, Deprecated: true},
+					},
+				},
+			},
+		},
+	}, nil
+}
+
+func (c staticValidationFakeClient) ValidateResourceConfig(_ context.Context, req typ.ValidateResourceConfigRequest) (*typ.ValidateResourceConfigResponse, typ.Diagnostics) {
+	*c.called++
+	return &typ.ValidateResourceConfigResponse{}, nil
+}
+
+func TestValidateStatically(t *testing.T) {
+	var called int
+	c := ValidateStatically(staticValidationFakeClient{called: &called})
+
+	// The provider is not called on error.
+	_, diags := c.ValidateResourceConfig(context.Background(), typ.ValidateResourceConfigRequest{
+		TypeName: "foo_thing",
+		Config: cty.ObjectVal(map[string]cty.Value{
+			"name":   cty.NullVal(cty.String),
+			"legacy": cty.NullVal(cty.String),
+		}),
+	})
+	if !diags.HasErrors() {
+		t.Fatal("expect error")
+	}
+	if called != 0 {
+		t.Errorf("the provider shouldn't be called")
+	}
+
+	// The warnings are returned together with the provider's diagnostics.
+	_, diags = c.ValidateResourceConfig(context.Background(), typ.ValidateResourceConfigRequest{
+		TypeName: "foo_thing",
+		Config: cty.ObjectVal(map[string]cty.Value{
+			"name":   cty.StringVal("a"),
+			"legacy": cty.StringVal("b"),
+		}),
+	})
+	if diags.HasErrors() {
+		t.Fatal(diags.Err())
+	}
+	if len(diags) != 1 || diags[0].Severity != typ.Warning {
+		t.Errorf("expect one warning, got %#v", diags)
+	}
+	if called != 1 {
+		t.Errorf("the provider should be called once, got %d", called)
+	}
+
+	// Unknown types are left to the provider.
+	if _, diags := c.ValidateResourceConfig(context.Background(), typ.ValidateResourceConfigRequest{
+		TypeName: "foo_other",
+		Config:   cty.EmptyObjectVal,
+	}); diags.HasErrors() {
+		t.Fatal(diags.Err())
+	}
+	if called != 2 {
+		t.Errorf("the provider should be called twice, got %d", called)
+	}
+}
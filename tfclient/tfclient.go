@@ -7,10 +7,12 @@ import (
 	"io"
 	"net"
 	"os/exec"
+	"path/filepath"
 	"time"
 
 	"github.com/hashicorp/go-hclog"
 	"github.com/hashicorp/go-plugin"
+	"github.com/magodo/terraform-client-go/tfclient/providerlog"
 	"github.com/magodo/terraform-client-go/tfclient/tfprotov5/tf5client"
 	"github.com/magodo/terraform-client-go/tfclient/tfprotov6/tf6client"
 	"github.com/magodo/terraform-client-go/tfclient/typ"
@@ -93,23 +95,112 @@ type Option struct {
 	// a GetProviderSchema call during the client initialization.
 	// Tis is only used for performance sensitive scenario where multiple clients are created,
 	// but target to the same provider.
+	// A schema dumped by the terraform-client-schema command can be loaded via providerschema.Unmarshal.
 	ProviderSchema *typ.GetProviderSchemaResponse
+
+	// CacheSchema enables the on-disk provider schema cache. The cache entry is keyed by the hash
+	// of the provider executable (for Cmd), or by the address and protocol version (for Reattach).
+	// On a cache hit, the GetProviderSchema and GetResourceIdentitySchemas calls are skipped during
+	// the client initialization. This is ignored if ProviderSchema is set.
+	CacheSchema bool
+
+	// SchemaCacheDir is the directory of the schema cache. Defaults to DefaultSchemaCacheDir().
+	SchemaCacheDir string
+
+	// WrapV5Client and WrapV6Client wrap the gRPC client of the provider of the negotiated protocol version,
+	// e.g. to intercept the calls (see the faultinject package). The kill function ends the provider process.
+	WrapV5Client func(client tf5client.TFProtoV5Client, kill func()) tf5client.TFProtoV5Client
+	WrapV6Client func(client tf6client.TFProtoV6Client, kill func()) tf6client.TFProtoV6Client
+
+	// MarkSensitive marks the values returned from the provider as sensitive by the schema. See MarkSensitive.
+	// This is only used by New.
+	MarkSensitive bool
+
+	// StaticValidation validates the configs against the schema before sending them to the provider. See ValidateStatically.
+	// This is only used by New.
+	StaticValidation bool
+
+	// SkipLegacyNormalization skips normalizing the planned and new states returned from the providers built
+	// with the legacy SDK, which is done by default. See NormalizeLegacySDK.
+	// This is only used by New.
+	SkipLegacyNormalization bool
+
+	// CaptureProviderLogs parses the structured logs written by the provider to stderr (see the providerlog
+	// package), which are logged by the Logger at their levels instead of by go-plugin, and attached to the
+	// responses of the calls (see CaptureLogs) on a best-effort basis (see providerlog.Capture). The Stderr still
+	// receives the raw lines.
+	// This only works with Cmd, as a reattached provider's stderr is not available.
+	CaptureProviderLogs bool
 }
 
 // New creates a normalized client. It spins up an un-configured provider server, whose lifecycle is managed by the client, so make sure to call the "Kill" method on exit.
 func New(opts Option) (Client, error) {
+	logger := opts.Logger
+	if logger == nil {
+		logger = hclog.Default()
+	}
+
+	var cache *schemaCache
+	if opts.CacheSchema && opts.ProviderSchema == nil {
+		var err error
+		cache, err = newSchemaCache(opts)
+		if err != nil {
+			logger.Warn("schema cache disabled", "error", err)
+		} else {
+			schema, err := cache.load()
+			if err != nil {
+				logger.Warn("loading schema cache", "error", err)
+			}
+			opts.ProviderSchema = schema
+		}
+	}
+
 	c, v, err := newRaw(opts)
 	if err != nil {
 		return nil, err
 	}
+
+	var client Client
 	switch v {
 	case 5:
-		return tf5client.New(c.pluginClient, c.v5client, opts.ProviderSchema)
+		client, err = tf5client.New(c.pluginClient, c.v5client, opts.ProviderSchema)
 	case 6:
-		return tf6client.New(c.pluginClient, c.v6client, opts.ProviderSchema)
+		client, err = tf6client.New(c.pluginClient, c.v6client, opts.ProviderSchema)
 	default:
-		return nil, fmt.Errorf("unsupported protocol version %d", v)
+		err = fmt.Errorf("unsupported protocol version %d", v)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if cache != nil && opts.ProviderSchema == nil {
+		schema, diags := client.GetProviderSchema()
+		if !diags.HasErrors() {
+			if err := cache.store(schema); err != nil {
+				logger.Warn("storing schema cache", "error", err)
+			}
+		}
 	}
+
+	if c.logCapture != nil {
+		client = CaptureLogs(client, c.logCapture)
+	}
+
+	return wrapClient(client, opts), nil
+}
+
+// wrapClient wraps the client as specified by the option.
+func wrapClient(client Client, opts Option) Client {
+	if !opts.SkipLegacyNormalization {
+		client = NormalizeLegacySDK(client)
+	}
+	if opts.MarkSensitive {
+		client = MarkSensitive(client)
+	}
+	if opts.StaticValidation {
+		client = ValidateStatically(client)
+	}
+	return client
 }
 
 // NewRaw creates a raw client. It spins up an un-configured provider server, whose lifecycle is managed by the client, so make sure to call the "Kill" method on exit.
@@ -169,6 +260,26 @@ func newRaw(opts Option) (*RawClient, int, error) {
 
 	var client RawClient
 
+	if opts.CaptureProviderLogs && opts.Cmd != nil {
+		logger := opts.Logger
+		if logger == nil {
+			// The same as the default logger of go-plugin.
+			logger = hclog.New(&hclog.LoggerOptions{
+				Output: hclog.DefaultOutput,
+				Level:  hclog.Trace,
+				Name:   "plugin",
+			})
+		}
+		// go-plugin logs the stderr by the logger named after the plugin executable.
+		name := filepath.Base(opts.Cmd.Path)
+		client.logCapture = providerlog.NewCapture(logger.Named(name))
+		config.Logger = stderrMutedLogger{Logger: logger, name: name}
+		config.Stderr = client.logCapture
+		if opts.Stderr != nil {
+			config.Stderr = io.MultiWriter(opts.Stderr, client.logCapture)
+		}
+	}
+
 	pclient := plugin.NewClient(config)
 
 	client.pluginClient = pclient
@@ -187,10 +298,16 @@ func newRaw(opts Option) (*RawClient, int, error) {
 	switch protoVer {
 	case 5:
 		p := raw.(tf5client.TFProtoV5Client)
+		if opts.WrapV5Client != nil {
+			p = opts.WrapV5Client(p, pclient.Kill)
+		}
 		client.v5client = p
 		return &client, 5, nil
 	case 6:
 		p := raw.(tf6client.TFProtoV6Client)
+		if opts.WrapV6Client != nil {
+			p = opts.WrapV6Client(p, pclient.Kill)
+		}
 		client.v6client = p
 		return &client, 6, nil
 	default:
@@ -198,7 +315,28 @@ func newRaw(opts Option) (*RawClient, int, error) {
 	}
 }
 
+// ParseReattach parses the TF_REATTACH_PROVIDERS, which is expected to contain exactly one provider.
+// Use ParseReattachProviders for multiple providers.
 func ParseReattach(in string) (*plugin.ReattachConfig, error) {
+	m, err := ParseReattachProviders(in)
+	if err != nil {
+		return nil, err
+	}
+	if m == nil {
+		return nil, nil
+	}
+	if len(m) != 1 {
+		return nil, fmt.Errorf("expect only one of provider specified in the TF_REATTACH_PROVIDERS, got=%d", len(m))
+	}
+	for _, c := range m {
+		return c, nil
+	}
+	panic("unreachable")
+}
+
+// ParseReattachProviders parses the TF_REATTACH_PROVIDERS, which can contain multiple providers.
+// The returned map is keyed by the provider source address as is specified in the input.
+func ParseReattachProviders(in string) (map[string]*plugin.ReattachConfig, error) {
 	if in == "" {
 		return nil, nil
 	}
@@ -218,37 +356,31 @@ func ParseReattach(in string) (*plugin.ReattachConfig, error) {
 	if err != nil {
 		return nil, fmt.Errorf("Invalid format for TF_REATTACH_PROVIDERS: %w", err)
 	}
-	if len(m) != 1 {
-		return nil, fmt.Errorf("expect only one of provider specified in the TF_REATTACH_PROVIDERS, got=%d", len(m))
-	}
 
-	var c reattachConfig
-	var p string
-	for k, v := range m {
-		c = v
-		p = k
-	}
-
-	var addr net.Addr
-	switch c.Addr.Network {
-	case "unix":
-		addr, err = net.ResolveUnixAddr("unix", c.Addr.String)
-		if err != nil {
-			return nil, fmt.Errorf("Invalid unix socket path %q: %w", c.Addr.String, err)
+	out := map[string]*plugin.ReattachConfig{}
+	for p, c := range m {
+		var addr net.Addr
+		switch c.Addr.Network {
+		case "unix":
+			addr, err = net.ResolveUnixAddr("unix", c.Addr.String)
+			if err != nil {
+				return nil, fmt.Errorf("Invalid unix socket path %q: %w", c.Addr.String, err)
+			}
+		case "tcp":
+			addr, err = net.ResolveTCPAddr("tcp", c.Addr.String)
+			if err != nil {
+				return nil, fmt.Errorf("Invalid TCP address %q: %w", c.Addr.String, err)
+			}
+		default:
+			return nil, fmt.Errorf("Unknown address type %q for %q", c.Addr.Network, p)
 		}
-	case "tcp":
-		addr, err = net.ResolveTCPAddr("tcp", c.Addr.String)
-		if err != nil {
-			return nil, fmt.Errorf("Invalid TCP address %q: %w", c.Addr.String, err)
+		out[p] = &plugin.ReattachConfig{
+			Protocol:        plugin.Protocol(c.Protocol),
+			ProtocolVersion: c.ProtocolVersion,
+			Pid:             c.Pid,
+			Test:            c.Test,
+			Addr:            addr,
 		}
-	default:
-		return nil, fmt.Errorf("Unknown address type %q for %q", c.Addr.Network, p)
-	}
-	return &plugin.ReattachConfig{
-		Protocol:        plugin.Protocol(c.Protocol),
-		ProtocolVersion: c.ProtocolVersion,
-		Pid:             c.Pid,
-		Test:            c.Test,
-		Addr:            addr,
-	}, nil
+	}
+	return out, nil
 }
@@ -70,3 +70,42 @@ func InvokeAction_InvokeActionEvent(in *tfplugin5.InvokeAction_Event) (*tfprotov
 	// as a new case above.
 	panic(fmt.Sprintf("unimplemented tfprotov5.InvokeActionEventType type: %T", in.Type))
 }
+
+func ValidateActionConfigRequest(in *tfplugin5.ValidateActionConfig_Request) *tfprotov5.ValidateActionConfigRequest {
+	if in == nil {
+		return nil
+	}
+
+	return &tfprotov5.ValidateActionConfigRequest{
+		ActionType: in.ActionType,
+		Config:     DynamicValue(in.Config),
+	}
+}
+
+func PlanActionRequest(in *tfplugin5.PlanAction_Request) *tfprotov5.PlanActionRequest {
+	if in == nil {
+		return nil
+	}
+
+	resp := &tfprotov5.PlanActionRequest{
+		ActionType:         in.ActionType,
+		Config:             DynamicValue(in.Config),
+		ClientCapabilities: PlanActionClientCapabilities(in.ClientCapabilities),
+	}
+
+	return resp
+}
+
+func InvokeActionRequest(in *tfplugin5.InvokeAction_Request) *tfprotov5.InvokeActionRequest {
+	if in == nil {
+		return nil
+	}
+
+	resp := &tfprotov5.InvokeActionRequest{
+		ActionType:         in.ActionType,
+		Config:             DynamicValue(in.Config),
+		ClientCapabilities: InvokeActionClientCapabilities(in.ClientCapabilities),
+	}
+
+	return resp
+}
@@ -38,3 +38,31 @@ func ReadDataSourceResponse(in *tfplugin5.ReadDataSource_Response) (*tfprotov5.R
 
 	return resp, nil
 }
+
+func ValidateDataSourceConfigRequest(in *tfplugin5.ValidateDataSourceConfig_Request) *tfprotov5.ValidateDataSourceConfigRequest {
+	if in == nil {
+		return nil
+	}
+
+	resp := &tfprotov5.ValidateDataSourceConfigRequest{
+		Config:   DynamicValue(in.Config),
+		TypeName: in.TypeName,
+	}
+
+	return resp
+}
+
+func ReadDataSourceRequest(in *tfplugin5.ReadDataSource_Request) *tfprotov5.ReadDataSourceRequest {
+	if in == nil {
+		return nil
+	}
+
+	resp := &tfprotov5.ReadDataSourceRequest{
+		Config:             DynamicValue(in.Config),
+		ProviderMeta:       DynamicValue(in.ProviderMeta),
+		TypeName:           in.TypeName,
+		ClientCapabilities: ReadDataSourceClientCapabilities(in.ClientCapabilities),
+	}
+
+	return resp
+}
@@ -146,3 +146,30 @@ func FunctionReturn(in *tfplugin5.Function_Return) (*tfprotov5.FunctionReturn, e
 		Type: typ,
 	}, nil
 }
+
+func CallFunctionRequest(in *tfplugin5.CallFunction_Request) *tfprotov5.CallFunctionRequest {
+	if in == nil {
+		return nil
+	}
+
+	resp := &tfprotov5.CallFunctionRequest{
+		Arguments: make([]*tfprotov5.DynamicValue, 0, len(in.Arguments)),
+		Name:      in.Name,
+	}
+
+	for _, argument := range in.Arguments {
+		resp.Arguments = append(resp.Arguments, DynamicValue(argument))
+	}
+
+	return resp
+}
+
+func GetFunctionsRequest(in *tfplugin5.GetFunctions_Request) *tfprotov5.GetFunctionsRequest {
+	if in == nil {
+		return nil
+	}
+
+	resp := &tfprotov5.GetFunctionsRequest{}
+
+	return resp
+}
@@ -34,3 +34,29 @@ func ValidateListResourceConfig_Response(in *tfplugin5.ValidateListResourceConfi
 		Diagnostics: diags,
 	}, nil
 }
+
+func ListResourceRequest(in *tfplugin5.ListResource_Request) *tfprotov5.ListResourceRequest {
+	if in == nil {
+		return nil
+	}
+
+	return &tfprotov5.ListResourceRequest{
+		TypeName:        in.TypeName,
+		Config:          DynamicValue(in.Config),
+		IncludeResource: in.IncludeResourceObject,
+		Limit:           in.Limit,
+	}
+}
+
+func ValidateListResourceConfigRequest(in *tfplugin5.ValidateListResourceConfig_Request) *tfprotov5.ValidateListResourceConfigRequest {
+	if in == nil {
+		return nil
+	}
+
+	return &tfprotov5.ValidateListResourceConfigRequest{
+		TypeName:              in.TypeName,
+		Config:                DynamicValue(in.Config),
+		IncludeResourceObject: DynamicValue(in.IncludeResourceObject),
+		Limit:                 DynamicValue(in.Limit),
+	}
+}
@@ -9,6 +9,9 @@ import (
 )
 
 func Schema(in *tfplugin5.Schema) (*tfprotov5.Schema, error) {
+	if in == nil {
+		return nil, nil
+	}
 	var resp tfprotov5.Schema
 	resp.Version = in.Version
 	if in.Block != nil {
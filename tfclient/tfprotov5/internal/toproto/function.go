@@ -95,3 +95,33 @@ func GetMetadata_FunctionMetadata(in *tfprotov5.FunctionMetadata) *tfplugin5.Get
 
 	return resp
 }
+
+func CallFunction_Response(in *tfprotov5.CallFunctionResponse) *tfplugin5.CallFunction_Response {
+	if in == nil {
+		return nil
+	}
+
+	resp := &tfplugin5.CallFunction_Response{
+		Error:  FunctionError(in.Error),
+		Result: DynamicValue(in.Result),
+	}
+
+	return resp
+}
+
+func GetFunctions_Response(in *tfprotov5.GetFunctionsResponse) *tfplugin5.GetFunctions_Response {
+	if in == nil {
+		return nil
+	}
+
+	resp := &tfplugin5.GetFunctions_Response{
+		Diagnostics: Diagnostics(in.Diagnostics),
+		Functions:   make(map[string]*tfplugin5.Function, len(in.Functions)),
+	}
+
+	for name, function := range in.Functions {
+		resp.Functions[name] = Function(function)
+	}
+
+	return resp
+}
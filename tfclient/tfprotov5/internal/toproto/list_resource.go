@@ -22,3 +22,32 @@ func ValidateListResourceConfigRequest(in *tfprotov5.ValidateListResourceConfigR
 		Limit:                 DynamicValue(in.Limit),
 	}
 }
+
+func GetMetadata_ListResourceMetadata(in *tfprotov5.ListResourceMetadata) *tfplugin5.GetMetadata_ListResourceMetadata {
+	if in == nil {
+		return nil
+	}
+
+	return &tfplugin5.GetMetadata_ListResourceMetadata{
+		TypeName: in.TypeName,
+	}
+}
+
+func ListResource_ListResourceEvent(in *tfprotov5.ListResourceResult) *tfplugin5.ListResource_Event {
+	return &tfplugin5.ListResource_Event{
+		DisplayName:    in.DisplayName,
+		ResourceObject: DynamicValue(in.Resource),
+		Identity:       ResourceIdentityData(in.Identity),
+		Diagnostic:     Diagnostics(in.Diagnostics),
+	}
+}
+
+func ValidateListResourceConfig_Response(in *tfprotov5.ValidateListResourceConfigResponse) *tfplugin5.ValidateListResourceConfig_Response {
+	if in == nil {
+		return nil
+	}
+
+	return &tfplugin5.ValidateListResourceConfig_Response{
+		Diagnostics: Diagnostics(in.Diagnostics),
+	}
+}
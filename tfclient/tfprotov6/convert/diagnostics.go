@@ -52,3 +52,51 @@ func DecodeAttributePath(raws *tftypes.AttributePath) cty.Path {
 	}
 	return ret
 }
+
+// EncodeDiagnostics is the reverse of DecodeDiagnostics.
+func EncodeDiagnostics(diags typ.Diagnostics) []*tfprotov6.Diagnostic {
+	if len(diags) == 0 {
+		return nil
+	}
+	raws := make([]*tfprotov6.Diagnostic, 0, len(diags))
+	for _, diag := range diags {
+		raw := &tfprotov6.Diagnostic{
+			Summary:   diag.Summary,
+			Detail:    diag.Detail,
+			Attribute: EncodeAttributePath(diag.Attribute),
+		}
+
+		switch diag.Severity {
+		case typ.Error:
+			raw.Severity = tfprotov6.DiagnosticSeverityError
+		case typ.Warning:
+			raw.Severity = tfprotov6.DiagnosticSeverityWarning
+		}
+
+		raws = append(raws, raw)
+	}
+	return raws
+}
+
+// EncodeAttributePath is the reverse of DecodeAttributePath. Steps that can't be represented are dropped.
+func EncodeAttributePath(path cty.Path) *tftypes.AttributePath {
+	if len(path) == 0 {
+		return nil
+	}
+	ret := tftypes.NewAttributePath()
+	for _, step := range path {
+		switch s := step.(type) {
+		case cty.GetAttrStep:
+			ret = ret.WithAttributeName(s.Name)
+		case cty.IndexStep:
+			switch s.Key.Type() {
+			case cty.String:
+				ret = ret.WithElementKeyString(s.Key.AsString())
+			case cty.Number:
+				i, _ := s.Key.AsBigFloat().Int64()
+				ret = ret.WithElementKeyInt(int(i))
+			}
+		}
+	}
+	return ret
+}
@@ -68,3 +68,42 @@ func InvokeAction_InvokeActionEvent(in *tfplugin6.InvokeAction_Event) (*tfprotov
 	// as a new case above.
 	panic(fmt.Sprintf("unimplemented tfprotov6.InvokeActionEventType type: %T", in.Type))
 }
+
+func ValidateActionConfigRequest(in *tfplugin6.ValidateActionConfig_Request) *tfprotov6.ValidateActionConfigRequest {
+	if in == nil {
+		return nil
+	}
+
+	return &tfprotov6.ValidateActionConfigRequest{
+		ActionType: in.ActionType,
+		Config:     DynamicValue(in.Config),
+	}
+}
+
+func PlanActionRequest(in *tfplugin6.PlanAction_Request) *tfprotov6.PlanActionRequest {
+	if in == nil {
+		return nil
+	}
+
+	resp := &tfprotov6.PlanActionRequest{
+		ActionType:         in.ActionType,
+		Config:             DynamicValue(in.Config),
+		ClientCapabilities: PlanActionClientCapabilities(in.ClientCapabilities),
+	}
+
+	return resp
+}
+
+func InvokeActionRequest(in *tfplugin6.InvokeAction_Request) *tfprotov6.InvokeActionRequest {
+	if in == nil {
+		return nil
+	}
+
+	resp := &tfprotov6.InvokeActionRequest{
+		ActionType:         in.ActionType,
+		Config:             DynamicValue(in.Config),
+		ClientCapabilities: InvokeActionClientCapabilities(in.ClientCapabilities),
+	}
+
+	return resp
+}
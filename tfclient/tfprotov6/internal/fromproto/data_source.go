@@ -50,3 +50,31 @@ func ReadDataSourceResponse(in *tfplugin6.ReadDataSource_Response) (*tfprotov6.R
 
 	return resp, nil
 }
+
+func ValidateDataResourceConfigRequest(in *tfplugin6.ValidateDataResourceConfig_Request) *tfprotov6.ValidateDataResourceConfigRequest {
+	if in == nil {
+		return nil
+	}
+
+	resp := &tfprotov6.ValidateDataResourceConfigRequest{
+		Config:   DynamicValue(in.Config),
+		TypeName: in.TypeName,
+	}
+
+	return resp
+}
+
+func ReadDataSourceRequest(in *tfplugin6.ReadDataSource_Request) *tfprotov6.ReadDataSourceRequest {
+	if in == nil {
+		return nil
+	}
+
+	resp := &tfprotov6.ReadDataSourceRequest{
+		Config:             DynamicValue(in.Config),
+		ProviderMeta:       DynamicValue(in.ProviderMeta),
+		TypeName:           in.TypeName,
+		ClientCapabilities: ReadDataSourceClientCapabilities(in.ClientCapabilities),
+	}
+
+	return resp
+}
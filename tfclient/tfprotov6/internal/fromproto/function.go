@@ -146,3 +146,30 @@ func FunctionReturn(in *tfplugin6.Function_Return) (*tfprotov6.FunctionReturn, e
 		Type: typ,
 	}, nil
 }
+
+func CallFunctionRequest(in *tfplugin6.CallFunction_Request) *tfprotov6.CallFunctionRequest {
+	if in == nil {
+		return nil
+	}
+
+	resp := &tfprotov6.CallFunctionRequest{
+		Arguments: make([]*tfprotov6.DynamicValue, 0, len(in.Arguments)),
+		Name:      in.Name,
+	}
+
+	for _, argument := range in.Arguments {
+		resp.Arguments = append(resp.Arguments, DynamicValue(argument))
+	}
+
+	return resp
+}
+
+func GetFunctionsRequest(in *tfplugin6.GetFunctions_Request) *tfprotov6.GetFunctionsRequest {
+	if in == nil {
+		return nil
+	}
+
+	resp := &tfprotov6.GetFunctionsRequest{}
+
+	return resp
+}
@@ -37,3 +37,29 @@ func ValidateListResourceConfig_Response(in *tfplugin6.ValidateListResourceConfi
 		Diagnostics: diags,
 	}, nil
 }
+
+func ListResourceRequest(in *tfplugin6.ListResource_Request) *tfprotov6.ListResourceRequest {
+	if in == nil {
+		return nil
+	}
+
+	return &tfprotov6.ListResourceRequest{
+		TypeName:        in.TypeName,
+		Config:          DynamicValue(in.Config),
+		IncludeResource: in.IncludeResourceObject,
+		Limit:           in.Limit,
+	}
+}
+
+func ValidateListResourceConfigRequest(in *tfplugin6.ValidateListResourceConfig_Request) *tfprotov6.ValidateListResourceConfigRequest {
+	if in == nil {
+		return nil
+	}
+
+	return &tfprotov6.ValidateListResourceConfigRequest{
+		TypeName:              in.TypeName,
+		Config:                DynamicValue(in.Config),
+		IncludeResourceObject: DynamicValue(in.IncludeResourceObject),
+		Limit:                 DynamicValue(in.Limit),
+	}
+}
@@ -93,3 +93,33 @@ func GetMetadata_FunctionMetadata(in *tfprotov6.FunctionMetadata) *tfplugin6.Get
 		Name: in.Name,
 	}
 }
+
+func CallFunction_Response(in *tfprotov6.CallFunctionResponse) *tfplugin6.CallFunction_Response {
+	if in == nil {
+		return nil
+	}
+
+	resp := &tfplugin6.CallFunction_Response{
+		Error:  FunctionError(in.Error),
+		Result: DynamicValue(in.Result),
+	}
+
+	return resp
+}
+
+func GetFunctions_Response(in *tfprotov6.GetFunctionsResponse) *tfplugin6.GetFunctions_Response {
+	if in == nil {
+		return nil
+	}
+
+	resp := &tfplugin6.GetFunctions_Response{
+		Diagnostics: Diagnostics(in.Diagnostics),
+		Functions:   make(map[string]*tfplugin6.Function, len(in.Functions)),
+	}
+
+	for name, function := range in.Functions {
+		resp.Functions[name] = Function(function)
+	}
+
+	return resp
+}
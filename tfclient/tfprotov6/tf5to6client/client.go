@@ -0,0 +1,243 @@
+// Package tf5to6client upgrades a protocol v5 provider client to the protocol v6 client interface, like the
+// tf5to6server of terraform-plugin-mux, but on the client side. This allows the raw client users to target the
+// protocol v6 alone.
+//
+// The requests are converted from v6 to v5, and the responses from v5 to v6. As the v5 protocol is a subset of the
+// v6 protocol, the responses are always convertible. The only v6-only feature, the nested attributes, only appears
+// in the schema of the GetProviderSchema response, which can't be returned by a v5 provider.
+package tf5to6client
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/magodo/terraform-client-go/tfclient/tfprotov5/tf5client"
+	"github.com/magodo/terraform-client-go/tfclient/tfprotov6/tf6client"
+)
+
+// New returns a protocol v6 client that calls the protocol v5 client.
+func New(c tf5client.TFProtoV5Client) tf6client.TFProtoV6Client {
+	return &client{v5: c}
+}
+
+type client struct {
+	v5 tf5client.TFProtoV5Client
+}
+
+var _ tf6client.TFProtoV6Client = &client{}
+
+func (c *client) GetMetadata(ctx context.Context, req *tfprotov6.GetMetadataRequest) (*tfprotov6.GetMetadataResponse, error) {
+	resp, err := c.v5.GetMetadata(ctx, toV5GetMetadataRequest(req))
+	if err != nil {
+		return nil, err
+	}
+	return toV6GetMetadataResponse(resp), nil
+}
+
+func (c *client) GetProviderSchema(ctx context.Context, req *tfprotov6.GetProviderSchemaRequest) (*tfprotov6.GetProviderSchemaResponse, error) {
+	resp, err := c.v5.GetProviderSchema(ctx, toV5GetProviderSchemaRequest(req))
+	if err != nil {
+		return nil, err
+	}
+	return toV6GetProviderSchemaResponse(resp), nil
+}
+
+func (c *client) GetResourceIdentitySchemas(ctx context.Context, req *tfprotov6.GetResourceIdentitySchemasRequest) (*tfprotov6.GetResourceIdentitySchemasResponse, error) {
+	resp, err := c.v5.GetResourceIdentitySchemas(ctx, toV5GetResourceIdentitySchemasRequest(req))
+	if err != nil {
+		return nil, err
+	}
+	return toV6GetResourceIdentitySchemasResponse(resp), nil
+}
+
+func (c *client) ValidateProviderConfig(ctx context.Context, req *tfprotov6.ValidateProviderConfigRequest) (*tfprotov6.ValidateProviderConfigResponse, error) {
+	resp, err := c.v5.PrepareProviderConfig(ctx, toV5ValidateProviderConfigRequest(req))
+	if err != nil {
+		return nil, err
+	}
+	return toV6PrepareProviderConfigResponse(resp), nil
+}
+
+func (c *client) ConfigureProvider(ctx context.Context, req *tfprotov6.ConfigureProviderRequest) (*tfprotov6.ConfigureProviderResponse, error) {
+	resp, err := c.v5.ConfigureProvider(ctx, toV5ConfigureProviderRequest(req))
+	if err != nil {
+		return nil, err
+	}
+	return toV6ConfigureProviderResponse(resp), nil
+}
+
+func (c *client) StopProvider(ctx context.Context, req *tfprotov6.StopProviderRequest) (*tfprotov6.StopProviderResponse, error) {
+	resp, err := c.v5.StopProvider(ctx, toV5StopProviderRequest(req))
+	if err != nil {
+		return nil, err
+	}
+	return toV6StopProviderResponse(resp), nil
+}
+
+func (c *client) ValidateResourceConfig(ctx context.Context, req *tfprotov6.ValidateResourceConfigRequest) (*tfprotov6.ValidateResourceConfigResponse, error) {
+	resp, err := c.v5.ValidateResourceTypeConfig(ctx, toV5ValidateResourceConfigRequest(req))
+	if err != nil {
+		return nil, err
+	}
+	return toV6ValidateResourceTypeConfigResponse(resp), nil
+}
+
+func (c *client) UpgradeResourceState(ctx context.Context, req *tfprotov6.UpgradeResourceStateRequest) (*tfprotov6.UpgradeResourceStateResponse, error) {
+	resp, err := c.v5.UpgradeResourceState(ctx, toV5UpgradeResourceStateRequest(req))
+	if err != nil {
+		return nil, err
+	}
+	return toV6UpgradeResourceStateResponse(resp), nil
+}
+
+func (c *client) UpgradeResourceIdentity(ctx context.Context, req *tfprotov6.UpgradeResourceIdentityRequest) (*tfprotov6.UpgradeResourceIdentityResponse, error) {
+	resp, err := c.v5.UpgradeResourceIdentity(ctx, toV5UpgradeResourceIdentityRequest(req))
+	if err != nil {
+		return nil, err
+	}
+	return toV6UpgradeResourceIdentityResponse(resp), nil
+}
+
+func (c *client) ReadResource(ctx context.Context, req *tfprotov6.ReadResourceRequest) (*tfprotov6.ReadResourceResponse, error) {
+	resp, err := c.v5.ReadResource(ctx, toV5ReadResourceRequest(req))
+	if err != nil {
+		return nil, err
+	}
+	return toV6ReadResourceResponse(resp), nil
+}
+
+func (c *client) PlanResourceChange(ctx context.Context, req *tfprotov6.PlanResourceChangeRequest) (*tfprotov6.PlanResourceChangeResponse, error) {
+	resp, err := c.v5.PlanResourceChange(ctx, toV5PlanResourceChangeRequest(req))
+	if err != nil {
+		return nil, err
+	}
+	return toV6PlanResourceChangeResponse(resp), nil
+}
+
+func (c *client) ApplyResourceChange(ctx context.Context, req *tfprotov6.ApplyResourceChangeRequest) (*tfprotov6.ApplyResourceChangeResponse, error) {
+	resp, err := c.v5.ApplyResourceChange(ctx, toV5ApplyResourceChangeRequest(req))
+	if err != nil {
+		return nil, err
+	}
+	return toV6ApplyResourceChangeResponse(resp), nil
+}
+
+func (c *client) ImportResourceState(ctx context.Context, req *tfprotov6.ImportResourceStateRequest) (*tfprotov6.ImportResourceStateResponse, error) {
+	resp, err := c.v5.ImportResourceState(ctx, toV5ImportResourceStateRequest(req))
+	if err != nil {
+		return nil, err
+	}
+	return toV6ImportResourceStateResponse(resp), nil
+}
+
+func (c *client) MoveResourceState(ctx context.Context, req *tfprotov6.MoveResourceStateRequest) (*tfprotov6.MoveResourceStateResponse, error) {
+	resp, err := c.v5.MoveResourceState(ctx, toV5MoveResourceStateRequest(req))
+	if err != nil {
+		return nil, err
+	}
+	return toV6MoveResourceStateResponse(resp), nil
+}
+
+func (c *client) ValidateDataResourceConfig(ctx context.Context, req *tfprotov6.ValidateDataResourceConfigRequest) (*tfprotov6.ValidateDataResourceConfigResponse, error) {
+	resp, err := c.v5.ValidateDataSourceConfig(ctx, toV5ValidateDataResourceConfigRequest(req))
+	if err != nil {
+		return nil, err
+	}
+	return toV6ValidateDataSourceConfigResponse(resp), nil
+}
+
+func (c *client) ReadDataSource(ctx context.Context, req *tfprotov6.ReadDataSourceRequest) (*tfprotov6.ReadDataSourceResponse, error) {
+	resp, err := c.v5.ReadDataSource(ctx, toV5ReadDataSourceRequest(req))
+	if err != nil {
+		return nil, err
+	}
+	return toV6ReadDataSourceResponse(resp), nil
+}
+
+func (c *client) ValidateEphemeralResourceConfig(ctx context.Context, req *tfprotov6.ValidateEphemeralResourceConfigRequest) (*tfprotov6.ValidateEphemeralResourceConfigResponse, error) {
+	resp, err := c.v5.ValidateEphemeralResourceConfig(ctx, toV5ValidateEphemeralResourceConfigRequest(req))
+	if err != nil {
+		return nil, err
+	}
+	return toV6ValidateEphemeralResourceConfigResponse(resp), nil
+}
+
+func (c *client) OpenEphemeralResource(ctx context.Context, req *tfprotov6.OpenEphemeralResourceRequest) (*tfprotov6.OpenEphemeralResourceResponse, error) {
+	resp, err := c.v5.OpenEphemeralResource(ctx, toV5OpenEphemeralResourceRequest(req))
+	if err != nil {
+		return nil, err
+	}
+	return toV6OpenEphemeralResourceResponse(resp), nil
+}
+
+func (c *client) RenewEphemeralResource(ctx context.Context, req *tfprotov6.RenewEphemeralResourceRequest) (*tfprotov6.RenewEphemeralResourceResponse, error) {
+	resp, err := c.v5.RenewEphemeralResource(ctx, toV5RenewEphemeralResourceRequest(req))
+	if err != nil {
+		return nil, err
+	}
+	return toV6RenewEphemeralResourceResponse(resp), nil
+}
+
+func (c *client) CloseEphemeralResource(ctx context.Context, req *tfprotov6.CloseEphemeralResourceRequest) (*tfprotov6.CloseEphemeralResourceResponse, error) {
+	resp, err := c.v5.CloseEphemeralResource(ctx, toV5CloseEphemeralResourceRequest(req))
+	if err != nil {
+		return nil, err
+	}
+	return toV6CloseEphemeralResourceResponse(resp), nil
+}
+
+func (c *client) CallFunction(ctx context.Context, req *tfprotov6.CallFunctionRequest) (*tfprotov6.CallFunctionResponse, error) {
+	resp, err := c.v5.CallFunction(ctx, toV5CallFunctionRequest(req))
+	if err != nil {
+		return nil, err
+	}
+	return toV6CallFunctionResponse(resp), nil
+}
+
+func (c *client) GetFunctions(ctx context.Context, req *tfprotov6.GetFunctionsRequest) (*tfprotov6.GetFunctionsResponse, error) {
+	resp, err := c.v5.GetFunctions(ctx, toV5GetFunctionsRequest(req))
+	if err != nil {
+		return nil, err
+	}
+	return toV6GetFunctionsResponse(resp), nil
+}
+
+func (c *client) ValidateListResourceConfig(ctx context.Context, req *tfprotov6.ValidateListResourceConfigRequest) (*tfprotov6.ValidateListResourceConfigResponse, error) {
+	resp, err := c.v5.ValidateListResourceConfig(ctx, toV5ValidateListResourceConfigRequest(req))
+	if err != nil {
+		return nil, err
+	}
+	return toV6ValidateListResourceConfigResponse(resp), nil
+}
+
+func (c *client) ListResource(ctx context.Context, req *tfprotov6.ListResourceRequest) (*tfprotov6.ListResourceServerStream, error) {
+	resp, err := c.v5.ListResource(ctx, toV5ListResourceRequest(req))
+	if err != nil {
+		return nil, err
+	}
+	return toV6ListResourceServerStream(resp), nil
+}
+
+func (c *client) ValidateActionConfig(ctx context.Context, req *tfprotov6.ValidateActionConfigRequest) (*tfprotov6.ValidateActionConfigResponse, error) {
+	resp, err := c.v5.ValidateActionConfig(ctx, toV5ValidateActionConfigRequest(req))
+	if err != nil {
+		return nil, err
+	}
+	return toV6ValidateActionConfigResponse(resp), nil
+}
+
+func (c *client) PlanAction(ctx context.Context, req *tfprotov6.PlanActionRequest) (*tfprotov6.PlanActionResponse, error) {
+	resp, err := c.v5.PlanAction(ctx, toV5PlanActionRequest(req))
+	if err != nil {
+		return nil, err
+	}
+	return toV6PlanActionResponse(resp), nil
+}
+
+func (c *client) InvokeAction(ctx context.Context, req *tfprotov6.InvokeActionRequest) (*tfprotov6.InvokeActionServerStream, error) {
+	resp, err := c.v5.InvokeAction(ctx, toV5InvokeActionRequest(req))
+	if err != nil {
+		return nil, err
+	}
+	return toV6InvokeActionServerStream(resp), nil
+}
@@ -0,0 +1,149 @@
+package tf5to6client
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov5"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/magodo/terraform-client-go/tfclient/tfprotov5/tf5client"
+	"github.com/magodo/terraform-client-go/tfclient/tfprotov6/tf6client"
+	"github.com/magodo/terraform-client-go/tfclient/typ"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// fakeV5Client implements the RPCs used by the tests, the others panic.
+type fakeV5Client struct {
+	tf5client.TFProtoV5Client
+}
+
+func (fakeV5Client) GetProviderSchema(context.Context, *tfprotov5.GetProviderSchemaRequest) (*tfprotov5.GetProviderSchemaResponse, error) {
+	return &tfprotov5.GetProviderSchemaResponse{
+		ResourceSchemas: map[string]*tfprotov5.Schema{
+			"foo_thing": {
+				Block: &tfprotov5.SchemaBlock{
+					Attributes: []*tfprotov5.SchemaAttribute{
+						{Name: "id", Type: tftypes.String, Required: true, DescriptionKind: tfprotov5.StringKindMarkdown},
+					},
+					BlockTypes: []*tfprotov5.SchemaNestedBlock{
+						{TypeName: "rule", Nesting: tfprotov5.SchemaNestedBlockNestingModeSet, MaxItems: 2, Block: &tfprotov5.SchemaBlock{}},
+					},
+				},
+			},
+		},
+	}, nil
+}
+
+func (fakeV5Client) GetResourceIdentitySchemas(context.Context, *tfprotov5.GetResourceIdentitySchemasRequest) (*tfprotov5.GetResourceIdentitySchemasResponse, error) {
+	return &tfprotov5.GetResourceIdentitySchemasResponse{}, nil
+}
+
+func (fakeV5Client) PrepareProviderConfig(_ context.Context, req *tfprotov5.PrepareProviderConfigRequest) (*tfprotov5.PrepareProviderConfigResponse, error) {
+	return &tfprotov5.PrepareProviderConfigResponse{
+		PreparedConfig: req.Config,
+		Diagnostics: []*tfprotov5.Diagnostic{
+			{Severity: tfprotov5.DiagnosticSeverityWarning, Summary: "prepared", Attribute: tftypes.NewAttributePath().WithAttributeName("a")},
+		},
+	}, nil
+}
+
+func (fakeV5Client) ReadResource(_ context.Context, req *tfprotov5.ReadResourceRequest) (*tfprotov5.ReadResourceResponse, error) {
+	return &tfprotov5.ReadResourceResponse{NewState: req.CurrentState, Private: []byte("private")}, nil
+}
+
+func (fakeV5Client) ValidateResourceTypeConfig(context.Context, *tfprotov5.ValidateResourceTypeConfigRequest) (*tfprotov5.ValidateResourceTypeConfigResponse, error) {
+	return nil, errors.New("boom")
+}
+
+func (fakeV5Client) InvokeAction(context.Context, *tfprotov5.InvokeActionRequest) (*tfprotov5.InvokeActionServerStream, error) {
+	return &tfprotov5.InvokeActionServerStream{
+		Events: slices.Values([]tfprotov5.InvokeActionEvent{
+			{Type: tfprotov5.ProgressInvokeActionEventType{Message: "working"}},
+			{Type: tfprotov5.CompletedInvokeActionEventType{Diagnostics: []*tfprotov5.Diagnostic{{Severity: tfprotov5.DiagnosticSeverityError, Summary: "failed"}}}},
+		}),
+	}, nil
+}
+
+func TestClient(t *testing.T) {
+	ctx := context.Background()
+	c := New(fakeV5Client{})
+
+	schResp, err := c.GetProviderSchema(ctx, &tfprotov6.GetProviderSchemaRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantSchema := &tfprotov6.Schema{
+		Block: &tfprotov6.SchemaBlock{
+			Attributes: []*tfprotov6.SchemaAttribute{
+				{Name: "id", Type: tftypes.String, Required: true, DescriptionKind: tfprotov6.StringKindMarkdown},
+			},
+			BlockTypes: []*tfprotov6.SchemaNestedBlock{
+				{TypeName: "rule", Nesting: tfprotov6.SchemaNestedBlockNestingModeSet, MaxItems: 2, Block: &tfprotov6.SchemaBlock{}},
+			},
+		},
+	}
+	if diff := cmp.Diff(wantSchema, schResp.ResourceSchemas["foo_thing"]); diff != "" {
+		t.Errorf("wrong schema\n%s", diff)
+	}
+
+	// The renamed RPC
+	cfgResp, err := c.ValidateProviderConfig(ctx, &tfprotov6.ValidateProviderConfigRequest{Config: &tfprotov6.DynamicValue{JSON: []byte(`{}`)}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(cfgResp.PreparedConfig.JSON) != `{}` {
+		t.Errorf("wrong prepared config: %v", cfgResp.PreparedConfig)
+	}
+	if len(cfgResp.Diagnostics) != 1 || cfgResp.Diagnostics[0].Severity != tfprotov6.DiagnosticSeverityWarning || !cfgResp.Diagnostics[0].Attribute.Equal(tftypes.NewAttributePath().WithAttributeName("a")) {
+		t.Errorf("wrong diagnostics: %v", cfgResp.Diagnostics)
+	}
+
+	// The error is returned as is.
+	if _, err := c.ValidateResourceConfig(ctx, &tfprotov6.ValidateResourceConfigRequest{}); err == nil || err.Error() != "boom" {
+		t.Errorf("wrong error: %v", err)
+	}
+
+	stream, err := c.InvokeAction(ctx, &tfprotov6.InvokeActionRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var events []tfprotov6.InvokeActionEvent
+	for e := range stream.Events {
+		events = append(events, e)
+	}
+	wantEvents := []tfprotov6.InvokeActionEvent{
+		{Type: tfprotov6.ProgressInvokeActionEventType{Message: "working"}},
+		{Type: tfprotov6.CompletedInvokeActionEventType{Diagnostics: []*tfprotov6.Diagnostic{{Severity: tfprotov6.DiagnosticSeverityError, Summary: "failed"}}}},
+	}
+	if diff := cmp.Diff(wantEvents, events); diff != "" {
+		t.Errorf("wrong events\n%s", diff)
+	}
+}
+
+func TestNormalizedClient(t *testing.T) {
+	// The upgraded client can be used by the protocol v6 normalized client.
+	c, err := tf6client.New(nil, New(fakeV5Client{}), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	state := cty.ObjectVal(map[string]cty.Value{
+		"id":   cty.StringVal("1"),
+		"rule": cty.SetValEmpty(cty.EmptyObject),
+	})
+	resp, diags := c.ReadResource(context.Background(), typ.ReadResourceRequest{TypeName: "foo_thing", PriorState: state})
+	if diags.HasErrors() {
+		t.Fatal(diags.Err())
+	}
+	if !resp.NewState.RawEquals(state) {
+		t.Errorf("wrong state: %#v", resp.NewState)
+	}
+	if string(resp.Private) != "private" {
+		t.Errorf("wrong private: %q", resp.Private)
+	}
+}
@@ -0,0 +1,417 @@
+package tf5to6client
+
+import (
+	"github.com/hashicorp/terraform-plugin-go/tfprotov5"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+)
+
+// The conversions of the requests from protocol v6 to v5.
+
+func toV5ApplyResourceChangeRequest(in *tfprotov6.ApplyResourceChangeRequest) *tfprotov5.ApplyResourceChangeRequest {
+	if in == nil {
+		return nil
+	}
+	return &tfprotov5.ApplyResourceChangeRequest{
+		TypeName:        in.TypeName,
+		PriorState:      toV5DynamicValue(in.PriorState),
+		PlannedState:    toV5DynamicValue(in.PlannedState),
+		Config:          toV5DynamicValue(in.Config),
+		PlannedPrivate:  in.PlannedPrivate,
+		ProviderMeta:    toV5DynamicValue(in.ProviderMeta),
+		PlannedIdentity: toV5ResourceIdentityData(in.PlannedIdentity),
+	}
+}
+
+func toV5CallFunctionRequest(in *tfprotov6.CallFunctionRequest) *tfprotov5.CallFunctionRequest {
+	if in == nil {
+		return nil
+	}
+	return &tfprotov5.CallFunctionRequest{
+		Name:      in.Name,
+		Arguments: toV5DynamicValues(in.Arguments),
+	}
+}
+
+func toV5CloseEphemeralResourceRequest(in *tfprotov6.CloseEphemeralResourceRequest) *tfprotov5.CloseEphemeralResourceRequest {
+	if in == nil {
+		return nil
+	}
+	return &tfprotov5.CloseEphemeralResourceRequest{
+		TypeName: in.TypeName,
+		Private:  in.Private,
+	}
+}
+
+func toV5ConfigureProviderRequest(in *tfprotov6.ConfigureProviderRequest) *tfprotov5.ConfigureProviderRequest {
+	if in == nil {
+		return nil
+	}
+	return &tfprotov5.ConfigureProviderRequest{
+		TerraformVersion:   in.TerraformVersion,
+		Config:             toV5DynamicValue(in.Config),
+		ClientCapabilities: toV5ConfigureProviderClientCapabilities(in.ClientCapabilities),
+	}
+}
+
+func toV5GetFunctionsRequest(in *tfprotov6.GetFunctionsRequest) *tfprotov5.GetFunctionsRequest {
+	if in == nil {
+		return nil
+	}
+	return &tfprotov5.GetFunctionsRequest{}
+}
+
+func toV5GetMetadataRequest(in *tfprotov6.GetMetadataRequest) *tfprotov5.GetMetadataRequest {
+	if in == nil {
+		return nil
+	}
+	return &tfprotov5.GetMetadataRequest{}
+}
+
+func toV5GetProviderSchemaRequest(in *tfprotov6.GetProviderSchemaRequest) *tfprotov5.GetProviderSchemaRequest {
+	if in == nil {
+		return nil
+	}
+	return &tfprotov5.GetProviderSchemaRequest{}
+}
+
+func toV5GetResourceIdentitySchemasRequest(in *tfprotov6.GetResourceIdentitySchemasRequest) *tfprotov5.GetResourceIdentitySchemasRequest {
+	if in == nil {
+		return nil
+	}
+	return &tfprotov5.GetResourceIdentitySchemasRequest{}
+}
+
+func toV5ImportResourceStateRequest(in *tfprotov6.ImportResourceStateRequest) *tfprotov5.ImportResourceStateRequest {
+	if in == nil {
+		return nil
+	}
+	return &tfprotov5.ImportResourceStateRequest{
+		TypeName:           in.TypeName,
+		ID:                 in.ID,
+		ClientCapabilities: toV5ImportResourceStateClientCapabilities(in.ClientCapabilities),
+		Identity:           toV5ResourceIdentityData(in.Identity),
+	}
+}
+
+func toV5InvokeActionRequest(in *tfprotov6.InvokeActionRequest) *tfprotov5.InvokeActionRequest {
+	if in == nil {
+		return nil
+	}
+	return &tfprotov5.InvokeActionRequest{
+		ActionType:         in.ActionType,
+		Config:             toV5DynamicValue(in.Config),
+		ClientCapabilities: toV5InvokeActionClientCapabilities(in.ClientCapabilities),
+	}
+}
+
+func toV5ListResourceRequest(in *tfprotov6.ListResourceRequest) *tfprotov5.ListResourceRequest {
+	if in == nil {
+		return nil
+	}
+	return &tfprotov5.ListResourceRequest{
+		TypeName:        in.TypeName,
+		Config:          toV5DynamicValue(in.Config),
+		IncludeResource: in.IncludeResource,
+		Limit:           in.Limit,
+	}
+}
+
+func toV5MoveResourceStateRequest(in *tfprotov6.MoveResourceStateRequest) *tfprotov5.MoveResourceStateRequest {
+	if in == nil {
+		return nil
+	}
+	return &tfprotov5.MoveResourceStateRequest{
+		SourcePrivate:               in.SourcePrivate,
+		SourceProviderAddress:       in.SourceProviderAddress,
+		SourceSchemaVersion:         in.SourceSchemaVersion,
+		SourceState:                 toV5RawState(in.SourceState),
+		SourceTypeName:              in.SourceTypeName,
+		TargetTypeName:              in.TargetTypeName,
+		SourceIdentity:              toV5RawState(in.SourceIdentity),
+		SourceIdentitySchemaVersion: in.SourceIdentitySchemaVersion,
+	}
+}
+
+func toV5OpenEphemeralResourceRequest(in *tfprotov6.OpenEphemeralResourceRequest) *tfprotov5.OpenEphemeralResourceRequest {
+	if in == nil {
+		return nil
+	}
+	return &tfprotov5.OpenEphemeralResourceRequest{
+		TypeName:           in.TypeName,
+		Config:             toV5DynamicValue(in.Config),
+		ClientCapabilities: toV5OpenEphemeralResourceClientCapabilities(in.ClientCapabilities),
+	}
+}
+
+func toV5PlanActionRequest(in *tfprotov6.PlanActionRequest) *tfprotov5.PlanActionRequest {
+	if in == nil {
+		return nil
+	}
+	return &tfprotov5.PlanActionRequest{
+		ActionType:         in.ActionType,
+		Config:             toV5DynamicValue(in.Config),
+		ClientCapabilities: toV5PlanActionClientCapabilities(in.ClientCapabilities),
+	}
+}
+
+func toV5PlanResourceChangeRequest(in *tfprotov6.PlanResourceChangeRequest) *tfprotov5.PlanResourceChangeRequest {
+	if in == nil {
+		return nil
+	}
+	return &tfprotov5.PlanResourceChangeRequest{
+		TypeName:           in.TypeName,
+		PriorState:         toV5DynamicValue(in.PriorState),
+		ProposedNewState:   toV5DynamicValue(in.ProposedNewState),
+		Config:             toV5DynamicValue(in.Config),
+		PriorPrivate:       in.PriorPrivate,
+		ProviderMeta:       toV5DynamicValue(in.ProviderMeta),
+		ClientCapabilities: toV5PlanResourceChangeClientCapabilities(in.ClientCapabilities),
+		PriorIdentity:      toV5ResourceIdentityData(in.PriorIdentity),
+	}
+}
+
+func toV5ReadDataSourceRequest(in *tfprotov6.ReadDataSourceRequest) *tfprotov5.ReadDataSourceRequest {
+	if in == nil {
+		return nil
+	}
+	return &tfprotov5.ReadDataSourceRequest{
+		TypeName:           in.TypeName,
+		Config:             toV5DynamicValue(in.Config),
+		ProviderMeta:       toV5DynamicValue(in.ProviderMeta),
+		ClientCapabilities: toV5ReadDataSourceClientCapabilities(in.ClientCapabilities),
+	}
+}
+
+func toV5ReadResourceRequest(in *tfprotov6.ReadResourceRequest) *tfprotov5.ReadResourceRequest {
+	if in == nil {
+		return nil
+	}
+	return &tfprotov5.ReadResourceRequest{
+		TypeName:           in.TypeName,
+		CurrentState:       toV5DynamicValue(in.CurrentState),
+		Private:            in.Private,
+		ProviderMeta:       toV5DynamicValue(in.ProviderMeta),
+		ClientCapabilities: toV5ReadResourceClientCapabilities(in.ClientCapabilities),
+		CurrentIdentity:    toV5ResourceIdentityData(in.CurrentIdentity),
+	}
+}
+
+func toV5RenewEphemeralResourceRequest(in *tfprotov6.RenewEphemeralResourceRequest) *tfprotov5.RenewEphemeralResourceRequest {
+	if in == nil {
+		return nil
+	}
+	return &tfprotov5.RenewEphemeralResourceRequest{
+		TypeName: in.TypeName,
+		Private:  in.Private,
+	}
+}
+
+func toV5StopProviderRequest(in *tfprotov6.StopProviderRequest) *tfprotov5.StopProviderRequest {
+	if in == nil {
+		return nil
+	}
+	return &tfprotov5.StopProviderRequest{}
+}
+
+func toV5UpgradeResourceIdentityRequest(in *tfprotov6.UpgradeResourceIdentityRequest) *tfprotov5.UpgradeResourceIdentityRequest {
+	if in == nil {
+		return nil
+	}
+	return &tfprotov5.UpgradeResourceIdentityRequest{
+		TypeName:    in.TypeName,
+		Version:     in.Version,
+		RawIdentity: toV5RawState(in.RawIdentity),
+	}
+}
+
+func toV5UpgradeResourceStateRequest(in *tfprotov6.UpgradeResourceStateRequest) *tfprotov5.UpgradeResourceStateRequest {
+	if in == nil {
+		return nil
+	}
+	return &tfprotov5.UpgradeResourceStateRequest{
+		TypeName: in.TypeName,
+		Version:  in.Version,
+		RawState: toV5RawState(in.RawState),
+	}
+}
+
+func toV5ValidateActionConfigRequest(in *tfprotov6.ValidateActionConfigRequest) *tfprotov5.ValidateActionConfigRequest {
+	if in == nil {
+		return nil
+	}
+	return &tfprotov5.ValidateActionConfigRequest{
+		ActionType: in.ActionType,
+		Config:     toV5DynamicValue(in.Config),
+	}
+}
+
+func toV5ValidateDataResourceConfigRequest(in *tfprotov6.ValidateDataResourceConfigRequest) *tfprotov5.ValidateDataSourceConfigRequest {
+	if in == nil {
+		return nil
+	}
+	return &tfprotov5.ValidateDataSourceConfigRequest{
+		TypeName: in.TypeName,
+		Config:   toV5DynamicValue(in.Config),
+	}
+}
+
+func toV5ValidateEphemeralResourceConfigRequest(in *tfprotov6.ValidateEphemeralResourceConfigRequest) *tfprotov5.ValidateEphemeralResourceConfigRequest {
+	if in == nil {
+		return nil
+	}
+	return &tfprotov5.ValidateEphemeralResourceConfigRequest{
+		TypeName: in.TypeName,
+		Config:   toV5DynamicValue(in.Config),
+	}
+}
+
+func toV5ValidateListResourceConfigRequest(in *tfprotov6.ValidateListResourceConfigRequest) *tfprotov5.ValidateListResourceConfigRequest {
+	if in == nil {
+		return nil
+	}
+	return &tfprotov5.ValidateListResourceConfigRequest{
+		TypeName:              in.TypeName,
+		Config:                toV5DynamicValue(in.Config),
+		IncludeResourceObject: toV5DynamicValue(in.IncludeResourceObject),
+		Limit:                 toV5DynamicValue(in.Limit),
+	}
+}
+
+func toV5ValidateProviderConfigRequest(in *tfprotov6.ValidateProviderConfigRequest) *tfprotov5.PrepareProviderConfigRequest {
+	if in == nil {
+		return nil
+	}
+	return &tfprotov5.PrepareProviderConfigRequest{
+		Config: toV5DynamicValue(in.Config),
+	}
+}
+
+func toV5ValidateResourceConfigRequest(in *tfprotov6.ValidateResourceConfigRequest) *tfprotov5.ValidateResourceTypeConfigRequest {
+	if in == nil {
+		return nil
+	}
+	return &tfprotov5.ValidateResourceTypeConfigRequest{
+		TypeName:           in.TypeName,
+		Config:             toV5DynamicValue(in.Config),
+		ClientCapabilities: toV5ValidateResourceConfigClientCapabilities(in.ClientCapabilities),
+	}
+}
+
+func toV5DynamicValue(in *tfprotov6.DynamicValue) *tfprotov5.DynamicValue {
+	if in == nil {
+		return nil
+	}
+	return &tfprotov5.DynamicValue{
+		MsgPack: in.MsgPack,
+		JSON:    in.JSON,
+	}
+}
+
+func toV5ResourceIdentityData(in *tfprotov6.ResourceIdentityData) *tfprotov5.ResourceIdentityData {
+	if in == nil {
+		return nil
+	}
+	return &tfprotov5.ResourceIdentityData{
+		IdentityData: toV5DynamicValue(in.IdentityData),
+	}
+}
+
+func toV5ConfigureProviderClientCapabilities(in *tfprotov6.ConfigureProviderClientCapabilities) *tfprotov5.ConfigureProviderClientCapabilities {
+	if in == nil {
+		return nil
+	}
+	return &tfprotov5.ConfigureProviderClientCapabilities{
+		DeferralAllowed: in.DeferralAllowed,
+	}
+}
+
+func toV5ImportResourceStateClientCapabilities(in *tfprotov6.ImportResourceStateClientCapabilities) *tfprotov5.ImportResourceStateClientCapabilities {
+	if in == nil {
+		return nil
+	}
+	return &tfprotov5.ImportResourceStateClientCapabilities{
+		DeferralAllowed: in.DeferralAllowed,
+	}
+}
+
+func toV5InvokeActionClientCapabilities(in *tfprotov6.InvokeActionClientCapabilities) *tfprotov5.InvokeActionClientCapabilities {
+	if in == nil {
+		return nil
+	}
+	return &tfprotov5.InvokeActionClientCapabilities{}
+}
+
+func toV5RawState(in *tfprotov6.RawState) *tfprotov5.RawState {
+	if in == nil {
+		return nil
+	}
+	return &tfprotov5.RawState{
+		JSON:    in.JSON,
+		Flatmap: in.Flatmap,
+	}
+}
+
+func toV5OpenEphemeralResourceClientCapabilities(in *tfprotov6.OpenEphemeralResourceClientCapabilities) *tfprotov5.OpenEphemeralResourceClientCapabilities {
+	if in == nil {
+		return nil
+	}
+	return &tfprotov5.OpenEphemeralResourceClientCapabilities{
+		DeferralAllowed: in.DeferralAllowed,
+	}
+}
+
+func toV5PlanActionClientCapabilities(in *tfprotov6.PlanActionClientCapabilities) *tfprotov5.PlanActionClientCapabilities {
+	if in == nil {
+		return nil
+	}
+	return &tfprotov5.PlanActionClientCapabilities{
+		DeferralAllowed: in.DeferralAllowed,
+	}
+}
+
+func toV5PlanResourceChangeClientCapabilities(in *tfprotov6.PlanResourceChangeClientCapabilities) *tfprotov5.PlanResourceChangeClientCapabilities {
+	if in == nil {
+		return nil
+	}
+	return &tfprotov5.PlanResourceChangeClientCapabilities{
+		DeferralAllowed: in.DeferralAllowed,
+	}
+}
+
+func toV5ReadDataSourceClientCapabilities(in *tfprotov6.ReadDataSourceClientCapabilities) *tfprotov5.ReadDataSourceClientCapabilities {
+	if in == nil {
+		return nil
+	}
+	return &tfprotov5.ReadDataSourceClientCapabilities{
+		DeferralAllowed: in.DeferralAllowed,
+	}
+}
+
+func toV5ReadResourceClientCapabilities(in *tfprotov6.ReadResourceClientCapabilities) *tfprotov5.ReadResourceClientCapabilities {
+	if in == nil {
+		return nil
+	}
+	return &tfprotov5.ReadResourceClientCapabilities{
+		DeferralAllowed: in.DeferralAllowed,
+	}
+}
+
+func toV5ValidateResourceConfigClientCapabilities(in *tfprotov6.ValidateResourceConfigClientCapabilities) *tfprotov5.ValidateResourceTypeConfigClientCapabilities {
+	if in == nil {
+		return nil
+	}
+	return &tfprotov5.ValidateResourceTypeConfigClientCapabilities{
+		WriteOnlyAttributesAllowed: in.WriteOnlyAttributesAllowed,
+	}
+}
+
+func toV5DynamicValues(in []*tfprotov6.DynamicValue) []*tfprotov5.DynamicValue {
+	if in == nil {
+		return nil
+	}
+	out := make([]*tfprotov5.DynamicValue, 0, len(in))
+	for _, e := range in {
+		out = append(out, toV5DynamicValue(e))
+	}
+	return out
+}
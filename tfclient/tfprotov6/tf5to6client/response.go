@@ -0,0 +1,768 @@
+package tf5to6client
+
+import (
+	"github.com/hashicorp/terraform-plugin-go/tfprotov5"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+)
+
+// The conversions of the responses from protocol v5 to v6.
+
+func toV6ApplyResourceChangeResponse(in *tfprotov5.ApplyResourceChangeResponse) *tfprotov6.ApplyResourceChangeResponse {
+	if in == nil {
+		return nil
+	}
+	return &tfprotov6.ApplyResourceChangeResponse{
+		NewState:                    toV6DynamicValue(in.NewState),
+		Private:                     in.Private,
+		Diagnostics:                 toV6Diagnostics(in.Diagnostics),
+		UnsafeToUseLegacyTypeSystem: in.UnsafeToUseLegacyTypeSystem,
+		NewIdentity:                 toV6ResourceIdentityData(in.NewIdentity),
+	}
+}
+
+func toV6CallFunctionResponse(in *tfprotov5.CallFunctionResponse) *tfprotov6.CallFunctionResponse {
+	if in == nil {
+		return nil
+	}
+	return &tfprotov6.CallFunctionResponse{
+		Error:  toV6FunctionError(in.Error),
+		Result: toV6DynamicValue(in.Result),
+	}
+}
+
+func toV6CloseEphemeralResourceResponse(in *tfprotov5.CloseEphemeralResourceResponse) *tfprotov6.CloseEphemeralResourceResponse {
+	if in == nil {
+		return nil
+	}
+	return &tfprotov6.CloseEphemeralResourceResponse{
+		Diagnostics: toV6Diagnostics(in.Diagnostics),
+	}
+}
+
+func toV6ConfigureProviderResponse(in *tfprotov5.ConfigureProviderResponse) *tfprotov6.ConfigureProviderResponse {
+	if in == nil {
+		return nil
+	}
+	return &tfprotov6.ConfigureProviderResponse{
+		Diagnostics: toV6Diagnostics(in.Diagnostics),
+	}
+}
+
+func toV6GetFunctionsResponse(in *tfprotov5.GetFunctionsResponse) *tfprotov6.GetFunctionsResponse {
+	if in == nil {
+		return nil
+	}
+	return &tfprotov6.GetFunctionsResponse{
+		Diagnostics: toV6Diagnostics(in.Diagnostics),
+		Functions:   toV6FunctionMap(in.Functions),
+	}
+}
+
+func toV6GetMetadataResponse(in *tfprotov5.GetMetadataResponse) *tfprotov6.GetMetadataResponse {
+	if in == nil {
+		return nil
+	}
+	return &tfprotov6.GetMetadataResponse{
+		ServerCapabilities: toV6ServerCapabilities(in.ServerCapabilities),
+		Diagnostics:        toV6Diagnostics(in.Diagnostics),
+		DataSources:        toV6DataSourceMetadatas(in.DataSources),
+		Functions:          toV6FunctionMetadatas(in.Functions),
+		Resources:          toV6ResourceMetadatas(in.Resources),
+		EphemeralResources: toV6EphemeralResourceMetadatas(in.EphemeralResources),
+		ListResources:      toV6ListResourceMetadatas(in.ListResources),
+		Actions:            toV6ActionMetadatas(in.Actions),
+	}
+}
+
+func toV6GetProviderSchemaResponse(in *tfprotov5.GetProviderSchemaResponse) *tfprotov6.GetProviderSchemaResponse {
+	if in == nil {
+		return nil
+	}
+	return &tfprotov6.GetProviderSchemaResponse{
+		ServerCapabilities:       toV6ServerCapabilities(in.ServerCapabilities),
+		Provider:                 toV6Schema(in.Provider),
+		ProviderMeta:             toV6Schema(in.ProviderMeta),
+		ResourceSchemas:          toV6SchemaMap(in.ResourceSchemas),
+		DataSourceSchemas:        toV6SchemaMap(in.DataSourceSchemas),
+		Functions:                toV6FunctionMap(in.Functions),
+		EphemeralResourceSchemas: toV6SchemaMap(in.EphemeralResourceSchemas),
+		ListResourceSchemas:      toV6SchemaMap(in.ListResourceSchemas),
+		ActionSchemas:            toV6ActionSchemaMap(in.ActionSchemas),
+		Diagnostics:              toV6Diagnostics(in.Diagnostics),
+	}
+}
+
+func toV6GetResourceIdentitySchemasResponse(in *tfprotov5.GetResourceIdentitySchemasResponse) *tfprotov6.GetResourceIdentitySchemasResponse {
+	if in == nil {
+		return nil
+	}
+	return &tfprotov6.GetResourceIdentitySchemasResponse{
+		IdentitySchemas: toV6ResourceIdentitySchemaMap(in.IdentitySchemas),
+		Diagnostics:     toV6Diagnostics(in.Diagnostics),
+	}
+}
+
+func toV6ImportResourceStateResponse(in *tfprotov5.ImportResourceStateResponse) *tfprotov6.ImportResourceStateResponse {
+	if in == nil {
+		return nil
+	}
+	return &tfprotov6.ImportResourceStateResponse{
+		ImportedResources: toV6ImportedResources(in.ImportedResources),
+		Diagnostics:       toV6Diagnostics(in.Diagnostics),
+		Deferred:          toV6Deferred(in.Deferred),
+	}
+}
+
+func toV6InvokeActionServerStream(in *tfprotov5.InvokeActionServerStream) *tfprotov6.InvokeActionServerStream {
+	if in == nil {
+		return nil
+	}
+	return &tfprotov6.InvokeActionServerStream{
+		Events: func(yield func(tfprotov6.InvokeActionEvent) bool) {
+			for e := range in.Events {
+				if !yield(toV6InvokeActionEvent(e)) {
+					return
+				}
+			}
+		},
+	}
+}
+
+func toV6ListResourceServerStream(in *tfprotov5.ListResourceServerStream) *tfprotov6.ListResourceServerStream {
+	if in == nil {
+		return nil
+	}
+	return &tfprotov6.ListResourceServerStream{
+		Results: func(yield func(tfprotov6.ListResourceResult) bool) {
+			for r := range in.Results {
+				if !yield(*toV6ListResourceResult(&r)) {
+					return
+				}
+			}
+		},
+	}
+}
+
+func toV6MoveResourceStateResponse(in *tfprotov5.MoveResourceStateResponse) *tfprotov6.MoveResourceStateResponse {
+	if in == nil {
+		return nil
+	}
+	return &tfprotov6.MoveResourceStateResponse{
+		TargetPrivate:  in.TargetPrivate,
+		TargetState:    toV6DynamicValue(in.TargetState),
+		Diagnostics:    toV6Diagnostics(in.Diagnostics),
+		TargetIdentity: toV6ResourceIdentityData(in.TargetIdentity),
+	}
+}
+
+func toV6OpenEphemeralResourceResponse(in *tfprotov5.OpenEphemeralResourceResponse) *tfprotov6.OpenEphemeralResourceResponse {
+	if in == nil {
+		return nil
+	}
+	return &tfprotov6.OpenEphemeralResourceResponse{
+		Result:      toV6DynamicValue(in.Result),
+		Diagnostics: toV6Diagnostics(in.Diagnostics),
+		Private:     in.Private,
+		RenewAt:     in.RenewAt,
+		Deferred:    toV6Deferred(in.Deferred),
+	}
+}
+
+func toV6PlanActionResponse(in *tfprotov5.PlanActionResponse) *tfprotov6.PlanActionResponse {
+	if in == nil {
+		return nil
+	}
+	return &tfprotov6.PlanActionResponse{
+		Diagnostics: toV6Diagnostics(in.Diagnostics),
+		Deferred:    toV6Deferred(in.Deferred),
+	}
+}
+
+func toV6PlanResourceChangeResponse(in *tfprotov5.PlanResourceChangeResponse) *tfprotov6.PlanResourceChangeResponse {
+	if in == nil {
+		return nil
+	}
+	return &tfprotov6.PlanResourceChangeResponse{
+		PlannedState:                toV6DynamicValue(in.PlannedState),
+		RequiresReplace:             in.RequiresReplace,
+		PlannedPrivate:              in.PlannedPrivate,
+		Diagnostics:                 toV6Diagnostics(in.Diagnostics),
+		UnsafeToUseLegacyTypeSystem: in.UnsafeToUseLegacyTypeSystem,
+		Deferred:                    toV6Deferred(in.Deferred),
+		PlannedIdentity:             toV6ResourceIdentityData(in.PlannedIdentity),
+	}
+}
+
+func toV6ReadDataSourceResponse(in *tfprotov5.ReadDataSourceResponse) *tfprotov6.ReadDataSourceResponse {
+	if in == nil {
+		return nil
+	}
+	return &tfprotov6.ReadDataSourceResponse{
+		State:       toV6DynamicValue(in.State),
+		Diagnostics: toV6Diagnostics(in.Diagnostics),
+		Deferred:    toV6Deferred(in.Deferred),
+	}
+}
+
+func toV6ReadResourceResponse(in *tfprotov5.ReadResourceResponse) *tfprotov6.ReadResourceResponse {
+	if in == nil {
+		return nil
+	}
+	return &tfprotov6.ReadResourceResponse{
+		NewState:    toV6DynamicValue(in.NewState),
+		Diagnostics: toV6Diagnostics(in.Diagnostics),
+		Private:     in.Private,
+		Deferred:    toV6Deferred(in.Deferred),
+		NewIdentity: toV6ResourceIdentityData(in.NewIdentity),
+	}
+}
+
+func toV6RenewEphemeralResourceResponse(in *tfprotov5.RenewEphemeralResourceResponse) *tfprotov6.RenewEphemeralResourceResponse {
+	if in == nil {
+		return nil
+	}
+	return &tfprotov6.RenewEphemeralResourceResponse{
+		Diagnostics: toV6Diagnostics(in.Diagnostics),
+		Private:     in.Private,
+		RenewAt:     in.RenewAt,
+	}
+}
+
+func toV6StopProviderResponse(in *tfprotov5.StopProviderResponse) *tfprotov6.StopProviderResponse {
+	if in == nil {
+		return nil
+	}
+	return &tfprotov6.StopProviderResponse{
+		Error: in.Error,
+	}
+}
+
+func toV6UpgradeResourceIdentityResponse(in *tfprotov5.UpgradeResourceIdentityResponse) *tfprotov6.UpgradeResourceIdentityResponse {
+	if in == nil {
+		return nil
+	}
+	return &tfprotov6.UpgradeResourceIdentityResponse{
+		UpgradedIdentity: toV6ResourceIdentityData(in.UpgradedIdentity),
+		Diagnostics:      toV6Diagnostics(in.Diagnostics),
+	}
+}
+
+func toV6UpgradeResourceStateResponse(in *tfprotov5.UpgradeResourceStateResponse) *tfprotov6.UpgradeResourceStateResponse {
+	if in == nil {
+		return nil
+	}
+	return &tfprotov6.UpgradeResourceStateResponse{
+		UpgradedState: toV6DynamicValue(in.UpgradedState),
+		Diagnostics:   toV6Diagnostics(in.Diagnostics),
+	}
+}
+
+func toV6ValidateActionConfigResponse(in *tfprotov5.ValidateActionConfigResponse) *tfprotov6.ValidateActionConfigResponse {
+	if in == nil {
+		return nil
+	}
+	return &tfprotov6.ValidateActionConfigResponse{
+		Diagnostics: toV6Diagnostics(in.Diagnostics),
+	}
+}
+
+func toV6ValidateDataSourceConfigResponse(in *tfprotov5.ValidateDataSourceConfigResponse) *tfprotov6.ValidateDataResourceConfigResponse {
+	if in == nil {
+		return nil
+	}
+	return &tfprotov6.ValidateDataResourceConfigResponse{
+		Diagnostics: toV6Diagnostics(in.Diagnostics),
+	}
+}
+
+func toV6ValidateEphemeralResourceConfigResponse(in *tfprotov5.ValidateEphemeralResourceConfigResponse) *tfprotov6.ValidateEphemeralResourceConfigResponse {
+	if in == nil {
+		return nil
+	}
+	return &tfprotov6.ValidateEphemeralResourceConfigResponse{
+		Diagnostics: toV6Diagnostics(in.Diagnostics),
+	}
+}
+
+func toV6ValidateListResourceConfigResponse(in *tfprotov5.ValidateListResourceConfigResponse) *tfprotov6.ValidateListResourceConfigResponse {
+	if in == nil {
+		return nil
+	}
+	return &tfprotov6.ValidateListResourceConfigResponse{
+		Diagnostics: toV6Diagnostics(in.Diagnostics),
+	}
+}
+
+func toV6PrepareProviderConfigResponse(in *tfprotov5.PrepareProviderConfigResponse) *tfprotov6.ValidateProviderConfigResponse {
+	if in == nil {
+		return nil
+	}
+	return &tfprotov6.ValidateProviderConfigResponse{
+		PreparedConfig: toV6DynamicValue(in.PreparedConfig),
+		Diagnostics:    toV6Diagnostics(in.Diagnostics),
+	}
+}
+
+func toV6ValidateResourceTypeConfigResponse(in *tfprotov5.ValidateResourceTypeConfigResponse) *tfprotov6.ValidateResourceConfigResponse {
+	if in == nil {
+		return nil
+	}
+	return &tfprotov6.ValidateResourceConfigResponse{
+		Diagnostics: toV6Diagnostics(in.Diagnostics),
+	}
+}
+
+func toV6DynamicValue(in *tfprotov5.DynamicValue) *tfprotov6.DynamicValue {
+	if in == nil {
+		return nil
+	}
+	return &tfprotov6.DynamicValue{
+		MsgPack: in.MsgPack,
+		JSON:    in.JSON,
+	}
+}
+
+func toV6Diagnostic(in *tfprotov5.Diagnostic) *tfprotov6.Diagnostic {
+	if in == nil {
+		return nil
+	}
+	return &tfprotov6.Diagnostic{
+		Severity:  tfprotov6.DiagnosticSeverity(in.Severity),
+		Summary:   in.Summary,
+		Detail:    in.Detail,
+		Attribute: in.Attribute,
+	}
+}
+
+func toV6ResourceIdentityData(in *tfprotov5.ResourceIdentityData) *tfprotov6.ResourceIdentityData {
+	if in == nil {
+		return nil
+	}
+	return &tfprotov6.ResourceIdentityData{
+		IdentityData: toV6DynamicValue(in.IdentityData),
+	}
+}
+
+func toV6FunctionError(in *tfprotov5.FunctionError) *tfprotov6.FunctionError {
+	if in == nil {
+		return nil
+	}
+	return &tfprotov6.FunctionError{
+		Text:             in.Text,
+		FunctionArgument: in.FunctionArgument,
+	}
+}
+
+func toV6Function(in *tfprotov5.Function) *tfprotov6.Function {
+	if in == nil {
+		return nil
+	}
+	return &tfprotov6.Function{
+		Parameters:         toV6FunctionParameters(in.Parameters),
+		VariadicParameter:  toV6FunctionParameter(in.VariadicParameter),
+		Return:             toV6FunctionReturn(in.Return),
+		Summary:            in.Summary,
+		Description:        in.Description,
+		DescriptionKind:    tfprotov6.StringKind(in.DescriptionKind),
+		DeprecationMessage: in.DeprecationMessage,
+	}
+}
+
+func toV6ServerCapabilities(in *tfprotov5.ServerCapabilities) *tfprotov6.ServerCapabilities {
+	if in == nil {
+		return nil
+	}
+	return &tfprotov6.ServerCapabilities{
+		GetProviderSchemaOptional: in.GetProviderSchemaOptional,
+		MoveResourceState:         in.MoveResourceState,
+		PlanDestroy:               in.PlanDestroy,
+	}
+}
+
+func toV6DataSourceMetadata(in *tfprotov5.DataSourceMetadata) *tfprotov6.DataSourceMetadata {
+	if in == nil {
+		return nil
+	}
+	return &tfprotov6.DataSourceMetadata{
+		TypeName: in.TypeName,
+	}
+}
+
+func toV6FunctionMetadata(in *tfprotov5.FunctionMetadata) *tfprotov6.FunctionMetadata {
+	if in == nil {
+		return nil
+	}
+	return &tfprotov6.FunctionMetadata{
+		Name: in.Name,
+	}
+}
+
+func toV6ResourceMetadata(in *tfprotov5.ResourceMetadata) *tfprotov6.ResourceMetadata {
+	if in == nil {
+		return nil
+	}
+	return &tfprotov6.ResourceMetadata{
+		TypeName: in.TypeName,
+	}
+}
+
+func toV6EphemeralResourceMetadata(in *tfprotov5.EphemeralResourceMetadata) *tfprotov6.EphemeralResourceMetadata {
+	if in == nil {
+		return nil
+	}
+	return &tfprotov6.EphemeralResourceMetadata{
+		TypeName: in.TypeName,
+	}
+}
+
+func toV6ListResourceMetadata(in *tfprotov5.ListResourceMetadata) *tfprotov6.ListResourceMetadata {
+	if in == nil {
+		return nil
+	}
+	return &tfprotov6.ListResourceMetadata{
+		TypeName: in.TypeName,
+	}
+}
+
+func toV6ActionMetadata(in *tfprotov5.ActionMetadata) *tfprotov6.ActionMetadata {
+	if in == nil {
+		return nil
+	}
+	return &tfprotov6.ActionMetadata{
+		TypeName: in.TypeName,
+	}
+}
+
+func toV6Schema(in *tfprotov5.Schema) *tfprotov6.Schema {
+	if in == nil {
+		return nil
+	}
+	return &tfprotov6.Schema{
+		Version: in.Version,
+		Block:   toV6SchemaBlock(in.Block),
+	}
+}
+
+func toV6ActionSchema(in *tfprotov5.ActionSchema) *tfprotov6.ActionSchema {
+	if in == nil {
+		return nil
+	}
+	return &tfprotov6.ActionSchema{
+		Schema: toV6Schema(in.Schema),
+	}
+}
+
+func toV6ResourceIdentitySchema(in *tfprotov5.ResourceIdentitySchema) *tfprotov6.ResourceIdentitySchema {
+	if in == nil {
+		return nil
+	}
+	return &tfprotov6.ResourceIdentitySchema{
+		Version:            in.Version,
+		IdentityAttributes: toV6ResourceIdentitySchemaAttributes(in.IdentityAttributes),
+	}
+}
+
+func toV6ImportedResource(in *tfprotov5.ImportedResource) *tfprotov6.ImportedResource {
+	if in == nil {
+		return nil
+	}
+	return &tfprotov6.ImportedResource{
+		TypeName: in.TypeName,
+		State:    toV6DynamicValue(in.State),
+		Private:  in.Private,
+		Identity: toV6ResourceIdentityData(in.Identity),
+	}
+}
+
+func toV6Deferred(in *tfprotov5.Deferred) *tfprotov6.Deferred {
+	if in == nil {
+		return nil
+	}
+	return &tfprotov6.Deferred{
+		Reason: tfprotov6.DeferredReason(in.Reason),
+	}
+}
+
+func toV6FunctionParameter(in *tfprotov5.FunctionParameter) *tfprotov6.FunctionParameter {
+	if in == nil {
+		return nil
+	}
+	return &tfprotov6.FunctionParameter{
+		AllowNullValue:     in.AllowNullValue,
+		AllowUnknownValues: in.AllowUnknownValues,
+		Description:        in.Description,
+		DescriptionKind:    tfprotov6.StringKind(in.DescriptionKind),
+		Name:               in.Name,
+		Type:               in.Type,
+	}
+}
+
+func toV6FunctionReturn(in *tfprotov5.FunctionReturn) *tfprotov6.FunctionReturn {
+	if in == nil {
+		return nil
+	}
+	return &tfprotov6.FunctionReturn{
+		Type: in.Type,
+	}
+}
+
+func toV6SchemaBlock(in *tfprotov5.SchemaBlock) *tfprotov6.SchemaBlock {
+	if in == nil {
+		return nil
+	}
+	return &tfprotov6.SchemaBlock{
+		Version:         in.Version,
+		Attributes:      toV6SchemaAttributes(in.Attributes),
+		BlockTypes:      toV6SchemaNestedBlocks(in.BlockTypes),
+		Description:     in.Description,
+		DescriptionKind: tfprotov6.StringKind(in.DescriptionKind),
+		Deprecated:      in.Deprecated,
+	}
+}
+
+func toV6ResourceIdentitySchemaAttribute(in *tfprotov5.ResourceIdentitySchemaAttribute) *tfprotov6.ResourceIdentitySchemaAttribute {
+	if in == nil {
+		return nil
+	}
+	return &tfprotov6.ResourceIdentitySchemaAttribute{
+		Name:              in.Name,
+		Type:              in.Type,
+		RequiredForImport: in.RequiredForImport,
+		OptionalForImport: in.OptionalForImport,
+		Description:       in.Description,
+	}
+}
+
+func toV6SchemaAttribute(in *tfprotov5.SchemaAttribute) *tfprotov6.SchemaAttribute {
+	if in == nil {
+		return nil
+	}
+	return &tfprotov6.SchemaAttribute{
+		Name:            in.Name,
+		Type:            in.Type,
+		Description:     in.Description,
+		Required:        in.Required,
+		Optional:        in.Optional,
+		Computed:        in.Computed,
+		Sensitive:       in.Sensitive,
+		DescriptionKind: tfprotov6.StringKind(in.DescriptionKind),
+		Deprecated:      in.Deprecated,
+		WriteOnly:       in.WriteOnly,
+	}
+}
+
+func toV6SchemaNestedBlock(in *tfprotov5.SchemaNestedBlock) *tfprotov6.SchemaNestedBlock {
+	if in == nil {
+		return nil
+	}
+	return &tfprotov6.SchemaNestedBlock{
+		TypeName: in.TypeName,
+		Block:    toV6SchemaBlock(in.Block),
+		Nesting:  tfprotov6.SchemaNestedBlockNestingMode(in.Nesting),
+		MinItems: in.MinItems,
+		MaxItems: in.MaxItems,
+	}
+}
+
+func toV6ActionMetadatas(in []tfprotov5.ActionMetadata) []tfprotov6.ActionMetadata {
+	if in == nil {
+		return nil
+	}
+	out := make([]tfprotov6.ActionMetadata, 0, len(in))
+	for _, e := range in {
+		out = append(out, *toV6ActionMetadata(&e))
+	}
+	return out
+}
+
+func toV6ActionSchemaMap(in map[string]*tfprotov5.ActionSchema) map[string]*tfprotov6.ActionSchema {
+	if in == nil {
+		return nil
+	}
+	out := make(map[string]*tfprotov6.ActionSchema, len(in))
+	for k, v := range in {
+		out[k] = toV6ActionSchema(v)
+	}
+	return out
+}
+
+func toV6DataSourceMetadatas(in []tfprotov5.DataSourceMetadata) []tfprotov6.DataSourceMetadata {
+	if in == nil {
+		return nil
+	}
+	out := make([]tfprotov6.DataSourceMetadata, 0, len(in))
+	for _, e := range in {
+		out = append(out, *toV6DataSourceMetadata(&e))
+	}
+	return out
+}
+
+func toV6Diagnostics(in []*tfprotov5.Diagnostic) []*tfprotov6.Diagnostic {
+	if in == nil {
+		return nil
+	}
+	out := make([]*tfprotov6.Diagnostic, 0, len(in))
+	for _, e := range in {
+		out = append(out, toV6Diagnostic(e))
+	}
+	return out
+}
+
+func toV6EphemeralResourceMetadatas(in []tfprotov5.EphemeralResourceMetadata) []tfprotov6.EphemeralResourceMetadata {
+	if in == nil {
+		return nil
+	}
+	out := make([]tfprotov6.EphemeralResourceMetadata, 0, len(in))
+	for _, e := range in {
+		out = append(out, *toV6EphemeralResourceMetadata(&e))
+	}
+	return out
+}
+
+func toV6FunctionMap(in map[string]*tfprotov5.Function) map[string]*tfprotov6.Function {
+	if in == nil {
+		return nil
+	}
+	out := make(map[string]*tfprotov6.Function, len(in))
+	for k, v := range in {
+		out[k] = toV6Function(v)
+	}
+	return out
+}
+
+func toV6FunctionMetadatas(in []tfprotov5.FunctionMetadata) []tfprotov6.FunctionMetadata {
+	if in == nil {
+		return nil
+	}
+	out := make([]tfprotov6.FunctionMetadata, 0, len(in))
+	for _, e := range in {
+		out = append(out, *toV6FunctionMetadata(&e))
+	}
+	return out
+}
+
+func toV6FunctionParameters(in []*tfprotov5.FunctionParameter) []*tfprotov6.FunctionParameter {
+	if in == nil {
+		return nil
+	}
+	out := make([]*tfprotov6.FunctionParameter, 0, len(in))
+	for _, e := range in {
+		out = append(out, toV6FunctionParameter(e))
+	}
+	return out
+}
+
+func toV6ImportedResources(in []*tfprotov5.ImportedResource) []*tfprotov6.ImportedResource {
+	if in == nil {
+		return nil
+	}
+	out := make([]*tfprotov6.ImportedResource, 0, len(in))
+	for _, e := range in {
+		out = append(out, toV6ImportedResource(e))
+	}
+	return out
+}
+
+func toV6ListResourceMetadatas(in []tfprotov5.ListResourceMetadata) []tfprotov6.ListResourceMetadata {
+	if in == nil {
+		return nil
+	}
+	out := make([]tfprotov6.ListResourceMetadata, 0, len(in))
+	for _, e := range in {
+		out = append(out, *toV6ListResourceMetadata(&e))
+	}
+	return out
+}
+
+func toV6ResourceIdentitySchemaAttributes(in []*tfprotov5.ResourceIdentitySchemaAttribute) []*tfprotov6.ResourceIdentitySchemaAttribute {
+	if in == nil {
+		return nil
+	}
+	out := make([]*tfprotov6.ResourceIdentitySchemaAttribute, 0, len(in))
+	for _, e := range in {
+		out = append(out, toV6ResourceIdentitySchemaAttribute(e))
+	}
+	return out
+}
+
+func toV6ResourceIdentitySchemaMap(in map[string]*tfprotov5.ResourceIdentitySchema) map[string]*tfprotov6.ResourceIdentitySchema {
+	if in == nil {
+		return nil
+	}
+	out := make(map[string]*tfprotov6.ResourceIdentitySchema, len(in))
+	for k, v := range in {
+		out[k] = toV6ResourceIdentitySchema(v)
+	}
+	return out
+}
+
+func toV6ResourceMetadatas(in []tfprotov5.ResourceMetadata) []tfprotov6.ResourceMetadata {
+	if in == nil {
+		return nil
+	}
+	out := make([]tfprotov6.ResourceMetadata, 0, len(in))
+	for _, e := range in {
+		out = append(out, *toV6ResourceMetadata(&e))
+	}
+	return out
+}
+
+func toV6SchemaAttributes(in []*tfprotov5.SchemaAttribute) []*tfprotov6.SchemaAttribute {
+	if in == nil {
+		return nil
+	}
+	out := make([]*tfprotov6.SchemaAttribute, 0, len(in))
+	for _, e := range in {
+		out = append(out, toV6SchemaAttribute(e))
+	}
+	return out
+}
+
+func toV6SchemaMap(in map[string]*tfprotov5.Schema) map[string]*tfprotov6.Schema {
+	if in == nil {
+		return nil
+	}
+	out := make(map[string]*tfprotov6.Schema, len(in))
+	for k, v := range in {
+		out[k] = toV6Schema(v)
+	}
+	return out
+}
+
+func toV6SchemaNestedBlocks(in []*tfprotov5.SchemaNestedBlock) []*tfprotov6.SchemaNestedBlock {
+	if in == nil {
+		return nil
+	}
+	out := make([]*tfprotov6.SchemaNestedBlock, 0, len(in))
+	for _, e := range in {
+		out = append(out, toV6SchemaNestedBlock(e))
+	}
+	return out
+}
+
+func toV6ListResourceResult(in *tfprotov5.ListResourceResult) *tfprotov6.ListResourceResult {
+	if in == nil {
+		return nil
+	}
+	return &tfprotov6.ListResourceResult{
+		DisplayName: in.DisplayName,
+		Resource:    toV6DynamicValue(in.Resource),
+		Identity:    toV6ResourceIdentityData(in.Identity),
+		Diagnostics: toV6Diagnostics(in.Diagnostics),
+	}
+}
+
+func toV6InvokeActionEvent(in tfprotov5.InvokeActionEvent) tfprotov6.InvokeActionEvent {
+	var out tfprotov6.InvokeActionEvent
+	switch t := in.Type.(type) {
+	case tfprotov5.ProgressInvokeActionEventType:
+		out.Type = tfprotov6.ProgressInvokeActionEventType{
+			Message: t.Message,
+		}
+	case tfprotov5.CompletedInvokeActionEventType:
+		out.Type = tfprotov6.CompletedInvokeActionEventType{
+			Diagnostics: toV6Diagnostics(t.Diagnostics),
+		}
+	}
+	return out
+}
@@ -14,11 +14,10 @@ import (
 	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
 	"github.com/magodo/terraform-client-go/tfclient/configschema"
 	"github.com/magodo/terraform-client-go/tfclient/tfprotov6/convert"
+	"github.com/magodo/terraform-client-go/tfclient/tftypesconv"
 	"github.com/magodo/terraform-client-go/tfclient/typ"
 	"github.com/zclconf/go-cty/cty"
 	"github.com/zclconf/go-cty/cty/function"
-	ctyjson "github.com/zclconf/go-cty/cty/json"
-	"github.com/zclconf/go-cty/cty/msgpack"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 )
@@ -49,6 +48,8 @@ type Client struct {
 	configuredMu sync.Mutex
 }
 
+// New creates the client of the provider. The pluginClient is the plugin process managed by the client, which is
+// killed on Close. It can be nil if the provider is not run as a plugin, e.g. running in-process.
 func New(pluginClient *plugin.Client, grpcClient TFProtoV6Client, schema *typ.GetProviderSchemaResponse) (*Client, error) {
 	ctx := context.Background()
 	c := &Client{
@@ -161,7 +162,7 @@ func (c *Client) ValidateProviderConfig(ctx context.Context, request typ.Validat
 
 	ty := c.schemas.ProviderCty
 
-	mp, err := msgpack.Marshal(request.Config, ty)
+	mp, err := marshalMsgpack(request.Config, ty)
 	if err != nil {
 		diags = append(diags, typ.ErrorDiagnostics("msgpack marshal", err)...)
 		return nil, diags
@@ -203,7 +204,7 @@ func (c *Client) ValidateResourceConfig(ctx context.Context, request typ.Validat
 		return nil, diags
 	}
 
-	mp, err := msgpack.Marshal(request.Config, resourceTyp)
+	mp, err := marshalMsgpack(request.Config, resourceTyp)
 	if err != nil {
 		diags = append(diags, typ.ErrorDiagnostics("msgpack marshal", err)...)
 		return nil, diags
@@ -239,7 +240,7 @@ func (c *Client) ValidateDataResourceConfig(ctx context.Context, request typ.Val
 		return nil, diags
 	}
 
-	mp, err := msgpack.Marshal(request.Config, datasourceTyp)
+	mp, err := marshalMsgpack(request.Config, datasourceTyp)
 	if err != nil {
 		diags = append(diags, typ.ErrorDiagnostics("msgpack marshal", err)...)
 		return nil, diags
@@ -322,7 +323,7 @@ func (c *Client) ConfigureProvider(ctx context.Context, request typ.ConfigurePro
 	var diags typ.Diagnostics
 
 	schema := c.schemas
-	mp, err := msgpack.Marshal(
+	mp, err := marshalMsgpack(
 		request.Config,
 		schema.ProviderCty,
 	)
@@ -383,7 +384,7 @@ func (c *Client) ReadResource(ctx context.Context, request typ.ReadResourceReque
 
 	metaTyp := schema.ProviderMetaCty
 
-	mp, err := msgpack.Marshal(request.PriorState, resTyp)
+	mp, err := marshalMsgpack(request.PriorState, resTyp)
 	if err != nil {
 		diags = append(diags, typ.ErrorDiagnostics("msgpack marshal", err)...)
 		return nil, diags
@@ -401,7 +402,7 @@ func (c *Client) ReadResource(ctx context.Context, request typ.ReadResourceReque
 	// The second check here is not something from terraform's implementation, should be derived from the schema drift in tfjson module.
 	//if metaSchema.Block != nil && len(metaSchema.Block.NestedBlocks)+len(metaSchema.Block.Attributes) != 0 {
 	if !metaTyp.Equals(cty.EmptyObject) {
-		metaMP, err := msgpack.Marshal(request.ProviderMeta, metaTyp)
+		metaMP, err := marshalMsgpack(request.ProviderMeta, metaTyp)
 		if err != nil {
 			diags = append(diags, typ.ErrorDiagnostics("msgpack marshal", err)...)
 			return nil, diags
@@ -414,7 +415,7 @@ func (c *Client) ReadResource(ctx context.Context, request typ.ReadResourceReque
 			diags = append(diags, typ.ErrorDiagnostics("identity type not found", fmt.Errorf("identity type not found for resoruce type %s", request.TypeName))...)
 			return nil, diags
 		}
-		currentIdentityMP, err := msgpack.Marshal(request.CurrentIdentity, configschema.SchemaNestedAttributeTypeImpliedType(resSchema.Identity))
+		currentIdentityMP, err := marshalMsgpack(request.CurrentIdentity, configschema.SchemaNestedAttributeTypeImpliedType(resSchema.Identity))
 		if err != nil {
 			diags = append(diags, typ.ErrorDiagnostics("msgpach marshal", err)...)
 			return nil, diags
@@ -492,19 +493,19 @@ func (c *Client) PlanResourceChange(ctx context.Context, request typ.PlanResourc
 		return &resp, nil
 	}
 
-	priorMP, err := msgpack.Marshal(request.PriorState, resTyp)
+	priorMP, err := marshalMsgpack(request.PriorState, resTyp)
 	if err != nil {
 		diags = append(diags, typ.ErrorDiagnostics("msgpack marshal", err)...)
 		return nil, diags
 	}
 
-	configMP, err := msgpack.Marshal(request.Config, resTyp)
+	configMP, err := marshalMsgpack(request.Config, resTyp)
 	if err != nil {
 		diags = append(diags, typ.ErrorDiagnostics("msgpack marshal", err)...)
 		return nil, diags
 	}
 
-	propMP, err := msgpack.Marshal(request.ProposedNewState, resTyp)
+	propMP, err := marshalMsgpack(request.ProposedNewState, resTyp)
 	if err != nil {
 		diags = append(diags, typ.ErrorDiagnostics("msgpack marshal", err)...)
 		return nil, diags
@@ -528,7 +529,7 @@ func (c *Client) PlanResourceChange(ctx context.Context, request typ.PlanResourc
 		if metaVal == cty.NilVal {
 			metaVal = cty.NullVal(metaTyp)
 		}
-		metaMP, err := msgpack.Marshal(metaVal, metaTyp)
+		metaMP, err := marshalMsgpack(metaVal, metaTyp)
 		if err != nil {
 			diags = append(diags, typ.ErrorDiagnostics("msgpack marshal", err)...)
 			return nil, diags
@@ -541,7 +542,7 @@ func (c *Client) PlanResourceChange(ctx context.Context, request typ.PlanResourc
 			diags = append(diags, typ.ErrorDiagnostics("identity type not found", fmt.Errorf("identity type not found for resoruce type %s", request.TypeName))...)
 			return nil, diags
 		}
-		priorIdentityMP, err := msgpack.Marshal(request.PriorIdentity, configschema.SchemaNestedAttributeTypeImpliedType(resSchema.Identity))
+		priorIdentityMP, err := marshalMsgpack(request.PriorIdentity, configschema.SchemaNestedAttributeTypeImpliedType(resSchema.Identity))
 		if err != nil {
 			diags = append(diags, typ.ErrorDiagnostics("msgpach marshal", err)...)
 			return nil, diags
@@ -612,17 +613,17 @@ func (c *Client) ApplyResourceChange(ctx context.Context, request typ.ApplyResou
 
 	metaTyp := schema.ProviderMetaCty
 
-	priorMP, err := msgpack.Marshal(request.PriorState, resTyp)
+	priorMP, err := marshalMsgpack(request.PriorState, resTyp)
 	if err != nil {
 		diags = append(diags, typ.ErrorDiagnostics("msgpack marshal", err)...)
 		return nil, diags
 	}
-	plannedMP, err := msgpack.Marshal(request.PlannedState, resTyp)
+	plannedMP, err := marshalMsgpack(request.PlannedState, resTyp)
 	if err != nil {
 		diags = append(diags, typ.ErrorDiagnostics("msgpack marshal", err)...)
 		return nil, diags
 	}
-	configMP, err := msgpack.Marshal(request.Config, resTyp)
+	configMP, err := marshalMsgpack(request.Config, resTyp)
 	if err != nil {
 		diags = append(diags, typ.ErrorDiagnostics("msgpack marshal", err)...)
 		return nil, diags
@@ -643,7 +644,7 @@ func (c *Client) ApplyResourceChange(ctx context.Context, request typ.ApplyResou
 		if metaVal == cty.NilVal {
 			metaVal = cty.NullVal(metaTyp)
 		}
-		metaMP, err := msgpack.Marshal(metaVal, metaTyp)
+		metaMP, err := marshalMsgpack(metaVal, metaTyp)
 		if err != nil {
 			diags = append(diags, typ.ErrorDiagnostics("msgpack marshal", err)...)
 			return nil, diags
@@ -656,7 +657,7 @@ func (c *Client) ApplyResourceChange(ctx context.Context, request typ.ApplyResou
 			diags = append(diags, typ.ErrorDiagnostics("identity type not found", fmt.Errorf("identity type not found for resoruce type %s", request.TypeName))...)
 			return nil, diags
 		}
-		currentIdentityMP, err := msgpack.Marshal(request.PlannedIdentity, configschema.SchemaNestedAttributeTypeImpliedType(resSchema.Identity))
+		currentIdentityMP, err := marshalMsgpack(request.PlannedIdentity, configschema.SchemaNestedAttributeTypeImpliedType(resSchema.Identity))
 		if err != nil {
 			diags = append(diags, typ.ErrorDiagnostics("msgpach marshal", err)...)
 			return nil, diags
@@ -728,7 +729,7 @@ func (c *Client) ImportResourceState(ctx context.Context, request typ.ImportReso
 			diags = append(diags, typ.ErrorDiagnostics("identity type not found", fmt.Errorf("identity type not found for resoruce type %s", request.TypeName))...)
 			return nil, diags
 		}
-		mp, err := msgpack.Marshal(request.Identity, configschema.SchemaNestedAttributeTypeImpliedType(resSchema.Identity))
+		mp, err := marshalMsgpack(request.Identity, configschema.SchemaNestedAttributeTypeImpliedType(resSchema.Identity))
 		if err != nil {
 			diags = append(diags, typ.ErrorDiagnostics("msgpach marshal", err)...)
 			return nil, diags
@@ -865,7 +866,7 @@ func (c *Client) ReadDataSource(ctx context.Context, request typ.ReadDataSourceR
 
 	metaTyp := schema.ProviderMetaCty
 
-	mp, err := msgpack.Marshal(request.Config, dstTyp)
+	mp, err := marshalMsgpack(request.Config, dstTyp)
 	if err != nil {
 		diags = append(diags, typ.ErrorDiagnostics("msgpack marshal", err)...)
 		return nil, diags
@@ -882,7 +883,7 @@ func (c *Client) ReadDataSource(ctx context.Context, request typ.ReadDataSourceR
 	// The second check here is not something from terraform's implementation, should be derived from the schema drift in tfjson module.
 	//if metaTyp.Block != nil && len(metaTyp.Block.NestedBlocks)+len(metaTyp.Block.Attributes) != 0 {
 	if !metaTyp.Equals(cty.EmptyObject) {
-		metaMP, err := msgpack.Marshal(request.ProviderMeta, metaTyp)
+		metaMP, err := marshalMsgpack(request.ProviderMeta, metaTyp)
 		if err != nil {
 			diags = append(diags, typ.ErrorDiagnostics("msgpack marshal", err)...)
 			return nil, diags
@@ -940,7 +941,7 @@ func (c *Client) CallFunction(ctx context.Context, request typ.CallFunctionReque
 			paramDecl = *funcDecl.VariadicParameter
 		}
 
-		argValRaw, err := msgpack.Marshal(argVal, paramDecl.Type)
+		argValRaw, err := marshalMsgpack(argVal, paramDecl.Type)
 		if err != nil {
 			diags = append(diags, typ.ErrorDiagnostics("call function error", fmt.Errorf("marshal argument: %v", err))...)
 			return nil, diags
@@ -1074,7 +1075,7 @@ func (c *Client) ValidateEphemeralResourceConfig(ctx context.Context, request ty
 		return diags
 	}
 
-	mp, err := msgpack.Marshal(request.Config, ephemSchema)
+	mp, err := marshalMsgpack(request.Config, ephemSchema)
 	if err != nil {
 		diags = append(diags, typ.ErrorDiagnostics("msgpack marshal", err)...)
 		return diags
@@ -1111,7 +1112,7 @@ func (c *Client) OpenEphemeralResource(ctx context.Context, request typ.OpenEphe
 		return nil, diags
 	}
 
-	mp, err := msgpack.Marshal(request.Config, ephemSchema)
+	mp, err := marshalMsgpack(request.Config, ephemSchema)
 	if err != nil {
 		diags = append(diags, typ.ErrorDiagnostics("msgpack marshal", err)...)
 		return nil, diags
@@ -1217,7 +1218,7 @@ func (c *Client) ValidateListResourceConfig(ctx context.Context, req typ.Validat
 
 	configSchema := lsch.Block.NestedBlocks["config"]
 	config := req.Config.GetAttr("config")
-	mp, err := msgpack.Marshal(config, configschema.SchemaBlockImpliedType(configSchema.Block))
+	mp, err := marshalMsgpack(config, configschema.SchemaBlockImpliedType(configSchema.Block))
 	if err != nil {
 		return typ.ErrorDiagnostics("msgpack marshal", err)
 	}
@@ -1263,7 +1264,7 @@ func (c *Client) ListResource(ctx context.Context, req typ.ListResourceRequest)
 	}
 
 	config := req.Config.GetAttr("config")
-	mp, err := msgpack.Marshal(config, configschema.SchemaBlockImpliedType(listSchema.Block))
+	mp, err := marshalMsgpack(config, configschema.SchemaBlockImpliedType(listSchema.Block))
 	if err != nil {
 		diags = append(diags, typ.ErrorDiagnostics("msgpack marshal", err)...)
 	}
@@ -1300,13 +1301,15 @@ func (c *Client) ListResource(ctx context.Context, req typ.ListResourceRequest)
 			return diag != nil && diag.Severity == tfprotov6.DiagnosticSeverityError
 		}) {
 			// If we have errors, we stop processing and return early
+			diags = append(diags, convert.DecodeDiagnostics(event.Diagnostics)...)
 			break
 		}
 
 		if slices.ContainsFunc(event.Diagnostics, func(diag *tfprotov6.Diagnostic) bool {
 			return diag != nil && diag.Severity == tfprotov6.DiagnosticSeverityWarning
-		}) && event.Identity.IdentityData == nil {
+		}) && (event.Identity == nil || event.Identity.IdentityData == nil) {
 			// If we have warnings but no identity data, we continue with the next event
+			diags = append(diags, convert.DecodeDiagnostics(event.Diagnostics)...)
 			break
 		}
 
@@ -1367,7 +1370,7 @@ func (c *Client) ValidateActionConfig(ctx context.Context, req typ.ValidateActio
 		return
 	}
 
-	mp, err := msgpack.Marshal(req.Config, actionSchema)
+	mp, err := marshalMsgpack(req.Config, actionSchema)
 	if err != nil {
 		diags = append(diags, typ.ErrorDiagnostics("msgpack marshal", err)...)
 		return
@@ -1402,7 +1405,7 @@ func (c *Client) PlanAction(ctx context.Context, req typ.PlanActionRequest) (res
 		return
 	}
 
-	mp, err := msgpack.Marshal(req.ProposedActionData, actionSchema)
+	mp, err := marshalMsgpack(req.ProposedActionData, actionSchema)
 	if err != nil {
 		diags = append(diags, typ.ErrorDiagnostics("msgpack marshal", err)...)
 		return
@@ -1446,7 +1449,7 @@ func (c *Client) InvokeAction(ctx context.Context, req typ.InvokeActionRequest)
 		return
 	}
 
-	mp, err := msgpack.Marshal(req.PlannedActionData, actionSchema)
+	mp, err := marshalMsgpack(req.PlannedActionData, actionSchema)
 	if err != nil {
 		diags = append(diags, typ.ErrorDiagnostics("msgpack marshal", err)...)
 		return
@@ -1484,25 +1487,20 @@ func (c *Client) InvokeAction(ctx context.Context, req typ.InvokeActionRequest)
 	return
 }
 
+// Close kills the provider process, if it is managed by the client.
 func (c *Client) Close() {
-	c.pluginClient.Kill()
+	if c.pluginClient != nil {
+		c.pluginClient.Kill()
+	}
 }
 
 // Decode a DynamicValue from either the JSON or MsgPack encoding.
-// Derived from github.com/hashicorp/terraform/internal/plugin6/grpc_provider.go (15ecdb66c84cd8202b0ae3d34c44cb4bbece5444)
 func decodeDynamicValue(v *tfprotov6.DynamicValue, ty cty.Type) (cty.Value, error) {
-	// always return a valid value
-	var err error
-	res := cty.NullVal(ty)
-	if v == nil {
-		return res, nil
-	}
+	return tftypesconv.DecodeV6DynamicValue(v, ty)
+}
 
-	switch {
-	case len(v.MsgPack) > 0:
-		res, err = msgpack.Unmarshal(v.MsgPack, ty)
-	case len(v.JSON) > 0:
-		res, err = ctyjson.Unmarshal(v.JSON, ty)
-	}
-	return res, err
+// marshalMsgpack encodes the value in msgpack, where the marks (e.g. the sensitive marks) are removed first as they
+// can't be encoded.
+func marshalMsgpack(val cty.Value, ty cty.Type) ([]byte, error) {
+	return tftypesconv.MarshalMsgpack(val, ty)
 }
@@ -0,0 +1,36 @@
+package tf6server
+
+import (
+	"context"
+	"errors"
+	"net/rpc"
+
+	"github.com/hashicorp/go-plugin"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/magodo/terraform-client-go/tfclient/tfprotov6/internal/tfplugin6"
+	"google.golang.org/grpc"
+)
+
+// GRPCServerPlugin serves the provider server as a gRPC plugin for go-plugin.
+type GRPCServerPlugin struct {
+	plugin.Plugin
+
+	Provider tfprotov6.ProviderServer
+}
+
+func (p *GRPCServerPlugin) Server(*plugin.MuxBroker) (interface{}, error) {
+	return nil, errors.New("terraform-client-go only implements gRPC servers")
+}
+
+func (p *GRPCServerPlugin) Client(*plugin.MuxBroker, *rpc.Client) (interface{}, error) {
+	return nil, errors.New("terraform-client-go only implements gRPC servers")
+}
+
+func (p *GRPCServerPlugin) GRPCClient(context.Context, *plugin.GRPCBroker, *grpc.ClientConn) (interface{}, error) {
+	return nil, errors.New("terraform-client-go only implements gRPC servers")
+}
+
+func (p *GRPCServerPlugin) GRPCServer(broker *plugin.GRPCBroker, s *grpc.Server) error {
+	tfplugin6.RegisterProviderServer(s, New(p.Provider))
+	return nil
+}
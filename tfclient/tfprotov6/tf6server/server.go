@@ -0,0 +1,407 @@
+// This is derived from github.com/hashicorp/terraform-plugin-go/tfprotov6/tf6server/server.go, which can't be
+// linked together with this module, as both register the same protobuf file.
+
+package tf6server
+
+import (
+	"context"
+	"sync"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/go-plugin"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/magodo/terraform-client-go/tfclient/tfprotov6/internal/fromproto"
+	"github.com/magodo/terraform-client-go/tfclient/tfprotov6/internal/tfplugin6"
+	"github.com/magodo/terraform-client-go/tfclient/tfprotov6/internal/toproto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// grpcMaxMessageSize is the maximum gRPC send and receive message sizes, which is the same as Terraform.
+const grpcMaxMessageSize = 256 << 20
+
+type ServeOption struct {
+	// Logger is the logger of go-plugin. If none is provided, it will default to hclog's default logger.
+	Logger hclog.Logger
+
+	// Test, if set, serves the provider in the unmanaged mode, e.g. for Terraform to attach to it via
+	// the TF_REATTACH_PROVIDERS. The reattach config is sent to the Test.ReattachConfigCh, and Serve
+	// returns once the Test.Context is done.
+	Test *plugin.ServeTestConfig
+}
+
+// Serve serves the provider server over go-plugin, in protocol v6.
+func Serve(provider tfprotov6.ProviderServer, opts ServeOption) {
+	plugin.Serve(&plugin.ServeConfig{
+		// Reference: https://github.com/hashicorp/terraform/blob/a9230c9e7582c353c224cf0f4832d472ce042c0d/internal/plugin/serve.go#L22
+		HandshakeConfig: plugin.HandshakeConfig{
+			ProtocolVersion:  6,
+			MagicCookieKey:   "TF_PLUGIN_MAGIC_COOKIE",
+			MagicCookieValue: "d602bf8f470bc67ca7faa0386276bbdd4330efaf76d1a219cb4d6991ca9872b2",
+		},
+		Plugins: plugin.PluginSet{
+			"provider": &GRPCServerPlugin{Provider: provider},
+		},
+		GRPCServer: func(opts []grpc.ServerOption) *grpc.Server {
+			opts = append(opts, grpc.MaxRecvMsgSize(grpcMaxMessageSize), grpc.MaxSendMsgSize(grpcMaxMessageSize))
+			return grpc.NewServer(opts...)
+		},
+		Logger: opts.Logger,
+		Test:   opts.Test,
+	})
+}
+
+type server struct {
+	tfplugin6.UnimplementedProviderServer
+
+	downstream tfprotov6.ProviderServer
+
+	// stopCh is closed on Stop, to cancel the contexts of the in flight calls.
+	stopCh chan struct{}
+	stopMu sync.Mutex
+}
+
+// New returns the gRPC server of the provider server. The ListResourceServer and ActionServer are
+// optional, their RPCs return the Unimplemented error if the provider server doesn't implement them.
+func New(provider tfprotov6.ProviderServer) tfplugin6.ProviderServer {
+	return &server{
+		downstream: provider,
+		stopCh:     make(chan struct{}),
+	}
+}
+
+func (s *server) stoppableContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	s.stopMu.Lock()
+	stopCh := s.stopCh
+	s.stopMu.Unlock()
+
+	ctx, cancel := context.WithCancel(ctx)
+	go func() {
+		select {
+		case <-stopCh:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	return ctx, cancel
+}
+
+func (s *server) stop() {
+	s.stopMu.Lock()
+	defer s.stopMu.Unlock()
+
+	close(s.stopCh)
+	s.stopCh = make(chan struct{})
+}
+
+func (s *server) GetMetadata(ctx context.Context, req *tfplugin6.GetMetadata_Request) (*tfplugin6.GetMetadata_Response, error) {
+	ctx, cancel := s.stoppableContext(ctx)
+	defer cancel()
+	resp, err := s.downstream.GetMetadata(ctx, fromproto.GetMetadataRequest(req))
+	if err != nil {
+		return nil, err
+	}
+	return toproto.GetMetadata_Response(resp), nil
+}
+
+func (s *server) GetProviderSchema(ctx context.Context, req *tfplugin6.GetProviderSchema_Request) (*tfplugin6.GetProviderSchema_Response, error) {
+	ctx, cancel := s.stoppableContext(ctx)
+	defer cancel()
+	resp, err := s.downstream.GetProviderSchema(ctx, fromproto.GetProviderSchemaRequest(req))
+	if err != nil {
+		return nil, err
+	}
+	return toproto.GetProviderSchema_Response(resp), nil
+}
+
+func (s *server) GetResourceIdentitySchemas(ctx context.Context, req *tfplugin6.GetResourceIdentitySchemas_Request) (*tfplugin6.GetResourceIdentitySchemas_Response, error) {
+	ctx, cancel := s.stoppableContext(ctx)
+	defer cancel()
+	resp, err := s.downstream.GetResourceIdentitySchemas(ctx, fromproto.GetResourceIdentitySchemasRequest(req))
+	if err != nil {
+		return nil, err
+	}
+	return toproto.GetResourceIdentitySchemas_Response(resp), nil
+}
+
+func (s *server) ValidateProviderConfig(ctx context.Context, req *tfplugin6.ValidateProviderConfig_Request) (*tfplugin6.ValidateProviderConfig_Response, error) {
+	ctx, cancel := s.stoppableContext(ctx)
+	defer cancel()
+	resp, err := s.downstream.ValidateProviderConfig(ctx, fromproto.ValidateProviderConfigRequest(req))
+	if err != nil {
+		return nil, err
+	}
+	return toproto.ValidateProviderConfig_Response(resp), nil
+}
+
+func (s *server) ValidateResourceConfig(ctx context.Context, req *tfplugin6.ValidateResourceConfig_Request) (*tfplugin6.ValidateResourceConfig_Response, error) {
+	ctx, cancel := s.stoppableContext(ctx)
+	defer cancel()
+	resp, err := s.downstream.ValidateResourceConfig(ctx, fromproto.ValidateResourceConfigRequest(req))
+	if err != nil {
+		return nil, err
+	}
+	return toproto.ValidateResourceConfig_Response(resp), nil
+}
+
+func (s *server) ValidateDataResourceConfig(ctx context.Context, req *tfplugin6.ValidateDataResourceConfig_Request) (*tfplugin6.ValidateDataResourceConfig_Response, error) {
+	ctx, cancel := s.stoppableContext(ctx)
+	defer cancel()
+	resp, err := s.downstream.ValidateDataResourceConfig(ctx, fromproto.ValidateDataResourceConfigRequest(req))
+	if err != nil {
+		return nil, err
+	}
+	return toproto.ValidateDataResourceConfig_Response(resp), nil
+}
+
+func (s *server) UpgradeResourceState(ctx context.Context, req *tfplugin6.UpgradeResourceState_Request) (*tfplugin6.UpgradeResourceState_Response, error) {
+	ctx, cancel := s.stoppableContext(ctx)
+	defer cancel()
+	resp, err := s.downstream.UpgradeResourceState(ctx, fromproto.UpgradeResourceStateRequest(req))
+	if err != nil {
+		return nil, err
+	}
+	return toproto.UpgradeResourceState_Response(resp), nil
+}
+
+func (s *server) UpgradeResourceIdentity(ctx context.Context, req *tfplugin6.UpgradeResourceIdentity_Request) (*tfplugin6.UpgradeResourceIdentity_Response, error) {
+	ctx, cancel := s.stoppableContext(ctx)
+	defer cancel()
+	resp, err := s.downstream.UpgradeResourceIdentity(ctx, fromproto.UpgradeResourceIdentityRequest(req))
+	if err != nil {
+		return nil, err
+	}
+	return toproto.UpgradeResourceIdentity_Response(resp), nil
+}
+
+func (s *server) ConfigureProvider(ctx context.Context, req *tfplugin6.ConfigureProvider_Request) (*tfplugin6.ConfigureProvider_Response, error) {
+	ctx, cancel := s.stoppableContext(ctx)
+	defer cancel()
+	resp, err := s.downstream.ConfigureProvider(ctx, fromproto.ConfigureProviderRequest(req))
+	if err != nil {
+		return nil, err
+	}
+	return toproto.ConfigureProvider_Response(resp), nil
+}
+
+func (s *server) ReadResource(ctx context.Context, req *tfplugin6.ReadResource_Request) (*tfplugin6.ReadResource_Response, error) {
+	ctx, cancel := s.stoppableContext(ctx)
+	defer cancel()
+	resp, err := s.downstream.ReadResource(ctx, fromproto.ReadResourceRequest(req))
+	if err != nil {
+		return nil, err
+	}
+	return toproto.ReadResource_Response(resp), nil
+}
+
+func (s *server) PlanResourceChange(ctx context.Context, req *tfplugin6.PlanResourceChange_Request) (*tfplugin6.PlanResourceChange_Response, error) {
+	ctx, cancel := s.stoppableContext(ctx)
+	defer cancel()
+	resp, err := s.downstream.PlanResourceChange(ctx, fromproto.PlanResourceChangeRequest(req))
+	if err != nil {
+		return nil, err
+	}
+	return toproto.PlanResourceChange_Response(resp), nil
+}
+
+func (s *server) ApplyResourceChange(ctx context.Context, req *tfplugin6.ApplyResourceChange_Request) (*tfplugin6.ApplyResourceChange_Response, error) {
+	ctx, cancel := s.stoppableContext(ctx)
+	defer cancel()
+	resp, err := s.downstream.ApplyResourceChange(ctx, fromproto.ApplyResourceChangeRequest(req))
+	if err != nil {
+		return nil, err
+	}
+	return toproto.ApplyResourceChange_Response(resp), nil
+}
+
+func (s *server) ImportResourceState(ctx context.Context, req *tfplugin6.ImportResourceState_Request) (*tfplugin6.ImportResourceState_Response, error) {
+	ctx, cancel := s.stoppableContext(ctx)
+	defer cancel()
+	resp, err := s.downstream.ImportResourceState(ctx, fromproto.ImportResourceStateRequest(req))
+	if err != nil {
+		return nil, err
+	}
+	return toproto.ImportResourceState_Response(resp), nil
+}
+
+func (s *server) MoveResourceState(ctx context.Context, req *tfplugin6.MoveResourceState_Request) (*tfplugin6.MoveResourceState_Response, error) {
+	ctx, cancel := s.stoppableContext(ctx)
+	defer cancel()
+	resp, err := s.downstream.MoveResourceState(ctx, fromproto.MoveResourceStateRequest(req))
+	if err != nil {
+		return nil, err
+	}
+	return toproto.MoveResourceState_Response(resp), nil
+}
+
+func (s *server) ReadDataSource(ctx context.Context, req *tfplugin6.ReadDataSource_Request) (*tfplugin6.ReadDataSource_Response, error) {
+	ctx, cancel := s.stoppableContext(ctx)
+	defer cancel()
+	resp, err := s.downstream.ReadDataSource(ctx, fromproto.ReadDataSourceRequest(req))
+	if err != nil {
+		return nil, err
+	}
+	return toproto.ReadDataSource_Response(resp), nil
+}
+
+func (s *server) ValidateEphemeralResourceConfig(ctx context.Context, req *tfplugin6.ValidateEphemeralResourceConfig_Request) (*tfplugin6.ValidateEphemeralResourceConfig_Response, error) {
+	ctx, cancel := s.stoppableContext(ctx)
+	defer cancel()
+	resp, err := s.downstream.ValidateEphemeralResourceConfig(ctx, fromproto.ValidateEphemeralResourceConfigRequest(req))
+	if err != nil {
+		return nil, err
+	}
+	return toproto.ValidateEphemeralResourceConfig_Response(resp), nil
+}
+
+func (s *server) OpenEphemeralResource(ctx context.Context, req *tfplugin6.OpenEphemeralResource_Request) (*tfplugin6.OpenEphemeralResource_Response, error) {
+	ctx, cancel := s.stoppableContext(ctx)
+	defer cancel()
+	resp, err := s.downstream.OpenEphemeralResource(ctx, fromproto.OpenEphemeralResourceRequest(req))
+	if err != nil {
+		return nil, err
+	}
+	return toproto.OpenEphemeralResource_Response(resp), nil
+}
+
+func (s *server) RenewEphemeralResource(ctx context.Context, req *tfplugin6.RenewEphemeralResource_Request) (*tfplugin6.RenewEphemeralResource_Response, error) {
+	ctx, cancel := s.stoppableContext(ctx)
+	defer cancel()
+	resp, err := s.downstream.RenewEphemeralResource(ctx, fromproto.RenewEphemeralResourceRequest(req))
+	if err != nil {
+		return nil, err
+	}
+	return toproto.RenewEphemeralResource_Response(resp), nil
+}
+
+func (s *server) CloseEphemeralResource(ctx context.Context, req *tfplugin6.CloseEphemeralResource_Request) (*tfplugin6.CloseEphemeralResource_Response, error) {
+	ctx, cancel := s.stoppableContext(ctx)
+	defer cancel()
+	resp, err := s.downstream.CloseEphemeralResource(ctx, fromproto.CloseEphemeralResourceRequest(req))
+	if err != nil {
+		return nil, err
+	}
+	return toproto.CloseEphemeralResource_Response(resp), nil
+}
+
+func (s *server) ValidateListResourceConfig(ctx context.Context, req *tfplugin6.ValidateListResourceConfig_Request) (*tfplugin6.ValidateListResourceConfig_Response, error) {
+	downstream, ok := s.downstream.(tfprotov6.ListResourceServer)
+	if !ok {
+		return nil, status.Error(codes.Unimplemented, "ProviderServer does not implement ValidateListResourceConfig")
+	}
+	ctx, cancel := s.stoppableContext(ctx)
+	defer cancel()
+	resp, err := downstream.ValidateListResourceConfig(ctx, fromproto.ValidateListResourceConfigRequest(req))
+	if err != nil {
+		return nil, err
+	}
+	return toproto.ValidateListResourceConfig_Response(resp), nil
+}
+
+func (s *server) ListResource(req *tfplugin6.ListResource_Request, stream tfplugin6.Provider_ListResourceServer) error {
+	downstream, ok := s.downstream.(tfprotov6.ListResourceServer)
+	if !ok {
+		return status.Error(codes.Unimplemented, "ProviderServer does not implement ListResource")
+	}
+	ctx, cancel := s.stoppableContext(stream.Context())
+	defer cancel()
+	resp, err := downstream.ListResource(ctx, fromproto.ListResourceRequest(req))
+	if err != nil {
+		return err
+	}
+	if resp == nil || resp.Results == nil {
+		return nil
+	}
+	for ev := range resp.Results {
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err := stream.Send(toproto.ListResource_ListResourceEvent(&ev)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *server) GetFunctions(ctx context.Context, req *tfplugin6.GetFunctions_Request) (*tfplugin6.GetFunctions_Response, error) {
+	ctx, cancel := s.stoppableContext(ctx)
+	defer cancel()
+	resp, err := s.downstream.GetFunctions(ctx, fromproto.GetFunctionsRequest(req))
+	if err != nil {
+		return nil, err
+	}
+	return toproto.GetFunctions_Response(resp), nil
+}
+
+func (s *server) CallFunction(ctx context.Context, req *tfplugin6.CallFunction_Request) (*tfplugin6.CallFunction_Response, error) {
+	ctx, cancel := s.stoppableContext(ctx)
+	defer cancel()
+	resp, err := s.downstream.CallFunction(ctx, fromproto.CallFunctionRequest(req))
+	if err != nil {
+		return nil, err
+	}
+	return toproto.CallFunction_Response(resp), nil
+}
+
+func (s *server) ValidateActionConfig(ctx context.Context, req *tfplugin6.ValidateActionConfig_Request) (*tfplugin6.ValidateActionConfig_Response, error) {
+	downstream, ok := s.downstream.(tfprotov6.ActionServer)
+	if !ok {
+		return nil, status.Error(codes.Unimplemented, "ProviderServer does not implement ValidateActionConfig")
+	}
+	ctx, cancel := s.stoppableContext(ctx)
+	defer cancel()
+	resp, err := downstream.ValidateActionConfig(ctx, fromproto.ValidateActionConfigRequest(req))
+	if err != nil {
+		return nil, err
+	}
+	return toproto.ValidateActionConfig_Response(resp), nil
+}
+
+func (s *server) PlanAction(ctx context.Context, req *tfplugin6.PlanAction_Request) (*tfplugin6.PlanAction_Response, error) {
+	downstream, ok := s.downstream.(tfprotov6.ActionServer)
+	if !ok {
+		return nil, status.Error(codes.Unimplemented, "ProviderServer does not implement PlanAction")
+	}
+	ctx, cancel := s.stoppableContext(ctx)
+	defer cancel()
+	resp, err := downstream.PlanAction(ctx, fromproto.PlanActionRequest(req))
+	if err != nil {
+		return nil, err
+	}
+	return toproto.PlanAction_Response(resp), nil
+}
+
+func (s *server) InvokeAction(req *tfplugin6.InvokeAction_Request, stream tfplugin6.Provider_InvokeActionServer) error {
+	downstream, ok := s.downstream.(tfprotov6.ActionServer)
+	if !ok {
+		return status.Error(codes.Unimplemented, "ProviderServer does not implement InvokeAction")
+	}
+	ctx, cancel := s.stoppableContext(stream.Context())
+	defer cancel()
+	resp, err := downstream.InvokeAction(ctx, fromproto.InvokeActionRequest(req))
+	if err != nil {
+		return err
+	}
+	if resp == nil || resp.Events == nil {
+		return nil
+	}
+	for ev := range resp.Events {
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err := stream.Send(toproto.InvokeAction_InvokeActionEvent(&ev)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *server) StopProvider(ctx context.Context, req *tfplugin6.StopProvider_Request) (*tfplugin6.StopProvider_Response, error) {
+	resp, err := s.downstream.StopProvider(ctx, fromproto.StopProviderRequest(req))
+	if err != nil {
+		return nil, err
+	}
+	s.stop()
+	return toproto.StopProvider_Response(resp), nil
+}
@@ -0,0 +1,69 @@
+package tftypesconv
+
+import (
+	"github.com/hashicorp/terraform-plugin-go/tfprotov5"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/zclconf/go-cty/cty"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
+	"github.com/zclconf/go-cty/cty/msgpack"
+)
+
+// MarshalMsgpack encodes the value of the type in msgpack, which keeps the refinements of the unknown values. The
+// marks (e.g. the sensitive marks) are removed first as they can't be encoded.
+//
+// The type is the implied type of the schema, e.g. configschema.SchemaBlockImpliedType for a resource, or
+// configschema.SchemaNestedAttributeTypeImpliedType for a resource identity.
+func MarshalMsgpack(val cty.Value, ty cty.Type) ([]byte, error) {
+	val, _ = val.UnmarkDeep()
+	return msgpack.Marshal(val, ty)
+}
+
+// EncodeV5DynamicValue encodes the value of the type as a protocol v5 DynamicValue. See MarshalMsgpack.
+func EncodeV5DynamicValue(val cty.Value, ty cty.Type) (*tfprotov5.DynamicValue, error) {
+	b, err := MarshalMsgpack(val, ty)
+	if err != nil {
+		return nil, err
+	}
+	return &tfprotov5.DynamicValue{MsgPack: b}, nil
+}
+
+// DecodeV5DynamicValue decodes the protocol v5 DynamicValue, encoded in either msgpack or JSON, as a value of the
+// type. A nil or empty DynamicValue is decoded as null.
+func DecodeV5DynamicValue(v *tfprotov5.DynamicValue, ty cty.Type) (cty.Value, error) {
+	if v == nil {
+		return cty.NullVal(ty), nil
+	}
+	return decodeDynamicValue(v.MsgPack, v.JSON, ty)
+}
+
+// EncodeV6DynamicValue encodes the value of the type as a protocol v6 DynamicValue. See MarshalMsgpack.
+func EncodeV6DynamicValue(val cty.Value, ty cty.Type) (*tfprotov6.DynamicValue, error) {
+	b, err := MarshalMsgpack(val, ty)
+	if err != nil {
+		return nil, err
+	}
+	return &tfprotov6.DynamicValue{MsgPack: b}, nil
+}
+
+// DecodeV6DynamicValue decodes the protocol v6 DynamicValue, encoded in either msgpack or JSON, as a value of the
+// type. A nil or empty DynamicValue is decoded as null.
+func DecodeV6DynamicValue(v *tfprotov6.DynamicValue, ty cty.Type) (cty.Value, error) {
+	if v == nil {
+		return cty.NullVal(ty), nil
+	}
+	return decodeDynamicValue(v.MsgPack, v.JSON, ty)
+}
+
+// Derived from github.com/hashicorp/terraform/internal/plugin/grpc_provider.go (15ecdb66c84cd8202b0ae3d34c44cb4bbece5444)
+func decodeDynamicValue(mp, js []byte, ty cty.Type) (cty.Value, error) {
+	// always return a valid value
+	var err error
+	res := cty.NullVal(ty)
+	switch {
+	case len(mp) > 0:
+		res, err = msgpack.Unmarshal(mp, ty)
+	case len(js) > 0:
+		res, err = ctyjson.Unmarshal(js, ty)
+	}
+	return res, err
+}
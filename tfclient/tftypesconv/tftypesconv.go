@@ -0,0 +1,273 @@
+// Package tftypesconv converts the types and values between cty and the tftypes of terraform-plugin-go, and
+// encodes/decodes the cty values as the DynamicValue of the plugin protocols. This allows mixing the usage of the
+// normalized client and the raw client (see tfclient.RawClient).
+package tftypesconv
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// TypeFromCty converts the cty type to the tftypes type.
+func TypeFromCty(ty cty.Type) (tftypes.Type, error) {
+	b, err := json.Marshal(ty)
+	if err != nil {
+		return nil, err
+	}
+	return tftypes.ParseJSONType(b)
+}
+
+// TypeToCty converts the tftypes type to the cty type.
+func TypeToCty(ty tftypes.Type) (cty.Type, error) {
+	if ty == nil {
+		return cty.NilType, fmt.Errorf("nil type")
+	}
+	b, err := ty.MarshalJSON()
+	if err != nil {
+		return cty.NilType, err
+	}
+	var ret cty.Type
+	if err := json.Unmarshal(b, &ret); err != nil {
+		return cty.NilType, err
+	}
+	return ret, nil
+}
+
+// Extras are the parts of a cty value that can't be represented by tftypes, i.e. the marks and the refinements of
+// the unknown values. They are returned by ValueFromCty, and can be reapplied by ValueToCty.
+type Extras struct {
+	// Marks are the marks of the value, as returned by cty.Value.UnmarkDeepWithPaths.
+	Marks []cty.PathValueMarks
+	// Refined are the unknown values that have refinements.
+	Refined []RefinedValue
+}
+
+// RefinedValue is a refined unknown value at the path. The keys of the set elements in the path have no
+// refinements, so the unknown elements of a set that only differ in the refinements share the same path, in which
+// case ValueToCty reapplies the refinements of the first one to all of them.
+type RefinedValue struct {
+	Path  cty.Path
+	Value cty.Value
+}
+
+// ValueFromCty converts the cty value to the tftypes value. The marks and the refinements, which can't be
+// represented by tftypes, are returned as the extras.
+func ValueFromCty(v cty.Value) (tftypes.Value, *Extras, error) {
+	if v == cty.NilVal {
+		return tftypes.Value{}, nil, fmt.Errorf("nil value")
+	}
+	v, marks := v.UnmarkDeepWithPaths()
+	extras := &Extras{Marks: marks}
+	tv, err := valueFromCty(v, nil, extras)
+	if err != nil {
+		return tftypes.Value{}, nil, err
+	}
+	return tv, extras, nil
+}
+
+func valueFromCty(v cty.Value, path cty.Path, extras *Extras) (tftypes.Value, error) {
+	ty := v.Type()
+	tty, err := TypeFromCty(ty)
+	if err != nil {
+		return tftypes.Value{}, path.NewError(err)
+	}
+
+	switch {
+	case !v.IsKnown():
+		if !v.RawEquals(cty.UnknownVal(ty)) {
+			extras.Refined = append(extras.Refined, RefinedValue{Path: copyPath(path), Value: v})
+		}
+		return tftypes.NewValue(tty, tftypes.UnknownValue), nil
+	case v.IsNull():
+		return tftypes.NewValue(tty, nil), nil
+	}
+
+	var val any
+	switch {
+	case ty == cty.String:
+		val = v.AsString()
+	case ty == cty.Number:
+		val = v.AsBigFloat()
+	case ty == cty.Bool:
+		val = v.True()
+	case ty.IsListType() || ty.IsSetType() || ty.IsTupleType():
+		elems := make([]tftypes.Value, 0, v.LengthInt())
+		for it := v.ElementIterator(); it.Next(); {
+			k, ev := it.Element()
+			if ty.IsSetType() {
+				// The set elements converted back by ValueToCty have no refinements, which are then reapplied.
+				k = unrefined(ev)
+			}
+			tev, err := valueFromCty(ev, append(path, cty.IndexStep{Key: k}), extras)
+			if err != nil {
+				return tftypes.Value{}, err
+			}
+			elems = append(elems, tev)
+		}
+		val = elems
+	case ty.IsMapType():
+		elems := make(map[string]tftypes.Value, v.LengthInt())
+		for it := v.ElementIterator(); it.Next(); {
+			k, ev := it.Element()
+			tev, err := valueFromCty(ev, append(path, cty.IndexStep{Key: k}), extras)
+			if err != nil {
+				return tftypes.Value{}, err
+			}
+			elems[k.AsString()] = tev
+		}
+		val = elems
+	case ty.IsObjectType():
+		attrs := make(map[string]tftypes.Value, len(ty.AttributeTypes()))
+		for name := range ty.AttributeTypes() {
+			tav, err := valueFromCty(v.GetAttr(name), append(path, cty.GetAttrStep{Name: name}), extras)
+			if err != nil {
+				return tftypes.Value{}, err
+			}
+			attrs[name] = tav
+		}
+		val = attrs
+	default:
+		return tftypes.Value{}, path.NewErrorf("unsupported type %s", ty.FriendlyName())
+	}
+
+	if err := tftypes.ValidateValue(tty, val); err != nil {
+		return tftypes.Value{}, path.NewError(err)
+	}
+	return tftypes.NewValue(tty, val), nil
+}
+
+// unrefined returns the value with the refinements of the unknown values removed.
+func unrefined(v cty.Value) cty.Value {
+	v, _ = cty.Transform(v, func(_ cty.Path, v cty.Value) (cty.Value, error) {
+		if !v.IsKnown() {
+			return cty.UnknownVal(v.Type()), nil
+		}
+		return v, nil
+	})
+	return v
+}
+
+// ValueToCty converts the tftypes value to the cty value. The extras returned by ValueFromCty, if any, are
+// reapplied to the result.
+func ValueToCty(v tftypes.Value, extras *Extras) (cty.Value, error) {
+	cv, err := valueToCty(v, nil)
+	if err != nil {
+		return cty.NilVal, err
+	}
+	if extras == nil {
+		return cv, nil
+	}
+	if len(extras.Refined) != 0 {
+		cv, err = cty.Transform(cv, func(path cty.Path, v cty.Value) (cty.Value, error) {
+			if v.IsKnown() {
+				return v, nil
+			}
+			for _, r := range extras.Refined {
+				if r.Path.Equals(path) && r.Value.Type().Equals(v.Type()) {
+					return r.Value, nil
+				}
+			}
+			return v, nil
+		})
+		if err != nil {
+			return cty.NilVal, err
+		}
+	}
+	return cv.MarkWithPaths(extras.Marks), nil
+}
+
+func valueToCty(v tftypes.Value, path cty.Path) (cty.Value, error) {
+	ty, err := TypeToCty(v.Type())
+	if err != nil {
+		return cty.NilVal, path.NewError(err)
+	}
+
+	switch {
+	case !v.IsKnown():
+		return cty.UnknownVal(ty), nil
+	case v.IsNull():
+		return cty.NullVal(ty), nil
+	}
+
+	switch {
+	case ty == cty.String:
+		var s string
+		if err := v.As(&s); err != nil {
+			return cty.NilVal, path.NewError(err)
+		}
+		return cty.StringVal(s), nil
+	case ty == cty.Number:
+		var f big.Float
+		if err := v.As(&f); err != nil {
+			return cty.NilVal, path.NewError(err)
+		}
+		return cty.NumberVal(&f), nil
+	case ty == cty.Bool:
+		var b bool
+		if err := v.As(&b); err != nil {
+			return cty.NilVal, path.NewError(err)
+		}
+		return cty.BoolVal(b), nil
+	case ty.IsListType() || ty.IsSetType() || ty.IsTupleType():
+		var tvs []tftypes.Value
+		if err := v.As(&tvs); err != nil {
+			return cty.NilVal, path.NewError(err)
+		}
+		elems := make([]cty.Value, 0, len(tvs))
+		for i, tv := range tvs {
+			ev, err := valueToCty(tv, append(path, cty.IndexStep{Key: cty.NumberIntVal(int64(i))}))
+			if err != nil {
+				return cty.NilVal, err
+			}
+			elems = append(elems, ev)
+		}
+		switch {
+		case ty.IsTupleType():
+			return cty.TupleVal(elems), nil
+		case len(elems) == 0 && ty.IsListType():
+			return cty.ListValEmpty(ty.ElementType()), nil
+		case len(elems) == 0:
+			return cty.SetValEmpty(ty.ElementType()), nil
+		case ty.IsListType():
+			return cty.ListVal(elems), nil
+		default:
+			return cty.SetVal(elems), nil
+		}
+	case ty.IsMapType() || ty.IsObjectType():
+		var tvs map[string]tftypes.Value
+		if err := v.As(&tvs); err != nil {
+			return cty.NilVal, path.NewError(err)
+		}
+		elems := make(map[string]cty.Value, len(tvs))
+		for k, tv := range tvs {
+			var step cty.PathStep = cty.GetAttrStep{Name: k}
+			if ty.IsMapType() {
+				step = cty.IndexStep{Key: cty.StringVal(k)}
+			}
+			ev, err := valueToCty(tv, append(path, step))
+			if err != nil {
+				return cty.NilVal, err
+			}
+			elems[k] = ev
+		}
+		switch {
+		case ty.IsObjectType():
+			return cty.ObjectVal(elems), nil
+		case len(elems) == 0:
+			return cty.MapValEmpty(ty.ElementType()), nil
+		default:
+			return cty.MapVal(elems), nil
+		}
+	default:
+		return cty.NilVal, path.NewErrorf("unsupported type %s", ty.FriendlyName())
+	}
+}
+
+// copyPath copies the path, so that it is not affected by the appending to the original path.
+func copyPath(path cty.Path) cty.Path {
+	return append(make(cty.Path, 0, len(path)), path...)
+}
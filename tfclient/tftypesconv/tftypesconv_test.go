@@ -0,0 +1,158 @@
+package tftypesconv
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-go/tfprotov5"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/magodo/terraform-client-go/tfclient/marks"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestType(t *testing.T) {
+	ty := cty.Object(map[string]cty.Type{
+		"str":  cty.String,
+		"list": cty.List(cty.Number),
+		"set":  cty.Set(cty.Bool),
+		"map":  cty.Map(cty.String),
+		"tup":  cty.Tuple([]cty.Type{cty.String, cty.DynamicPseudoType}),
+	})
+	tty, err := TypeFromCty(ty)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := tftypes.Object{AttributeTypes: map[string]tftypes.Type{
+		"str":  tftypes.String,
+		"list": tftypes.List{ElementType: tftypes.Number},
+		"set":  tftypes.Set{ElementType: tftypes.Bool},
+		"map":  tftypes.Map{ElementType: tftypes.String},
+		"tup":  tftypes.Tuple{ElementTypes: []tftypes.Type{tftypes.String, tftypes.DynamicPseudoType}},
+	}}
+	if !tty.Equal(want) {
+		t.Errorf("wrong tftypes type: %s", tty)
+	}
+	got, err := TypeToCty(tty)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !got.Equals(ty) {
+		t.Errorf("wrong cty type: %#v", got)
+	}
+}
+
+func TestValue(t *testing.T) {
+	val := cty.ObjectVal(map[string]cty.Value{
+		"str":     cty.StringVal("a").Mark(marks.Sensitive),
+		"num":     cty.NumberFloatVal(1.5),
+		"null":    cty.NullVal(cty.Bool),
+		"unknown": cty.UnknownVal(cty.String),
+		"refined": cty.UnknownVal(cty.String).Refine().NotNull().StringPrefix("x").NewValue(),
+		"list":    cty.ListVal([]cty.Value{cty.StringVal("a"), cty.StringVal("b")}),
+		"empty":   cty.ListValEmpty(cty.String),
+		"set":     cty.SetVal([]cty.Value{cty.NumberIntVal(1), cty.NumberIntVal(2)}),
+		"map":     cty.MapVal(map[string]cty.Value{"k": cty.True}),
+		"tup":     cty.TupleVal([]cty.Value{cty.StringVal("a"), cty.NumberIntVal(1)}),
+		"nested": cty.ListVal([]cty.Value{
+			cty.ObjectVal(map[string]cty.Value{"id": cty.UnknownVal(cty.Number).Refine().NumberRangeLowerBound(cty.Zero, true).NewValue()}),
+		}),
+		"set_refined": cty.SetVal([]cty.Value{cty.StringVal("a"), cty.UnknownVal(cty.String).RefineNotNull()}),
+		"set_nested": cty.SetVal([]cty.Value{
+			cty.ObjectVal(map[string]cty.Value{"id": cty.UnknownVal(cty.String).Refine().StringPrefix("x").NewValue()}),
+		}),
+	})
+
+	tv, extras, err := ValueFromCty(val)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var attrs map[string]tftypes.Value
+	if err := tv.As(&attrs); err != nil {
+		t.Fatal(err)
+	}
+	if !attrs["str"].Equal(tftypes.NewValue(tftypes.String, "a")) {
+		t.Errorf("wrong str: %s", attrs["str"])
+	}
+	if attrs["refined"].IsKnown() {
+		t.Errorf("refined should be unknown")
+	}
+	if len(extras.Marks) != 1 || len(extras.Refined) != 4 {
+		t.Errorf("wrong extras: %#v", extras)
+	}
+
+	got, err := ValueToCty(tv, extras)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !got.RawEquals(val) {
+		t.Errorf("wrong round trip\ngot:  %#v\nwant: %#v", got, val)
+	}
+
+	// Without the extras, the marks and the refinements are lost.
+	got, err = ValueToCty(tv, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.ContainsMarked() {
+		t.Errorf("expect no marks")
+	}
+	if !got.GetAttr("refined").RawEquals(cty.UnknownVal(cty.String)) {
+		t.Errorf("expect no refinements: %#v", got.GetAttr("refined"))
+	}
+	if !got.GetAttr("str").RawEquals(cty.StringVal("a")) {
+		t.Errorf("wrong str: %#v", got.GetAttr("str"))
+	}
+}
+
+func TestDynamicValue(t *testing.T) {
+	ty := cty.Object(map[string]cty.Type{"id": cty.String, "n": cty.Number})
+	val := cty.ObjectVal(map[string]cty.Value{
+		"id": cty.StringVal("a").Mark(marks.Sensitive),
+		"n":  cty.UnknownVal(cty.Number).Refine().NotNull().NewValue(),
+	})
+
+	dv, err := EncodeV5DynamicValue(val, ty)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := DecodeV5DynamicValue(dv, ty)
+	if err != nil {
+		t.Fatal(err)
+	}
+	unmarked, _ := val.UnmarkDeep()
+	if !got.RawEquals(unmarked) {
+		t.Errorf("wrong value\ngot:  %#v\nwant: %#v", got, unmarked)
+	}
+
+	// The DynamicValue can be decoded by tftypes as well.
+	tty, err := TypeFromCty(ty)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tv, err := dv.Unmarshal(tty)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var attrs map[string]tftypes.Value
+	if err := tv.As(&attrs); err != nil {
+		t.Fatal(err)
+	}
+	if !attrs["id"].Equal(tftypes.NewValue(tftypes.String, "a")) {
+		t.Errorf("wrong id: %s", attrs["id"])
+	}
+
+	// JSON encoded and nil DynamicValue
+	got, err = DecodeV5DynamicValue(&tfprotov5.DynamicValue{JSON: []byte(`{"id": "b", "n": 1}`)}, ty)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := cty.ObjectVal(map[string]cty.Value{"id": cty.StringVal("b"), "n": cty.NumberIntVal(1)}); !got.RawEquals(want) {
+		t.Errorf("wrong value: %#v", got)
+	}
+	got, err = DecodeV5DynamicValue(nil, ty)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !got.RawEquals(cty.NullVal(ty)) {
+		t.Errorf("wrong value: %#v", got)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	tfjson "github.com/hashicorp/terraform-json"
+	"github.com/magodo/terraform-client-go/tfclient/providerlog"
 	"github.com/zclconf/go-cty/cty"
 )
 
@@ -248,6 +249,10 @@ type ReadResourceResponse struct {
 	// Identity is the object-typed value representing the identity of the remote
 	// object within Terraform.
 	Identity cty.Value
+
+	// ProviderLogs are the provider's logs written during the call, which are only collected by the
+	// client wrapped by tfclient.CaptureLogs.
+	ProviderLogs []providerlog.Entry
 }
 
 type PlanResourceChangeRequest struct {
@@ -313,6 +318,10 @@ type PlanResourceChangeResponse struct {
 
 	// PlannedIdentity is the planned identity data of the resource.
 	PlannedIdentity cty.Value
+
+	// ProviderLogs are the provider's logs written during the call, which are only collected by the
+	// client wrapped by tfclient.CaptureLogs.
+	ProviderLogs []providerlog.Entry
 }
 
 type ApplyResourceChangeRequest struct {
@@ -364,6 +373,10 @@ type ApplyResourceChangeResponse struct {
 
 	// NewIdentity is the new identity data of the resource.
 	NewIdentity cty.Value
+
+	// ProviderLogs are the provider's logs written during the call, which are only collected by the
+	// client wrapped by tfclient.CaptureLogs.
+	ProviderLogs []providerlog.Entry
 }
 
 type ImportResourceStateRequest struct {
@@ -391,6 +404,10 @@ type ImportResourceStateResponse struct {
 	// Deferred if present signals that the provider was not able to fully
 	// complete this operation and a susequent run is required.
 	Deferred *Deferred
+
+	// ProviderLogs are the provider's logs written during the call, which are only collected by the
+	// client wrapped by tfclient.CaptureLogs.
+	ProviderLogs []providerlog.Entry
 }
 
 // ImportedResource represents an object being imported into Terraform with the
@@ -482,6 +499,10 @@ type ReadDataSourceResponse struct {
 	// Deferred if present signals that the provider was not able to fully
 	// complete this operation and a susequent run is required.
 	Deferred *Deferred
+
+	// ProviderLogs are the provider's logs written during the call, which are only collected by the
+	// client wrapped by tfclient.CaptureLogs.
+	ProviderLogs []providerlog.Entry
 }
 
 type CallFunctionRequest struct {
@@ -0,0 +1,228 @@
+// Package validate validates the configuration values against the schema statically, i.e. without a round trip to
+// the provider.
+package validate
+
+import (
+	"fmt"
+
+	tfjson "github.com/hashicorp/terraform-json"
+	"github.com/magodo/terraform-client-go/tfclient/typ"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/convert"
+)
+
+// Config validates the configuration value against the block, which reports the following as errors:
+//
+//   - The required attributes that are null.
+//   - The computed-only attributes that are set.
+//   - The number of the nested blocks or the nested attribute elements that violates the MinItems or MaxItems.
+//   - The values that can't be converted to the types of the attributes.
+//
+// The deprecated attributes and blocks that are set are reported as warnings. The unknown values are skipped, as
+// they can't be validated until they are known.
+func Config(b *tfjson.SchemaBlock, val cty.Value) typ.Diagnostics {
+	if b == nil || val == cty.NilVal {
+		return nil
+	}
+	val, _ = val.UnmarkDeep()
+	var v validator
+	v.block(b, val, nil)
+	return v.diags
+}
+
+type validator struct {
+	diags typ.Diagnostics
+}
+
+func (v *validator) errorf(path cty.Path, summary, format string, a ...any) {
+	v.diags = append(v.diags, typ.Diagnostic{
+		Severity:  typ.Error,
+		Summary:   summary,
+		Detail:    fmt.Sprintf(format, a...),
+		Attribute: path,
+	})
+}
+
+func (v *validator) warnf(path cty.Path, summary, format string, a ...any) {
+	v.diags = append(v.diags, typ.Diagnostic{
+		Severity:  typ.Warning,
+		Summary:   summary,
+		Detail:    fmt.Sprintf(format, a...),
+		Attribute: path,
+	})
+}
+
+// object checks the value is a known object, which returns false if the value can't be further validated.
+func (v *validator) object(val cty.Value, path cty.Path) bool {
+	if val.IsNull() || !val.IsKnown() {
+		return false
+	}
+	if !val.Type().IsObjectType() {
+		v.errorf(path, "Incorrect value type", "An object is required, got %s.", val.Type().FriendlyName())
+		return false
+	}
+	return true
+}
+
+func (v *validator) block(b *tfjson.SchemaBlock, val cty.Value, path cty.Path) {
+	if !v.object(val, path) {
+		return
+	}
+	ty := val.Type()
+	for name := range ty.AttributeTypes() {
+		if _, ok := b.Attributes[name]; ok {
+			continue
+		}
+		if _, ok := b.NestedBlocks[name]; ok {
+			continue
+		}
+		v.errorf(path.GetAttr(name), "Unsupported argument", "An argument named %q is not expected here.", name)
+	}
+	v.attributes(b.Attributes, val, path)
+
+	for name, bs := range b.NestedBlocks {
+		bpath := copyPath(path).GetAttr(name)
+		if !ty.HasAttribute(name) {
+			if bs.MinItems > 0 {
+				v.errorf(bpath, "Insufficient "+name+" blocks", "At least %d %q blocks are required.", bs.MinItems, name)
+			}
+			continue
+		}
+		bval := val.GetAttr(name)
+		if !bval.IsKnown() {
+			continue
+		}
+
+		var elems []elem
+		switch bs.NestingMode {
+		case tfjson.SchemaNestingModeSingle, tfjson.SchemaNestingModeGroup:
+			if !bval.IsNull() {
+				elems = append(elems, elem{val: bval, path: bpath})
+			}
+		default:
+			var ok bool
+			if elems, ok = v.elements(bval, bpath); !ok {
+				continue
+			}
+		}
+
+		if len(elems) != 0 && bs.Block != nil && bs.Block.Deprecated {
+			v.warnf(bpath, "Deprecated block", "The block %q is deprecated.", name)
+		}
+		// The number of the elements can't be determined if a set contains unknown values, as they might coalesce
+		// after becoming known.
+		if bs.NestingMode != tfjson.SchemaNestingModeSet || bval.IsWhollyKnown() {
+			if bs.NestingMode == tfjson.SchemaNestingModeSingle && bs.MinItems > 0 && len(elems) == 0 {
+				v.errorf(bpath, "Missing required block", "A block %q is required.", name)
+			} else {
+				v.checkItems(bpath, "blocks", name, len(elems), bs.MinItems, bs.MaxItems)
+			}
+		}
+		if bs.Block == nil {
+			continue
+		}
+		for _, e := range elems {
+			v.block(bs.Block, e.val, e.path)
+		}
+	}
+}
+
+func (v *validator) attributes(attrs map[string]*tfjson.SchemaAttribute, val cty.Value, path cty.Path) {
+	ty := val.Type()
+	for name, as := range attrs {
+		apath := copyPath(path).GetAttr(name)
+		aval := cty.NullVal(cty.DynamicPseudoType)
+		if ty.HasAttribute(name) {
+			aval = val.GetAttr(name)
+		}
+		switch {
+		case aval.IsNull():
+			if as.Required {
+				v.errorf(apath, "Missing required argument", "The argument %q is required, but no definition was found.", name)
+			}
+			continue
+		case as.Computed && !as.Optional && !as.Required:
+			v.errorf(apath, "Invalid configuration for read-only attribute", "The attribute %q is read-only, whose value can't be set.", name)
+			continue
+		}
+		if as.Deprecated {
+			v.warnf(apath, "Argument is deprecated", "The argument %q is deprecated.", name)
+		}
+
+		if as.AttributeNestedType == nil {
+			if _, err := convert.Convert(aval, as.AttributeType); err != nil {
+				v.errorf(apath, "Incorrect attribute value type", "Inappropriate value for attribute %q: %s.", name, err)
+			}
+			continue
+		}
+		v.nestedType(name, as.AttributeNestedType, aval, apath)
+	}
+}
+
+func (v *validator) nestedType(name string, o *tfjson.SchemaNestedAttributeType, val cty.Value, path cty.Path) {
+	if !val.IsKnown() {
+		return
+	}
+	var elems []elem
+	switch o.NestingMode {
+	case tfjson.SchemaNestingModeSingle, tfjson.SchemaNestingModeGroup:
+		elems = append(elems, elem{val: val, path: path})
+	default:
+		var ok bool
+		if elems, ok = v.elements(val, path); !ok {
+			return
+		}
+		if o.NestingMode != tfjson.SchemaNestingModeSet || val.IsWhollyKnown() {
+			v.checkItems(path, "elements", name, len(elems), o.MinItems, o.MaxItems)
+		}
+	}
+	for _, e := range elems {
+		if v.object(e.val, e.path) {
+			v.attributes(o.Attributes, e.val, e.path)
+		}
+	}
+}
+
+func (v *validator) checkItems(path cty.Path, kind, name string, n int, min, max uint64) {
+	switch {
+	case min > 0 && uint64(n) < min:
+		v.errorf(path, fmt.Sprintf("Insufficient %s %s", name, kind), "At least %d %q %s are required, got %d.", min, name, kind, n)
+	case max > 0 && uint64(n) > max:
+		v.errorf(path, fmt.Sprintf("Too many %s %s", name, kind), "No more than %d %q %s are allowed, got %d.", max, name, kind, n)
+	}
+}
+
+type elem struct {
+	val  cty.Value
+	path cty.Path
+}
+
+// elements returns the elements of a list, set or map value, which returns false if the value is not a collection.
+func (v *validator) elements(val cty.Value, path cty.Path) ([]elem, bool) {
+	if val.IsNull() {
+		return nil, true
+	}
+	ty := val.Type()
+	if !(ty.IsCollectionType() || ty.IsTupleType() || ty.IsObjectType()) {
+		v.errorf(path, "Incorrect value type", "A collection is required, got %s.", ty.FriendlyName())
+		return nil, false
+	}
+	var elems []elem
+	for it := val.ElementIterator(); it.Next(); {
+		k, ev := it.Element()
+		var epath cty.Path
+		switch {
+		case ty.IsSetType():
+			epath = copyPath(path).Index(ev)
+		default:
+			epath = copyPath(path).Index(k)
+		}
+		elems = append(elems, elem{val: ev, path: epath})
+	}
+	return elems, true
+}
+
+// copyPath copies the path, so that it can be extended without affecting the others.
+func copyPath(path cty.Path) cty.Path {
+	return append(make(cty.Path, 0, len(path)+1), path...)
+}
@@ -0,0 +1,235 @@
+package validate
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	tfjson "github.com/hashicorp/terraform-json"
+	"github.com/magodo/terraform-client-go/tfclient/marks"
+	"github.com/magodo/terraform-client-go/tfclient/typ"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestConfig(t *testing.T) {
+	block := &tfjson.SchemaBlock{
+		Attributes: map[string]*tfjson.SchemaAttribute{
+			"name":   {AttributeType: cty.String, Required: true},
+			"id":     {AttributeType: cty.String, Computed: true},
+			"count":  {AttributeType: cty.Number, Optional: true},
+			"legacy": {AttributeType: cty.String, Optional: true, Deprecated: true},
+			"rules": {
+				AttributeNestedType: &tfjson.SchemaNestedAttributeType{
+					NestingMode: tfjson.SchemaNestingModeList,
+					Attributes: map[string]*tfjson.SchemaAttribute{
+						"port": {AttributeType: cty.Number, Required: true},
+					},
+					MaxItems: 1,
+				},
+				Optional: true,
+			},
+		},
+		NestedBlocks: map[string]*tfjson.SchemaBlockType{
+			"item": {
+				NestingMode: tfjson.SchemaNestingModeList,
+				Block: &tfjson.SchemaBlock{
+					Attributes: map[string]*tfjson.SchemaAttribute{
+						"x": {AttributeType: cty.String, Required: true},
+					},
+				},
+				MinItems: 1,
+				MaxItems: 2,
+			},
+			"old": {
+				NestingMode: tfjson.SchemaNestingModeSingle,
+				Block:       &tfjson.SchemaBlock{Deprecated: true},
+			},
+		},
+	}
+	itemTy := cty.Object(map[string]cty.Type{"x": cty.String})
+	rulesTy := cty.List(cty.Object(map[string]cty.Type{"port": cty.Number}))
+
+	config := func(attrs map[string]cty.Value) cty.Value {
+		v := map[string]cty.Value{
+			"name":   cty.StringVal("a"),
+			"id":     cty.NullVal(cty.String),
+			"count":  cty.NullVal(cty.Number),
+			"legacy": cty.NullVal(cty.String),
+			"rules":  cty.NullVal(rulesTy),
+			"item":   cty.ListVal([]cty.Value{cty.ObjectVal(map[string]cty.Value{"x": cty.StringVal("1")})}),
+			"old":    cty.NullVal(cty.EmptyObject),
+		}
+		for k, av := range attrs {
+			v[k] = av
+		}
+		return cty.ObjectVal(v)
+	}
+
+	cases := []struct {
+		name string
+		val  cty.Value
+		want typ.Diagnostics
+	}{
+		{
+			name: "valid",
+			val:  config(nil),
+		},
+		{
+			name: "null",
+			val:  cty.NullVal(cty.DynamicPseudoType),
+		},
+		{
+			name: "unknown values are skipped",
+			val: config(map[string]cty.Value{
+				"name":  cty.UnknownVal(cty.String),
+				"rules": cty.UnknownVal(rulesTy),
+				"item":  cty.UnknownVal(cty.List(itemTy)),
+			}),
+		},
+		{
+			name: "sensitive values are validated",
+			val:  config(map[string]cty.Value{"name": cty.NullVal(cty.String).Mark(marks.Sensitive)}),
+			want: typ.Diagnostics{
+				{
+					Severity:  typ.Error,
+					Summary:   "Missing required argument",
+					Detail:    `The argument "name" is required, but no definition was found.`,
+					Attribute: cty.GetAttrPath("name"),
+				},
+			},
+		},
+		{
+			name: "computed only",
+			val:  config(map[string]cty.Value{"id": cty.StringVal("1")}),
+			want: typ.Diagnostics{
+				{
+					Severity:  typ.Error,
+					Summary:   "Invalid configuration for read-only attribute",
+					Detail:    `The attribute "id" is read-only, whose value can't be set.`,
+					Attribute: cty.GetAttrPath("id"),
+				},
+			},
+		},
+		{
+			name: "wrong type",
+			val:  config(map[string]cty.Value{"count": cty.StringVal("one")}),
+			want: typ.Diagnostics{
+				{
+					Severity:  typ.Error,
+					Summary:   "Incorrect attribute value type",
+					Detail:    `Inappropriate value for attribute "count": a number is required.`,
+					Attribute: cty.GetAttrPath("count"),
+				},
+			},
+		},
+		{
+			name: "deprecated",
+			val: config(map[string]cty.Value{
+				"legacy": cty.StringVal("a"),
+				"old":    cty.EmptyObjectVal,
+			}),
+			want: typ.Diagnostics{
+				{
+					Severity:  typ.Warning,
+					Summary:   "Argument is deprecated",
+					Detail:    `The argument "legacy" is deprecated.`,
+					Attribute: cty.GetAttrPath("legacy"),
+				},
+				{
+					Severity:  typ.Warning,
+					Summary:   "Deprecated block",
+					Detail:    `The block "old" is deprecated.`,
+					Attribute: cty.GetAttrPath("old"),
+				},
+			},
+		},
+		{
+			name: "insufficient blocks",
+			val:  config(map[string]cty.Value{"item": cty.ListValEmpty(itemTy)}),
+			want: typ.Diagnostics{
+				{
+					Severity:  typ.Error,
+					Summary:   "Insufficient item blocks",
+					Detail:    `At least 1 "item" blocks are required, got 0.`,
+					Attribute: cty.GetAttrPath("item"),
+				},
+			},
+		},
+		{
+			name: "too many blocks and nested errors",
+			val: config(map[string]cty.Value{
+				"item": cty.ListVal([]cty.Value{
+					cty.ObjectVal(map[string]cty.Value{"x": cty.StringVal("1")}),
+					cty.ObjectVal(map[string]cty.Value{"x": cty.StringVal("2")}),
+					cty.ObjectVal(map[string]cty.Value{"x": cty.NullVal(cty.String)}),
+				}),
+			}),
+			want: typ.Diagnostics{
+				{
+					Severity:  typ.Error,
+					Summary:   "Too many item blocks",
+					Detail:    `No more than 2 "item" blocks are allowed, got 3.`,
+					Attribute: cty.GetAttrPath("item"),
+				},
+				{
+					Severity:  typ.Error,
+					Summary:   "Missing required argument",
+					Detail:    `The argument "x" is required, but no definition was found.`,
+					Attribute: cty.GetAttrPath("item").IndexInt(2).GetAttr("x"),
+				},
+			},
+		},
+		{
+			name: "nested attribute",
+			val: config(map[string]cty.Value{
+				"rules": cty.ListVal([]cty.Value{
+					cty.ObjectVal(map[string]cty.Value{"port": cty.NumberIntVal(80)}),
+					cty.ObjectVal(map[string]cty.Value{"port": cty.NullVal(cty.Number)}),
+				}),
+			}),
+			want: typ.Diagnostics{
+				{
+					Severity:  typ.Error,
+					Summary:   "Too many rules elements",
+					Detail:    `No more than 1 "rules" elements are allowed, got 2.`,
+					Attribute: cty.GetAttrPath("rules"),
+				},
+				{
+					Severity:  typ.Error,
+					Summary:   "Missing required argument",
+					Detail:    `The argument "port" is required, but no definition was found.`,
+					Attribute: cty.GetAttrPath("rules").IndexInt(1).GetAttr("port"),
+				},
+			},
+		},
+		{
+			name: "unsupported argument",
+			val: cty.ObjectVal(map[string]cty.Value{
+				"name": cty.StringVal("a"),
+				"item": cty.ListVal([]cty.Value{cty.ObjectVal(map[string]cty.Value{"x": cty.StringVal("1")})}),
+				"foo":  cty.True,
+			}),
+			want: typ.Diagnostics{
+				{
+					Severity:  typ.Error,
+					Summary:   "Unsupported argument",
+					Detail:    `An argument named "foo" is not expected here.`,
+					Attribute: cty.GetAttrPath("foo"),
+				},
+			},
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Config(block, tt.val)
+			// The schema maps are iterated in random order.
+			sort.SliceStable(got, func(i, j int) bool {
+				return typ.FormatCtyPath(got[i].Attribute) < typ.FormatCtyPath(got[j].Attribute)
+			})
+			if diff := cmp.Diff(tt.want, got, cmp.Comparer(func(a, b cty.Path) bool { return a.Equals(b) })); diff != "" {
+				t.Errorf("wrong diagnostics\n%s", diff)
+			}
+		})
+	}
+}
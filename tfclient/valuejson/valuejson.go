@@ -0,0 +1,424 @@
+// Package valuejson encodes the cty values in JSON together with their types, where the unknown values, the
+// refinements of the unknown values and the sensitive marks are kept, so that the values can be decoded losslessly.
+//
+// The encoded document is a JSON object of the following properties:
+//
+//   - "type": The type of the value, in the encoding of the cty/json package.
+//   - "value": The value, where the unknown values are written as null.
+//   - "unknown": Mirrors the structure of the value, where the unknown values are true, in the same encoding as the
+//     "after_unknown" of `terraform show -json`. Omitted if the value is wholly known.
+//   - "refinements": Mirrors the structure of the value, where the refined unknown values are objects of the
+//     refinements, i.e. "not_null", "string_prefix", "number_lower", "number_upper", "length_lower" and
+//     "length_upper". Omitted if there is no refined unknown value.
+//   - "sensitive": Mirrors the structure of the value, where the values marked by marks.Sensitive are true, in the
+//     same encoding as the "after_sensitive" of `terraform show -json`. Omitted if there is no sensitive value.
+//
+// A null in the "value" is a null value, unless it is marked as unknown in the "unknown". The structures of the
+// mirrors only consist of the objects and the arrays leading to the true values, where the objects omit the
+// properties of the other values, while the arrays use false for them. The set elements are in the order of the
+// "value".
+package valuejson
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+
+	"github.com/magodo/terraform-client-go/tfclient/marks"
+	"github.com/zclconf/go-cty/cty"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
+)
+
+type document struct {
+	Type        json.RawMessage `json:"type"`
+	Value       any             `json:"value"`
+	Unknown     any             `json:"unknown,omitempty"`
+	Refinements any             `json:"refinements,omitempty"`
+	Sensitive   any             `json:"sensitive,omitempty"`
+}
+
+// refinements are the refinements of an unknown value.
+type refinements struct {
+	NotNull      bool         `json:"not_null,omitempty"`
+	StringPrefix string       `json:"string_prefix,omitempty"`
+	NumberLower  *numberBound `json:"number_lower,omitempty"`
+	NumberUpper  *numberBound `json:"number_upper,omitempty"`
+	LengthLower  int          `json:"length_lower,omitempty"`
+	LengthUpper  *int         `json:"length_upper,omitempty"`
+}
+
+type numberBound struct {
+	Value     json.Number `json:"value"`
+	Inclusive bool        `json:"inclusive"`
+}
+
+// Marshal encodes the value together with its type. The marks other than marks.Sensitive are dropped, and the
+// sensitive marks nested in a sensitive value are merged into it.
+func Marshal(v cty.Value) ([]byte, error) {
+	if v == cty.NilVal {
+		v = cty.NullVal(cty.DynamicPseudoType)
+	}
+	ty, err := ctyjson.MarshalType(v.Type())
+	if err != nil {
+		return nil, err
+	}
+	doc := document{Type: ty}
+	doc.Value, doc.Unknown, doc.Refinements, doc.Sensitive, err = encode(v)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(doc)
+}
+
+// encode returns the JSON value, and the mirrors of the unknowns, the refinements and the sensitive marks, where
+// nil means there is nothing to record.
+func encode(v cty.Value) (val, unknown, refine, sensitive any, err error) {
+	if v.HasMark(marks.Sensitive) {
+		sensitive = true
+	}
+	v, _ = v.Unmark()
+
+	if !v.IsKnown() {
+		if r := encodeRefinements(v); r != nil {
+			refine = r
+		}
+		return nil, true, refine, sensitive, nil
+	}
+	if v.IsNull() {
+		return nil, nil, nil, sensitive, nil
+	}
+
+	ty := v.Type()
+	switch {
+	case ty == cty.String:
+		return v.AsString(), nil, nil, sensitive, nil
+	case ty == cty.Number:
+		return json.Number(v.AsBigFloat().Text('f', -1)), nil, nil, sensitive, nil
+	case ty == cty.Bool:
+		return v.True(), nil, nil, sensitive, nil
+	case ty.IsObjectType() || ty.IsMapType():
+		obj := map[string]any{}
+		unknowns, refines, sensitives := map[string]any{}, map[string]any{}, map[string]any{}
+		for it := v.ElementIterator(); it.Next(); {
+			k, ev := it.Element()
+			key := k.AsString()
+			ejson, eunknown, erefine, esensitive, err := encode(ev)
+			if err != nil {
+				return nil, nil, nil, nil, err
+			}
+			obj[key] = ejson
+			if eunknown != nil {
+				unknowns[key] = eunknown
+			}
+			if erefine != nil {
+				refines[key] = erefine
+			}
+			if esensitive != nil {
+				sensitives[key] = esensitive
+			}
+		}
+		return obj, objectMirror(unknowns), objectMirror(refines), sensitiveMirror(sensitive, objectMirror(sensitives)), nil
+	case ty.IsListType() || ty.IsSetType() || ty.IsTupleType():
+		arr := []any{}
+		var unknowns, refines, sensitives []any
+		var hasUnknown, hasRefine, hasSensitive bool
+		for it := v.ElementIterator(); it.Next(); {
+			_, ev := it.Element()
+			ejson, eunknown, erefine, esensitive, err := encode(ev)
+			if err != nil {
+				return nil, nil, nil, nil, err
+			}
+			arr = append(arr, ejson)
+			unknowns = append(unknowns, arrayElem(eunknown, &hasUnknown))
+			refines = append(refines, arrayElem(erefine, &hasRefine))
+			sensitives = append(sensitives, arrayElem(esensitive, &hasSensitive))
+		}
+		return arr, arrayMirror(unknowns, hasUnknown), arrayMirror(refines, hasRefine), sensitiveMirror(sensitive, arrayMirror(sensitives, hasSensitive)), nil
+	default:
+		return nil, nil, nil, nil, fmt.Errorf("unsupported type %s", ty.FriendlyName())
+	}
+}
+
+func objectMirror(m map[string]any) any {
+	if len(m) == 0 {
+		return nil
+	}
+	return m
+}
+
+func arrayMirror(a []any, has bool) any {
+	if !has {
+		return nil
+	}
+	return a
+}
+
+func arrayElem(v any, has *bool) any {
+	if v == nil {
+		return false
+	}
+	*has = true
+	return v
+}
+
+// sensitiveMirror returns true if the value itself is sensitive, otherwise the mirror of its nested values.
+func sensitiveMirror(self, nested any) any {
+	if self != nil {
+		return self
+	}
+	return nested
+}
+
+func encodeRefinements(v cty.Value) *refinements {
+	rng := v.Range()
+	ty := v.Type()
+	var r refinements
+	r.NotNull = rng.DefinitelyNotNull()
+	switch {
+	case ty == cty.String:
+		r.StringPrefix = rng.StringPrefix()
+	case ty == cty.Number:
+		if min, inclusive := rng.NumberLowerBound(); min.IsKnown() && !min.IsNull() && !min.AsBigFloat().IsInf() {
+			r.NumberLower = &numberBound{Value: json.Number(min.AsBigFloat().Text('f', -1)), Inclusive: inclusive}
+		}
+		if max, inclusive := rng.NumberUpperBound(); max.IsKnown() && !max.IsNull() && !max.AsBigFloat().IsInf() {
+			r.NumberUpper = &numberBound{Value: json.Number(max.AsBigFloat().Text('f', -1)), Inclusive: inclusive}
+		}
+	case ty.IsCollectionType():
+		r.LengthLower = rng.LengthLowerBound()
+		if upper := rng.LengthUpperBound(); upper != math.MaxInt {
+			r.LengthUpper = &upper
+		}
+	}
+	if r == (refinements{}) {
+		return nil
+	}
+	return &r
+}
+
+// Unmarshal decodes the value encoded by Marshal.
+func Unmarshal(b []byte) (cty.Value, error) {
+	var doc document
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	if err := dec.Decode(&doc); err != nil {
+		return cty.NilVal, err
+	}
+	if len(doc.Type) == 0 {
+		return cty.NilVal, fmt.Errorf(`missing "type"`)
+	}
+	ty, err := ctyjson.UnmarshalType(doc.Type)
+	if err != nil {
+		return cty.NilVal, fmt.Errorf("decoding the type: %v", err)
+	}
+	return decode(nil, ty, doc.Value, doc.Unknown, doc.Refinements, doc.Sensitive)
+}
+
+func decode(path cty.Path, ty cty.Type, val, unknown, refine, sensitive any) (cty.Value, error) {
+	v, err := decodeUnmarked(path, ty, val, unknown, refine, sensitive)
+	if err != nil {
+		return cty.NilVal, err
+	}
+	if sensitive == true {
+		v = v.Mark(marks.Sensitive)
+	}
+	return v, nil
+}
+
+func decodeUnmarked(path cty.Path, ty cty.Type, val, unknown, refine, sensitive any) (cty.Value, error) {
+	if unknown == true {
+		return decodeUnknown(path, ty, refine)
+	}
+	if val == nil {
+		return cty.NullVal(ty), nil
+	}
+	if sensitive == true {
+		// The nested values are covered by the mark of this value.
+		sensitive = nil
+	}
+
+	errorf := func(format string, a ...any) error {
+		return path.NewErrorf(format, a...)
+	}
+	switch {
+	case ty == cty.String:
+		s, ok := val.(string)
+		if !ok {
+			return cty.NilVal, errorf("string is required")
+		}
+		return cty.StringVal(s), nil
+	case ty == cty.Number:
+		n, ok := val.(json.Number)
+		if !ok {
+			return cty.NilVal, errorf("number is required")
+		}
+		v, err := cty.ParseNumberVal(n.String())
+		if err != nil {
+			return cty.NilVal, errorf("%v", err)
+		}
+		return v, nil
+	case ty == cty.Bool:
+		b, ok := val.(bool)
+		if !ok {
+			return cty.NilVal, errorf("bool is required")
+		}
+		return cty.BoolVal(b), nil
+	case ty.IsObjectType() || ty.IsMapType():
+		obj, ok := val.(map[string]any)
+		if !ok {
+			return cty.NilVal, errorf("object is required")
+		}
+		if ty.IsObjectType() {
+			for k := range obj {
+				if !ty.HasAttribute(k) {
+					return cty.NilVal, errorf("unsupported attribute %q", k)
+				}
+			}
+		}
+		attrs := map[string]cty.Value{}
+		names := make([]string, 0, len(obj))
+		if ty.IsObjectType() {
+			for k := range ty.AttributeTypes() {
+				names = append(names, k)
+			}
+		} else {
+			for k := range obj {
+				names = append(names, k)
+			}
+		}
+		for _, k := range names {
+			ety := cty.DynamicPseudoType
+			var epath cty.Path
+			if ty.IsObjectType() {
+				ety = ty.AttributeType(k)
+				epath = path.GetAttr(k)
+			} else {
+				ety = ty.ElementType()
+				epath = path.Index(cty.StringVal(k))
+			}
+			ev, err := decode(epath, ety, obj[k], objectChild(unknown, k), objectChild(refine, k), objectChild(sensitive, k))
+			if err != nil {
+				return cty.NilVal, err
+			}
+			attrs[k] = ev
+		}
+		if ty.IsObjectType() {
+			return cty.ObjectVal(attrs), nil
+		}
+		if len(attrs) == 0 {
+			return cty.MapValEmpty(ty.ElementType()), nil
+		}
+		return cty.MapVal(attrs), nil
+	case ty.IsListType() || ty.IsSetType() || ty.IsTupleType():
+		arr, ok := val.([]any)
+		if !ok {
+			return cty.NilVal, errorf("array is required")
+		}
+		if ty.IsTupleType() && len(arr) != len(ty.TupleElementTypes()) {
+			return cty.NilVal, errorf("%d elements are required", len(ty.TupleElementTypes()))
+		}
+		var elems []cty.Value
+		for i, e := range arr {
+			var ety cty.Type
+			if ty.IsTupleType() {
+				ety = ty.TupleElementType(i)
+			} else {
+				ety = ty.ElementType()
+			}
+			ev, err := decode(path.IndexInt(i), ety, e, arrayChild(unknown, i), arrayChild(refine, i), arrayChild(sensitive, i))
+			if err != nil {
+				return cty.NilVal, err
+			}
+			elems = append(elems, ev)
+		}
+		switch {
+		case ty.IsTupleType():
+			return cty.TupleVal(elems), nil
+		case len(elems) == 0 && ty.IsListType():
+			return cty.ListValEmpty(ty.ElementType()), nil
+		case len(elems) == 0:
+			return cty.SetValEmpty(ty.ElementType()), nil
+		case ty.IsListType():
+			return cty.ListVal(elems), nil
+		default:
+			return cty.SetVal(elems), nil
+		}
+	default:
+		return cty.NilVal, errorf("unexpected known value of type %s", ty.FriendlyName())
+	}
+}
+
+func decodeUnknown(path cty.Path, ty cty.Type, refine any) (_ cty.Value, err error) {
+	v := cty.UnknownVal(ty)
+	if refine == nil {
+		return v, nil
+	}
+	b, err := json.Marshal(refine)
+	if err != nil {
+		return cty.NilVal, path.NewError(err)
+	}
+	var r refinements
+	if err := json.Unmarshal(b, &r); err != nil {
+		return cty.NilVal, path.NewErrorf("decoding the refinements: %v", err)
+	}
+	switch {
+	case r.StringPrefix != "" && ty != cty.String:
+		return cty.NilVal, path.NewErrorf("string prefix refinement is only valid for string")
+	case (r.NumberLower != nil || r.NumberUpper != nil) && ty != cty.Number:
+		return cty.NilVal, path.NewErrorf("number bound refinements are only valid for number")
+	case (r.LengthLower != 0 || r.LengthUpper != nil) && !ty.IsCollectionType():
+		return cty.NilVal, path.NewErrorf("length bound refinements are only valid for collections")
+	}
+	// The refinement builder panics on the conflicting refinements, e.g. the lower bound exceeds the upper bound.
+	defer func() {
+		if r := recover(); r != nil {
+			err = path.NewErrorf("invalid refinements: %v", r)
+		}
+	}()
+	rb := v.Refine()
+	if r.NotNull {
+		rb = rb.NotNull()
+	}
+	if r.StringPrefix != "" {
+		rb = rb.StringPrefixFull(r.StringPrefix)
+	}
+	for _, bound := range []struct {
+		b     *numberBound
+		lower bool
+	}{{r.NumberLower, true}, {r.NumberUpper, false}} {
+		if bound.b == nil {
+			continue
+		}
+		n, err := cty.ParseNumberVal(bound.b.Value.String())
+		if err != nil {
+			return cty.NilVal, path.NewErrorf("decoding the number bound: %v", err)
+		}
+		if bound.lower {
+			rb = rb.NumberRangeLowerBound(n, bound.b.Inclusive)
+		} else {
+			rb = rb.NumberRangeUpperBound(n, bound.b.Inclusive)
+		}
+	}
+	if r.LengthLower != 0 {
+		rb = rb.CollectionLengthLowerBound(r.LengthLower)
+	}
+	if r.LengthUpper != nil {
+		rb = rb.CollectionLengthUpperBound(*r.LengthUpper)
+	}
+	return rb.NewValue(), nil
+}
+
+func objectChild(mirror any, key string) any {
+	if m, ok := mirror.(map[string]any); ok {
+		return m[key]
+	}
+	return nil
+}
+
+func arrayChild(mirror any, i int) any {
+	if a, ok := mirror.([]any); ok && i < len(a) {
+		return a[i]
+	}
+	return nil
+}
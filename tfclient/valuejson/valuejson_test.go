@@ -0,0 +1,102 @@
+package valuejson
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/magodo/terraform-client-go/tfclient/marks"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestRoundTrip(t *testing.T) {
+	cases := []struct {
+		name string
+		val  cty.Value
+		want string
+	}{
+		{
+			name: "nil",
+			val:  cty.NilVal,
+			want: `{"type":"dynamic","value":null}`,
+		},
+		{
+			name: "dynamic unknown",
+			val:  cty.DynamicVal,
+			want: `{"type":"dynamic","value":null,"unknown":true}`,
+		},
+		{
+			name: "object",
+			val: cty.ObjectVal(map[string]cty.Value{
+				"id":       cty.UnknownVal(cty.String),
+				"name":     cty.StringVal("a"),
+				"null":     cty.NullVal(cty.Number),
+				"num":      cty.NumberFloatVal(1.5),
+				"password": cty.StringVal("secret").Mark(marks.Sensitive),
+				"tags": cty.MapVal(map[string]cty.Value{
+					"k": cty.UnknownVal(cty.String).RefineNotNull(),
+				}),
+				"list": cty.ListVal([]cty.Value{
+					cty.StringVal("x"),
+					cty.UnknownVal(cty.String).Refine().StringPrefixFull("pre").NewValue(),
+				}),
+				"set": cty.SetVal([]cty.Value{
+					cty.ObjectVal(map[string]cty.Value{"n": cty.UnknownVal(cty.Number).Refine().NumberRangeInclusive(cty.NumberIntVal(1), cty.NumberIntVal(3)).NewValue()}),
+					cty.ObjectVal(map[string]cty.Value{"n": cty.NumberIntVal(2)}),
+				}),
+				"coll": cty.UnknownVal(cty.List(cty.String)).Refine().CollectionLengthLowerBound(1).NewValue(),
+				"tuple": cty.TupleVal([]cty.Value{
+					cty.True,
+					cty.MapValEmpty(cty.String).Mark(marks.Sensitive),
+				}),
+			}),
+			want: `{"type":["object",{"coll":["list","string"],"id":"string","list":["list","string"],"name":"string","null":"number","num":"number","password":"string","set":["set",["object",{"n":"number"}]],"tags":["map","string"],"tuple":["tuple",["bool",["map","string"]]]}],` +
+				`"value":{"coll":null,"id":null,"list":["x",null],"name":"a","null":null,"num":1.5,"password":"secret","set":[{"n":2},{"n":null}],"tags":{"k":null},"tuple":[true,{}]},` +
+				`"unknown":{"coll":true,"id":true,"list":[false,true],"set":[false,{"n":true}],"tags":{"k":true}},` +
+				`"refinements":{"coll":{"length_lower":1},"list":[false,{"string_prefix":"pre"}],"set":[false,{"n":{"number_lower":{"value":1,"inclusive":true},"number_upper":{"value":3,"inclusive":true}}}],"tags":{"k":{"not_null":true}}},` +
+				`"sensitive":{"password":true,"tuple":[false,true]}}`,
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := Marshal(tt.val)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tt.want, string(b)); diff != "" {
+				t.Errorf("wrong encoding\n%s", diff)
+			}
+			got, err := Unmarshal(b)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := tt.val
+			if want == cty.NilVal {
+				want = cty.NullVal(cty.DynamicPseudoType)
+			}
+			if !got.RawEquals(want) {
+				t.Errorf("wrong decoded value\ngot:  %#v\nwant: %#v", got, want)
+			}
+		})
+	}
+}
+
+func TestUnmarshalError(t *testing.T) {
+	cases := []struct {
+		name string
+		in   string
+	}{
+		{"missing type", `{"value":"a"}`},
+		{"type mismatch", `{"type":"string","value":1}`},
+		{"unknown attribute", `{"type":["object",{"a":"string"}],"value":{"b":"x"}}`},
+		{"invalid refinement", `{"type":"number","value":null,"unknown":true,"refinements":{"string_prefix":"a"}}`},
+		{"conflicting refinements", `{"type":"number","value":null,"unknown":true,"refinements":{"number_lower":{"value":"3","inclusive":true},"number_upper":{"value":"1","inclusive":true}}}`},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Unmarshal([]byte(tt.in)); err == nil {
+				t.Errorf("expect error")
+			}
+		})
+	}
+}
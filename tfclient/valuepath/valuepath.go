@@ -0,0 +1,438 @@
+// Package valuepath addresses the values inside a cty.Value by the Terraform-style traversals, e.g. `a.b[0].c`,
+// `tags["env"]`, or `rule[{name = "x"}]` for a set element, which is addressed by its value.
+//
+// A traversal is parsed to a cty.Path by Parse, which can then be checked (and normalized) against the type of the
+// value by Resolve, or against the implied type of a schema block by ParseBlock. The resolved path can be used to
+// get, set or delete the addressed value by Get, Set and Delete, respectively, which keep the types and the marks of
+// the values.
+package valuepath
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	tfjson "github.com/hashicorp/terraform-json"
+	"github.com/magodo/terraform-client-go/tfclient/configschema"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/convert"
+)
+
+// Parse parses the traversal to a path. The index keys are only evaluated, without being checked against any type,
+// which is the job of Resolve.
+func Parse(s string) (cty.Path, error) {
+	expr, diags := hclsyntax.ParseExpression([]byte(s), "", hcl.InitialPos)
+	if diags.HasErrors() {
+		return nil, fmt.Errorf("parsing path %q: %s", s, diags.Error())
+	}
+	path, err := exprPath(expr)
+	if err != nil {
+		return nil, fmt.Errorf("parsing path %q: %v", s, err)
+	}
+	return path, nil
+}
+
+func exprPath(expr hclsyntax.Expression) (cty.Path, error) {
+	switch expr := expr.(type) {
+	case *hclsyntax.ScopeTraversalExpr:
+		return traversalPath(nil, expr.Traversal)
+	case *hclsyntax.RelativeTraversalExpr:
+		path, err := exprPath(expr.Source)
+		if err != nil {
+			return nil, err
+		}
+		return traversalPath(path, expr.Traversal)
+	case *hclsyntax.IndexExpr:
+		path, err := exprPath(expr.Collection)
+		if err != nil {
+			return nil, err
+		}
+		key, diags := expr.Key.Value(nil)
+		if diags.HasErrors() {
+			return nil, fmt.Errorf("evaluating the index key: %s", diags.Error())
+		}
+		if !key.IsWhollyKnown() || key.IsNull() {
+			return nil, fmt.Errorf("the index key must be a known non-null value")
+		}
+		return append(path, cty.IndexStep{Key: key}), nil
+	default:
+		return nil, fmt.Errorf("only the attribute accesses and the indexes are allowed")
+	}
+}
+
+func traversalPath(path cty.Path, traversal hcl.Traversal) (cty.Path, error) {
+	for _, step := range traversal {
+		switch step := step.(type) {
+		case hcl.TraverseRoot:
+			path = append(path, cty.GetAttrStep{Name: step.Name})
+		case hcl.TraverseAttr:
+			path = append(path, cty.GetAttrStep{Name: step.Name})
+		case hcl.TraverseIndex:
+			path = append(path, cty.IndexStep{Key: step.Key})
+		default:
+			return nil, fmt.Errorf("the splat is not allowed")
+		}
+	}
+	return path, nil
+}
+
+// ParseBlock parses the traversal and resolves it against the implied type of the schema block. It returns the
+// resolved path and the type of the addressed value.
+func ParseBlock(b *tfjson.SchemaBlock, s string) (cty.Path, cty.Type, error) {
+	path, err := Parse(s)
+	if err != nil {
+		return nil, cty.NilType, err
+	}
+	return Resolve(configschema.SchemaBlockImpliedType(b), path)
+}
+
+// Resolve checks the path against the type, and returns the normalized path together with the type of the
+// addressed value. The normalized path only uses the cty.GetAttrStep for the object attributes, and the
+// cty.IndexStep of the type-correct keys for the others, e.g. `tags.env` is the same as `tags["env"]` for a map,
+// while the key of a set element is converted to the element type. The steps inside a value of the dynamic type
+// are kept as is, as they can't be checked.
+//
+// The returned error is a cty.PathError, whose path is where the check fails.
+func Resolve(ty cty.Type, path cty.Path) (cty.Path, cty.Type, error) {
+	ret := make(cty.Path, 0, len(path))
+	for i, step := range path {
+		switch {
+		case ty == cty.DynamicPseudoType:
+			return append(ret, path[i:]...), cty.DynamicPseudoType, nil
+		case ty.IsObjectType():
+			name, ok := stepName(step)
+			if !ok {
+				return nil, cty.NilType, ret.NewErrorf("an attribute name is required")
+			}
+			if !ty.HasAttribute(name) {
+				return nil, cty.NilType, ret.NewErrorf("unsupported attribute %q", name)
+			}
+			ret = append(ret, cty.GetAttrStep{Name: name})
+			ty = ty.AttributeType(name)
+		case ty.IsMapType():
+			name, ok := stepName(step)
+			if !ok {
+				return nil, cty.NilType, ret.NewErrorf("a string key is required")
+			}
+			ret = append(ret, cty.IndexStep{Key: cty.StringVal(name)})
+			ty = ty.ElementType()
+		case ty.IsListType() || ty.IsTupleType():
+			idx, ok := stepIndex(step)
+			if !ok {
+				return nil, cty.NilType, ret.NewErrorf("a non-negative integer index is required")
+			}
+			ret = append(ret, cty.IndexStep{Key: cty.NumberIntVal(int64(idx))})
+			if ty.IsListType() {
+				ty = ty.ElementType()
+				break
+			}
+			if idx >= ty.Length() {
+				return nil, cty.NilType, ret[:len(ret)-1].NewErrorf("index %d out of range for a tuple of %d elements", idx, ty.Length())
+			}
+			ty = ty.TupleElementType(idx)
+		case ty.IsSetType():
+			step, ok := step.(cty.IndexStep)
+			if !ok {
+				return nil, cty.NilType, ret.NewErrorf("a set element must be addressed by its value")
+			}
+			key, err := convert.Convert(step.Key, ty.ElementType())
+			if err != nil {
+				return nil, cty.NilType, ret.NewErrorf("invalid set element: %v", err)
+			}
+			ret = append(ret, cty.IndexStep{Key: key})
+			ty = ty.ElementType()
+		default:
+			return nil, cty.NilType, ret.NewErrorf("can't address into a %s value", ty.FriendlyName())
+		}
+	}
+	return ret, ty, nil
+}
+
+// stepName returns the name of an attribute access, or the key of a string index.
+func stepName(step cty.PathStep) (string, bool) {
+	switch step := step.(type) {
+	case cty.GetAttrStep:
+		return step.Name, true
+	case cty.IndexStep:
+		if step.Key.Type() == cty.String {
+			return step.Key.AsString(), true
+		}
+	}
+	return "", false
+}
+
+// stepIndex returns the non-negative integer of a number index.
+func stepIndex(step cty.PathStep) (int, bool) {
+	istep, ok := step.(cty.IndexStep)
+	if !ok || istep.Key.Type() != cty.Number {
+		return 0, false
+	}
+	bf := istep.Key.AsBigFloat()
+	if !bf.IsInt() || bf.Sign() < 0 {
+		return 0, false
+	}
+	idx, acc := bf.Int64()
+	if acc != 0 || int64(int(idx)) != idx {
+		return 0, false
+	}
+	return int(idx), true
+}
+
+// Get returns the value addressed by the path. The marks of the addressed value are kept.
+func Get(v cty.Value, path cty.Path) (cty.Value, error) {
+	for i, step := range path {
+		var err error
+		v, err = getStep(v, step, path[:i])
+		if err != nil {
+			return cty.NilVal, err
+		}
+	}
+	return v, nil
+}
+
+// Set returns a copy of the value, where the value addressed by the path is replaced by nv, which is converted to
+// the type of the addressed value. A missing map element or set element is added. As the set elements are
+// addressed by value, replacing a set element is equivalent to removing the element and adding nv.
+func Set(v cty.Value, path cty.Path, nv cty.Value) (cty.Value, error) {
+	if len(path) == 0 {
+		return convertTo(nv, v.Type(), path)
+	}
+	return modify(v, nil, path, func(parent cty.Value, step cty.PathStep, at cty.Path) (cty.Value, error) {
+		return replaceStep(parent, step, nv, at, true)
+	})
+}
+
+// Delete returns a copy of the value, where the value addressed by the path is removed. The elements of the lists,
+// the maps and the sets are removed from the collection, while the object attributes are set to null, as the
+// attributes of an object type can't be removed. The tuple elements can't be deleted.
+func Delete(v cty.Value, path cty.Path) (cty.Value, error) {
+	if len(path) == 0 {
+		return cty.NullVal(v.Type()), nil
+	}
+	return modify(v, nil, path, deleteStep)
+}
+
+// modify walks to the parent of the last step of the path, replaces the parent by the result of fn, and rebuilds
+// the ancestors.
+func modify(v cty.Value, at, path cty.Path, fn func(parent cty.Value, step cty.PathStep, at cty.Path) (cty.Value, error)) (cty.Value, error) {
+	if len(path) == 1 {
+		return fn(v, path[0], at)
+	}
+	child, err := getStep(v, path[0], at)
+	if err != nil {
+		return cty.NilVal, err
+	}
+	childPath := append(at.Copy(), path[0])
+	child, err = modify(child, childPath, path[1:], fn)
+	if err != nil {
+		return cty.NilVal, err
+	}
+	return replaceStep(v, path[0], child, at, false)
+}
+
+// collection returns the unmarked value and its marks, given it can be addressed into.
+func collection(v cty.Value, at cty.Path) (cty.Value, cty.ValueMarks, error) {
+	uv, m := v.Unmark()
+	switch {
+	case !uv.IsKnown():
+		return cty.NilVal, nil, at.NewErrorf("can't address into an unknown value")
+	case uv.IsNull():
+		return cty.NilVal, nil, at.NewErrorf("can't address into a null value")
+	}
+	return uv, m, nil
+}
+
+func getStep(v cty.Value, step cty.PathStep, at cty.Path) (cty.Value, error) {
+	uv, m, err := collection(v, at)
+	if err != nil {
+		return cty.NilVal, err
+	}
+	if uv.Type().IsSetType() {
+		istep, ok := step.(cty.IndexStep)
+		if !ok {
+			return cty.NilVal, at.NewErrorf("a set element must be addressed by its value")
+		}
+		elems := uv.AsValueSlice()
+		idx := setElementIndex(elems, istep.Key, uv.Type().ElementType())
+		if idx == -1 {
+			return cty.NilVal, at.NewErrorf("no such set element")
+		}
+		return elems[idx].WithMarks(m), nil
+	}
+	ev, err := step.Apply(uv)
+	if err != nil {
+		return cty.NilVal, at.NewError(err)
+	}
+	return ev.WithMarks(m), nil
+}
+
+// replaceStep replaces the element of the parent addressed by the step with nv. If insert is true, the missing map
+// element or set element is added.
+func replaceStep(parent cty.Value, step cty.PathStep, nv cty.Value, at cty.Path, insert bool) (cty.Value, error) {
+	uv, m, err := collection(parent, at)
+	if err != nil {
+		return cty.NilVal, err
+	}
+	ty := uv.Type()
+	stepPath := append(at.Copy(), step)
+	switch {
+	case ty.IsObjectType():
+		name, ok := stepName(step)
+		if !ok || !ty.HasAttribute(name) {
+			return cty.NilVal, at.NewErrorf("unsupported attribute")
+		}
+		nv, err := convertTo(nv, ty.AttributeType(name), stepPath)
+		if err != nil {
+			return cty.NilVal, err
+		}
+		attrs := uv.AsValueMap()
+		attrs[name] = nv
+		return cty.ObjectVal(attrs).WithMarks(m), nil
+	case ty.IsMapType():
+		name, ok := stepName(step)
+		if !ok {
+			return cty.NilVal, at.NewErrorf("a string key is required")
+		}
+		nv, err := convertTo(nv, ty.ElementType(), stepPath)
+		if err != nil {
+			return cty.NilVal, err
+		}
+		elems := uv.AsValueMap()
+		if _, ok := elems[name]; !ok && !insert {
+			return cty.NilVal, at.NewErrorf("no such key %q", name)
+		}
+		if elems == nil {
+			elems = map[string]cty.Value{}
+		}
+		elems[name] = nv
+		return cty.MapVal(elems).WithMarks(m), nil
+	case ty.IsListType() || ty.IsTupleType():
+		idx, ok := stepIndex(step)
+		elems := uv.AsValueSlice()
+		if !ok || idx >= len(elems) {
+			return cty.NilVal, at.NewErrorf("invalid index, the length is %d", len(elems))
+		}
+		var ety cty.Type
+		if ty.IsListType() {
+			ety = ty.ElementType()
+		} else {
+			ety = ty.TupleElementType(idx)
+		}
+		nv, err := convertTo(nv, ety, stepPath)
+		if err != nil {
+			return cty.NilVal, err
+		}
+		elems[idx] = nv
+		if ty.IsListType() {
+			return cty.ListVal(elems).WithMarks(m), nil
+		}
+		return cty.TupleVal(elems).WithMarks(m), nil
+	case ty.IsSetType():
+		istep, ok := step.(cty.IndexStep)
+		if !ok {
+			return cty.NilVal, at.NewErrorf("a set element must be addressed by its value")
+		}
+		nv, err := convertTo(nv, ty.ElementType(), stepPath)
+		if err != nil {
+			return cty.NilVal, err
+		}
+		elems := uv.AsValueSlice()
+		idx := setElementIndex(elems, istep.Key, ty.ElementType())
+		switch {
+		case idx != -1:
+			elems[idx] = nv
+		case insert:
+			elems = append(elems, nv)
+		default:
+			return cty.NilVal, at.NewErrorf("no such set element")
+		}
+		return cty.SetVal(elems).WithMarks(m), nil
+	default:
+		return cty.NilVal, at.NewErrorf("can't address into a %s value", ty.FriendlyName())
+	}
+}
+
+func deleteStep(parent cty.Value, step cty.PathStep, at cty.Path) (cty.Value, error) {
+	uv, m, err := collection(parent, at)
+	if err != nil {
+		return cty.NilVal, err
+	}
+	ty := uv.Type()
+	switch {
+	case ty.IsObjectType():
+		name, ok := stepName(step)
+		if !ok || !ty.HasAttribute(name) {
+			return cty.NilVal, at.NewErrorf("unsupported attribute")
+		}
+		return replaceStep(parent, step, cty.NullVal(ty.AttributeType(name)), at, false)
+	case ty.IsMapType():
+		name, ok := stepName(step)
+		if !ok {
+			return cty.NilVal, at.NewErrorf("a string key is required")
+		}
+		elems := uv.AsValueMap()
+		if _, ok := elems[name]; !ok {
+			return cty.NilVal, at.NewErrorf("no such key %q", name)
+		}
+		delete(elems, name)
+		if len(elems) == 0 {
+			return cty.MapValEmpty(ty.ElementType()).WithMarks(m), nil
+		}
+		return cty.MapVal(elems).WithMarks(m), nil
+	case ty.IsListType():
+		idx, ok := stepIndex(step)
+		elems := uv.AsValueSlice()
+		if !ok || idx >= len(elems) {
+			return cty.NilVal, at.NewErrorf("invalid index, the length is %d", len(elems))
+		}
+		elems = append(elems[:idx], elems[idx+1:]...)
+		if len(elems) == 0 {
+			return cty.ListValEmpty(ty.ElementType()).WithMarks(m), nil
+		}
+		return cty.ListVal(elems).WithMarks(m), nil
+	case ty.IsSetType():
+		istep, ok := step.(cty.IndexStep)
+		if !ok {
+			return cty.NilVal, at.NewErrorf("a set element must be addressed by its value")
+		}
+		elems := uv.AsValueSlice()
+		idx := setElementIndex(elems, istep.Key, ty.ElementType())
+		if idx == -1 {
+			return cty.NilVal, at.NewErrorf("no such set element")
+		}
+		elems = append(elems[:idx], elems[idx+1:]...)
+		if len(elems) == 0 {
+			return cty.SetValEmpty(ty.ElementType()).WithMarks(m), nil
+		}
+		return cty.SetVal(elems).WithMarks(m), nil
+	case ty.IsTupleType():
+		return cty.NilVal, at.NewErrorf("can't delete a tuple element")
+	default:
+		return cty.NilVal, at.NewErrorf("can't address into a %s value", ty.FriendlyName())
+	}
+}
+
+// setElementIndex returns the index of the set element that equals to the key regardless of the marks, or -1 if
+// not found. The key is converted to the element type beforehand, in case the path is not resolved.
+func setElementIndex(elems []cty.Value, key cty.Value, ety cty.Type) int {
+	key, _ = key.UnmarkDeep()
+	key, err := convert.Convert(key, ety)
+	if err != nil {
+		return -1
+	}
+	for i, elem := range elems {
+		elem, _ = elem.UnmarkDeep()
+		if elem.RawEquals(key) {
+			return i
+		}
+	}
+	return -1
+}
+
+func convertTo(v cty.Value, ty cty.Type, path cty.Path) (cty.Value, error) {
+	cv, err := convert.Convert(v, ty)
+	if err != nil {
+		return cty.NilVal, path.NewError(err)
+	}
+	return cv, nil
+}
@@ -0,0 +1,184 @@
+package valuepath
+
+import (
+	"testing"
+
+	tfjson "github.com/hashicorp/terraform-json"
+	"github.com/magodo/terraform-client-go/tfclient/marks"
+	"github.com/magodo/terraform-client-go/tfclient/typ"
+	"github.com/zclconf/go-cty/cty"
+)
+
+var testSchema = &tfjson.SchemaBlock{
+	Attributes: map[string]*tfjson.SchemaAttribute{
+		"name":  {AttributeType: cty.String, Required: true},
+		"tags":  {AttributeType: cty.Map(cty.String), Optional: true},
+		"ports": {AttributeType: cty.List(cty.Number), Optional: true},
+	},
+	NestedBlocks: map[string]*tfjson.SchemaBlockType{
+		"rule": {
+			NestingMode: tfjson.SchemaNestingModeSet,
+			Block: &tfjson.SchemaBlock{
+				Attributes: map[string]*tfjson.SchemaAttribute{
+					"name":  {AttributeType: cty.String, Required: true},
+					"value": {AttributeType: cty.String, Optional: true},
+				},
+			},
+		},
+	},
+}
+
+func testRule(name, value string) cty.Value {
+	return cty.ObjectVal(map[string]cty.Value{
+		"name":  cty.StringVal(name),
+		"value": cty.StringVal(value),
+	})
+}
+
+func testValue() cty.Value {
+	return cty.ObjectVal(map[string]cty.Value{
+		"name":  cty.StringVal("foo").Mark(marks.Sensitive),
+		"tags":  cty.MapVal(map[string]cty.Value{"env": cty.StringVal("dev")}),
+		"ports": cty.ListVal([]cty.Value{cty.NumberIntVal(80), cty.NumberIntVal(443)}),
+		"rule":  cty.SetVal([]cty.Value{testRule("a", "1"), testRule("b", "2")}),
+	})
+}
+
+func TestParseBlock(t *testing.T) {
+	cases := []struct {
+		input string
+		want  string
+		ty    cty.Type
+		err   string
+	}{
+		{input: "name", want: ".name", ty: cty.String},
+		{input: `tags["env"]`, want: `.tags["env"]`, ty: cty.String},
+		{input: "tags.env", want: `.tags["env"]`, ty: cty.String},
+		{input: "ports[1]", want: ".ports[1]", ty: cty.Number},
+		{input: `rule[{name = "a", value = "1"}].value`, want: ".rule[...].value", ty: cty.String},
+		{input: "foo", err: `: unsupported attribute "foo"`},
+		{input: `ports["a"]`, err: ".ports: a non-negative integer index is required"},
+		{input: "rule[0]", err: ".rule: invalid set element: object required, but have number"},
+		{input: "rule[*].name", err: `parsing path "rule[*].name": only the attribute accesses and the indexes are allowed`},
+	}
+	for _, c := range cases {
+		path, ty, err := ParseBlock(testSchema, c.input)
+		if c.err != "" {
+			if err == nil || typ.FormatError(err) != c.err {
+				t.Errorf("%s: wrong error %v, expect %q", c.input, err, c.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", c.input, err)
+			continue
+		}
+		if got := typ.FormatCtyPath(path); got != c.want {
+			t.Errorf("%s: wrong path %s, expect %s", c.input, got, c.want)
+		}
+		if !ty.Equals(c.ty) {
+			t.Errorf("%s: wrong type %#v", c.input, ty)
+		}
+	}
+}
+
+func mustParse(t *testing.T, s string) cty.Path {
+	t.Helper()
+	path, _, err := ParseBlock(testSchema, s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestGet(t *testing.T) {
+	v := testValue()
+
+	got, err := Get(v, mustParse(t, "name"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !got.RawEquals(cty.StringVal("foo").Mark(marks.Sensitive)) {
+		t.Errorf("wrong value: %#v", got)
+	}
+
+	got, err = Get(v, mustParse(t, `rule[{name = "b", value = "2"}].value`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !got.RawEquals(cty.StringVal("2")) {
+		t.Errorf("wrong value: %#v", got)
+	}
+
+	if _, err := Get(v, mustParse(t, `rule[{name = "c", value = "3"}]`)); err == nil {
+		t.Errorf("expect error for the missing set element")
+	}
+}
+
+func TestSet(t *testing.T) {
+	v := testValue()
+
+	v, err := Set(v, mustParse(t, "ports[0]"), cty.StringVal("8080"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, err = Set(v, mustParse(t, "tags.owner"), cty.StringVal("me"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, err = Set(v, mustParse(t, `rule[{name = "a", value = "1"}].value`), cty.StringVal("10"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, err = Set(v, mustParse(t, `rule[{name = "c", value = "3"}]`), testRule("c", "3"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Set(v, mustParse(t, "ports[5]"), cty.NumberIntVal(1)); err == nil {
+		t.Errorf("expect error for the out of range index")
+	}
+	if _, err := Set(v, mustParse(t, "ports[0]"), cty.StringVal("a")); err == nil {
+		t.Errorf("expect error for the inconvertible value")
+	}
+
+	want := cty.ObjectVal(map[string]cty.Value{
+		"name":  cty.StringVal("foo").Mark(marks.Sensitive),
+		"tags":  cty.MapVal(map[string]cty.Value{"env": cty.StringVal("dev"), "owner": cty.StringVal("me")}),
+		"ports": cty.ListVal([]cty.Value{cty.NumberIntVal(8080), cty.NumberIntVal(443)}),
+		"rule":  cty.SetVal([]cty.Value{testRule("a", "10"), testRule("b", "2"), testRule("c", "3")}),
+	})
+	if !v.RawEquals(want) {
+		t.Errorf("wrong value: %#v", v)
+	}
+}
+
+func TestDelete(t *testing.T) {
+	v := testValue()
+
+	v, err := Delete(v, mustParse(t, "name"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, err = Delete(v, mustParse(t, `tags["env"]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, err = Delete(v, mustParse(t, "ports[0]"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, err = Delete(v, mustParse(t, `rule[{name = "a", value = "1"}]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := cty.ObjectVal(map[string]cty.Value{
+		"name":  cty.NullVal(cty.String),
+		"tags":  cty.MapValEmpty(cty.String),
+		"ports": cty.ListVal([]cty.Value{cty.NumberIntVal(443)}),
+		"rule":  cty.SetVal([]cty.Value{testRule("b", "2")}),
+	})
+	if !v.RawEquals(want) {
+		t.Errorf("wrong value: %#v", v)
+	}
+}
@@ -87,6 +87,9 @@ locals {
     "tfprotov6/internal/toproto/ephemeral_resource.go",
     "tfprotov6/internal/toproto/timestamp.go",
     "tfprotov6/internal/toproto/resource_identity_data.go",
+
+    "tfprotov5/tf5server/server.go",
+    "tfprotov6/tf6server/server.go",
   ])
   terraform-commit = "v1.13.0-alpha20250521"
   terraform = toset([
@@ -95,6 +98,15 @@ locals {
     "internal/configs/configschema/decoder_spec.go",
     "internal/configs/configschema/empty_value.go",
     "internal/configs/configschema/implied_type.go",
+    "internal/configs/configschema/path.go",
+    "internal/configs/configschema/coerce_value.go",
+    "internal/configs/configschema/marks.go",
+    "internal/lang/marks/marks.go",
+    "internal/plans/objchange/objchange.go",
+    "internal/plans/objchange/normalize_obj.go",
+    "internal/command/jsonplan/plan.go",
+    "internal/command/jsonstate/state.go",
+    "internal/genconfig/generate_config.go",
     "internal/plugin/convert/schema.go",
     "internal/plugin/convert/deferred.go",
     "internal/plugin/convert/functions.go",